@@ -0,0 +1,298 @@
+package automation
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/contre95/soulsolid/src/features/config"
+	"github.com/contre95/soulsolid/src/features/jobs"
+	"github.com/contre95/soulsolid/src/music"
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/vm"
+)
+
+// Ensure Service can observe finished jobs
+var _ jobs.JobObserver = (*Service)(nil)
+
+// Events a rule can be attached to.
+const (
+	EventImport      = "import"       // a track is about to be imported, tags can still be changed
+	EventJobFinished = "job_finished" // a job reached a terminal state
+)
+
+// Notifier delivers rule notifications, e.g. to Telegram chats.
+type Notifier interface {
+	Notify(message string)
+}
+
+// Service evaluates the user's automation rules. Rules are written with expr
+// (https://expr-lang.org) and read from the config on every event, so edits apply without a restart.
+type Service struct {
+	config    *config.Manager
+	notifiers []Notifier
+	mu        sync.Mutex
+	programs  map[string]*vm.Program // compiled expressions by event and source
+}
+
+// NewService creates a new automation service and reports rules that don't compile.
+func NewService(cfg *config.Manager) *Service {
+	s := &Service{config: cfg, programs: make(map[string]*vm.Program)}
+	for _, err := range s.Validate() {
+		slog.Warn("Invalid automation rule", "error", err)
+	}
+	return s
+}
+
+// AddNotifier registers a notifier used by rules with a notify expression.
+func (s *Service) AddNotifier(n Notifier) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.notifiers = append(s.notifiers, n)
+}
+
+// Validate compiles every configured rule and returns the errors found.
+func (s *Service) Validate() []error {
+	var errs []error
+	for _, rule := range s.config.Get().Automation.Rules {
+		var env map[string]any
+		switch rule.Event {
+		case EventImport:
+			env = trackEnv(&music.Track{})
+		case EventJobFinished:
+			env = jobEnv(&music.Job{}, 0)
+			if len(rule.Set) > 0 {
+				errs = append(errs, fmt.Errorf("rule %q: set is only supported on import", rule.Name))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("rule %q: unknown event %q", rule.Name, rule.Event))
+			continue
+		}
+		sources := []string{rule.When, rule.Notify}
+		for field, source := range rule.Set {
+			if _, ok := settableFields[field]; !ok {
+				errs = append(errs, fmt.Errorf("rule %q: field %q can't be set", rule.Name, field))
+			}
+			sources = append(sources, source)
+		}
+		for _, source := range sources {
+			if source == "" {
+				continue
+			}
+			if _, err := s.program(rule.Event, source, env); err != nil {
+				errs = append(errs, fmt.Errorf("rule %q: %w", rule.Name, err))
+			}
+		}
+	}
+	return errs
+}
+
+// ApplyImportRules runs the import rules against a track before it's imported,
+// setting the tags of every matching rule. It returns the names of the rules that matched.
+func (s *Service) ApplyImportRules(track *music.Track, logger *slog.Logger) []string {
+	var matched []string
+	for _, rule := range s.rules(EventImport) {
+		env := trackEnv(track)
+		ok, err := s.match(rule, env)
+		if err != nil {
+			logger.Warn("Automation rule failed", "rule", rule.Name, "error", err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		for field, source := range rule.Set {
+			value, err := s.eval(EventImport, source, env)
+			if err != nil {
+				logger.Warn("Automation rule failed to set field", "rule", rule.Name, "field", field, "error", err)
+				continue
+			}
+			if err := setField(track, field, value); err != nil {
+				logger.Warn("Automation rule failed to set field", "rule", rule.Name, "field", field, "error", err)
+			}
+		}
+		s.notify(rule, EventImport, trackEnv(track), logger)
+		logger.Info("Automation rule applied", "rule", rule.Name, "title", track.Title, "color", "violet")
+		matched = append(matched, rule.Name)
+	}
+	return matched
+}
+
+// JobFinished runs the job_finished rules for a finished job.
+func (s *Service) JobFinished(job *music.Job, duration time.Duration) {
+	logger := job.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	env := jobEnv(job, duration)
+	for _, rule := range s.rules(EventJobFinished) {
+		ok, err := s.match(rule, env)
+		if err != nil {
+			logger.Warn("Automation rule failed", "rule", rule.Name, "error", err)
+			continue
+		}
+		if ok {
+			s.notify(rule, EventJobFinished, env, logger)
+		}
+	}
+}
+
+func (s *Service) rules(event string) []config.AutomationRule {
+	cfg := s.config.Get().Automation
+	if !cfg.Enabled {
+		return nil
+	}
+	var rules []config.AutomationRule
+	for _, rule := range cfg.Rules {
+		if rule.Event == event {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+func (s *Service) match(rule config.AutomationRule, env map[string]any) (bool, error) {
+	if strings.TrimSpace(rule.When) == "" {
+		return true, nil
+	}
+	out, err := s.eval(rule.Event, rule.When, env)
+	if err != nil {
+		return false, err
+	}
+	ok, isBool := out.(bool)
+	if !isBool {
+		return false, fmt.Errorf("when must be a boolean expression, got %T", out)
+	}
+	return ok, nil
+}
+
+func (s *Service) notify(rule config.AutomationRule, event string, env map[string]any, logger *slog.Logger) {
+	if rule.Notify == "" {
+		return
+	}
+	out, err := s.eval(event, rule.Notify, env)
+	if err != nil {
+		logger.Warn("Automation rule failed to build notification", "rule", rule.Name, "error", err)
+		return
+	}
+	message := fmt.Sprint(out)
+	logger.Info("Automation notification", "rule", rule.Name, "message", message, "color", "cyan")
+	s.mu.Lock()
+	notifiers := append([]Notifier(nil), s.notifiers...)
+	s.mu.Unlock()
+	for _, n := range notifiers {
+		n.Notify(message)
+	}
+}
+
+func (s *Service) eval(event, source string, env map[string]any) (any, error) {
+	program, err := s.program(event, source, env)
+	if err != nil {
+		return nil, err
+	}
+	return expr.Run(program, env)
+}
+
+// program returns the compiled expression, compiling it on first use.
+func (s *Service) program(event, source string, env map[string]any) (*vm.Program, error) {
+	key := event + "\x00" + source
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if program, ok := s.programs[key]; ok {
+		return program, nil
+	}
+	program, err := expr.Compile(source, expr.Env(env))
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile %q: %w", source, err)
+	}
+	s.programs[key] = program
+	return program, nil
+}
+
+// trackEnv exposes a track's tags to import rules.
+func trackEnv(track *music.Track) map[string]any {
+	artists := []string{}
+	for _, ar := range track.Artists {
+		if ar.Artist != nil {
+			artists = append(artists, ar.Artist.Name)
+		}
+	}
+	artist := ""
+	if len(artists) > 0 {
+		artist = artists[0]
+	}
+	album, albumArtist := "", ""
+	if track.Album != nil {
+		album = track.Album.Title
+		if len(track.Album.Artists) > 0 && track.Album.Artists[0].Artist != nil {
+			albumArtist = track.Album.Artists[0].Artist.Name
+		}
+	}
+	return map[string]any{
+		"title":       track.Title,
+		"artist":      artist,
+		"artists":     artists,
+		"album":       album,
+		"albumArtist": albumArtist,
+		"genre":       track.Metadata.Genre,
+		"year":        track.Metadata.Year,
+		"composer":    track.Metadata.Composer,
+		"isrc":        track.ISRC,
+		"format":      track.Format,
+		"bitrate":     track.Bitrate,
+		"explicit":    track.ExplicitContent,
+		"path":        track.Path,
+		"source":      track.MetadataSource.Source,
+	}
+}
+
+// jobEnv exposes a finished job to job_finished rules.
+func jobEnv(job *music.Job, duration time.Duration) map[string]any {
+	metadata := job.Metadata
+	if metadata == nil {
+		metadata = map[string]any{}
+	}
+	return map[string]any{
+		"type":     job.Type,
+		"name":     job.Name,
+		"status":   string(job.Status),
+		"message":  job.Message,
+		"error":    job.Error,
+		"duration": duration.Round(time.Second).String(),
+		"seconds":  duration.Seconds(),
+		"metadata": metadata,
+	}
+}
+
+// settableFields are the tags import rules are allowed to set.
+var settableFields = map[string]struct{}{
+	"title": {}, "album": {}, "genre": {}, "year": {}, "composer": {},
+}
+
+func setField(track *music.Track, field string, value any) error {
+	switch field {
+	case "year":
+		year, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("year must be an integer, got %T", value)
+		}
+		track.Metadata.Year = year
+		return nil
+	case "title":
+		track.Title = fmt.Sprint(value)
+	case "genre":
+		track.Metadata.Genre = fmt.Sprint(value)
+	case "composer":
+		track.Metadata.Composer = fmt.Sprint(value)
+	case "album":
+		if track.Album == nil {
+			track.Album = &music.Album{}
+		}
+		track.Album.Title = fmt.Sprint(value)
+	default:
+		return fmt.Errorf("field %q can't be set", field)
+	}
+	return nil
+}
@@ -2,22 +2,119 @@ package config
 
 // Config holds the application configuration.
 type Config struct {
-	LibraryPath  string      `yaml:"libraryPath" validate:"required"`
-	DownloadPath string      `yaml:"downloadPath" validate:"required"`
-	Telegram     Telegram    `yaml:"telegram"`
-	Logger       Logger      `yaml:"logger"`
-	Downloaders  Downloaders `yaml:"downloaders"`
-	Server       Server      `yaml:"server"`
-	Database     Database    `yaml:"database"`
-	Import       Import      `yaml:"import"`
-	Metadata     Metadata    `yaml:"metadata"`
-	Lyrics       Lyrics      `yaml:"lyrics"`
-	Jobs         Jobs        `yaml:"jobs"`
+	LibraryPath   string        `yaml:"libraryPath" validate:"required"`
+	DownloadPath  string        `yaml:"downloadPath" validate:"required"`
+	Telegram      Telegram      `yaml:"telegram"`
+	Logger        Logger        `yaml:"logger"`
+	Downloaders   Downloaders   `yaml:"downloaders"`
+	Server        Server        `yaml:"server"`
+	Database      Database      `yaml:"database"`
+	Import        Import        `yaml:"import"`
+	Metadata      Metadata      `yaml:"metadata"`
+	Lyrics        Lyrics        `yaml:"lyrics"`
+	Jobs          Jobs          `yaml:"jobs"`
+	Diagnostics   Diagnostics   `yaml:"diagnostics"`
+	Automation    Automation    `yaml:"automation"`
+	Storage       Storage       `yaml:"storage"`
+	Metrics       Metrics       `yaml:"metrics"`
+	Verification  Verification  `yaml:"verification"`
+	Email         Email         `yaml:"email"`
+	Federation    Federation    `yaml:"federation"`
+	RemoteControl RemoteControl `yaml:"remote_control"`
 }
+
+// RemoteControl is the token-authenticated API for scripts and home automation.
+type RemoteControl struct {
+	Token string `yaml:"token"` // bearer token of the API, empty disables it
+}
+
+// Federation shares the library read-only with other Soulsolid instances and reads theirs.
+type Federation struct {
+	APIKey  string   `yaml:"api_key"` // key other instances send to read this library, empty disables sharing
+	Remotes []Remote `yaml:"remotes"`
+}
+
+// Remote is another Soulsolid instance whose library is searched, streamed and pulled from.
+type Remote struct {
+	Name   string `yaml:"name"`
+	URL    string `yaml:"url"`
+	APIKey string `yaml:"api_key"` // the api_key of the remote instance
+}
+
+// Email sends notifications and digests over SMTP.
+type Email struct {
+	Enabled  bool     `yaml:"enabled"`
+	Host     string   `yaml:"host"`
+	Port     int      `yaml:"port"` // 465 uses implicit TLS, any other port STARTTLS when the server offers it
+	Username string   `yaml:"username"`
+	Password string   `yaml:"password"`
+	From     string   `yaml:"from"`
+	To       []string `yaml:"to"`
+	Instant  bool     `yaml:"instant"` // email every notification as it happens, like the Telegram bot
+	Digest   Digest   `yaml:"digest"`
+}
+
+// Digest is a scheduled email summarizing new music, failed jobs and pending reviews.
+type Digest struct {
+	Enabled   bool   `yaml:"enabled"`
+	Frequency string `yaml:"frequency"` // "daily" or "weekly" (sent on Mondays)
+	Hour      int    `yaml:"hour"`      // local hour the digest is sent at, 0-23
+}
+
+// Storage holds the configuration of the disk usage forecast of the library.
+type Storage struct {
+	AlertDays int `yaml:"alert_days"` // notify when the disk is forecast to fill within this many days, 0 disables
+}
+
+// Metrics holds the schedule of the full recomputation of the dashboard statistics. In between,
+// the counts and distributions follow every track, album and artist added, edited or deleted.
+type Metrics struct {
+	RecomputeHour int `yaml:"recompute_hour" validate:"min=-1,max=23"` // local hour of the nightly recomputation, -1 disables it
+}
+
+// Verification holds the schedule of the file verification, which re-reads the library files
+// the longest not verified first to catch missing and damaged ones.
+type Verification struct {
+	Hour        int `yaml:"hour" validate:"min=-1,max=23"` // local hour of the nightly run, -1 disables it
+	AfterMonths int `yaml:"after_months" validate:"min=1"` // a track is due once not verified for this many months
+	BatchSize   int `yaml:"batch_size" validate:"min=0"`   // most tracks verified per nightly run, 0 verifies every due track
+}
+
+// Diagnostics holds the configuration for the opt-in local usage recorder. Nothing is
+// recorded unless Enabled is set, and the recorded numbers never leave the instance.
+type Diagnostics struct {
+	Enabled bool   `yaml:"enabled"`
+	Path    string `yaml:"path"` // JSON file the usage counters are persisted to
+}
+
+// Automation holds user rules evaluated on library events (see docs/automation.md).
+type Automation struct {
+	Enabled bool             `yaml:"enabled"`
+	Rules   []AutomationRule `yaml:"rules"`
+}
+
+// AutomationRule runs its actions when Event fires and the When expression is true.
+type AutomationRule struct {
+	Name   string            `yaml:"name"`
+	Event  string            `yaml:"event"`            // "import" or "job_finished"
+	When   string            `yaml:"when"`             // Expression, empty always matches
+	Set    map[string]string `yaml:"set,omitempty"`    // Import only: tag -> expression
+	Notify string            `yaml:"notify,omitempty"` // Expression producing a notification message
+}
+
 type Jobs struct {
 	Log      bool          `yaml:"log"`
 	LogPath  string        `yaml:"log_path"`
 	Webhooks WebhookConfig `yaml:"webhooks"`
+	Offline  Offline       `yaml:"offline"`
+}
+
+// Offline parks the jobs failing for lack of connectivity until a periodic check reaches CheckURL again.
+type Offline struct {
+	Enabled       bool   `yaml:"enabled"`
+	CheckURL      string `yaml:"check_url"`
+	CheckInterval int    `yaml:"check_interval"` // seconds between connectivity checks
+	MaxRetries    int    `yaml:"max_retries"`    // times a job is resumed before it fails, 0 for no limit
 }
 
 type WebhookConfig struct {
@@ -29,10 +126,43 @@ type WebhookConfig struct {
 type Import struct {
 	Move                 bool                 `yaml:"move"` // If not copies
 	AlwaysQueue          bool                 `yaml:"always_queue"`
-	Duplicates           string               `yaml:"duplicates"` // "replace", "skip", "queue"
+	Duplicates           string               `yaml:"duplicates"`  // "replace", "skip", "queue"
+	PCMToFLAC            bool                 `yaml:"pcm_to_flac"` // convert WAV and AIFF files to FLAC as they enter the library, needs ffmpeg
+	Convert              Convert              `yaml:"convert"`
 	PathOptions          Paths                `yaml:"paths"`
 	AutoStartWatcher     bool                 `yaml:"auto_start_watcher"`
 	AllowMissingMetadata AllowMissingMetadata `yaml:"allow_missing_metadata"`
+	QueueAging           QueueAging           `yaml:"queue_aging"`
+	TrashPath            string               `yaml:"trash_path"` // Rolled back imports are moved here
+	Retention            Retention            `yaml:"retention"`
+}
+
+// Convert transcodes imported files of unwanted formats with ffmpeg before their tags are read,
+// keeping the tags. Formats are file extensions without the dot.
+type Convert struct {
+	Target            string   `yaml:"target" validate:"omitempty,oneof=flac mp3"` // format files are converted to, empty disables conversion
+	Formats           []string `yaml:"formats"`                                    // always converted, e.g. [wma, ape]
+	LowBitrateFormats []string `yaml:"low_bitrate_formats"`                        // converted only below MinBitrate, e.g. [ogg]
+	MinBitrate        int      `yaml:"min_bitrate"`                                // kbps
+}
+
+// Retention keeps the download path from growing forever. The policy runs daily and from the
+// Importing section; every limit is disabled with 0.
+type Retention struct {
+	Enabled           bool `yaml:"enabled"`
+	ImportedAfterDays int  `yaml:"imported_after_days"` // delete files already in the library older than this
+	StaleAfterDays    int  `yaml:"stale_after_days"`    // delete any file older than this
+	MaxSizeGB         int  `yaml:"max_size_gb"`         // delete the oldest files until the folder is under this size
+	MinFreeGB         int  `yaml:"min_free_gb"`         // delete the oldest files until the disk has this much free space
+}
+
+// QueueAging keeps the review queue from growing unbounded. Items older than AlertAfterDays
+// are flagged in the UI, items older than ExpireAfterDays are resolved with ExpireAction.
+// A value of 0 disables the alert or the expiry.
+type QueueAging struct {
+	AlertAfterDays  int    `yaml:"alert_after_days"`
+	ExpireAfterDays int    `yaml:"expire_after_days"`
+	ExpireAction    string `yaml:"expire_action"` // "skip" or "import"
 }
 
 // AllowMissingMetadata controls, per field, whether tracks missing that metadata field may
@@ -53,17 +183,41 @@ type Paths struct {
 	AlbumEP         string `yaml:"album:ep"`
 	DefaultPath     string `yaml:"default_path"`
 	Fat32Safe       bool   `yaml:"fat32_safe"`
+	WindowsSafe     bool   `yaml:"windows_safe"` // reserved names, trailing dots and MAX_PATH, keeping the casing
 }
 
 // Database holds the configuration for the database
 type Database struct {
-	Path string `yaml:"path" validate:"required"`
+	Path    string  `yaml:"path" validate:"required"`
+	Sorting Sorting `yaml:"sorting"`
+}
+
+// Sorting orders artist, album and track names by the collation of a locale rather than byte by
+// byte, which breaks for non-Latin scripts. Changing it rebuilds the stored sort keys at startup.
+type Sorting struct {
+	Locale        string `yaml:"locale" validate:"omitempty,bcp47_language_tag"` // e.g. "ja", "ru" or "zh-u-co-stroke", empty for the root collation
+	Transliterate bool   `yaml:"transliterate"`                                  // sort names by their Latin transliteration, among the Latin names
 }
 
 // Server hold the configuration for the Fiber server Config
 type Server struct {
 	PrintRoutes bool   `yaml:"show_routes"`
 	Port        uint32 `yaml:"port"`
+	PublicURL   string `yaml:"public_url"` // used in links sent outside of the UI, e.g. notifications
+	Guest       Guest  `yaml:"guest"`
+	// Admins are the users, as named by the auth proxy or by their client IP without one, who
+	// change the config directly. The changes of other users wait for an admin's approval.
+	// Empty makes everyone an admin.
+	Admins []string `yaml:"admins"`
+	// TrustedProxies are the IPs or CIDR ranges of the reverse proxies whose user headers name
+	// the requesting user. Without them users are named by their client IP. Applied on restart.
+	TrustedProxies []string `yaml:"trusted_proxies"`
+}
+
+// Guest holds the configuration of the read-only guest mode, served to requests for Host
+type Guest struct {
+	Enabled bool   `yaml:"enabled"`
+	Host    string `yaml:"host"`
 }
 
 // Logger holds the configuration for the app logging
@@ -79,23 +233,52 @@ type Telegram struct {
 	Token        string   `yaml:"token"`
 	AllowedUsers []string `yaml:"allowedUsers"`
 	BotHandle    string   `yaml:"bot_handle"`
+	Admins       []string `yaml:"admins"` // allowed users who approve the config changes proposed by non-admins
 }
 
 // Downloaders holds the configuration for the various downloaders.
 type Downloaders struct {
-	Plugins []PluginConfig `yaml:"plugins"`
-	Artwork Artwork        `yaml:"artwork"`
+	Plugins  []PluginConfig `yaml:"plugins"`
+	Artwork  Artwork        `yaml:"artwork"`
+	Fallback Fallback       `yaml:"fallback"`
+}
+
+// Fallback makes a search that finds nothing on the selected downloader try the other ones.
+type Fallback struct {
+	Enabled bool     `yaml:"enabled"`
+	Order   []string `yaml:"order"` // downloader names tried in turn, empty for the order of the plugins
 }
 
 // Metadata holds the configuration for metadata tagging providers
 type Metadata struct {
-	Providers map[string]Provider `yaml:"providers"`
+	Providers         map[string]Provider `yaml:"providers"`
+	Normalization     Normalization       `yaml:"normalization"`
+	ReleasePreference ReleasePreference   `yaml:"release_preference"`
+	CustomFields      []string            `yaml:"custom_fields"` // track attributes embedded in the files as TXXX/Vorbis fields and read back on import
+	NotesComment      bool                `yaml:"notes_comment"` // write the track notes to the COMMENT tag and read them back on import
+}
+
+// ReleasePreference ranks provider matches by the release they come from, e.g. to prefer
+// the original US edition over a Japanese promo.
+type ReleasePreference struct {
+	Countries []string `yaml:"countries"` // ISO 3166 codes, most preferred first, e.g. ["US", "XW"]
+	Status    string   `yaml:"status"`    // "", "official" or "promotion"
+}
+
+// Normalization holds the cleanup rules for tag text, applied on import and by the normalize job
+type Normalization struct {
+	OnImport       bool   `yaml:"on_import"`
+	Casing         string `yaml:"casing"` // "", "title" or "sentence"
+	TrimWhitespace bool   `yaml:"trim_whitespace"`
+	Feat           string `yaml:"feat"`   // "" keeps them as-is, else the form "ft."/"featuring" variants are rewritten to, e.g. "feat."
+	Quotes         string `yaml:"quotes"` // "", "straight" or "smart"
 }
 
 // Provider holds configuration for individual tagging providers
 type Provider struct {
 	Enabled bool    `yaml:"enabled"`
 	Secret  *string `yaml:"secret,omitempty"`
+	URL     string  `yaml:"url,omitempty"` // Alternative server, e.g. a local MusicBrainz mirror
 }
 
 // Lyrics holds the configuration for lyrics providers
@@ -112,13 +295,27 @@ type LyricsProvider struct {
 // Artwork holds configuration for artwork handling
 type Artwork struct {
 	Embedded EmbeddedArtwork `yaml:"embedded"`
+	Upgrade  ArtworkUpgrade  `yaml:"upgrade"`
+}
+
+// ArtworkUpgrade holds the configuration of the job replacing low resolution album covers
+type ArtworkUpgrade struct {
+	MinSize int `yaml:"min_size"` // covers whose shorter side is below this many pixels are upgraded, 0 uses 600
 }
 
 // EmbeddedArtwork holds configuration for embedded artwork
 type EmbeddedArtwork struct {
-	Enabled bool `yaml:"enabled"`
-	Size    int  `yaml:"size"`
-	Quality int  `yaml:"quality"`
+	Enabled bool                     `yaml:"enabled"`
+	Size    int                      `yaml:"size"`
+	Quality int                      `yaml:"quality"`
+	Formats map[string]FormatArtwork `yaml:"formats,omitempty"` // per-format overrides keyed by extension, e.g. "flac"
+}
+
+// FormatArtwork overrides the embedded artwork settings for the files of one format
+type FormatArtwork struct {
+	Skip    bool `yaml:"skip"`    // don't embed artwork, e.g. for archives relying on folder.jpg
+	Size    int  `yaml:"size"`    // max width/height in pixels, 0 keeps the global size
+	Quality int  `yaml:"quality"` // JPEG quality, 0 keeps the global quality
 }
 
 // PluginConfig holds configuration for a plugin downloader
@@ -23,6 +23,9 @@ var defaultConfig = Config{
 				Size:    1000,
 				Quality: 85,
 			},
+			Upgrade: ArtworkUpgrade{
+				MinSize: 600,
+			},
 		},
 	},
 	Server: Server{
@@ -37,6 +40,7 @@ var defaultConfig = Config{
 		AlwaysQueue:      false,
 		Duplicates:       "queue",
 		AutoStartWatcher: false,
+		TrashPath:        "./trash",
 		AllowMissingMetadata: AllowMissingMetadata{
 			Artist: false,
 			Album:  false,
@@ -44,6 +48,21 @@ var defaultConfig = Config{
 			Year:   true,
 			Genre:  true,
 		},
+		QueueAging: QueueAging{
+			AlertAfterDays:  7,
+			ExpireAfterDays: 0,
+			ExpireAction:    "skip",
+		},
+		Convert: Convert{
+			MinBitrate: 160,
+		},
+		Retention: Retention{
+			Enabled:           false,
+			ImportedAfterDays: 7,
+			StaleAfterDays:    0,
+			MaxSizeGB:         0,
+			MinFreeGB:         0,
+		},
 		PathOptions: Paths{
 			Compilations:    "%asciify{$genre}/%asciify{$format}/%asciify{$albumartist}/%asciify{$album} (%if{$original_year,$original_year,$year})/%asciify{$track $title}",
 			AlbumSoundtrack: "%asciify{$genre}/%asciify{$format}/%asciify{$albumartist}/%asciify{$album} [OST] (%if{$original_year,$original_year,$year})/%asciify{$track $title}",
@@ -69,6 +88,14 @@ var defaultConfig = Config{
 				Secret:  nil,
 			},
 		},
+		Normalization: Normalization{
+			TrimWhitespace: true,
+			Feat:           "feat.",
+		},
+		ReleasePreference: ReleasePreference{
+			Countries: []string{},
+			Status:    "official",
+		},
 	},
 	Lyrics: Lyrics{
 		Providers: map[string]LyricsProvider{
@@ -86,5 +113,41 @@ var defaultConfig = Config{
 			JobTypes: []string{},
 			Command:  "",
 		},
+		Offline: Offline{
+			Enabled:       false, // opt-in, the check contacts CheckURL
+			CheckURL:      "http://connectivitycheck.gstatic.com/generate_204",
+			CheckInterval: 60,
+			MaxRetries:    10,
+		},
+	},
+	Diagnostics: Diagnostics{
+		Enabled: false,
+		Path:    "./logs/usage.json",
+	},
+	Automation: Automation{
+		Enabled: false,
+		Rules:   []AutomationRule{},
+	},
+	Storage: Storage{
+		AlertDays: 30,
+	},
+	Metrics: Metrics{
+		RecomputeHour: 3,
+	},
+	Verification: Verification{
+		Hour:        4,
+		AfterMonths: 12,
+		BatchSize:   2000,
+	},
+	Email: Email{
+		Enabled: false,
+		Port:    587,
+		To:      []string{},
+		Instant: false,
+		Digest: Digest{
+			Enabled:   false,
+			Frequency: "daily",
+			Hour:      8,
+		},
 	},
 }
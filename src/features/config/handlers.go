@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log/slog"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/contre95/soulsolid/src/features/hosting/respond"
@@ -42,9 +43,17 @@ func (h *Handler) UpdateSettings(c *fiber.Ctx) error {
 		Database:     currentConfig.Database, // Preserve database settings
 		Import: Import{
 			AutoStartWatcher: currentConfig.Import.AutoStartWatcher,
+			TrashPath:        currentConfig.Import.TrashPath,
 			Move:             c.FormValue("import.move") == "true",
 			AlwaysQueue:      c.FormValue("import.always_queue") == "true",
 			Duplicates:       c.FormValue("import.duplicates"),
+			PCMToFLAC:        c.FormValue("import.pcm_to_flac") == "true",
+			Convert: Convert{
+				Target:            c.FormValue("import.convert.target"),
+				Formats:           parseStringSlice(c.FormValue("import.convert.formats")),
+				LowBitrateFormats: parseStringSlice(c.FormValue("import.convert.low_bitrate_formats")),
+				MinBitrate:        parseNonNegativeInt(c.FormValue("import.convert.min_bitrate")),
+			},
 			AllowMissingMetadata: AllowMissingMetadata{
 				Artist: c.FormValue("import.allow_missing_metadata.artist") == "true",
 				Album:  c.FormValue("import.allow_missing_metadata.album") == "true",
@@ -52,6 +61,18 @@ func (h *Handler) UpdateSettings(c *fiber.Ctx) error {
 				Year:   c.FormValue("import.allow_missing_metadata.year") == "true",
 				Genre:  c.FormValue("import.allow_missing_metadata.genre") == "true",
 			},
+			QueueAging: QueueAging{
+				AlertAfterDays:  parseNonNegativeInt(c.FormValue("import.queue_aging.alert_after_days")),
+				ExpireAfterDays: parseNonNegativeInt(c.FormValue("import.queue_aging.expire_after_days")),
+				ExpireAction:    c.FormValue("import.queue_aging.expire_action"),
+			},
+			Retention: Retention{
+				Enabled:           c.FormValue("import.retention.enabled") == "true",
+				ImportedAfterDays: parseNonNegativeInt(c.FormValue("import.retention.imported_after_days")),
+				StaleAfterDays:    parseNonNegativeInt(c.FormValue("import.retention.stale_after_days")),
+				MaxSizeGB:         parseNonNegativeInt(c.FormValue("import.retention.max_size_gb")),
+				MinFreeGB:         parseNonNegativeInt(c.FormValue("import.retention.min_free_gb")),
+			},
 			PathOptions: Paths{
 				DefaultPath:     c.FormValue("import.paths.default_path"),
 				Compilations:    c.FormValue("import.paths.compilations"),
@@ -59,6 +80,7 @@ func (h *Handler) UpdateSettings(c *fiber.Ctx) error {
 				AlbumSingle:     c.FormValue("import.paths.album:single"),
 				AlbumEP:         c.FormValue("import.paths.album:ep"),
 				Fat32Safe:       c.FormValue("import.paths.fat32_safe") == "true",
+				WindowsSafe:     c.FormValue("import.paths.windows_safe") == "true",
 			},
 		},
 		Telegram: Telegram{
@@ -66,15 +88,21 @@ func (h *Handler) UpdateSettings(c *fiber.Ctx) error {
 			Token:        c.FormValue("telegram.token"),
 			AllowedUsers: parseStringSlice(c.FormValue("telegram.allowedUsers")),
 			BotHandle:    c.FormValue("telegram.bot_handle"),
+			Admins:       currentConfig.Telegram.Admins, // Roles are edited in the YAML file
 		},
 		Downloaders: Downloaders{
 			Plugins: currentConfig.Downloaders.Plugins, // Preserve plugins
 			Artwork: currentConfig.Downloaders.Artwork, // Preserve artwork settings
+			Fallback: Fallback{
+				Enabled: c.FormValue("downloaders.fallback.enabled") == "true",
+				Order:   parseStringSlice(c.FormValue("downloaders.fallback.order")),
+			},
 		},
 		Metadata: Metadata{
 			Providers: map[string]Provider{
 				"musicbrainz": {
 					Enabled: c.FormValue("metadata.providers.musicbrainz.enabled") == "true",
+					URL:     c.FormValue("metadata.providers.musicbrainz.url"),
 				},
 				"discogs": {
 					Enabled: c.FormValue("metadata.providers.discogs.enabled") == "true",
@@ -100,12 +128,30 @@ func (h *Handler) UpdateSettings(c *fiber.Ctx) error {
 					}(),
 				},
 			},
+			Normalization: Normalization{
+				OnImport:       c.FormValue("metadata.normalization.on_import") == "true",
+				Casing:         c.FormValue("metadata.normalization.casing"),
+				TrimWhitespace: c.FormValue("metadata.normalization.trim_whitespace") == "true",
+				Feat:           strings.TrimSpace(c.FormValue("metadata.normalization.feat")),
+				Quotes:         c.FormValue("metadata.normalization.quotes"),
+			},
+			ReleasePreference: ReleasePreference{
+				Countries: parseStringSlice(strings.ToUpper(c.FormValue("metadata.release_preference.countries"))),
+				Status:    c.FormValue("metadata.release_preference.status"),
+			},
+			CustomFields: parseStringSlice(c.FormValue("metadata.custom_fields")),
+			NotesComment: c.FormValue("metadata.notes_comment") == "true",
 		},
 		Lyrics: currentConfig.Lyrics,
 		// Preserve server settings from current config, no sense to be changed on runtime
 		Server: Server{
 			Port:        currentConfig.Server.Port,
 			PrintRoutes: currentConfig.Server.PrintRoutes,
+			PublicURL:   currentConfig.Server.PublicURL,
+			Guest:       currentConfig.Server.Guest,
+			Admins:      currentConfig.Server.Admins, // Roles are edited in the YAML file
+			// Trusted proxies decide who is an admin, so they're edited in the YAML file too
+			TrustedProxies: currentConfig.Server.TrustedProxies,
 		},
 		Logger: Logger{
 			Enabled:   c.FormValue("logger.enabled") == "true",
@@ -117,13 +163,43 @@ func (h *Handler) UpdateSettings(c *fiber.Ctx) error {
 			Log:      c.FormValue("jobs.log") == "true",
 			LogPath:  c.FormValue("jobs.log_path"),
 			Webhooks: currentConfig.Jobs.Webhooks,
+			Offline: Offline{
+				Enabled:       c.FormValue("jobs.offline.enabled") == "true",
+				CheckURL:      currentConfig.Jobs.Offline.CheckURL,
+				CheckInterval: currentConfig.Jobs.Offline.CheckInterval,
+				MaxRetries:    currentConfig.Jobs.Offline.MaxRetries,
+			},
 		},
+		Diagnostics: Diagnostics{
+			Enabled: c.FormValue("diagnostics.enabled") == "true",
+			Path:    currentConfig.Diagnostics.Path,
+		},
+		Automation: currentConfig.Automation, // Rules are edited in the YAML file
+		Storage: Storage{
+			AlertDays: parseNonNegativeInt(c.FormValue("storage.alert_days")),
+		},
+		Metrics: Metrics{
+			RecomputeHour: parseRecomputeHour(c.FormValue("metrics.recompute_hour")),
+		},
+		Verification: Verification{
+			Hour:        parseRecomputeHour(c.FormValue("verification.hour")),
+			AfterMonths: max(1, parseNonNegativeInt(c.FormValue("verification.after_months"))),
+			BatchSize:   parseNonNegativeInt(c.FormValue("verification.batch_size")),
+		},
+		Email:         currentConfig.Email,         // SMTP credentials are edited in the YAML file
+		Federation:    currentConfig.Federation,    // Keys and remotes are edited in the YAML file
+		RemoteControl: currentConfig.RemoteControl, // The token is edited in the YAML file
+	}
+
+	// Changes of non-admins wait for an admin's approval
+	if user := RequestUser(c); !h.configManager.IsAdmin(user) {
+		return h.propose(c, newConfig, user)
 	}
 
 	// Update the configuration
 	h.configManager.Update(newConfig)
 	slog.Info("Configuration updated in memory")
-	if err := h.configManager.Save(); err != nil {
+	if err := h.configManager.Save(RequestUser(c)); err != nil {
 		slog.Warn("failed to save config to file (this is normal in containerized environments)", "error", err)
 	} else {
 		slog.Info("Configuration saved to file successfully")
@@ -131,6 +207,113 @@ func (h *Handler) UpdateSettings(c *fiber.Ctx) error {
 	return respond.ToastOk(c, "Configuration updated successfully!")
 }
 
+// RenderHistorySection renders the config change history.
+func (h *Handler) RenderHistorySection(c *fiber.Ctx) error {
+	slog.Debug("RenderHistorySection handler called")
+	return respond.Section(c, "config_history", fiber.Map{"Title": "Config History"})
+}
+
+// GetHistory returns the config change history, newest first.
+func (h *Handler) GetHistory(c *fiber.Ctx) error {
+	history, err := h.configManager.History()
+	if err != nil {
+		slog.Error("Failed to read config history", "error", err)
+		return respond.ToastErr(c, fiber.StatusInternalServerError, "Failed to read config history")
+	}
+	return respond.Partial(c, "config/history", fiber.Map{"History": history})
+}
+
+// RollbackConfig restores the config as it was before a change in the history.
+func (h *Handler) RollbackConfig(c *fiber.Ctx) error {
+	id := c.Params("id")
+	slog.Info("Configuration rollback requested", "change", id)
+	if user := RequestUser(c); !h.configManager.IsAdmin(user) {
+		cfg, err := h.configManager.Version(id)
+		if err != nil {
+			return respond.ToastErr(c, fiber.StatusBadRequest, "Failed to roll back: "+err.Error())
+		}
+		return h.propose(c, cfg, user)
+	}
+	if err := h.configManager.Rollback(id, RequestUser(c)); err != nil {
+		slog.Error("Failed to roll back configuration", "change", id, "error", err)
+		return respond.ToastErr(c, fiber.StatusBadRequest, "Failed to roll back: "+err.Error())
+	}
+	c.Set("HX-Trigger", "refreshConfigHistory")
+	return respond.ToastOk(c, "Configuration rolled back, some settings need a restart")
+}
+
+// propose holds a config change of a non-admin until an admin approves it.
+func (h *Handler) propose(c *fiber.Ctx, cfg *Config, user string) error {
+	proposal, err := h.configManager.Propose(cfg, user)
+	if err != nil {
+		slog.Error("Failed to propose configuration change", "user", user, "error", err)
+		return respond.ToastErr(c, fiber.StatusBadRequest, "Failed to propose the change: "+err.Error())
+	}
+	if proposal == nil {
+		return respond.ToastOk(c, "Nothing changed")
+	}
+	c.Set("HX-Trigger", "refreshConfigProposals")
+	return respond.ToastOk(c, fmt.Sprintf("%d change(s) sent to an admin for approval", len(proposal.Changes)))
+}
+
+// GetProposals renders the config changes waiting for an admin's approval.
+func (h *Handler) GetProposals(c *fiber.Ctx) error {
+	proposals, err := h.configManager.Proposals()
+	if err != nil {
+		slog.Error("Failed to read config proposals", "error", err)
+		return respond.ToastErr(c, fiber.StatusInternalServerError, "Failed to read pending changes")
+	}
+	return respond.Partial(c, "config/proposals", fiber.Map{
+		"Proposals": proposals,
+		"Admin":     h.configManager.IsAdmin(RequestUser(c)),
+	})
+}
+
+// ApproveProposal applies a config change proposed by a non-admin.
+func (h *Handler) ApproveProposal(c *fiber.Ctx) error {
+	user := RequestUser(c)
+	if !h.configManager.IsAdmin(user) {
+		return respond.ToastErr(c, fiber.StatusForbidden, ErrNotAdmin.Error())
+	}
+	proposal, err := h.configManager.Approve(c.Params("id"), user)
+	if err != nil {
+		slog.Error("Failed to approve config proposal", "proposal", c.Params("id"), "error", err)
+		c.Set("HX-Trigger", "refreshConfigProposals")
+		return respond.ToastErr(c, fiber.StatusConflict, "Failed to approve: "+err.Error())
+	}
+	c.Set("HX-Trigger", "refreshConfigProposals, refreshConfigHistory")
+	return respond.ToastOk(c, fmt.Sprintf("Changes of %s applied, some settings need a restart", proposal.Author))
+}
+
+// RejectProposal drops a config change proposed by a non-admin.
+func (h *Handler) RejectProposal(c *fiber.Ctx) error {
+	user := RequestUser(c)
+	if !h.configManager.IsAdmin(user) {
+		return respond.ToastErr(c, fiber.StatusForbidden, ErrNotAdmin.Error())
+	}
+	proposal, err := h.configManager.Reject(c.Params("id"), user)
+	if err != nil {
+		return respond.ToastErr(c, fiber.StatusNotFound, err.Error())
+	}
+	c.Set("HX-Trigger", "refreshConfigProposals")
+	return respond.ToastOk(c, fmt.Sprintf("Changes of %s rejected", proposal.Author))
+}
+
+// RequestUser names who made a request: the user set by an authenticating reverse proxy listed
+// in server.trusted_proxies, or the client IP otherwise. Any client can set the user headers, so
+// they're ignored unless the request comes from a trusted proxy.
+func RequestUser(c *fiber.Ctx) string {
+	if !c.App().Config().EnableTrustedProxyCheck || !c.IsProxyTrusted() {
+		return c.IP()
+	}
+	for _, header := range []string{"Remote-User", "X-Forwarded-User", "X-Auth-Request-User"} {
+		if user := strings.TrimSpace(c.Get(header)); user != "" {
+			return user
+		}
+	}
+	return c.IP()
+}
+
 func parseStringSlice(s string) []string {
 	if s == "" {
 		return []string{}
@@ -146,6 +329,25 @@ func parseStringSlice(s string) []string {
 	return result
 }
 
+// parseNonNegativeInt parses a numeric form value, treating empty, invalid and negative values as 0.
+func parseNonNegativeInt(s string) int {
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
+
+// parseRecomputeHour parses the hour of the nightly metrics recomputation, anything but an hour
+// of the day disables it.
+func parseRecomputeHour(s string) int {
+	hour, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || hour < 0 || hour > 23 {
+		return -1
+	}
+	return hour
+}
+
 func (h *Handler) GetConfigForm(c *fiber.Ctx) error {
 	slog.Debug("GetSettingsForm handler called")
 	config := h.configManager.Get()
@@ -0,0 +1,323 @@
+package config
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+)
+
+// historyKeep is the number of config changes kept in the history.
+const historyKeep = 100
+
+// redacted replaces the values of secret settings in the history diffs.
+const redacted = "[redacted]"
+
+// secretKeys are the setting names whose values never show up in a diff. Plugin configs
+// are free-form and usually hold credentials, so they are redacted as a whole.
+var secretKeys = []string{"token", "secret", "password", "api_key", "apikey", ".config."}
+
+// Change is a single setting that changed, addressed by its dotted YAML path.
+type Change struct {
+	Path string `json:"path"`
+	Old  string `json:"old"`
+	New  string `json:"new"`
+}
+
+// HistoryEntry is a saved config change.
+type HistoryEntry struct {
+	ID      string    `json:"id"`
+	Time    time.Time `json:"time"`
+	Author  string    `json:"author"`
+	Changes []Change  `json:"changes"`
+}
+
+// historyRecord is how an entry is persisted. Before holds the whole config as it was before
+// the change, so the change can be rolled back; it is never shown as it contains the secrets.
+type historyRecord struct {
+	HistoryEntry
+	Before string `json:"before"`
+}
+
+// HistoryFile returns the file the history of a config file is kept in, next to it.
+func HistoryFile(configPath string) string {
+	return filepath.Join(filepath.Dir(configPath), "config_history.jsonl")
+}
+
+func (m *Manager) historyPath() string {
+	return HistoryFile(m.configPath)
+}
+
+// History returns the saved config changes, newest first.
+func (m *Manager) History() ([]HistoryEntry, error) {
+	m.historyMu.Lock()
+	defer m.historyMu.Unlock()
+	records, err := m.readHistory()
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]HistoryEntry, 0, len(records))
+	for _, r := range slices.Backward(records) {
+		entries = append(entries, r.HistoryEntry)
+	}
+	return entries, nil
+}
+
+// Version returns the config as it was before the given change.
+func (m *Manager) Version(id string) (*Config, error) {
+	m.historyMu.Lock()
+	records, err := m.readHistory()
+	m.historyMu.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config history: %w", err)
+	}
+	i := slices.IndexFunc(records, func(r historyRecord) bool { return r.ID == id })
+	if i < 0 {
+		return nil, fmt.Errorf("config change %s not found", id)
+	}
+	var cfg Config
+	if err := yaml.Unmarshal([]byte(records[i].Before), &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config version: %w", err)
+	}
+	if err := validator.New().Struct(cfg); err != nil {
+		return nil, fmt.Errorf("config validation failed: %w", err)
+	}
+	return &cfg, nil
+}
+
+// Rollback restores the config as it was before the given change and saves it, which is
+// recorded in the history as a change of its own.
+func (m *Manager) Rollback(id, author string) error {
+	cfg, err := m.Version(id)
+	if err != nil {
+		return err
+	}
+	m.Update(cfg)
+	return m.Save(author)
+}
+
+// recordChange appends the difference between two configs to the history, secrets redacted.
+// Nothing is recorded when they are the same.
+func (m *Manager) recordChange(before, after *Config, author string) error {
+	changes, err := diffConfigs(before, after)
+	if err != nil || len(changes) == 0 {
+		return err
+	}
+	snapshot, err := yaml.Marshal(before)
+	if err != nil {
+		return err
+	}
+	record := historyRecord{
+		HistoryEntry: HistoryEntry{
+			ID:      uuid.New().String(),
+			Time:    time.Now(),
+			Author:  author,
+			Changes: changes,
+		},
+		Before: string(snapshot),
+	}
+
+	m.historyMu.Lock()
+	defer m.historyMu.Unlock()
+	records, err := m.readHistory()
+	if err != nil {
+		return err
+	}
+	if len(records) >= 2*historyKeep {
+		// Compact the file instead of letting it grow forever
+		records = append(records[len(records)-historyKeep+1:], record)
+		return m.writeHistory(records)
+	}
+	f, err := os.OpenFile(m.historyPath(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return json.NewEncoder(f).Encode(record)
+}
+
+func (m *Manager) readHistory() ([]historyRecord, error) {
+	f, err := os.Open(m.historyPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var records []historyRecord
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var r historyRecord
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			continue // skip a line cut by a crash
+		}
+		records = append(records, r)
+	}
+	return records, scanner.Err()
+}
+
+func (m *Manager) writeHistory(records []historyRecord) error {
+	tmp := m.historyPath() + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	for _, r := range records {
+		if err := enc.Encode(r); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, m.historyPath())
+}
+
+// diffConfigs compares two configs setting by setting.
+func diffConfigs(before, after *Config) ([]Change, error) {
+	old, err := flattenConfig(before)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := flattenConfig(after)
+	if err != nil {
+		return nil, err
+	}
+	var changes []Change
+	for path, value := range updated {
+		if prev, ok := old[path]; !ok || prev != value {
+			changes = append(changes, Change{Path: path, Old: prev, New: value})
+		}
+	}
+	for path, prev := range old {
+		if _, ok := updated[path]; !ok {
+			changes = append(changes, Change{Path: path, Old: prev})
+		}
+	}
+	for i, change := range changes {
+		if isSecret(change.Path) {
+			changes[i].Old, changes[i].New = redact(change.Old), redact(change.New)
+		}
+	}
+	slices.SortFunc(changes, func(a, b Change) int { return strings.Compare(a.Path, b.Path) })
+	return changes, nil
+}
+
+// flattenConfig maps the dotted YAML path of every setting to its value, e.g.
+// "telegram.allowedUsers[0]" -> "alice".
+func flattenConfig(cfg *Config) (map[string]string, error) {
+	raw, err := yaml.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	var tree any
+	if err := yaml.Unmarshal(raw, &tree); err != nil {
+		return nil, err
+	}
+	flat := map[string]string{}
+	var walk func(prefix string, node any)
+	walk = func(prefix string, node any) {
+		switch v := node.(type) {
+		case map[string]any:
+			for key, child := range v {
+				if prefix != "" {
+					key = prefix + "." + key
+				}
+				walk(key, child)
+			}
+		case []any:
+			for i, child := range v {
+				walk(fmt.Sprintf("%s[%d]", prefix, i), child)
+			}
+		case nil:
+			flat[prefix] = ""
+		default:
+			flat[prefix] = fmt.Sprint(v)
+		}
+	}
+	walk("", tree)
+	return flat, nil
+}
+
+// StripSecrets returns a copy of cfg with the values of its secret settings emptied, and the
+// paths of the settings that were emptied.
+func StripSecrets(cfg *Config) (*Config, []string, error) {
+	raw, err := yaml.Marshal(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	var tree any
+	if err := yaml.Unmarshal(raw, &tree); err != nil {
+		return nil, nil, err
+	}
+	var stripped []string
+	var walk func(prefix string, node any) any
+	walk = func(prefix string, node any) any {
+		switch v := node.(type) {
+		case map[string]any:
+			for key, child := range v {
+				path := key
+				if prefix != "" {
+					path = prefix + "." + key
+				}
+				v[key] = walk(path, child)
+			}
+		case []any:
+			for i, child := range v {
+				v[i] = walk(fmt.Sprintf("%s[%d]", prefix, i), child)
+			}
+		case string:
+			if v != "" && isSecret(prefix) {
+				stripped = append(stripped, prefix)
+				return ""
+			}
+		case nil, bool:
+		default:
+			if isSecret(prefix) {
+				stripped = append(stripped, prefix)
+				return nil
+			}
+		}
+		return node
+	}
+	raw, err = yaml.Marshal(walk("", tree))
+	if err != nil {
+		return nil, nil, err
+	}
+	var clean Config
+	if err := yaml.Unmarshal(raw, &clean); err != nil {
+		return nil, nil, err
+	}
+	slices.Sort(stripped)
+	return &clean, stripped, nil
+}
+
+func isSecret(path string) bool {
+	path = strings.ToLower(path)
+	for _, key := range secretKeys {
+		if strings.Contains(path, key) {
+			return true
+		}
+	}
+	return false
+}
+
+func redact(value string) string {
+	if value == "" {
+		return ""
+	}
+	return redacted
+}
@@ -16,7 +16,12 @@ import (
 type Manager struct {
 	mu         sync.RWMutex
 	config     *Config
+	saved      *Config // the config as last loaded or saved, to diff the next save against
 	configPath string
+	historyMu  sync.Mutex
+	// proposalsMu guards the proposals file and the proposal notifiers
+	proposalsMu sync.Mutex
+	notifiers   []ProposalNotifier
 }
 
 // processEnvVarNodes recursively processes YAML nodes to handle !env_var tags
@@ -111,7 +116,9 @@ func (m *Manager) loadConfig(path string) (*Config, error) {
 	}
 
 	// Decode the processed node tree into Config struct
-	var cfg Config
+	// Configs written before the file verification existed get its default schedule rather than
+	// a run verifying the whole library at midnight
+	cfg := Config{Verification: defaultConfig.Verification}
 	if err := rootNode.Decode(&cfg); err != nil {
 		return nil, err
 	}
@@ -155,7 +162,7 @@ func NewManager(path string) (*Manager, error) {
 			return nil, fmt.Errorf("failed to create default config: %w", err)
 		}
 		slog.Info("Default configuration created successfully", "path", path)
-		manager := &Manager{config: &defaultConfig}
+		manager := &Manager{config: &defaultConfig, saved: &defaultConfig, configPath: path}
 		if err := manager.EnsureDirectories(); err != nil {
 			return nil, err
 		}
@@ -166,6 +173,7 @@ func NewManager(path string) (*Manager, error) {
 		return nil, err
 	}
 	manager.config = cfg
+	manager.saved = cfg
 	if err := manager.EnsureDirectories(); err != nil {
 		return nil, err
 	}
@@ -199,10 +207,11 @@ func (m *Manager) Update(config *Config) {
 	}
 }
 
-// Save writes the current configuration to the specified file path.
-func (m *Manager) Save() error {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+// Save writes the current configuration to the specified file path and records what changed
+// since the last save in the config history, with the author of the change.
+func (m *Manager) Save(author string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
 	// Ensure the directory exists
 	dir := filepath.Dir(m.configPath)
@@ -226,6 +235,12 @@ func (m *Manager) Save() error {
 	}
 
 	slog.Info("Configuration saved successfully", "path", m.configPath)
+	if m.saved != nil {
+		if err := m.recordChange(m.saved, m.config, author); err != nil {
+			slog.Warn("failed to record config change", "path", m.historyPath(), "error", err)
+		}
+	}
+	m.saved = m.config
 	return nil
 }
 
@@ -244,6 +259,11 @@ func (m *Manager) EnsureDirectories() error {
 	return nil
 }
 
+// Path returns the path of the config file.
+func (m *Manager) Path() string {
+	return m.configPath
+}
+
 func (m *Manager) GetYAML() string {
 	content, err := os.ReadFile(m.configPath)
 	if err != nil {
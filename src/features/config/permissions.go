@@ -0,0 +1,88 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// PathCheck is the result of checking that soulsolid can write to one of its configured paths.
+type PathCheck struct {
+	Name     string `json:"name"`
+	Path     string `json:"path"`
+	Writable bool   `json:"writable"`
+	Error    string `json:"error,omitempty"`
+}
+
+// CheckPaths checks that the library, download, database and job log directories are writable.
+func (m *Manager) CheckPaths() []PathCheck {
+	cfg := m.Get()
+	paths := []struct{ name, path string }{
+		{"library", cfg.LibraryPath},
+		{"downloads", cfg.DownloadPath},
+		{"database", filepath.Dir(cfg.Database.Path)},
+	}
+	if cfg.Jobs.Log {
+		paths = append(paths, struct{ name, path string }{"job logs", cfg.Jobs.LogPath})
+	}
+	checks := make([]PathCheck, 0, len(paths))
+	for _, p := range paths {
+		check := PathCheck{Name: p.name, Path: p.path, Writable: true}
+		if err := CheckWritable(p.path); err != nil {
+			check.Writable = false
+			check.Error = err.Error()
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// CheckWritable checks that dir exists and files can be created in it, by creating and removing
+// a probe file. Permission problems are returned as errors wrapping fs.ErrPermission that say
+// how to fix them.
+func CheckWritable(dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return ExplainPermission(dir, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+	probe, err := os.CreateTemp(dir, ".soulsolid-write-check-*")
+	if err != nil {
+		return ExplainPermission(dir, err)
+	}
+	probe.Close()
+	return os.Remove(probe.Name())
+}
+
+// CheckReadable checks that the entries of dir can be listed.
+func CheckReadable(dir string) error {
+	f, err := os.Open(dir)
+	if err != nil {
+		return ExplainPermission(dir, err)
+	}
+	defer f.Close()
+	if _, err := f.ReadDir(1); err != nil && !errors.Is(err, io.EOF) {
+		return ExplainPermission(dir, err)
+	}
+	return nil
+}
+
+// ExplainPermission turns EACCES, EPERM and EROFS errors on path into an actionable message
+// naming the user soulsolid runs as. Other errors are returned as they are.
+func ExplainPermission(path string, err error) error {
+	switch {
+	case errors.Is(err, syscall.EROFS):
+		return fmt.Errorf("%s is on a read-only filesystem, mount it read-write (e.g. drop the :ro flag of the Docker volume): %w (%w)", path, err, fs.ErrPermission)
+	case errors.Is(err, fs.ErrPermission):
+		return fmt.Errorf("soulsolid (uid %d, gid %d) has no permission to use %s, fix its owner or mode (e.g. chown -R %d:%d %s): %w",
+			os.Getuid(), os.Getgid(), path, os.Getuid(), os.Getgid(), path, err)
+	default:
+		return err
+	}
+}
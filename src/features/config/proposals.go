@@ -0,0 +1,227 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+)
+
+// ErrNotAdmin is returned when a user who isn't an admin decides on a proposal.
+var ErrNotAdmin = errors.New("only admins can approve or reject config changes")
+
+// Proposal is a config change made by a user who isn't an admin, held until an admin approves
+// or rejects it.
+type Proposal struct {
+	ID      string    `json:"id"`
+	Time    time.Time `json:"time"`
+	Author  string    `json:"author"`
+	Changes []Change  `json:"changes"`
+}
+
+// proposalRecord is how a proposal is persisted. Before and After hold the whole config as it
+// was when the change was proposed and as proposed; they are never shown as they contain the
+// secrets.
+type proposalRecord struct {
+	Proposal
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// ProposalNotifier is told about new proposals, e.g. the Telegram bot asking the admins to
+// approve them.
+type ProposalNotifier interface {
+	NotifyProposal(p Proposal)
+}
+
+// ProposalsFile returns the file the pending proposals of a config file are kept in, next to it.
+func ProposalsFile(configPath string) string {
+	return filepath.Join(filepath.Dir(configPath), "config_proposals.json")
+}
+
+func (m *Manager) proposalsPath() string {
+	return ProposalsFile(m.configPath)
+}
+
+// AddProposalNotifier registers a notifier told about new proposals.
+func (m *Manager) AddProposalNotifier(n ProposalNotifier) {
+	m.proposalsMu.Lock()
+	defer m.proposalsMu.Unlock()
+	m.notifiers = append(m.notifiers, n)
+}
+
+// IsAdmin reports whether user, as named by RequestUser, may change the config directly.
+// Everyone is an admin while server.admins is empty.
+func (m *Manager) IsAdmin(user string) bool {
+	admins := m.Get().Server.Admins
+	return len(admins) == 0 || slices.Contains(admins, user)
+}
+
+// IsTelegramAdmin reports whether a Telegram user may approve proposals from the bot.
+func (m *Manager) IsTelegramAdmin(username string) bool {
+	return slices.Contains(m.Get().Telegram.Admins, username)
+}
+
+// Propose holds cfg as a change of the current config by author until an admin approves it.
+// Nothing is held, and nil returned, when cfg changes nothing.
+func (m *Manager) Propose(cfg *Config, author string) (*Proposal, error) {
+	if err := validator.New().Struct(cfg); err != nil {
+		return nil, fmt.Errorf("config validation failed: %w", err)
+	}
+	current := m.Get()
+	changes, err := diffConfigs(current, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	before, err := yaml.Marshal(current)
+	if err != nil {
+		return nil, err
+	}
+	after, err := yaml.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	record := proposalRecord{
+		Proposal: Proposal{
+			ID:      uuid.New().String(),
+			Time:    time.Now(),
+			Author:  author,
+			Changes: changes,
+		},
+		Before: string(before),
+		After:  string(after),
+	}
+
+	m.proposalsMu.Lock()
+	records, err := m.readProposals()
+	if err == nil {
+		err = m.writeProposals(append(records, record))
+	}
+	notifiers := slices.Clone(m.notifiers)
+	m.proposalsMu.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("failed to save proposal: %w", err)
+	}
+	slog.Info("Config change proposed", "proposal", record.ID, "author", author, "changes", len(changes))
+	for _, n := range notifiers {
+		n.NotifyProposal(record.Proposal)
+	}
+	return &record.Proposal, nil
+}
+
+// Proposals returns the proposals waiting for an admin, oldest first.
+func (m *Manager) Proposals() ([]Proposal, error) {
+	m.proposalsMu.Lock()
+	defer m.proposalsMu.Unlock()
+	records, err := m.readProposals()
+	if err != nil {
+		return nil, err
+	}
+	proposals := make([]Proposal, 0, len(records))
+	for _, r := range records {
+		proposals = append(proposals, r.Proposal)
+	}
+	return proposals, nil
+}
+
+// Approve applies a proposal and saves the config, recorded in the history under the author
+// of the proposal and the admin approving it. A proposal made before the config changed
+// again is refused rather than undoing the later change.
+func (m *Manager) Approve(id, admin string) (*Proposal, error) {
+	record, err := m.takeProposal(id, func(r proposalRecord) error {
+		current, err := yaml.Marshal(m.Get())
+		if err != nil {
+			return err
+		}
+		if string(current) != r.Before {
+			return fmt.Errorf("the config changed since this proposal, it has to be proposed again")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	var cfg Config
+	if err := yaml.Unmarshal([]byte(record.After), &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse proposed config: %w", err)
+	}
+	m.Update(&cfg)
+	slog.Info("Config change approved", "proposal", id, "author", record.Author, "admin", admin)
+	if err := m.Save(fmt.Sprintf("%s, approved by %s", record.Author, admin)); err != nil {
+		slog.Warn("failed to save config to file (this is normal in containerized environments)", "error", err)
+	}
+	return &record.Proposal, nil
+}
+
+// Reject drops a proposal without applying it.
+func (m *Manager) Reject(id, admin string) (*Proposal, error) {
+	record, err := m.takeProposal(id, nil)
+	if err != nil {
+		return nil, err
+	}
+	slog.Info("Config change rejected", "proposal", id, "author", record.Author, "admin", admin)
+	return &record.Proposal, nil
+}
+
+// takeProposal removes a proposal once check, when given, accepts it. A refused proposal is
+// removed as well, it can't be approved anymore.
+func (m *Manager) takeProposal(id string, check func(proposalRecord) error) (*proposalRecord, error) {
+	m.proposalsMu.Lock()
+	defer m.proposalsMu.Unlock()
+	records, err := m.readProposals()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read proposals: %w", err)
+	}
+	i := slices.IndexFunc(records, func(r proposalRecord) bool { return r.ID == id })
+	if i < 0 {
+		return nil, fmt.Errorf("config proposal %s not found, it was already decided", id)
+	}
+	record := records[i]
+	if err := m.writeProposals(slices.Delete(records, i, i+1)); err != nil {
+		return nil, fmt.Errorf("failed to save proposals: %w", err)
+	}
+	if check != nil {
+		if err := check(record); err != nil {
+			return nil, err
+		}
+	}
+	return &record, nil
+}
+
+func (m *Manager) readProposals() ([]proposalRecord, error) {
+	raw, err := os.ReadFile(m.proposalsPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var records []proposalRecord
+	if err := json.Unmarshal(raw, &records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+func (m *Manager) writeProposals(records []proposalRecord) error {
+	raw, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := m.proposalsPath() + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, m.proposalsPath())
+}
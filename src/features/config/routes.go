@@ -11,6 +11,12 @@ func RegisterRoutes(app *fiber.App, configManager *Manager) {
 	app.Get("/settings", handler.RenderSettingsSection)
 	app.Get("/config/form", handler.GetConfigForm)
 	app.Put("/settings", handler.UpdateSettings)
+	app.Get("/settings/history", handler.RenderHistorySection)
+	app.Get("/config/history", handler.GetHistory)
+	app.Post("/config/history/:id/rollback", handler.RollbackConfig)
+	app.Get("/config/proposals", handler.GetProposals)
+	app.Post("/config/proposals/:id/approve", handler.ApproveProposal)
+	app.Post("/config/proposals/:id/reject", handler.RejectProposal)
 	app.Get("/config", handler.GetConfig)
 	app.Get("/config/database/download", handler.DownloadDatabase)
 }
@@ -2,6 +2,8 @@ package config
 
 import (
 	"fmt"
+	"log/slog"
+	"strings"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
@@ -21,6 +23,8 @@ func (h *TelegramHandler) HandleCommand(bot *tgbotapi.BotAPI, chatID int64, comm
 	switch command {
 	case "config":
 		return h.handleConfig(bot, chatID, args)
+	case "proposals":
+		return h.handleProposals(bot, chatID)
 	default:
 		msg := tgbotapi.NewMessage(chatID, "❌ Unknown config command. Use /config")
 		msg.ParseMode = tgbotapi.ModeMarkdown
@@ -32,13 +36,109 @@ func (h *TelegramHandler) HandleCommand(bot *tgbotapi.BotAPI, chatID int64, comm
 // GetCommands returns the available commands for this handler
 func (h *TelegramHandler) GetCommands() map[string]string {
 	return map[string]string{
-		"config": "Show configuration (use 'yaml' for YAML format)",
+		"config":    "Show configuration (use 'yaml' for YAML format)",
+		"proposals": "List the config changes waiting for an admin's approval",
 	}
 }
 
-// HandleCallback handles callback queries for this feature (config has no callbacks)
+// HandleCallback approves or rejects config proposals, for Telegram admins only
 func (h *TelegramHandler) HandleCallback(bot *tgbotapi.BotAPI, callback *tgbotapi.CallbackQuery) bool {
-	return false // Config feature doesn't handle any callbacks
+	data, ok := strings.CutPrefix(callback.Data, "cfgprop_")
+	if !ok {
+		return false
+	}
+	action, id, _ := strings.Cut(data, "_")
+	username := TelegramUsername(callback.From)
+	if !h.configManager.IsTelegramAdmin(username) {
+		slog.Warn("Config proposal decision by a non-admin", "username", username, "proposal", id)
+		bot.Request(tgbotapi.NewCallbackWithAlert(callback.ID, "❌ "+ErrNotAdmin.Error()))
+		return true
+	}
+
+	var proposal *Proposal
+	var err error
+	var outcome string
+	switch action {
+	case "approve":
+		proposal, err = h.configManager.Approve(id, "@"+username)
+		outcome = "✅ Approved by @" + username
+	case "reject":
+		proposal, err = h.configManager.Reject(id, "@"+username)
+		outcome = "🚫 Rejected by @" + username
+	default:
+		return false
+	}
+	if err != nil {
+		slog.Error("Failed to decide on config proposal", "proposal", id, "action", action, "error", err)
+		bot.Send(tgbotapi.NewMessage(callback.Message.Chat.ID, "❌ "+err.Error()))
+		return true
+	}
+	// Replace the buttons with the outcome
+	bot.Send(tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, ProposalText(*proposal)+"\n\n"+outcome))
+	return true
+}
+
+// handleProposals lists the pending config proposals, with approval buttons for admins
+func (h *TelegramHandler) handleProposals(bot *tgbotapi.BotAPI, chatID int64) error {
+	proposals, err := h.configManager.Proposals()
+	if err != nil {
+		return err
+	}
+	if len(proposals) == 0 {
+		bot.Send(tgbotapi.NewMessage(chatID, "⚙️ No config changes waiting for approval"))
+		return nil
+	}
+	for _, p := range proposals {
+		bot.Send(NewProposalMessage(chatID, p))
+	}
+	return nil
+}
+
+// maxProposalChanges caps the changes listed in a Telegram message about a proposal.
+const maxProposalChanges = 20
+
+// ProposalText describes a config proposal for a Telegram message. It's sent as plain text
+// since the setting values may hold Markdown characters.
+func ProposalText(p Proposal) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "⚙️ Config change proposed by %s (%s)\n", p.Author, p.Time.Format("Jan 2 15:04"))
+	for i, change := range p.Changes {
+		if i == maxProposalChanges {
+			fmt.Fprintf(&b, "\n… and %d more, see Settings", len(p.Changes)-maxProposalChanges)
+			break
+		}
+		fmt.Fprintf(&b, "\n• %s: %s → %s", change.Path, orUnset(change.Old), orUnset(change.New))
+	}
+	return b.String()
+}
+
+func orUnset(value string) string {
+	if value == "" {
+		return "(unset)"
+	}
+	return value
+}
+
+// NewProposalMessage builds the message asking to approve or reject a config proposal.
+func NewProposalMessage(chatID int64, p Proposal) tgbotapi.MessageConfig {
+	msg := tgbotapi.NewMessage(chatID, ProposalText(p))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("✅ Approve", "cfgprop_approve_"+p.ID),
+		tgbotapi.NewInlineKeyboardButtonData("🚫 Reject", "cfgprop_reject_"+p.ID),
+	))
+	return msg
+}
+
+// TelegramUsername returns the username of user, falling back to its first and last name
+func TelegramUsername(user *tgbotapi.User) string {
+	if user.UserName != "" {
+		return user.UserName
+	}
+	username := user.FirstName
+	if user.LastName != "" {
+		username += " " + user.LastName
+	}
+	return username
 }
 
 // handleConfig shows configuration
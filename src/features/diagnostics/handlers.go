@@ -0,0 +1,40 @@
+package diagnostics
+
+import (
+	"log/slog"
+
+	"github.com/contre95/soulsolid/src/features/hosting/respond"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Handler handles HTTP requests for the diagnostics feature.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new diagnostics handler.
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RenderDiagnosticsSection renders the diagnostics page.
+func (h *Handler) RenderDiagnosticsSection(c *fiber.Ctx) error {
+	slog.Debug("RenderDiagnosticsSection handler called")
+	return respond.Section(c, "diagnostics", fiber.Map{"Title": "Diagnostics"})
+}
+
+// GetUsage renders the recorded usage tables, or returns them as JSON for bug reports.
+func (h *Handler) GetUsage(c *fiber.Ctx) error {
+	slog.Debug("GetUsage handler called")
+	return respond.Partial(c, "diagnostics/usage", fiber.Map{"Report": h.service.GetReport()})
+}
+
+// ResetUsage discards all recorded usage.
+func (h *Handler) ResetUsage(c *fiber.Ctx) error {
+	slog.Info("Resetting recorded usage")
+	if err := h.service.Reset(); err != nil {
+		return respond.ToastErr(c, fiber.StatusInternalServerError, "Failed to reset usage: "+err.Error())
+	}
+	c.Set("HX-Trigger", "refreshUsage")
+	return respond.ToastOk(c, "Recorded usage cleared")
+}
@@ -0,0 +1,31 @@
+package diagnostics
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Middleware counts feature usage and HTTP error classes. Only requests that act on the
+// library (non-GET) are counted as feature usage, so polling and page loads don't drown
+// out the numbers. Routes are recorded by pattern (e.g. /tag/:trackId) to stay anonymous.
+func Middleware(service *Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		err := c.Next()
+		if !service.Enabled() {
+			return err
+		}
+		route := c.Route().Path
+		if strings.HasPrefix(route, "/diagnostics") {
+			return err // don't count looking at (or resetting) the stats themselves
+		}
+		if c.Method() != fiber.MethodGet && c.Method() != fiber.MethodHead {
+			service.RecordFeature(c.Method(), route)
+		}
+		if status := c.Response().StatusCode(); status >= 400 {
+			service.RecordError(fmt.Sprintf("http %dxx: %s", status/100, route))
+		}
+		return err
+	}
+}
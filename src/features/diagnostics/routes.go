@@ -0,0 +1,12 @@
+package diagnostics
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// RegisterRoutes registers the diagnostics routes with the Fiber app.
+func RegisterRoutes(app *fiber.App, handler *Handler) {
+	app.Get("/diagnostics", handler.RenderDiagnosticsSection)
+	app.Get("/diagnostics/usage", handler.GetUsage)
+	app.Post("/diagnostics/reset", handler.ResetUsage)
+}
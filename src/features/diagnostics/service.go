@@ -0,0 +1,319 @@
+package diagnostics
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/contre95/soulsolid/src/features/config"
+	"github.com/contre95/soulsolid/src/features/jobs"
+	"github.com/contre95/soulsolid/src/music"
+)
+
+// flushInterval is how often recorded usage is written to disk. Counting happens on every
+// request, so writes are batched instead.
+const flushInterval = time.Minute
+
+// Ensure Service can observe finished jobs
+var _ jobs.JobObserver = (*Service)(nil)
+
+// JobStats aggregates the runs of a single job type.
+type JobStats struct {
+	Runs      int   `json:"runs"`
+	Failed    int   `json:"failed"`
+	Cancelled int   `json:"cancelled"`
+	TotalMs   int64 `json:"total_ms"`
+	MaxMs     int64 `json:"max_ms"`
+	LastRunAt int64 `json:"last_run_at"` // unix seconds
+}
+
+// Usage holds the anonymous usage counters. Only route patterns, job types and error
+// categories are recorded: no paths, IDs, titles or any other library content.
+type Usage struct {
+	Since    time.Time            `json:"since"`
+	Features map[string]int       `json:"features"` // "METHOD /route/:pattern" -> count
+	Jobs     map[string]*JobStats `json:"jobs"`     // job type -> stats
+	Errors   map[string]int       `json:"errors"`   // error category -> count
+}
+
+func newUsage() *Usage {
+	return &Usage{
+		Since:    time.Now(),
+		Features: make(map[string]int),
+		Jobs:     make(map[string]*JobStats),
+		Errors:   make(map[string]int),
+	}
+}
+
+// Service records opt-in usage stats and persists them to a local JSON file.
+type Service struct {
+	configManager *config.Manager
+	mu            sync.Mutex
+	usage         *Usage
+	dirty         bool       // usage changed since it was last written, guarded by mu
+	saveMu        sync.Mutex // serializes writes of the usage file, which share its temp file
+}
+
+// NewService creates a new diagnostics service, loading previously recorded usage if present.
+func NewService(cfgManager *config.Manager) *Service {
+	s := &Service{configManager: cfgManager, usage: newUsage()}
+	if err := s.load(); err != nil {
+		slog.Warn("Failed to load recorded usage, starting fresh", "path", s.path(), "error", err)
+	}
+	go s.watchFlush()
+	return s
+}
+
+// Enabled reports whether the user opted in to usage recording.
+func (s *Service) Enabled() bool {
+	return s.configManager.Get().Diagnostics.Enabled
+}
+
+func (s *Service) path() string {
+	if path := s.configManager.Get().Diagnostics.Path; path != "" {
+		return path
+	}
+	return "./logs/usage.json"
+}
+
+// RecordFeature counts one use of the feature behind the given route pattern.
+func (s *Service) RecordFeature(method, route string) {
+	if !s.Enabled() {
+		return
+	}
+	s.mu.Lock()
+	s.usage.Features[method+" "+route]++
+	s.dirty = true
+	s.mu.Unlock()
+}
+
+// RecordError counts one error of the given category.
+func (s *Service) RecordError(category string) {
+	if !s.Enabled() {
+		return
+	}
+	s.mu.Lock()
+	s.usage.Errors[category]++
+	s.dirty = true
+	s.mu.Unlock()
+}
+
+// JobFinished records the duration and outcome of a finished job.
+func (s *Service) JobFinished(job *music.Job, duration time.Duration) {
+	if !s.Enabled() {
+		return
+	}
+	s.mu.Lock()
+	stats, ok := s.usage.Jobs[job.Type]
+	if !ok {
+		stats = &JobStats{}
+		s.usage.Jobs[job.Type] = stats
+	}
+	ms := duration.Milliseconds()
+	stats.Runs++
+	stats.TotalMs += ms
+	stats.MaxMs = max(stats.MaxMs, ms)
+	stats.LastRunAt = time.Now().Unix()
+	switch job.Status {
+	case music.JobStatusFailed:
+		stats.Failed++
+		s.usage.Errors["job: "+errorCategory(job.Message)]++
+	case music.JobStatusCancelled:
+		stats.Cancelled++
+	}
+	s.dirty = true
+	s.mu.Unlock()
+}
+
+// Reset discards all recorded usage.
+func (s *Service) Reset() error {
+	s.mu.Lock()
+	s.usage = newUsage()
+	s.mu.Unlock()
+	return s.save()
+}
+
+// Flush writes the recorded usage to disk when it changed since the last write, it's called
+// periodically and on shutdown.
+func (s *Service) Flush() error {
+	s.mu.Lock()
+	dirty := s.dirty
+	s.mu.Unlock()
+	if !dirty {
+		return nil
+	}
+	return s.save()
+}
+
+// watchFlush writes the recorded usage every flushInterval.
+func (s *Service) watchFlush() {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		s.Flush()
+	}
+}
+
+// errorCategory buckets an error message into a coarse category so no file names or
+// other library details end up in the recorded stats.
+func errorCategory(msg string) string {
+	msg = strings.ToLower(msg)
+	switch {
+	case msg == "":
+		return "unknown"
+	case strings.Contains(msg, "timeout") || strings.Contains(msg, "deadline exceeded"):
+		return "timeout"
+	case strings.Contains(msg, "connection") || strings.Contains(msg, "no such host") || strings.Contains(msg, "network"):
+		return "network"
+	case strings.Contains(msg, "permission denied"):
+		return "permission"
+	case strings.Contains(msg, "no space left"):
+		return "disk full"
+	case strings.Contains(msg, "not found") || strings.Contains(msg, "no such file"):
+		return "not found"
+	case strings.Contains(msg, "database") || strings.Contains(msg, "sql"):
+		return "database"
+	case strings.Contains(msg, "tag"):
+		return "tagging"
+	default:
+		return "other"
+	}
+}
+
+// load reads previously persisted usage from disk. A missing file is not an error.
+func (s *Service) load() error {
+	data, err := os.ReadFile(s.path())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	usage := newUsage()
+	if err := json.Unmarshal(data, usage); err != nil {
+		return fmt.Errorf("failed to decode usage file: %w", err)
+	}
+	s.mu.Lock()
+	s.usage = usage
+	s.mu.Unlock()
+	return nil
+}
+
+// save persists the usage counters, writing to a temp file first so a crash never
+// leaves a truncated file behind.
+func (s *Service) save() error {
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+	s.mu.Lock()
+	data, err := json.MarshalIndent(s.usage, "", "  ")
+	s.dirty = false
+	s.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode usage: %w", err)
+	}
+	if err := s.write(data); err != nil {
+		// The usage is written again on the next flush
+		s.mu.Lock()
+		s.dirty = true
+		s.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+func (s *Service) write(data []byte) error {
+	path := s.path()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		slog.Error("Failed to create usage directory", "path", path, "error", err)
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		slog.Error("Failed to write usage file", "path", tmp, "error", err)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		slog.Error("Failed to replace usage file", "path", path, "error", err)
+		return err
+	}
+	return nil
+}
+
+// Count is a single labelled counter, used to render sorted tables.
+type Count struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// JobRow is the per job type summary shown on the diagnostics page.
+type JobRow struct {
+	Type      string        `json:"type"`
+	Runs      int           `json:"runs"`
+	Failed    int           `json:"failed"`
+	Cancelled int           `json:"cancelled"`
+	Average   time.Duration `json:"average"`
+	Max       time.Duration `json:"max"`
+}
+
+// Report is a sorted snapshot of the recorded usage.
+type Report struct {
+	Enabled  bool      `json:"enabled"`
+	Since    time.Time `json:"since"`
+	Features []Count   `json:"features"`
+	Jobs     []JobRow  `json:"jobs"`
+	Errors   []Count   `json:"errors"`
+}
+
+// GetReport returns the recorded usage sorted by frequency.
+func (s *Service) GetReport() Report {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	report := Report{
+		Enabled:  s.Enabled(),
+		Since:    s.usage.Since,
+		Features: sortedCounts(s.usage.Features),
+		Errors:   sortedCounts(s.usage.Errors),
+		Jobs:     make([]JobRow, 0, len(s.usage.Jobs)),
+	}
+	for jobType, stats := range s.usage.Jobs {
+		row := JobRow{
+			Type:      jobType,
+			Runs:      stats.Runs,
+			Failed:    stats.Failed,
+			Cancelled: stats.Cancelled,
+			Max:       time.Duration(stats.MaxMs) * time.Millisecond,
+		}
+		if stats.Runs > 0 {
+			row.Average = time.Duration(stats.TotalMs/int64(stats.Runs)) * time.Millisecond
+		}
+		report.Jobs = append(report.Jobs, row)
+	}
+	sort.Slice(report.Jobs, func(i, j int) bool {
+		if report.Jobs[i].Runs != report.Jobs[j].Runs {
+			return report.Jobs[i].Runs > report.Jobs[j].Runs
+		}
+		return report.Jobs[i].Type < report.Jobs[j].Type
+	})
+	return report
+}
+
+func sortedCounts(counts map[string]int) []Count {
+	result := make([]Count, 0, len(counts))
+	for name, count := range counts {
+		result = append(result, Count{Name: name, Count: count})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
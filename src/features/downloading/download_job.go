@@ -74,11 +74,26 @@ func (e *DownloadJobTask) Execute(ctx context.Context, job *music.Job, progressU
 		return e.executeTracksDownload(ctx, job, progressUpdater, downloadPath)
 	case "playlist":
 		return e.executePlaylistDownload(ctx, job, progressUpdater, downloadPath)
+	case "source":
+		return e.executeSourceDownload(ctx, job, progressUpdater, downloadPath)
 	default:
 		return nil, fmt.Errorf("unsupported download type: %s", jobType)
 	}
 }
 
+// discardExplicit deletes a downloaded track when the job was started with the family filter and
+// the track turned out to be explicit. Explicitness is only known once the provider returns the track.
+func discardExplicit(job *music.Job, track *music.Track) bool {
+	if enabled, _ := job.Metadata["familyFilter"].(bool); !enabled || !track.IsExplicit() {
+		return false
+	}
+	if err := os.Remove(track.Path); err != nil && !os.IsNotExist(err) {
+		slog.Warn("Failed to remove explicit track", "path", track.Path, "error", err)
+	}
+	job.Logger.Info("Discarded explicit track (family filter)", "title", track.Title, "artist", safeArtistName(track), "color", "orange")
+	return true
+}
+
 // executeTrackDownload handles track download jobs
 func (e *DownloadJobTask) executeTrackDownload(ctx context.Context, job *music.Job, progressUpdater func(int, string), downloadPath string) (map[string]any, error) {
 	trackID, ok := job.Metadata["trackID"].(string)
@@ -128,6 +143,9 @@ func (e *DownloadJobTask) executeTrackDownload(ctx context.Context, job *music.J
 	}
 	// Print track pretty for debugging
 	slog.Debug("Track downloaded", "track", track.Pretty())
+	if discardExplicit(job, track) {
+		return nil, fmt.Errorf("track %q is explicit and was blocked by the family filter", track.Title)
+	}
 
 	e.service.jobService.SetJobName(job.ID, fmt.Sprintf("Download: %s (with %s)", track.Title, safeArtistName(track)))
 	slog.Info("Updated job name with track title", "jobID", job.ID, "title", track.Title)
@@ -213,6 +231,7 @@ func (e *DownloadJobTask) executeAlbumDownload(ctx context.Context, job *music.J
 
 	var downloadedTracks []*music.Track
 	var filePaths []string
+	blocked := 0
 	// Process each downloaded track
 	for i, track := range tracks {
 		select {
@@ -224,6 +243,11 @@ func (e *DownloadJobTask) executeAlbumDownload(ctx context.Context, job *music.J
 		progressUpdater(progress, fmt.Sprintf("Processing track %d/%d: %s...", i+1, totalTracks, track.Title))
 		slog.Debug("Processing album track", "albumID", albumID, "trackID", track.ID, "trackNumber", i+1, "title", track.Title)
 
+		if discardExplicit(job, track) {
+			blocked++
+			continue
+		}
+
 		// Track is already downloaded by plugin, just tag it. Downloads keep the source
 		// metadata as-is; missing-field defaulting only applies when importing.
 		slog.Debug("Processing album track metadata", "trackID", track.ID, "hasAlbum", track.Album != nil, "hasArtwork", track.Album != nil && len(track.Album.ArtworkData) > 0)
@@ -261,6 +285,7 @@ func (e *DownloadJobTask) executeAlbumDownload(ctx context.Context, job *music.J
 		"trackCount": len(downloadedTracks),
 		"filePaths":  filePaths,
 		"albumPath":  albumPath,
+		"blocked":    blocked,
 	}, nil
 }
 
@@ -326,6 +351,7 @@ func (e *DownloadJobTask) executeArtistDownload(ctx context.Context, job *music.
 
 	var downloadedTracks []*music.Track
 	var filePaths []string
+	blocked := 0
 
 	// Process each downloaded track
 	for i, track := range tracks {
@@ -339,6 +365,11 @@ func (e *DownloadJobTask) executeArtistDownload(ctx context.Context, job *music.
 		progressUpdater(progress, fmt.Sprintf("Processing track %d/%d: %s...", i+1, totalTracks, track.Title))
 		slog.Debug("Processing artist track", "artistID", artistID, "trackID", track.ID, "trackNumber", i+1, "title", track.Title)
 
+		if discardExplicit(job, track) {
+			blocked++
+			continue
+		}
+
 		// Track is already downloaded by plugin, just validate and tag
 		slog.Debug("Processing artist track metadata", "trackID", track.ID, "hasAlbum", track.Album != nil, "hasArtwork", track.Album != nil && len(track.Album.ArtworkData) > 0)
 
@@ -380,6 +411,7 @@ func (e *DownloadJobTask) executeArtistDownload(ctx context.Context, job *music.
 		"trackCount": len(downloadedTracks),
 		"filePaths":  filePaths,
 		"artistPath": artistPath,
+		"blocked":    blocked,
 	}, nil
 }
 
@@ -427,6 +459,7 @@ func (e *DownloadJobTask) executeTracksDownload(ctx context.Context, job *music.
 	totalTracks := len(trackIDs)
 	var downloadedTracks []*music.Track
 	var filePaths []string
+	blocked := 0
 
 	// Process each track
 	for i, trackID := range trackIDs {
@@ -455,6 +488,11 @@ func (e *DownloadJobTask) executeTracksDownload(ctx context.Context, job *music.
 			continue // Skip this track but continue with others
 		}
 
+		if discardExplicit(job, track) {
+			blocked++
+			continue
+		}
+
 		// Process the downloaded track. Downloads keep the source metadata as-is; missing-field
 		// defaulting only applies when importing.
 
@@ -483,6 +521,7 @@ func (e *DownloadJobTask) executeTracksDownload(ctx context.Context, job *music.
 		"trackIDs":   trackIDs,
 		"trackCount": len(downloadedTracks),
 		"filePaths":  filePaths,
+		"blocked":    blocked,
 	}, nil
 }
 
@@ -527,6 +566,7 @@ func (e *DownloadJobTask) executePlaylistDownload(ctx context.Context, job *musi
 	totalTracks := len(trackIDs)
 	var downloadedTracks []*music.Track
 	var filePaths []string
+	blocked := 0
 
 	for i, trackID := range trackIDs {
 		select {
@@ -553,6 +593,11 @@ func (e *DownloadJobTask) executePlaylistDownload(ctx context.Context, job *musi
 			continue // Skip this track but continue with others
 		}
 
+		if discardExplicit(job, track) {
+			blocked++
+			continue
+		}
+
 		// Process the downloaded track. Downloads keep the source metadata as-is; missing-field
 		// defaulting only applies when importing.
 
@@ -582,6 +627,74 @@ func (e *DownloadJobTask) executePlaylistDownload(ctx context.Context, job *musi
 		"trackIDs":     trackIDs,
 		"trackCount":   len(downloadedTracks),
 		"filePaths":    filePaths,
+		"blocked":      blocked,
+	}, nil
+}
+
+// executeSourceDownload re-fetches a library track from its source URL and replaces the library file
+func (e *DownloadJobTask) executeSourceDownload(ctx context.Context, job *music.Job, progressUpdater func(int, string), downloadPath string) (map[string]any, error) {
+	libraryTrackID, ok := job.Metadata["libraryTrackID"].(string)
+	if !ok {
+		return nil, fmt.Errorf("libraryTrackID not found in job metadata")
+	}
+
+	sourceURL, ok := job.Metadata["sourceURL"].(string)
+	if !ok {
+		return nil, fmt.Errorf("sourceURL not found in job metadata")
+	}
+
+	downloaderName, ok := job.Metadata["downloader"].(string)
+	if !ok {
+		return nil, fmt.Errorf("downloader not found in job metadata")
+	}
+
+	downloader, exists := e.service.pluginManager.GetDownloader(downloaderName)
+	if !exists {
+		return nil, fmt.Errorf("downloader %s not found", downloaderName)
+	}
+
+	slog.Debug("Starting source re-download job", "trackID", libraryTrackID, "url", sourceURL, "downloader", downloaderName, "jobID", job.ID)
+	progressUpdater(10, fmt.Sprintf("Re-downloading %s with %s...", sourceURL, downloader.Name()))
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	tracks, err := downloader.DownloadLink(sourceURL, downloadPath, func(downloaded, total int64) {
+		if total <= 0 {
+			return // plugin reported no total; avoid division by zero
+		}
+		progressUpdater(10+int(downloaded*60/total), fmt.Sprintf("Downloading... (%d%%)", downloaded*100/total))
+	})
+	if err != nil {
+		slog.Error("Failed to re-download track", "url", sourceURL, "error", err)
+		return nil, fmt.Errorf("failed to download source url: %w", err)
+	}
+	if len(tracks) != 1 {
+		return nil, fmt.Errorf("source url resolved to %d tracks, expected 1", len(tracks))
+	}
+	track := tracks[0]
+	e.service.jobService.SetJobName(job.ID, fmt.Sprintf("Re-download: %s (with %s)", track.Title, safeArtistName(track)))
+
+	progressUpdater(75, "Track downloaded, tagging...")
+	if err := e.service.tagWriter.WriteFileTags(ctx, track.Path, track); err != nil {
+		slog.Error("Failed to tag file", "trackID", track.ID, "error", err)
+		return nil, fmt.Errorf("failed to tag file: %w", err)
+	}
+
+	progressUpdater(90, "Replacing library file...")
+	if err := e.service.trackReplacer.ReplaceTrackFile(ctx, libraryTrackID, track.Path, job.Logger); err != nil {
+		return nil, fmt.Errorf("failed to replace library file: %w", err)
+	}
+
+	msg := fmt.Sprintf("Replaced %s with a fresh copy from %s", track.Title, downloader.Name())
+	job.Logger.Info(msg, "trackID", libraryTrackID, "color", "green")
+	progressUpdater(100, "Re-download completed")
+	return map[string]any{
+		"trackID": libraryTrackID,
+		"msg":     msg,
 	}, nil
 }
 
@@ -0,0 +1,93 @@
+package downloading
+
+import (
+	"log/slog"
+	"slices"
+
+	"github.com/contre95/soulsolid/src/music"
+)
+
+// SourceAttribute is the attribute of a search result naming the downloader it was found on,
+// set on the results of the fallback downloaders.
+const SourceAttribute = "downloader"
+
+// ResultSource returns the downloader a search result was found on, selected when it came from the selected downloader.
+func ResultSource(attributes map[string]string, selected string) string {
+	if source := attributes[SourceAttribute]; source != "" {
+		return source
+	}
+	return selected
+}
+
+// fallbackDownloaders returns the names of the downloaders to search when the selected one finds nothing, in the
+// configured order or else the order of the plugins. Disabled downloaders and the ones that can't
+// search are left out.
+func (s *Service) fallbackDownloaders(selected string) []string {
+	cfg := s.configManager.Get().Downloaders
+	if !cfg.Fallback.Enabled {
+		return nil
+	}
+	order := cfg.Fallback.Order
+	if len(order) == 0 {
+		for _, plugin := range cfg.Plugins {
+			order = append(order, plugin.Name)
+		}
+	}
+	var names []string
+	seen := []string{selected}
+	for _, name := range order {
+		if slices.Contains(seen, name) {
+			continue
+		}
+		seen = append(seen, name)
+		downloader, exists := s.pluginManager.GetDownloader(name)
+		if !exists {
+			slog.Debug("Fallback downloader not loaded", "downloader", name)
+			continue
+		}
+		if downloader.GetStatus().Status == "disabled" || !downloader.Capabilities().SupportsSearch {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// searchWithFallback runs search on the downloader selected by name and, when it finds nothing, on every
+// fallback downloader, merging their results labeled with the downloader they come from. The
+// selected downloader's error is returned only when no fallback finds anything either.
+func searchWithFallback[T any](s *Service, downloader Downloader, selected string, search func(Downloader) ([]T, error), attributes func(*T) *map[string]string) ([]T, error) {
+	results, err := search(downloader)
+	if err == nil && len(results) > 0 {
+		return results, nil
+	}
+	var merged []T
+	for _, name := range s.fallbackDownloaders(selected) {
+		fallback, exists := s.pluginManager.GetDownloader(name)
+		if !exists {
+			continue
+		}
+		found, ferr := search(fallback)
+		if ferr != nil {
+			slog.Warn("Fallback search failed", "downloader", name, "error", ferr)
+			continue
+		}
+		slog.Info("Fallback search", "selected", selected, "downloader", name, "results", len(found))
+		for i := range found {
+			attrs := attributes(&found[i])
+			if *attrs == nil {
+				*attrs = map[string]string{}
+			}
+			(*attrs)[SourceAttribute] = name
+		}
+		merged = append(merged, found...)
+	}
+	if len(merged) > 0 {
+		return merged, nil
+	}
+	return results, err
+}
+
+func trackAttributes(t *music.Track) *map[string]string { return &t.Attributes }
+
+func albumAttributes(a *music.Album) *map[string]string { return &a.Attributes }
@@ -1,11 +1,15 @@
 package downloading
 
 import (
+	"errors"
+	"io/fs"
 	"log/slog"
+	"slices"
 	"strconv"
 	"strings"
 
 	"github.com/contre95/soulsolid/src/features/hosting/respond"
+	"github.com/contre95/soulsolid/src/features/ui"
 	"github.com/contre95/soulsolid/src/music"
 	"github.com/gofiber/fiber/v2"
 )
@@ -33,6 +37,7 @@ func (h *Handler) RenderDownloadSection(c *fiber.Ctx) error {
 	return respond.Section(c, "download", fiber.Map{
 		"Title":             "Download",
 		"CurrentDownloader": downloader,
+		"Query":             c.Query("q"),
 	})
 }
 
@@ -85,10 +90,7 @@ func (h *Handler) SearchTracks(c *fiber.Ctx) error {
 		return respond.ToastErr(c, fiber.StatusInternalServerError, "Failed to search tracks")
 	}
 
-	trackPtrs := make([]*music.Track, len(tracks))
-	for i := range tracks {
-		trackPtrs[i] = &tracks[i]
-	}
+	trackPtrs := trackPointers(tracks, ui.FamilyFilter(c))
 	return respond.Partial(c, "downloading/spotify_track_results", fiber.Map{
 		"Tracks":     trackPtrs,
 		"Downloader": req.Downloader,
@@ -128,10 +130,7 @@ func (h *Handler) Search(c *fiber.Ctx) error {
 			slog.Error("Failed to search tracks", "error", err)
 			return respond.ToastErr(c, fiber.StatusInternalServerError, "Failed to search tracks")
 		}
-		trackPtrs := make([]*music.Track, len(tracks))
-		for i := range tracks {
-			trackPtrs[i] = &tracks[i]
-		}
+		trackPtrs := trackPointers(tracks, ui.FamilyFilter(c))
 		return respond.Partial(c, "downloading/spotify_track_results", fiber.Map{
 			"Tracks":     trackPtrs,
 			"Downloader": req.Downloader,
@@ -154,6 +153,9 @@ func (h *Handler) Search(c *fiber.Ctx) error {
 			slog.Error("Failed to search links", "error", err)
 			return respond.ToastErr(c, fiber.StatusInternalServerError, "Failed to search links")
 		}
+		if ui.FamilyFilter(c) {
+			result.Tracks = slices.DeleteFunc(result.Tracks, func(t music.Track) bool { return t.IsExplicit() })
+		}
 		if c.Get("HX-Request") != "true" {
 			return c.JSON(result)
 		}
@@ -168,10 +170,7 @@ func (h *Handler) Search(c *fiber.Ctx) error {
 		if len(result.Tracks) > 0 && result.Tracks[0].Attributes != nil {
 			playlistName = result.Tracks[0].Attributes["playlist_name"]
 		}
-		trackPtrs := make([]*music.Track, len(result.Tracks))
-		for i := range result.Tracks {
-			trackPtrs[i] = &result.Tracks[i]
-		}
+		trackPtrs := trackPointers(result.Tracks, false)
 		return c.Render("downloading/link_results", fiber.Map{
 			"Tracks":       trackPtrs,
 			"Downloader":   req.Downloader,
@@ -183,6 +182,18 @@ func (h *Handler) Search(c *fiber.Ctx) error {
 	}
 }
 
+// trackPointers returns pointers to the tracks, leaving out the explicit ones when excludeExplicit is set.
+func trackPointers(tracks []music.Track, excludeExplicit bool) []*music.Track {
+	trackPtrs := make([]*music.Track, 0, len(tracks))
+	for i := range tracks {
+		if excludeExplicit && tracks[i].IsExplicit() {
+			continue
+		}
+		trackPtrs = append(trackPtrs, &tracks[i])
+	}
+	return trackPtrs
+}
+
 // DownloadTrackRequest represents a download track request
 type DownloadTrackRequest struct {
 	TrackID string `json:"trackId" form:"trackId"`
@@ -218,10 +229,10 @@ func (h *Handler) DownloadTrack(c *fiber.Ctx) error {
 	downloader := strings.Clone(c.Query("downloader", "dummy"))
 	slog.Info("DownloadTrack", "downloader", downloader, "trackID", req.TrackID)
 
-	jobID, err := h.service.DownloadTrack(downloader, req.TrackID)
+	jobID, err := h.service.DownloadTrack(downloader, req.TrackID, ui.FamilyFilter(c))
 	if err != nil {
 		slog.Error("Failed to start track download", "error", err)
-		return respond.ToastErr(c, fiber.StatusInternalServerError, "Failed to start track download")
+		return startErr(c, err, "Failed to start track download")
 	}
 
 	return respond.ToastJob(c, jobID, "Track download started")
@@ -240,10 +251,10 @@ func (h *Handler) DownloadAlbum(c *fiber.Ctx) error {
 	}
 
 	downloader := strings.Clone(c.Query("downloader", "dummy"))
-	jobID, err := h.service.DownloadAlbum(downloader, req.AlbumID)
+	jobID, err := h.service.DownloadAlbum(downloader, req.AlbumID, ui.FamilyFilter(c))
 	if err != nil {
 		slog.Error("Failed to start album download", "error", err)
-		return respond.ToastErr(c, fiber.StatusInternalServerError, "Failed to start album download")
+		return startErr(c, err, "Failed to start album download")
 	}
 
 	return respond.ToastJob(c, jobID, "Album download started")
@@ -262,10 +273,10 @@ func (h *Handler) DownloadArtist(c *fiber.Ctx) error {
 	}
 
 	downloader := strings.Clone(c.Query("downloader", "dummy"))
-	jobID, err := h.service.DownloadArtist(downloader, req.ArtistID)
+	jobID, err := h.service.DownloadArtist(downloader, req.ArtistID, ui.FamilyFilter(c))
 	if err != nil {
 		slog.Error("Failed to start artist download", "error", err)
-		return respond.ToastErr(c, fiber.StatusInternalServerError, "Failed to start artist download")
+		return startErr(c, err, "Failed to start artist download")
 	}
 
 	return respond.ToastJob(c, jobID, "Artist download started")
@@ -289,10 +300,10 @@ func (h *Handler) DownloadTracks(c *fiber.Ctx) error {
 	}
 
 	downloader := strings.Clone(c.Query("downloader", "dummy"))
-	jobID, err := h.service.DownloadTracks(downloader, trackIDs)
+	jobID, err := h.service.DownloadTracks(downloader, trackIDs, ui.FamilyFilter(c))
 	if err != nil {
 		slog.Error("Failed to start tracks download", "error", err)
-		return respond.ToastErr(c, fiber.StatusInternalServerError, "Failed to start tracks download")
+		return startErr(c, err, "Failed to start tracks download")
 	}
 
 	return respond.ToastJob(c, jobID, "Tracks download started")
@@ -322,15 +333,55 @@ func (h *Handler) DownloadPlaylist(c *fiber.Ctx) error {
 	}
 
 	downloader := strings.Clone(c.Query("downloader", "dummy"))
-	jobID, err := h.service.DownloadPlaylist(downloader, trackIDs, req.PlaylistName)
+	jobID, err := h.service.DownloadPlaylist(downloader, trackIDs, req.PlaylistName, ui.FamilyFilter(c))
 	if err != nil {
 		slog.Error("Failed to start playlist download", "error", err)
-		return respond.ToastErr(c, fiber.StatusInternalServerError, "Failed to start playlist download")
+		return startErr(c, err, "Failed to start playlist download")
 	}
 
 	return respond.ToastJob(c, jobID, "Playlist '"+req.PlaylistName+"' download started")
 }
 
+// RedownloadFromSource handles requests to re-fetch a library track from its source URL
+func (h *Handler) RedownloadFromSource(c *fiber.Ctx) error {
+	slog.Debug("RedownloadFromSource handler called")
+
+	var req struct {
+		TrackID string `json:"trackId" form:"trackId"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return respond.ToastErr(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+	if req.TrackID == "" {
+		return respond.ToastErr(c, fiber.StatusBadRequest, "Track ID is required")
+	}
+
+	jobID, err := h.service.RedownloadFromSource(c.Context(), req.TrackID)
+	if err != nil {
+		slog.Error("Failed to start re-download", "error", err)
+		if errors.Is(err, music.ErrTrackLocked) {
+			return respond.ToastErr(c, fiber.StatusConflict, err.Error())
+		}
+		return startErr(c, err, "Failed to start re-download: "+err.Error())
+	}
+
+	c.Set("HX-Trigger", "refreshActiveJobsBadge")
+	return respond.ToastJob(c, jobID, "Re-download from source started")
+}
+
+// GetSourceButton renders the re-download button of a library track, empty when no downloader
+// handles its source.
+func (h *Handler) GetSourceButton(c *fiber.Ctx) error {
+	track, err := h.service.library.GetTrack(c.Context(), c.Params("trackId"))
+	if err != nil || track == nil {
+		return c.SendString("")
+	}
+	if _, ok := h.service.SourceDownloader(track); !ok {
+		return c.SendString("")
+	}
+	return respond.Partial(c, "downloading/source_button", fiber.Map{"Track": track})
+}
+
 // GetAlbumTracks handles requests to get tracks from an album
 func (h *Handler) GetAlbumTracks(c *fiber.Ctx) error {
 	slog.Debug("GetAlbumTracks handler called")
@@ -352,15 +403,11 @@ func (h *Handler) GetAlbumTracks(c *fiber.Ctx) error {
 		album = tracks[0].Album
 	}
 
+	trackPtrs := trackPointers(tracks, ui.FamilyFilter(c))
 	var totalDuration int
-	for _, track := range tracks {
+	for _, track := range trackPtrs {
 		totalDuration += track.Metadata.Duration
 	}
-
-	trackPtrs := make([]*music.Track, len(tracks))
-	for i := range tracks {
-		trackPtrs[i] = &tracks[i]
-	}
 	return respond.Partial(c, "downloading/album_tracks", fiber.Map{
 		"Album":         album,
 		"Tracks":        trackPtrs,
@@ -417,10 +464,7 @@ func (h *Handler) GetChartTracks(c *fiber.Ctx) error {
 		})
 	}
 
-	trackPtrs := make([]*music.Track, len(tracks))
-	for i := range tracks {
-		trackPtrs[i] = &tracks[i]
-	}
+	trackPtrs := trackPointers(tracks, ui.FamilyFilter(c))
 	return respond.Partial(c, "downloading/chart_tracks", fiber.Map{
 		"Tracks":           trackPtrs,
 		"DownloaderStatus": downloaderStatus,
@@ -456,6 +500,7 @@ func (h *Handler) GetUserInfo(c *fiber.Ctx) error {
 		"HasDownloaders":    hasDownloaders,
 		"CurrentDownloader": downloader,
 		"Capabilities":      caps,
+		"Query":             c.Query("q"),
 	})
 }
 
@@ -468,3 +513,15 @@ func (h *Handler) GetDownloaderCapabilities(c *fiber.Ctx) error {
 	}
 	return c.JSON(caps)
 }
+
+// startErr responds to a download that couldn't be started. Permission problems are shown as
+// they are since their message says how to fix them, other errors get msg.
+func startErr(c *fiber.Ctx, err error, msg string) error {
+	if errors.Is(err, fs.ErrPermission) {
+		return respond.ToastErr(c, fiber.StatusForbidden, err.Error())
+	}
+	if errors.Is(err, music.ErrMaintenance) {
+		return respond.ToastErr(c, fiber.StatusServiceUnavailable, err.Error())
+	}
+	return respond.ToastErr(c, fiber.StatusInternalServerError, msg)
+}
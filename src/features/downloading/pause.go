@@ -0,0 +1,31 @@
+package downloading
+
+import "log/slog"
+
+// JobTypes are the jobs the downloading service starts, all run by the download job task.
+var JobTypes = []string{"download_track", "download_album", "download_artist", "download_tracks", "download_playlist", "download_source"}
+
+// JobHolder keeps the jobs of some types pending.
+type JobHolder interface {
+	Hold(jobTypes ...string)
+	Release(jobTypes ...string)
+	Held(jobType string) bool
+}
+
+// PauseDownloads keeps new and pending downloads queued until ResumeDownloads. A download
+// already running is left to finish.
+func (s *Service) PauseDownloads() {
+	s.holder.Hold(JobTypes...)
+	slog.Info("Downloads paused")
+}
+
+// ResumeDownloads starts the downloads queued while paused.
+func (s *Service) ResumeDownloads() {
+	s.holder.Release(JobTypes...)
+	slog.Info("Downloads resumed")
+}
+
+// DownloadsPaused reports whether downloads are paused.
+func (s *Service) DownloadsPaused() bool {
+	return s.holder.Held(JobTypes[0])
+}
@@ -0,0 +1,18 @@
+package downloading
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/contre95/soulsolid/src/music"
+)
+
+// TrackLibrary loads the library tracks re-downloaded from their source.
+type TrackLibrary interface {
+	GetTrack(ctx context.Context, id string) (*music.Track, error)
+}
+
+// TrackReplacer swaps the file of an existing library track for a newly downloaded one.
+type TrackReplacer interface {
+	ReplaceTrackFile(ctx context.Context, trackID, filePath string, logger *slog.Logger) error
+}
@@ -20,6 +20,8 @@ func RegisterRoutes(app *fiber.App, service *Service) {
 	downloads.Post("/artist", handler.DownloadArtist)
 	downloads.Post("/tracks", handler.DownloadTracks)
 	downloads.Post("/playlist", handler.DownloadPlaylist)
+	downloads.Post("/source", handler.RedownloadFromSource)
+	downloads.Get("/source/:trackId/button", handler.GetSourceButton)
 	downloads.Get("/capabilities", handler.GetDownloaderCapabilities)
 	downloads.Get("/user/info", handler.GetUserInfo)
 	downloads.Get("/chart/tracks", handler.GetChartTracks)
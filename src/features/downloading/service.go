@@ -1,6 +1,7 @@
 package downloading
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"strings"
@@ -13,21 +14,27 @@ import (
 type Service struct {
 	configManager *config.Manager
 	jobService    music.JobService // TODO: Move this to domain job service
+	holder        JobHolder
 	pluginManager *PluginManager
 	tagWriter     TagWriter
+	trackReplacer TrackReplacer
+	library       TrackLibrary
 }
 
 // NewService creates a new downloading service
-func NewService(cfgManager *config.Manager, jobService music.JobService, pluginManager *PluginManager, tagWriter TagWriter) *Service {
+func NewService(cfgManager *config.Manager, jobService music.JobService, holder JobHolder, pluginManager *PluginManager, tagWriter TagWriter, trackReplacer TrackReplacer, library TrackLibrary) *Service {
 	return &Service{
 		configManager: cfgManager,
 		jobService:    jobService,
+		holder:        holder,
 		pluginManager: pluginManager,
 		tagWriter:     tagWriter,
+		trackReplacer: trackReplacer,
+		library:       library,
 	}
 }
 
-// SearchAlbums searches for albums
+// SearchAlbums searches for albums, on the fallback downloaders too when the downloader finds none.
 func (s *Service) SearchAlbums(downloaderName, query string, limit int) ([]music.Album, error) {
 	downloader, exists := s.pluginManager.GetDownloader(downloaderName)
 	if !exists {
@@ -39,10 +46,12 @@ func (s *Service) SearchAlbums(downloaderName, query string, limit int) ([]music
 	if limit > 100 {
 		limit = 100
 	}
-	return downloader.SearchAlbums(query, limit)
+	return searchWithFallback(s, downloader, downloaderName, func(d Downloader) ([]music.Album, error) {
+		return d.SearchAlbums(query, limit)
+	}, albumAttributes)
 }
 
-// SearchTracks searches for tracks
+// SearchTracks searches for tracks, on the fallback downloaders too when the downloader finds none.
 func (s *Service) SearchTracks(downloaderName, query string, limit int) ([]music.Track, error) {
 	downloader, exists := s.pluginManager.GetDownloader(downloaderName)
 	if !exists {
@@ -56,7 +65,9 @@ func (s *Service) SearchTracks(downloaderName, query string, limit int) ([]music
 		limit = 100
 	}
 
-	return downloader.SearchTracks(query, limit)
+	return searchWithFallback(s, downloader, downloaderName, func(d Downloader) ([]music.Track, error) {
+		return d.SearchTracks(query, limit)
+	}, trackAttributes)
 }
 
 // SearchArtists searches for artists
@@ -93,19 +104,33 @@ func (s *Service) SearchLinks(downloaderName, query string, limit int) (*LinkRes
 	return downloader.SearchLinks(query, limit)
 }
 
-// DownloadTrack starts a download job for a track
-func (s *Service) DownloadTrack(downloaderName, trackID string) (string, error) {
+// preflight checks that the download path is writable, so a read-only or mis-owned directory
+// fails the download right away with a message saying how to fix it.
+func (s *Service) preflight() error {
+	if err := config.CheckWritable(s.configManager.Get().DownloadPath); err != nil {
+		return fmt.Errorf("download path: %w", err)
+	}
+	return nil
+}
+
+// DownloadTrack starts a download job for a track.
+// With familyFilter the job discards explicit tracks instead of keeping them, the same goes for the other download jobs.
+func (s *Service) DownloadTrack(downloaderName, trackID string, familyFilter bool) (string, error) {
 	slog.Info("DownloadTrack service", "downloaderName", downloaderName, "trackID", trackID)
 	_, exists := s.pluginManager.GetDownloader(downloaderName)
 	if !exists {
 		slog.Error("Downloader not found", "downloaderName", downloaderName, "available", s.pluginManager.GetDownloaderNames())
 		return "", fmt.Errorf("downloader %s not found", downloaderName)
 	}
+	if err := s.preflight(); err != nil {
+		return "", err
+	}
 
 	jobID, err := s.jobService.StartJob("download_track", "Download Track", map[string]any{
-		"trackID":    trackID,
-		"downloader": downloaderName,
-		"type":       "track",
+		"trackID":      trackID,
+		"downloader":   downloaderName,
+		"type":         "track",
+		"familyFilter": familyFilter,
 	})
 	if err != nil {
 		slog.Error("Failed to start download job", "error", err)
@@ -116,16 +141,20 @@ func (s *Service) DownloadTrack(downloaderName, trackID string) (string, error)
 }
 
 // DownloadAlbum starts a download job for an album
-func (s *Service) DownloadAlbum(downloaderName, albumID string) (string, error) {
+func (s *Service) DownloadAlbum(downloaderName, albumID string, familyFilter bool) (string, error) {
 	_, exists := s.pluginManager.GetDownloader(downloaderName)
 	if !exists {
 		return "", fmt.Errorf("downloader %s not found", downloaderName)
 	}
+	if err := s.preflight(); err != nil {
+		return "", err
+	}
 
 	jobID, err := s.jobService.StartJob("download_album", "Download Album", map[string]any{
-		"albumID":    albumID,
-		"downloader": downloaderName,
-		"type":       "album",
+		"albumID":      albumID,
+		"downloader":   downloaderName,
+		"type":         "album",
+		"familyFilter": familyFilter,
 	})
 	if err != nil {
 		slog.Error("Failed to start download job", "error", err)
@@ -136,16 +165,20 @@ func (s *Service) DownloadAlbum(downloaderName, albumID string) (string, error)
 }
 
 // DownloadArtist starts a download job for an artist
-func (s *Service) DownloadArtist(downloaderName, artistID string) (string, error) {
+func (s *Service) DownloadArtist(downloaderName, artistID string, familyFilter bool) (string, error) {
 	_, exists := s.pluginManager.GetDownloader(downloaderName)
 	if !exists {
 		return "", fmt.Errorf("downloader %s not found", downloaderName)
 	}
+	if err := s.preflight(); err != nil {
+		return "", err
+	}
 
 	jobID, err := s.jobService.StartJob("download_artist", "Download Artist", map[string]any{
-		"artistID":   artistID,
-		"downloader": downloaderName,
-		"type":       "artist",
+		"artistID":     artistID,
+		"downloader":   downloaderName,
+		"type":         "artist",
+		"familyFilter": familyFilter,
 	})
 	if err != nil {
 		slog.Error("Failed to start download job", "error", err)
@@ -156,16 +189,20 @@ func (s *Service) DownloadArtist(downloaderName, artistID string) (string, error
 }
 
 // DownloadTracks starts a download job for multiple tracks
-func (s *Service) DownloadTracks(downloaderName string, trackIDs []string) (string, error) {
+func (s *Service) DownloadTracks(downloaderName string, trackIDs []string, familyFilter bool) (string, error) {
 	_, exists := s.pluginManager.GetDownloader(downloaderName)
 	if !exists {
 		return "", fmt.Errorf("downloader %s not found", downloaderName)
 	}
+	if err := s.preflight(); err != nil {
+		return "", err
+	}
 
 	jobID, err := s.jobService.StartJob("download_tracks", "Download Tracks", map[string]any{
-		"trackIDs":   trackIDs,
-		"downloader": downloaderName,
-		"type":       "tracks",
+		"trackIDs":     trackIDs,
+		"downloader":   downloaderName,
+		"type":         "tracks",
+		"familyFilter": familyFilter,
 	})
 	if err != nil {
 		slog.Error("Failed to start download job", "error", err)
@@ -176,17 +213,21 @@ func (s *Service) DownloadTracks(downloaderName string, trackIDs []string) (stri
 }
 
 // DownloadPlaylist starts a download job for a playlist
-func (s *Service) DownloadPlaylist(downloaderName string, trackIDs []string, playlistName string) (string, error) {
+func (s *Service) DownloadPlaylist(downloaderName string, trackIDs []string, playlistName string, familyFilter bool) (string, error) {
 	_, exists := s.pluginManager.GetDownloader(downloaderName)
 	if !exists {
 		return "", fmt.Errorf("downloader %s not found", downloaderName)
 	}
+	if err := s.preflight(); err != nil {
+		return "", err
+	}
 
 	jobID, err := s.jobService.StartJob("download_playlist", fmt.Sprintf("Download Playlist: %s", playlistName), map[string]any{
 		"trackIDs":     trackIDs,
 		"downloader":   downloaderName,
 		"playlistName": playlistName,
 		"type":         "playlist",
+		"familyFilter": familyFilter,
 	})
 	if err != nil {
 		slog.Error("Failed to start download job", "error", err)
@@ -196,6 +237,57 @@ func (s *Service) DownloadPlaylist(downloaderName string, trackIDs []string, pla
 	return jobID, nil
 }
 
+// SourceDownloader returns the downloader a library track was downloaded with, matched by the
+// track's metadata source, when it supports direct links and the track has a source URL.
+func (s *Service) SourceDownloader(track *music.Track) (string, bool) {
+	sourceURL := track.MetadataSource.MetadataSourceURL
+	if !strings.HasPrefix(sourceURL, "http://") && !strings.HasPrefix(sourceURL, "https://") {
+		return "", false
+	}
+	source := track.MetadataSource.Source
+	for _, name := range s.pluginManager.GetDownloaderNames() {
+		downloader, _ := s.pluginManager.GetDownloader(name)
+		if !downloader.Capabilities().SupportsDirectLinks {
+			continue
+		}
+		if strings.EqualFold(name, source) || strings.EqualFold(downloader.Name(), source) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// RedownloadFromSource starts a job that fetches a library track again from the source URL
+// stored with it, with the downloader of its source, and replaces the library file with the
+// result. Locked tracks and tracks from other sources, e.g. metadata providers, are refused.
+func (s *Service) RedownloadFromSource(ctx context.Context, trackID string) (string, error) {
+	track, err := s.library.GetTrack(ctx, trackID)
+	if err != nil || track == nil {
+		return "", fmt.Errorf("track not found: %s", trackID)
+	}
+	if track.IsLocked() {
+		return "", fmt.Errorf("%w: unlock %q to replace its file", music.ErrTrackLocked, track.Title)
+	}
+	downloaderName, ok := s.SourceDownloader(track)
+	if !ok {
+		return "", fmt.Errorf("no downloader for the %q source of this track", track.MetadataSource.Source)
+	}
+	if err := s.preflight(); err != nil {
+		return "", err
+	}
+
+	jobID, err := s.jobService.StartJob("download_source", "Re-download From Source", map[string]any{
+		"libraryTrackID": trackID,
+		"sourceURL":      track.MetadataSource.MetadataSourceURL,
+		"downloader":     downloaderName,
+		"type":           "source",
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to start re-download job: %w", err)
+	}
+	return jobID, nil
+}
+
 // GetAlbumTracks retrieves all tracks from a specific album
 func (s *Service) GetAlbumTracks(downloaderName, albumID string) ([]music.Track, error) {
 	downloader, exists := s.pluginManager.GetDownloader(downloaderName)
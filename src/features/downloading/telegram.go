@@ -0,0 +1,233 @@
+package downloading
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/contre95/soulsolid/src/features/config"
+	"github.com/contre95/soulsolid/src/music"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// telegramSearchLimit caps the tracks and albums listed by /search and inline queries
+const telegramSearchLimit = 10
+
+// TelegramHandler handles Telegram commands for the downloading feature.
+// Searches and downloads go through the first configured downloader.
+type TelegramHandler struct {
+	service *Service
+	config  *config.Manager
+}
+
+// NewTelegramHandler creates a new Telegram handler for the downloading feature
+func NewTelegramHandler(service *Service, cfg *config.Manager) *TelegramHandler {
+	return &TelegramHandler{service: service, config: cfg}
+}
+
+// HandleCommand processes downloading-related Telegram commands
+func (h *TelegramHandler) HandleCommand(bot *tgbotapi.BotAPI, chatID int64, command string, args string) error {
+	switch command {
+	case "search":
+		return h.handleSearch(bot, chatID, args)
+	case "download":
+		return h.handleDownload(bot, chatID, args)
+	default:
+		bot.Send(tgbotapi.NewMessage(chatID, "❌ Unknown download command. Use /search <query> or /download <type> <id>"))
+		return nil
+	}
+}
+
+// GetCommands returns the available commands for this handler
+func (h *TelegramHandler) GetCommands() map[string]string {
+	return map[string]string{
+		"search":   "Search the downloader (/search [tracks|albums] <query>)",
+		"download": "Download a track or album (/download <track|album> <id>)",
+	}
+}
+
+// HandleCallback handles the download buttons of search and inline results
+func (h *TelegramHandler) HandleCallback(bot *tgbotapi.BotAPI, callback *tgbotapi.CallbackQuery) bool {
+	data, ok := strings.CutPrefix(callback.Data, "dl:")
+	if !ok {
+		return false // Not handled by this feature
+	}
+
+	parts := strings.SplitN(data, ":", 3)
+	if len(parts) != 3 {
+		return false
+	}
+	jobID, err := h.startDownload(parts[0], parts[1], parts[2])
+	if err != nil {
+		slog.Error("Failed to start download from Telegram", "downloader", parts[0], "type", parts[1], "id", parts[2], "error", err)
+		bot.Request(tgbotapi.NewCallbackWithAlert(callback.ID, "❌ "+err.Error()))
+		return true
+	}
+	bot.Request(tgbotapi.NewCallback(callback.ID, fmt.Sprintf("⬇️ Download started (job %s)", jobID)))
+	return true
+}
+
+// HandleInlineQuery returns the downloader tracks and albums matching query as inline articles
+func (h *TelegramHandler) HandleInlineQuery(query string) ([]any, error) {
+	downloader := h.defaultDownloader()
+	if downloader == "" {
+		return nil, fmt.Errorf("no downloader configured")
+	}
+	tracks, albums, err := h.search(downloader, "", query)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]any, 0, len(tracks)+len(albums))
+	for _, track := range tracks {
+		artists := telegramArtists(track.Artists)
+		article := tgbotapi.NewInlineQueryResultArticle("dl_track_"+track.ID, "🎵 "+track.Title, fmt.Sprintf("🎵 %s\n👤 %s", track.Title, artists))
+		article.Description = artists
+		article.ReplyMarkup = downloadKeyboard(ResultSource(track.Attributes, downloader), "track", track.ID)
+		results = append(results, article)
+	}
+	for _, album := range albums {
+		artists := telegramArtists(album.Artists)
+		article := tgbotapi.NewInlineQueryResultArticle("dl_album_"+album.ID, "💿 "+album.Title, fmt.Sprintf("💿 %s\n👤 %s", album.Title, artists))
+		article.Description = artists
+		article.ReplyMarkup = downloadKeyboard(ResultSource(album.Attributes, downloader), "album", album.ID)
+		results = append(results, article)
+	}
+	return results, nil
+}
+
+// handleSearch lists the downloader results for args with a download button for each one
+func (h *TelegramHandler) handleSearch(bot *tgbotapi.BotAPI, chatID int64, args string) error {
+	downloader := h.defaultDownloader()
+	if downloader == "" {
+		bot.Send(tgbotapi.NewMessage(chatID, "❌ No downloader configured"))
+		return nil
+	}
+	kind, query := "", strings.TrimSpace(args)
+	if first, rest, ok := strings.Cut(query, " "); ok && (first == "tracks" || first == "albums") {
+		kind, query = first, strings.TrimSpace(rest)
+	}
+	if query == "" {
+		bot.Send(tgbotapi.NewMessage(chatID, "❌ Please provide a search query: /search [tracks|albums] <query>"))
+		return nil
+	}
+
+	tracks, albums, err := h.search(downloader, kind, query)
+	if err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, "❌ Search failed: "+err.Error()))
+		return err
+	}
+	if len(tracks) == 0 && len(albums) == 0 {
+		bot.Send(tgbotapi.NewMessage(chatID, "🔍 No results found"))
+		return nil
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, track := range tracks {
+		if data, ok := downloadData(ResultSource(track.Attributes, downloader), "track", track.ID); ok {
+			label := fmt.Sprintf("🎵 %s - %s", track.Title, telegramArtists(track.Artists))
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(label, data)))
+		}
+	}
+	for _, album := range albums {
+		if data, ok := downloadData(ResultSource(album.Attributes, downloader), "album", album.ID); ok {
+			label := fmt.Sprintf("💿 %s - %s", album.Title, telegramArtists(album.Artists))
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(label, data)))
+		}
+	}
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("🔍 Results for \"%s\" on %s, tap one to download it:", query, downloader))
+	if len(rows) > 0 {
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	}
+	bot.Send(msg)
+	return nil
+}
+
+// handleDownload starts a download for args in the form "<track|album> <id>"
+func (h *TelegramHandler) handleDownload(bot *tgbotapi.BotAPI, chatID int64, args string) error {
+	fields := strings.Fields(args)
+	if len(fields) != 2 {
+		bot.Send(tgbotapi.NewMessage(chatID, "❌ Please use the format: /download <track|album> <id>"))
+		return nil
+	}
+	downloader := h.defaultDownloader()
+	if downloader == "" {
+		bot.Send(tgbotapi.NewMessage(chatID, "❌ No downloader configured"))
+		return nil
+	}
+	jobID, err := h.startDownload(downloader, fields[0], fields[1])
+	if err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, "❌ Failed to start download: "+err.Error()))
+		return nil
+	}
+	bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("⬇️ Download started (job %s)", jobID)))
+	return nil
+}
+
+// search looks up tracks, albums or both (kind "") on downloader
+func (h *TelegramHandler) search(downloader, kind, query string) ([]music.Track, []music.Album, error) {
+	var tracks []music.Track
+	var albums []music.Album
+	var err error
+	if kind != "albums" {
+		if tracks, err = h.service.SearchTracks(downloader, query, telegramSearchLimit); err != nil {
+			return nil, nil, err
+		}
+	}
+	if kind != "tracks" {
+		if albums, err = h.service.SearchAlbums(downloader, query, telegramSearchLimit); err != nil {
+			return nil, nil, err
+		}
+	}
+	return tracks, albums, nil
+}
+
+// startDownload starts a track or album download job
+func (h *TelegramHandler) startDownload(downloader, kind, id string) (string, error) {
+	switch kind {
+	case "track":
+		return h.service.DownloadTrack(downloader, id, false)
+	case "album":
+		return h.service.DownloadAlbum(downloader, id, false)
+	default:
+		return "", fmt.Errorf("unknown download type %q, use track or album", kind)
+	}
+}
+
+// defaultDownloader returns the name of the first configured downloader, the one the download page opens with
+func (h *TelegramHandler) defaultDownloader() string {
+	plugins := h.config.Get().Downloaders.Plugins
+	if len(plugins) == 0 {
+		return ""
+	}
+	return plugins[0].Name
+}
+
+// downloadData builds the callback data of a download button. Telegram limits it to 64 bytes.
+func downloadData(downloader, kind, id string) (string, bool) {
+	data := fmt.Sprintf("dl:%s:%s:%s", downloader, kind, id)
+	return data, len(data) <= 64
+}
+
+// downloadKeyboard returns the keyboard of an inline result, nil when the id doesn't fit a button
+func downloadKeyboard(downloader, kind, id string) *tgbotapi.InlineKeyboardMarkup {
+	data, ok := downloadData(downloader, kind, id)
+	if !ok {
+		return nil
+	}
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("⬇️ Download", data),
+	))
+	return &keyboard
+}
+
+// telegramArtists joins the artist names of roles
+func telegramArtists(roles []music.ArtistRole) string {
+	names := make([]string, 0, len(roles))
+	for _, role := range roles {
+		if role.Artist != nil {
+			names = append(names, role.Artist.Name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
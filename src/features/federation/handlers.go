@@ -0,0 +1,155 @@
+package federation
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/contre95/soulsolid/src/features/hosting/respond"
+	"github.com/gofiber/fiber/v2"
+)
+
+// streamClient proxies remote streams. It has no timeout since a track plays for minutes, the
+// transfer ends when the player stops reading.
+var streamClient = &http.Client{}
+
+// Handler handles the federation requests: the shared API read by other instances and the UI
+// reading the remotes.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new federation handler.
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RequireKey rejects shared API requests without this instance's API key.
+func (h *Handler) RequireKey(c *fiber.Ctx) error {
+	if !h.service.Sharing() {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "library sharing is disabled"})
+	}
+	if !h.service.Authorized(c.Get(apiKeyHeader)) {
+		slog.Warn("Rejected federation request with an invalid API key", "ip", c.IP(), "path", c.Path())
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid API key"})
+	}
+	return c.Next()
+}
+
+// ServeInfo describes the shared library.
+func (h *Handler) ServeInfo(c *fiber.Ctx) error {
+	info, err := h.service.SharedInfo(c.Context())
+	if err != nil {
+		slog.Error("Failed to describe shared library", "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(info)
+}
+
+// ServeSearch searches the shared library (query: query, limit).
+func (h *Handler) ServeSearch(c *fiber.Ctx) error {
+	tracks, err := h.service.SharedSearch(c.Context(), c.Query("query"), c.QueryInt("limit", 20))
+	if err != nil {
+		slog.Error("Failed to search shared library", "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"tracks": tracks})
+}
+
+// ServeTrack returns a shared track.
+func (h *Handler) ServeTrack(c *fiber.Ctx) error {
+	track, _, err := h.service.SharedTrack(c.Context(), c.Params("trackId"))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(track)
+}
+
+// ServeTrackFile sends the file of a shared track, with range requests for streaming.
+func (h *Handler) ServeTrackFile(c *fiber.Ctx) error {
+	_, path, err := h.service.SharedTrack(c.Context(), c.Params("trackId"))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	}
+	slog.Debug("Serving shared track", "path", path, "ip", c.IP())
+	c.Set("Accept-Ranges", "bytes")
+	// Escaped for the same reason as the streaming handler: fasthttp parses the path as a URI
+	return c.SendFile((&url.URL{Path: path}).EscapedPath())
+}
+
+// GetPanel renders the federation card of the settings section.
+func (h *Handler) GetPanel(c *fiber.Ctx) error {
+	remotes := []string{}
+	for _, remote := range h.service.Remotes() {
+		remotes = append(remotes, remote.Name)
+	}
+	return respond.Partial(c, "cards/federation", fiber.Map{
+		"Sharing": h.service.Sharing(),
+		"Remotes": remotes,
+	})
+}
+
+// PingRemote checks that a remote answers and accepts the configured key.
+func (h *Handler) PingRemote(c *fiber.Ctx) error {
+	name := remoteParam(c)
+	info, err := h.service.Ping(c.Context(), name)
+	if err != nil {
+		slog.Error("Failed to reach remote", "remote", name, "error", err)
+		return respond.ToastErr(c, fiber.StatusBadGateway, err.Error())
+	}
+	return respond.ToastOk(c, fmt.Sprintf("%s is reachable, %d tracks shared", name, info.Tracks))
+}
+
+// Search searches the remotes (query: query, limit), rendered below the library search results.
+func (h *Handler) Search(c *fiber.Ctx) error {
+	query := strings.TrimSpace(c.Query("query"))
+	results := []RemoteResults{}
+	if query != "" {
+		results = h.service.Search(c.Context(), query, c.QueryInt("limit", 20))
+	}
+	return respond.Partial(c, "federation/remote_results", fiber.Map{
+		"Results": results,
+		"Query":   query,
+	})
+}
+
+// StreamRemoteTrack proxies the file of a remote track to the player, so the remote's key stays on the server.
+func (h *Handler) StreamRemoteTrack(c *fiber.Ctx) error {
+	name, trackID := remoteParam(c), c.Params("trackId")
+	resp, err := h.service.OpenTrackFile(c.Context(), streamClient, name, trackID, c.Get("Range"))
+	if err != nil {
+		slog.Error("Failed to stream remote track", "remote", name, "trackID", trackID, "error", err)
+		return c.Status(fiber.StatusBadGateway).SendString(err.Error())
+	}
+	c.Status(resp.StatusCode)
+	for _, header := range []string{"Content-Type", "Content-Range", "Accept-Ranges"} {
+		if value := resp.Header.Get(header); value != "" {
+			c.Set(header, value)
+		}
+	}
+	// The body is closed by fasthttp once it's sent
+	return c.SendStream(resp.Body, int(resp.ContentLength))
+}
+
+// PullRemoteTrack starts a job copying a remote track into the local library.
+func (h *Handler) PullRemoteTrack(c *fiber.Ctx) error {
+	name, trackID := remoteParam(c), c.Params("trackId")
+	jobID, err := h.service.StartPull(c.Context(), name, trackID)
+	if err != nil {
+		slog.Error("Failed to start pull", "remote", name, "trackID", trackID, "error", err)
+		return respond.ToastErr(c, fiber.StatusInternalServerError, "Failed to pull track: "+err.Error())
+	}
+	c.Set("HX-Trigger", "refreshJobList")
+	return respond.ToastJob(c, jobID, "Pulling track from "+name)
+}
+
+// remoteParam returns the remote name of the path, query-escaped by the views.
+func remoteParam(c *fiber.Ctx) string {
+	name, err := url.QueryUnescape(c.Params("remote"))
+	if err != nil {
+		return c.Params("remote")
+	}
+	return name
+}
@@ -0,0 +1,126 @@
+package federation
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/contre95/soulsolid/src/music"
+)
+
+// pullClient downloads the pulled files. The timeout is generous because a lossless file can
+// take minutes from a remote on a slow uplink, but still bounds a hung remote.
+var pullClient = &http.Client{Timeout: 30 * time.Minute}
+
+// PullTask implements jobs.Task for copying a remote track into the local library. The file is
+// downloaded to its own folder of the download path and imported from there like any download.
+type PullTask struct {
+	service *Service
+}
+
+// NewPullTask creates a new PullTask.
+func NewPullTask(service *Service) *PullTask {
+	return &PullTask{service: service}
+}
+
+// MetadataKeys returns the required metadata keys for a pull job.
+func (t *PullTask) MetadataKeys() []string {
+	return []string{"remote", "track_id"}
+}
+
+// Execute downloads the remote track and starts its import.
+func (t *PullTask) Execute(ctx context.Context, job *music.Job, progressUpdater func(int, string)) (map[string]any, error) {
+	remote, _ := job.Metadata["remote"].(string)
+	trackID, _ := job.Metadata["track_id"].(string)
+	logger := job.Logger
+
+	progressUpdater(0, "Looking up the track on "+remote)
+	track, err := t.service.GetRemoteTrack(ctx, remote, trackID)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("Pulling track", "remote", remote, "title", track.Title, "artist", track.Artist, "album", track.Album)
+
+	dir := filepath.Join(t.service.config.Get().DownloadPath, "federation", job.ID)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create pull folder: %w", err)
+	}
+	resp, err := t.service.OpenTrackFile(ctx, pullClient, remote, trackID, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	path := filepath.Join(dir, pulledFileName(track))
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	written, err := io.Copy(file, &progressReader{r: resp.Body, total: resp.ContentLength, update: func(done int64) {
+		progressUpdater(int(done*90/max(resp.ContentLength, 1)), "Downloading "+track.Title)
+	}})
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return nil, fmt.Errorf("failed to download %s: %w", track.Title, err)
+	}
+	logger.Info("Downloaded track", "path", path, "bytes", written, "color", "green")
+
+	progressUpdater(95, "Importing "+track.Title)
+	importJobID, err := t.service.importer.ImportDirectory(ctx, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start import of %s: %w", path, err)
+	}
+	msg := fmt.Sprintf("Pulled %s by %s from %s, importing it", track.Title, track.Artist, remote)
+	progressUpdater(100, msg)
+	slog.Info("Pulled remote track", "remote", remote, "trackID", trackID, "importJob", importJobID)
+	return map[string]any{"msg": msg, "path": path, "import_job_id": importJobID}, nil
+}
+
+// Cleanup does nothing, the pulled file is left to the import.
+func (t *PullTask) Cleanup(job *music.Job) error {
+	return nil
+}
+
+// pulledFileName names the pulled file after the track, the import renames it anyway. The
+// names come from the remote, so path separators are replaced.
+func pulledFileName(track *RemoteTrack) string {
+	safe := func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|.`, r) || r < ' ' {
+			return '_'
+		}
+		return r
+	}
+	format := strings.Map(safe, strings.ToLower(track.Format))
+	if format == "" {
+		format = "mp3"
+	}
+	return strings.Map(safe, strings.TrimSpace(track.Artist+" - "+track.Title)) + "." + format
+}
+
+// progressReader reports how much of a download was read, at most once per percent.
+type progressReader struct {
+	r       io.Reader
+	total   int64
+	done    int64
+	percent int64
+	update  func(done int64)
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.done += int64(n)
+	if percent := p.done * 100 / max(p.total, 1); percent != p.percent {
+		p.percent = percent
+		p.update(p.done)
+	}
+	return n, err
+}
@@ -0,0 +1,23 @@
+package federation
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// RegisterRoutes registers the federation routes with the Fiber app.
+func RegisterRoutes(app *fiber.App, handler *Handler) {
+	// Shared API read by other instances
+	shared := app.Group("/federation/v1", handler.RequireKey)
+	shared.Get("/info", handler.ServeInfo)
+	shared.Get("/search", handler.ServeSearch)
+	shared.Get("/tracks/:trackId", handler.ServeTrack)
+	shared.Get("/tracks/:trackId/file", handler.ServeTrackFile)
+
+	// Remotes read by this instance
+	group := app.Group("/federation")
+	group.Get("/panel", handler.GetPanel)
+	group.Get("/search", handler.Search)
+	group.Post("/remotes/:remote/ping", handler.PingRemote)
+	group.Get("/remotes/:remote/tracks/:trackId/stream", handler.StreamRemoteTrack)
+	group.Post("/remotes/:remote/tracks/:trackId/pull", handler.PullRemoteTrack)
+}
@@ -0,0 +1,228 @@
+package federation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/contre95/soulsolid/src/features/config"
+	"github.com/contre95/soulsolid/src/music"
+)
+
+// apiKeyHeader carries the key of the instance being read.
+const apiKeyHeader = "X-Api-Key"
+
+// searchClient queries the remote catalogs. Searches are bounded so a slow remote doesn't hold
+// the library search, file transfers use their own clients.
+var searchClient = &http.Client{Timeout: 10 * time.Second}
+
+// RemoteTrack is a track of a shared library, as served to other instances.
+type RemoteTrack struct {
+	ID       string `json:"id"`
+	Title    string `json:"title"`
+	Artist   string `json:"artist"`
+	Album    string `json:"album"`
+	Year     int    `json:"year,omitempty"`
+	Duration int    `json:"duration"` // seconds
+	Format   string `json:"format"`
+	Bitrate  int    `json:"bitrate,omitempty"`
+	Remote   string `json:"remote,omitempty"` // name of the remote it was found on, set by the reading instance
+}
+
+// RemoteResults are the tracks a remote found for a search.
+type RemoteResults struct {
+	Remote string        `json:"remote"`
+	Tracks []RemoteTrack `json:"tracks"`
+	Error  string        `json:"error,omitempty"`
+}
+
+// RemoteInfo describes a shared library.
+type RemoteInfo struct {
+	Tracks int `json:"tracks"`
+}
+
+// Catalog is the local library shared with the remotes.
+type Catalog interface {
+	GetTrack(ctx context.Context, id string) (*music.Track, error)
+	GetTracksCount(ctx context.Context) (int, error)
+	GetTracksFilteredPaginated(ctx context.Context, limit, offset int, filter *music.TrackFilter) ([]*music.Track, error)
+}
+
+// Importer imports the files pulled from a remote.
+type Importer interface {
+	ImportDirectory(ctx context.Context, pathToImport string) (string, error)
+}
+
+// Service shares the library with other Soulsolid instances and reads the libraries of the
+// configured remotes. Remote libraries are read-only: they are searched, streamed, and their
+// tracks pulled into the local library.
+type Service struct {
+	config     *config.Manager
+	catalog    Catalog
+	importer   Importer
+	jobService music.JobService
+}
+
+// NewService creates a new federation service.
+func NewService(cfg *config.Manager, catalog Catalog, importer Importer, jobService music.JobService) *Service {
+	return &Service{config: cfg, catalog: catalog, importer: importer, jobService: jobService}
+}
+
+// Remotes returns the configured remotes.
+func (s *Service) Remotes() []config.Remote {
+	return s.config.Get().Federation.Remotes
+}
+
+// Sharing reports whether other instances can read this library.
+func (s *Service) Sharing() bool {
+	return s.config.Get().Federation.APIKey != ""
+}
+
+func (s *Service) remote(name string) (config.Remote, error) {
+	for _, remote := range s.Remotes() {
+		if remote.Name == name {
+			return remote, nil
+		}
+	}
+	return config.Remote{}, fmt.Errorf("remote %q not found", name)
+}
+
+// newRequest builds a request to the federation API of a remote.
+func newRequest(ctx context.Context, remote config.Remote, path string, query url.Values) (*http.Request, error) {
+	u, err := url.Parse(strings.TrimRight(remote.URL, "/") + "/federation/v1" + path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL for remote %s: %w", remote.Name, err)
+	}
+	u.RawQuery = query.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(apiKeyHeader, remote.APIKey)
+	req.Header.Set("User-Agent", "SoulSolid/1.0")
+	return req, nil
+}
+
+// getJSON decodes the response of a federation API call into v.
+func getJSON(ctx context.Context, remote config.Remote, path string, query url.Values, v any) error {
+	req, err := newRequest(ctx, remote, path, query)
+	if err != nil {
+		return err
+	}
+	resp, err := searchClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("remote %s unreachable: %w", remote.Name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("remote %s answered %s", remote.Name, resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("invalid answer from remote %s: %w", remote.Name, err)
+	}
+	return nil
+}
+
+// Search searches the tracks of every remote at once. A remote that fails reports its error in
+// its results instead of failing the search.
+func (s *Service) Search(ctx context.Context, query string, limit int) []RemoteResults {
+	remotes := s.Remotes()
+	results := make([]RemoteResults, len(remotes))
+	var wg sync.WaitGroup
+	for i, remote := range remotes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = RemoteResults{Remote: remote.Name, Tracks: []RemoteTrack{}}
+			var page struct {
+				Tracks []RemoteTrack `json:"tracks"`
+			}
+			params := url.Values{"query": {query}, "limit": {fmt.Sprint(limit)}}
+			if err := getJSON(ctx, remote, "/search", params, &page); err != nil {
+				results[i].Error = err.Error()
+				return
+			}
+			for _, track := range page.Tracks {
+				track.Remote = remote.Name
+				results[i].Tracks = append(results[i].Tracks, track)
+			}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// Ping checks that a remote is reachable and accepts our key.
+func (s *Service) Ping(ctx context.Context, name string) (*RemoteInfo, error) {
+	remote, err := s.remote(name)
+	if err != nil {
+		return nil, err
+	}
+	var info RemoteInfo
+	if err := getJSON(ctx, remote, "/info", nil, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// GetRemoteTrack returns a track of a remote.
+func (s *Service) GetRemoteTrack(ctx context.Context, name, trackID string) (*RemoteTrack, error) {
+	remote, err := s.remote(name)
+	if err != nil {
+		return nil, err
+	}
+	var track RemoteTrack
+	if err := getJSON(ctx, remote, "/tracks/"+url.PathEscape(trackID), nil, &track); err != nil {
+		return nil, err
+	}
+	track.Remote = remote.Name
+	return &track, nil
+}
+
+// OpenTrackFile requests the file of a remote track, forwarding the Range header of a player
+// when set. The caller closes the response body.
+func (s *Service) OpenTrackFile(ctx context.Context, client *http.Client, name, trackID, rangeHeader string) (*http.Response, error) {
+	remote, err := s.remote(name)
+	if err != nil {
+		return nil, err
+	}
+	req, err := newRequest(ctx, remote, "/tracks/"+url.PathEscape(trackID)+"/file", nil)
+	if err != nil {
+		return nil, err
+	}
+	if rangeHeader != "" {
+		req.Header.Set("Range", rangeHeader)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("remote %s unreachable: %w", remote.Name, err)
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		resp.Body.Close()
+		return nil, fmt.Errorf("remote %s answered %s", remote.Name, resp.Status)
+	}
+	return resp, nil
+}
+
+// StartPull starts a job that copies a remote track into the local library.
+func (s *Service) StartPull(ctx context.Context, name, trackID string) (string, error) {
+	if _, err := s.remote(name); err != nil {
+		return "", err
+	}
+	if err := config.CheckWritable(s.config.Get().DownloadPath); err != nil {
+		return "", fmt.Errorf("download path: %w", err)
+	}
+	jobID, err := s.jobService.StartJob("federation_pull", "Pull from "+name, map[string]any{
+		"remote":   name,
+		"track_id": trackID,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to start pull job: %w", err)
+	}
+	return jobID, nil
+}
@@ -0,0 +1,81 @@
+package federation
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"strings"
+
+	"github.com/contre95/soulsolid/src/music"
+)
+
+// maxSharedResults caps the tracks returned by a single shared search.
+const maxSharedResults = 100
+
+// Authorized reports whether key is the API key of this instance. Nothing is authorized while
+// sharing is disabled.
+func (s *Service) Authorized(key string) bool {
+	own := s.config.Get().Federation.APIKey
+	return own != "" && subtle.ConstantTimeCompare([]byte(key), []byte(own)) == 1
+}
+
+// SharedInfo describes the local library to a remote instance.
+func (s *Service) SharedInfo(ctx context.Context) (*RemoteInfo, error) {
+	count, err := s.catalog.GetTracksCount(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count tracks: %w", err)
+	}
+	return &RemoteInfo{Tracks: count}, nil
+}
+
+// SharedSearch searches the local tracks for a remote instance.
+func (s *Service) SharedSearch(ctx context.Context, query string, limit int) ([]RemoteTrack, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return []RemoteTrack{}, nil
+	}
+	if limit <= 0 || limit > maxSharedResults {
+		limit = maxSharedResults
+	}
+	tracks, err := s.catalog.GetTracksFilteredPaginated(ctx, limit, 0, &music.TrackFilter{TextSearch: query})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search tracks: %w", err)
+	}
+	shared := make([]RemoteTrack, 0, len(tracks))
+	for _, track := range tracks {
+		shared = append(shared, toRemoteTrack(track))
+	}
+	return shared, nil
+}
+
+// SharedTrack returns a local track for a remote instance, with the path of its file.
+func (s *Service) SharedTrack(ctx context.Context, id string) (*RemoteTrack, string, error) {
+	track, err := s.catalog.GetTrack(ctx, id)
+	if err != nil || track == nil {
+		return nil, "", fmt.Errorf("track not found: %s", id)
+	}
+	shared := toRemoteTrack(track)
+	return &shared, track.Path, nil
+}
+
+func toRemoteTrack(track *music.Track) RemoteTrack {
+	shared := RemoteTrack{
+		ID:       track.ID,
+		Title:    track.Title,
+		Year:     track.Metadata.Year,
+		Duration: track.Metadata.Duration,
+		Format:   track.Format,
+		Bitrate:  track.Bitrate,
+	}
+	var artists []string
+	for _, role := range track.Artists {
+		if role.Artist != nil && (role.Role == "main" || role.Role == "") {
+			artists = append(artists, role.Artist.Name)
+		}
+	}
+	shared.Artist = strings.Join(artists, ", ")
+	if track.Album != nil {
+		shared.Album = track.Album.Title
+	}
+	return shared
+}
@@ -9,17 +9,25 @@ import (
 	"strings"
 
 	"github.com/contre95/soulsolid/src/features/config"
+	"github.com/contre95/soulsolid/src/features/diagnostics"
 	"github.com/contre95/soulsolid/src/features/downloading"
+	"github.com/contre95/soulsolid/src/features/federation"
 	"github.com/contre95/soulsolid/src/features/importing"
 	"github.com/contre95/soulsolid/src/features/jobs"
 	"github.com/contre95/soulsolid/src/features/library"
 	"github.com/contre95/soulsolid/src/features/lyrics"
+	"github.com/contre95/soulsolid/src/features/maintenance"
 	"github.com/contre95/soulsolid/src/features/metadata"
 	"github.com/contre95/soulsolid/src/features/metrics"
+	"github.com/contre95/soulsolid/src/features/migration"
+	"github.com/contre95/soulsolid/src/features/notifications"
 	"github.com/contre95/soulsolid/src/features/playlists"
+	"github.com/contre95/soulsolid/src/features/recommendations"
+	"github.com/contre95/soulsolid/src/features/remote"
 	"github.com/contre95/soulsolid/src/features/reorganize"
 	"github.com/contre95/soulsolid/src/features/streaming"
 	"github.com/contre95/soulsolid/src/features/ui"
+	"github.com/contre95/soulsolid/src/features/verification"
 	"github.com/contre95/soulsolid/src/music"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/recover"
@@ -33,7 +41,7 @@ type Server struct {
 }
 
 // NewServer creates a new HTTP server.
-func NewServer(cfg *config.Manager, importingService *importing.Service, libraryService *library.Service, playlistsService *playlists.Service, downloadingService *downloading.Service, jobService *jobs.Service, tagService *metadata.Service, lyricsService *lyrics.Service, metricsService *metrics.Service, reorganizeService *reorganize.Service, streamingService *streaming.Service) *Server {
+func NewServer(cfg *config.Manager, importingService *importing.Service, libraryService *library.Service, playlistsService *playlists.Service, downloadingService *downloading.Service, jobService *jobs.Service, tagService *metadata.Service, lyricsService *lyrics.Service, metricsService *metrics.Service, reorganizeService *reorganize.Service, streamingService *streaming.Service, diagnosticsService *diagnostics.Service, recommendationsService *recommendations.Service, maintenanceService *maintenance.Service, notificationsService *notifications.Service, federationService *federation.Service, migrationService *migration.Service, remoteService *remote.Service, verificationService *verification.Service) *Server {
 	engine := html.New("./views", ".html")
 	engine.Debug(cfg.Get().Logger.Level == "debug")
 	// Add custom template functions
@@ -83,6 +91,7 @@ func NewServer(cfg *config.Manager, importingService *importing.Service, library
 		return strings.Title(strings.ToLower(s))
 	})
 	engine.AddFunc("pathBase", filepath.Base)
+	engine.AddFunc("fileSize", metrics.FormatBytes)
 	engine.AddFunc("urlEncode", url.QueryEscape)
 
 	app := fiber.New(fiber.Config{
@@ -97,6 +106,9 @@ func NewServer(cfg *config.Manager, importingService *importing.Service, library
 		BodyLimit:             1000 * 1024 * 1024, // ~1GB limit for file uploads
 		PassLocalsToViews:     true,
 		Immutable:             true,
+		// The user headers of RequestUser are only honored from these proxies
+		EnableTrustedProxyCheck: len(cfg.Get().Server.TrustedProxies) > 0,
+		TrustedProxies:          cfg.Get().Server.TrustedProxies,
 	})
 
 	// Add middleware
@@ -104,6 +116,10 @@ func NewServer(cfg *config.Manager, importingService *importing.Service, library
 	app.Use(recover.New(recover.Config{EnableStackTrace: true}))
 	app.Use(HTMXMiddleware())
 	app.Use(LogAllRequestsMiddleware())
+	app.Use(diagnostics.Middleware(diagnosticsService))
+	preferences := ui.NewCookiePreferencesStore()
+	app.Use(ui.PreferencesMiddleware(preferences))
+	app.Use(ui.GuestMiddleware(cfg))
 
 	app.Use(func(c *fiber.Ctx) error {
 		version := os.Getenv("IMAGE_TAG")
@@ -123,11 +139,19 @@ func NewServer(cfg *config.Manager, importingService *importing.Service, library
 
 	app.Static("/", "./public")
 	app.Static("/node_modules", "./node_modules")
+	// The server answers 200 as long as it runs, path problems only mark it as degraded
 	app.Get("/health", func(c *fiber.Ctx) error {
-		return c.SendString("OK")
+		status := "ok"
+		checks := cfg.CheckPaths()
+		for _, check := range checks {
+			if !check.Writable {
+				status = "degraded"
+			}
+		}
+		return c.JSON(fiber.Map{"status": status, "permissions": checks})
 	})
 
-	uiHandler := ui.NewHandler(cfg)
+	uiHandler := ui.NewHandler(cfg, preferences)
 
 	// Route registration order matters: Fiber matches routes in registration order.
 	// More specific routes (literal path segments) must be registered before wildcard
@@ -149,6 +173,15 @@ func NewServer(cfg *config.Manager, importingService *importing.Service, library
 	reorganizeHandler := reorganize.NewHandler(reorganizeService, cfg)
 	reorganize.RegisterRoutes(app, reorganizeHandler)
 	streaming.RegisterRoutes(app, streamingService)
+	diagnosticsHandler := diagnostics.NewHandler(diagnosticsService)
+	diagnostics.RegisterRoutes(app, diagnosticsHandler)
+	recommendations.RegisterRoutes(app, recommendationsService)
+	maintenance.RegisterRoutes(app, maintenance.NewHandler(maintenanceService))
+	notifications.RegisterRoutes(app, notifications.NewHandler(notificationsService))
+	federation.RegisterRoutes(app, federation.NewHandler(federationService))
+	migration.RegisterRoutes(app, migration.NewHandler(migrationService))
+	remote.RegisterRoutes(app, remote.NewHandler(remoteService))
+	verification.RegisterRoutes(app, verification.NewHandler(verificationService))
 
 	return &Server{app: app, port: cfg.Get().Server.Port}
 }
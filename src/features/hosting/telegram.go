@@ -5,8 +5,10 @@ import (
 	"log/slog"
 	"slices"
 	"strings"
+	"sync"
 
 	"github.com/contre95/soulsolid/src/features/config"
+	"github.com/contre95/soulsolid/src/features/downloading"
 	"github.com/contre95/soulsolid/src/features/importing"
 	"github.com/contre95/soulsolid/src/features/jobs"
 	"github.com/contre95/soulsolid/src/features/library"
@@ -20,6 +22,12 @@ type TelegramCommandHandler interface {
 	HandleCallback(bot *tgbotapi.BotAPI, callback *tgbotapi.CallbackQuery) bool // Handle feature-specific callbacks
 }
 
+// TelegramInlineHandler is implemented by the features that answer inline queries
+// (@bot <query>), so their search results can be shared from any chat.
+type TelegramInlineHandler interface {
+	HandleInlineQuery(query string) ([]any, error) // Returns the inline results, at most 50
+}
+
 // TelegramBot handles Telegram bot operations
 type TelegramBot struct {
 	bot           *tgbotapi.BotAPI
@@ -28,10 +36,12 @@ type TelegramBot struct {
 	updates       tgbotapi.UpdatesChannel
 	stopChan      chan struct{}
 	pendingInputs map[string]string // chatID_messageID -> callbackData
+	chatsMu       sync.Mutex
+	chats         map[int64]string // chats of allowed users with their username, notified by Notify
 }
 
 // NewTelegramBot creates a new Telegram bot instance
-func NewTelegramBot(cfg *config.Manager, libraryService *library.Service, jobService *jobs.Service, importingService *importing.Service) (*TelegramBot, error) {
+func NewTelegramBot(cfg *config.Manager, libraryService *library.Service, jobService *jobs.Service, importingService *importing.Service, downloadingService *downloading.Service) (*TelegramBot, error) {
 	telegramConfig := cfg.Get().Telegram
 
 	if !telegramConfig.Enabled {
@@ -62,6 +72,7 @@ func NewTelegramBot(cfg *config.Manager, libraryService *library.Service, jobSer
 		updates:       updates,
 		stopChan:      make(chan struct{}),
 		pendingInputs: make(map[string]string),
+		chats:         make(map[int64]string),
 	}
 
 	// Register feature handlers
@@ -69,6 +80,7 @@ func NewTelegramBot(cfg *config.Manager, libraryService *library.Service, jobSer
 	telegramBot.RegisterHandler("config", config.NewTelegramHandler(cfg))
 	telegramBot.RegisterHandler("jobs", jobs.NewTelegramHandler(jobService))
 	telegramBot.RegisterHandler("importing", importing.NewTelegramHandler(importingService, cfg))
+	telegramBot.RegisterHandler("downloading", downloading.NewTelegramHandler(downloadingService, cfg))
 
 	return telegramBot, nil
 }
@@ -92,6 +104,9 @@ func (t *TelegramBot) Start() {
 			if update.CallbackQuery != nil {
 				go t.handleCallbackQuery(update)
 			}
+			if update.InlineQuery != nil {
+				go t.handleInlineQuery(update)
+			}
 		case <-t.stopChan:
 			slog.Info("Stopping Telegram bot listener")
 			return
@@ -117,20 +132,14 @@ func (t *TelegramBot) handleMessage(update tgbotapi.Update) {
 		return
 	}
 
-	username := message.From.UserName
-	if username == "" {
-		// Fallback to first name + last name
-		username = message.From.FirstName
-		if message.From.LastName != "" {
-			username += " " + message.From.LastName
-		}
-	}
-	found := slices.Contains(allowedUsers, username)
-	if !found && t.config.Get().Telegram.Enabled {
-		slog.Warn("Unauthorized user", "username", username, "chat_id", chatID)
+	if !t.isAllowed(message.From) && t.config.Get().Telegram.Enabled {
+		slog.Warn("Unauthorized user", "username", config.TelegramUsername(message.From), "chat_id", chatID)
 		t.sendMessage(chatID, "Unknown user, please add your user to the config")
 		return
 	}
+	t.chatsMu.Lock()
+	t.chats[chatID] = config.TelegramUsername(message.From)
+	t.chatsMu.Unlock()
 
 	// Handle commands
 	if message.IsCommand() {
@@ -149,6 +158,42 @@ func (t *TelegramBot) handleMessage(update tgbotapi.Update) {
 	t.sendMessage(chatID, "🤖 Send /menu or /help to see available options")
 }
 
+// isAllowed reports whether user is one of the configured allowed users
+func (t *TelegramBot) isAllowed(user *tgbotapi.User) bool {
+	return user != nil && slices.Contains(t.config.Get().Telegram.AllowedUsers, config.TelegramUsername(user))
+}
+
+// handleInlineQuery answers inline queries with library search results, or with downloader
+// search results when the query starts with "dl ". Unknown users get no results.
+func (t *TelegramBot) handleInlineQuery(update tgbotapi.Update) {
+	query := update.InlineQuery
+	answer := tgbotapi.InlineConfig{
+		InlineQueryID: query.ID,
+		Results:       []any{},
+		IsPersonal:    true,
+	}
+
+	feature, text := "library", strings.TrimSpace(query.Query)
+	if rest, ok := strings.CutPrefix(text, "dl "); ok {
+		feature, text = "downloading", strings.TrimSpace(rest)
+	}
+
+	if !t.isAllowed(query.From) {
+		slog.Warn("Unauthorized inline query", "username", config.TelegramUsername(query.From))
+	} else if handler, ok := t.handlers[feature].(TelegramInlineHandler); ok && text != "" {
+		results, err := handler.HandleInlineQuery(text)
+		if err != nil {
+			slog.Error("Failed to handle inline query", "feature", feature, "query", text, "error", err)
+		} else {
+			answer.Results = results
+		}
+	}
+
+	if _, err := t.bot.Request(answer); err != nil {
+		slog.Error("Failed to answer inline query", "error", err)
+	}
+}
+
 // handleCommand processes bot commands
 func (t *TelegramBot) handleCommand(update tgbotapi.Update) {
 	message := update.Message
@@ -181,10 +226,13 @@ func (t *TelegramBot) routeCommand(command, args string, chatID int64) error {
 		"stats":       "library",
 		"tree":        "library",
 		"config":      "config",
+		"proposals":   "config",
 		"jobs":        "jobs",
 		"import":      "importing",
 		"queue":       "importing",
 		"queue_clear": "importing",
+		"search":      "downloading",
+		"download":    "downloading",
 	}
 
 	feature, exists := commandMap[command]
@@ -236,10 +284,55 @@ func (t *TelegramBot) sendMessage(chatID int64, text string) {
 	}
 }
 
+// Notify sends a message to every allowed user that has talked to the bot since it started.
+// The Bot API can't message users by username, so chats are only known once they write first.
+func (t *TelegramBot) Notify(message string) {
+	t.chatsMu.Lock()
+	chats := make([]int64, 0, len(t.chats))
+	for chatID := range t.chats {
+		chats = append(chats, chatID)
+	}
+	t.chatsMu.Unlock()
+	for _, chatID := range chats {
+		msg := tgbotapi.NewMessage(chatID, message)
+		if _, err := t.bot.Send(msg); err != nil {
+			slog.Error("Failed to send notification", "error", err, "chat_id", chatID)
+		}
+	}
+}
+
+// NotifyProposal asks the Telegram admins that have talked to the bot since it started to
+// approve or reject a config change.
+func (t *TelegramBot) NotifyProposal(p config.Proposal) {
+	t.chatsMu.Lock()
+	var chats []int64
+	for chatID, username := range t.chats {
+		if t.config.IsTelegramAdmin(username) {
+			chats = append(chats, chatID)
+		}
+	}
+	t.chatsMu.Unlock()
+	if len(chats) == 0 {
+		slog.Info("No Telegram admin to ask for the config proposal approval", "proposal", p.ID)
+	}
+	for _, chatID := range chats {
+		if _, err := t.bot.Send(config.NewProposalMessage(chatID, p)); err != nil {
+			slog.Error("Failed to send config proposal", "error", err, "chat_id", chatID)
+		}
+	}
+}
+
 // handleCallbackQuery handles callback queries from inline keyboards
 func (t *TelegramBot) handleCallbackQuery(update tgbotapi.Update) {
 	callback := update.CallbackQuery
 
+	// Messages sent through inline mode carry their buttons to any chat, so anyone may press them
+	if !t.isAllowed(callback.From) {
+		slog.Warn("Unauthorized callback", "username", config.TelegramUsername(callback.From))
+		t.bot.Request(tgbotapi.NewCallbackWithAlert(callback.ID, "Unknown user, please add your user to the config"))
+		return
+	}
+
 	// Handle menu callbacks first
 	if strings.HasPrefix(callback.Data, "menu_") {
 		t.handleMenuCallback(callback)
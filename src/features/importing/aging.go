@@ -0,0 +1,101 @@
+package importing
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/contre95/soulsolid/src/music"
+)
+
+// queueAgingInterval is how often the queue is checked for expired items.
+const queueAgingInterval = time.Hour
+
+// QueueAgingStatus summarizes how old the items in the review queue are.
+type QueueAgingStatus struct {
+	AlertAfterDays  int
+	ExpireAfterDays int
+	ExpireAction    string
+	Stale           int        // items older than AlertAfterDays
+	Oldest          *time.Time // timestamp of the oldest item, nil when the queue is empty
+}
+
+// GetQueueAging returns the aging status of the review queue.
+func (s *Service) GetQueueAging() QueueAgingStatus {
+	aging := s.config.Get().Import.QueueAging
+	status := QueueAgingStatus{
+		AlertAfterDays:  aging.AlertAfterDays,
+		ExpireAfterDays: aging.ExpireAfterDays,
+		ExpireAction:    aging.ExpireAction,
+	}
+	for _, item := range s.queue.GetAll() {
+		if status.Oldest == nil || item.Timestamp.Before(*status.Oldest) {
+			ts := item.Timestamp
+			status.Oldest = &ts
+		}
+	}
+	if aging.AlertAfterDays > 0 {
+		status.Stale = len(s.itemsOlderThan(aging.AlertAfterDays))
+	}
+	return status
+}
+
+// ExpireQueueItems resolves the queue items older than the configured expiry age and returns how many were resolved.
+// With the "import" action items are imported when their type allows it, the rest are skipped like any other expired item.
+func (s *Service) ExpireQueueItems(ctx context.Context) int {
+	aging := s.config.Get().Import.QueueAging
+	if aging.ExpireAfterDays <= 0 {
+		return 0
+	}
+	expired := 0
+	for _, item := range s.itemsOlderThan(aging.ExpireAfterDays) {
+		action := "cancel"
+		if aging.ExpireAction == "import" && canAutoImport(item) {
+			action = "import"
+		}
+		if err := s.ProcessQueueItem(ctx, item.ID, action); err != nil {
+			slog.Warn("Failed to expire queue item", "id", item.ID, "action", action, "error", err)
+			continue
+		}
+		slog.Info("Expired queue item", "id", item.ID, "action", action, "age", time.Since(item.Timestamp).Round(time.Hour), "path", item.Track.Path)
+		expired++
+	}
+	return expired
+}
+
+// watchQueueAge periodically expires old queue items and warns about the stale ones.
+func (s *Service) watchQueueAge() {
+	ticker := time.NewTicker(queueAgingInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if s.paused.Load() {
+			continue
+		}
+		if n := s.ExpireQueueItems(context.Background()); n > 0 {
+			slog.Info("Queue expiry finished", "expired", n)
+		}
+		if status := s.GetQueueAging(); status.Stale > 0 {
+			slog.Warn("Import queue has stale items", "count", status.Stale, "olderThanDays", status.AlertAfterDays)
+		}
+	}
+}
+
+// itemsOlderThan returns the queue items older than the given number of days, oldest first.
+func (s *Service) itemsOlderThan(days int) []music.QueueItem {
+	cutoff := time.Now().AddDate(0, 0, -days)
+	var items []music.QueueItem
+	for _, item := range s.queue.GetAll() {
+		if item.Timestamp.Before(cutoff) {
+			items = append(items, item)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Timestamp.Before(items[j].Timestamp) })
+	return items
+}
+
+// canAutoImport reports whether an item can be imported without a user decision.
+// Duplicates need a replace or skip choice, and failed or incomplete tracks can't be imported at all.
+func canAutoImport(item music.QueueItem) bool {
+	return item.Track != nil && !item.HasType(FailedImport) && !item.HasType(MissingMetadata) && !item.HasType(Duplicate)
+}
@@ -0,0 +1,204 @@
+package importing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/contre95/soulsolid/src/features/config"
+	"github.com/contre95/soulsolid/src/music"
+)
+
+// convertedDirName is the directory of the download path where import jobs stage the files
+// they converted, one directory per job.
+const convertedDirName = ".converted"
+
+// convertedFromKey is the queue item metadata holding the original of a converted file, which
+// is removed when the item is resolved.
+const convertedFromKey = "converted_from"
+
+// pcmExtensions are the uncompressed formats converted to FLAC with import.pcm_to_flac.
+var pcmExtensions = map[string]bool{
+	".wav":  true,
+	".aif":  true,
+	".aiff": true,
+}
+
+// Transcoder converts audio files to another format, e.g. with ffmpeg.
+type Transcoder interface {
+	// Transcode converts the file of track to format into dir, tags it with the track and
+	// returns the path of the converted file.
+	Transcode(ctx context.Context, track *music.Track, format string, dir string) (string, error)
+	// TranscodeFile converts a file to format into dir keeping its tags and returns the path of
+	// the converted file.
+	TranscodeFile(ctx context.Context, path string, format string, dir string) (string, error)
+	// Bitrate returns the bitrate of a file in kbps.
+	Bitrate(ctx context.Context, path string) (int, error)
+}
+
+// Conversion is a file converted by an import job before it was imported.
+type Conversion struct {
+	File      string `json:"file"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Bitrate   int    `json:"bitrate,omitempty"` // kbps of the source, when it was checked
+	Converted string `json:"converted"`         // path of the converted file in the staging directory
+}
+
+// convertible reports whether files like path are converted before import with conv, whatever
+// their bitrate.
+func convertible(path string, conv config.Convert) bool {
+	if conv.Target == "" {
+		return false
+	}
+	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	return format != conv.Target && (slices.Contains(conv.Formats, format) || slices.Contains(conv.LowBitrateFormats, format))
+}
+
+// convertForImport converts a file matching the import.convert rules into the staging
+// directory of the job and returns the path to import, the file itself when it's not
+// converted. A file of a low bitrate format at or above the minimum bitrate is only imported
+// as it is when its format is supported.
+func (s *Service) convertForImport(ctx context.Context, path, jobID string, logger *slog.Logger) (string, *Conversion, error) {
+	conv := s.config.Get().Import.Convert
+	if !convertible(path, conv) {
+		return path, nil, nil
+	}
+	if s.transcoder == nil {
+		return path, nil, fmt.Errorf("no transcoder to convert %s", filepath.Base(path))
+	}
+	ext := strings.ToLower(filepath.Ext(path))
+	format := strings.TrimPrefix(ext, ".")
+	conversion := &Conversion{File: path, From: format, To: conv.Target}
+	if !slices.Contains(conv.Formats, format) {
+		bitrate, err := s.transcoder.Bitrate(ctx, path)
+		if err != nil {
+			return path, nil, fmt.Errorf("failed to read the bitrate: %w", err)
+		}
+		conversion.Bitrate = bitrate
+		if bitrate >= conv.MinBitrate {
+			if !supportedExtensions[ext] {
+				return path, nil, fmt.Errorf("%s files are not supported and %d kbps is not below the %d kbps to convert them", format, bitrate, conv.MinBitrate)
+			}
+			return path, nil, nil
+		}
+	}
+
+	staging := filepath.Join(s.config.Get().DownloadPath, convertedDirName, jobID)
+	if err := os.MkdirAll(staging, 0755); err != nil {
+		return path, nil, fmt.Errorf("failed to create conversion directory: %w", err)
+	}
+	// A directory per file, so files with the same name in different folders don't collide
+	dir, err := os.MkdirTemp(staging, "file-")
+	if err != nil {
+		return path, nil, fmt.Errorf("failed to create conversion directory: %w", err)
+	}
+	converted, err := s.transcoder.TranscodeFile(ctx, path, conv.Target, dir)
+	if err != nil {
+		os.Remove(dir)
+		return path, nil, fmt.Errorf("failed to convert %s to %s: %w", format, conv.Target, err)
+	}
+	conversion.Converted = converted
+	logger.Info("Converted file before import", "path", path, "from", format, "to", conv.Target, "color", "blue")
+	return converted, conversion, nil
+}
+
+// withConvertedFrom adds the original of a converted file to the metadata of its queue item.
+func withConvertedFrom(metadata map[string]string, conversion *Conversion) map[string]string {
+	if conversion == nil {
+		return metadata
+	}
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+	metadata[convertedFromKey] = conversion.File
+	return metadata
+}
+
+// queuedOriginals returns the originals of the converted files waiting in the queue, they're not
+// converted again until their items are resolved.
+func (s *Service) queuedOriginals() map[string]bool {
+	originals := map[string]bool{}
+	for _, item := range s.queue.GetAll() {
+		if original := item.Metadata[convertedFromKey]; original != "" {
+			originals[original] = true
+		}
+	}
+	return originals
+}
+
+// removeQueuedOriginal removes the original of a converted file once its queue item is resolved,
+// along with the staging directory of the job when nothing else in it is queued. The original
+// is kept when remove is false, e.g. when an import copies.
+func (s *Service) removeQueuedOriginal(item music.QueueItem, remove bool) {
+	original := item.Metadata[convertedFromKey]
+	if original == "" {
+		return
+	}
+	if remove {
+		if err := os.Remove(original); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			slog.Warn("Failed to remove the converted original file", "path", original, "error", err)
+		}
+	}
+	s.removeConvertedDir(item.JobID)
+}
+
+// isConverted reports whether path is a file staged by a conversion, such files are always
+// moved into the library.
+func (s *Service) isConverted(path string) bool {
+	staging := filepath.Join(s.config.Get().DownloadPath, convertedDirName) + string(filepath.Separator)
+	return strings.HasPrefix(path, staging)
+}
+
+// removeConvertedDir removes the conversion staging directory of a job once nothing in it is
+// left for the queue.
+func (s *Service) removeConvertedDir(jobID string) {
+	staging := filepath.Join(s.config.Get().DownloadPath, convertedDirName, jobID)
+	removeEmptyDirs(staging, "")
+	os.Remove(staging) // fails while queued files are left
+}
+
+// organizeTrack moves or copies the file of a track to its library path and returns the new
+// path. With import.pcm_to_flac, WAV and AIFF files are converted to FLAC on the way: the
+// converted file goes to the library and the original is removed only when moving.
+func (s *Service) organizeTrack(ctx context.Context, track *music.Track, move bool, logger *slog.Logger) (string, error) {
+	ext := strings.ToLower(filepath.Ext(track.Path))
+	if !s.config.Get().Import.PCMToFLAC || !pcmExtensions[ext] || s.transcoder == nil {
+		if move {
+			return s.fileManager.MoveTrackToLibrary(ctx, track)
+		}
+		return s.fileManager.CopyTrackToLibrary(ctx, track)
+	}
+
+	dir, err := os.MkdirTemp("", "soulsolid-convert-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create conversion directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	original, format, bitrate := track.Path, track.Format, track.Bitrate
+	converted, err := s.transcoder.Transcode(ctx, track, "flac", dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to convert %s to FLAC: %w", filepath.Base(original), err)
+	}
+	logger.Info("Converted track to FLAC", "path", original, "title", track.Title, "color", "blue")
+
+	// The FLAC bitrate is unknown until the file is read again
+	track.Path, track.Format, track.Bitrate = converted, "flac", 0
+	newPath, err := s.fileManager.MoveTrackToLibrary(ctx, track)
+	if err != nil {
+		track.Path, track.Format, track.Bitrate = original, format, bitrate
+		return "", err
+	}
+	if move {
+		if err := os.Remove(original); err != nil {
+			logger.Warn("Failed to remove the converted original file", "path", original, "error", err)
+		}
+	}
+	return newPath, nil
+}
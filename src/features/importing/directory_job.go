@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io/fs"
 	"log/slog"
 	"os"
 	"path/filepath"
@@ -49,7 +50,9 @@ func (e *DirectoryImportTask) MetadataKeys() []string {
 func (e *DirectoryImportTask) Execute(ctx context.Context, job *music.Job, progressUpdater func(int, string)) (map[string]any, error) {
 	path := job.Metadata["path"].(string)
 
-	stats, err := e.runDirectoryImport(ctx, path, progressUpdater, job.Logger, job)
+	report := e.service.startReport(job.ID, path, job.Logger)
+	defer report.Close()
+	stats, err := e.runDirectoryImport(ctx, path, progressUpdater, job.Logger, job, report, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to import directory: %w", err)
 	}
@@ -59,44 +62,126 @@ func (e *DirectoryImportTask) Execute(ctx context.Context, job *music.Job, progr
 		return nil, ctx.Err()
 	}
 
+	return e.finishImport(job, report, stats, "Directory import")
+}
+
+// finishImport logs the final stats, saves the import report and turns the stats into the
+// job result and status.
+func (e *DirectoryImportTask) finishImport(job *music.Job, report *ImportReport, stats ImportStats, label string) (map[string]any, error) {
 	totalProcessed := stats.TracksImported + stats.Skipped + stats.Queued + stats.Errors
-	finalMessage := fmt.Sprintf("Directory import finished. Processed %d tracks (%d imported, %d queued, %d skipped, %d errors).",
-		totalProcessed, stats.TracksImported, stats.Queued, stats.Skipped, stats.Errors)
+	finalMessage := fmt.Sprintf("%s finished. Processed %d tracks (%d imported, %d queued, %d skipped, %d errors).",
+		label, totalProcessed, stats.TracksImported, stats.Queued, stats.Skipped, stats.Errors)
+	if stats.Converted > 0 {
+		finalMessage = fmt.Sprintf("%s %d file(s) converted before import.", finalMessage, stats.Converted)
+	}
 	job.Logger.Info(finalMessage)
 
+	result := map[string]any{"stats": stats, "msg": finalMessage}
+	if len(report.Conversions) > 0 {
+		result["conversions"] = report.Conversions
+	}
+	report.Stats = stats
+	report.FinishedAt = time.Now()
+	if jsonPath, htmlPath, err := report.Save(e.service.reportDir()); err != nil {
+		job.Logger.Warn("Service.runDirectoryImport: failed to save import report", "error", err)
+	} else {
+		result["report_json"] = jsonPath
+		result["report_html"] = htmlPath
+		if report.Files != "" {
+			result["report_files"] = report.Files
+		}
+	}
+
 	// Determine job status - consider skips and queued as successful
 	if stats.TracksImported == 0 && stats.Skipped == 0 && stats.Queued == 0 && stats.Errors > 0 {
 		// Complete failure - no tracks processed successfully
 		slog.Warn("No tracks were successfully processed", "stats", stats)
-		return map[string]any{"stats": stats, "msg": finalMessage}, errors.New("No tracks were successfully processed")
+		return result, errors.New("No tracks were successfully processed")
 	} else if stats.Errors > 0 {
 		// Partial success - some failures occurred
 		slog.Warn("Some tracks failed to process", "stats", stats)
-		return map[string]any{"stats": stats, "msg": finalMessage}, fmt.Errorf("%w: %d track(s) failed to process", music.ErrJobPartialSuccess, stats.Errors)
+		return result, fmt.Errorf("%w: %d track(s) failed to process", music.ErrJobPartialSuccess, stats.Errors)
 	}
 
 	// Full success - all tracks processed without errors (including skips)
-	return map[string]any{"stats": stats, "msg": finalMessage}, nil
+	return result, nil
 }
 
-// Cleanup does nothing for directory imports.
+// Cleanup removes the directory of the files converted by the job once nothing in it is left
+// for the queue.
 func (e *DirectoryImportTask) Cleanup(job *music.Job) error {
+	e.service.removeConvertedDir(job.ID)
 	return nil
 }
 
-// countSupportedFiles counts the number of supported audio files in a directory
-func countSupportedFiles(pathToImport string) int {
-	totalFiles := 0
-	filepath.Walk(pathToImport, func(path string, info os.FileInfo, err error) error {
-		if err == nil && !info.IsDir() {
-			ext := strings.ToLower(filepath.Ext(path))
-			if supportedExtensions[ext] {
-				totalFiles++
-			}
+// walkBuffer bounds how many walked files wait to be imported. The walk stays at most this far
+// ahead of the import, so huge directories never have their file list held in memory.
+const walkBuffer = 64
+
+// walkedFile is a supported audio file found by walkSupportedFiles.
+type walkedFile struct {
+	path     string
+	size     int64
+	progress int // share of the walk done, from the entry of the root the file is under
+}
+
+// walkSupportedFiles streams the supported audio files under root, and the files converted
+// before import with conv. The files channel is closed once the walk ends, then the error
+// channel receives the walk error, nil when the walk finished or was cancelled with ctx.
+// The files staged by conversions are left out, their jobs import them.
+// The progress of a file is measured by the entries of root walked before it, so it doesn't
+// need the tree counted first.
+func walkSupportedFiles(ctx context.Context, root string, conv config.Convert, logger *slog.Logger) (<-chan walkedFile, <-chan error) {
+	files := make(chan walkedFile, walkBuffer)
+	errc := make(chan error, 1)
+	go func() {
+		defer close(errc)
+		topEntries, walkedEntries := 0, 0
+		if entries, err := os.ReadDir(root); err == nil {
+			topEntries = len(entries)
 		}
-		return nil
-	})
-	return totalFiles
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				logger.Error("Service.runDirectoryImport: could not walk root dir", "error", err)
+				return err
+			}
+			if path != root && filepath.Dir(path) == filepath.Clean(root) {
+				walkedEntries++
+			}
+			if d.IsDir() {
+				if path != root && d.Name() == convertedDirName {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !isSupportedFile(path) && !convertible(path, conv) {
+				logger.Debug("Service.runDirectoryImport: skipping unsupported file", "path", path, "extension", filepath.Ext(path))
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				logger.Warn("Service.runDirectoryImport: could not stat file", "path", path, "error", err)
+				return nil
+			}
+			progress := 0
+			if topEntries > 0 {
+				progress = min(max(walkedEntries-1, 0)*100/topEntries, 99)
+			}
+			select {
+			case files <- walkedFile{path: path, size: info.Size(), progress: progress}:
+				return nil
+			case <-ctx.Done():
+				return filepath.SkipAll
+			}
+		})
+		close(files)
+		errc <- err
+	}()
+	return files, errc
+}
+
+func isSupportedFile(path string) bool {
+	return supportedExtensions[strings.ToLower(filepath.Ext(path))]
 }
 
 // determineAction determines what action to take for a track based on config and duplicate
@@ -115,6 +200,11 @@ func determineAction(track *music.Track, duplicateTrack *music.Track, config con
 	}
 
 	if duplicateTrack != nil {
+		// A locked library track is never replaced, queued or otherwise touched by an import.
+		if duplicateTrack.IsLocked() {
+			logger.Info("Service.runDirectoryImport: Decided to skip duplicate track", "reason", "existing track is locked", "duplicate_path", duplicateTrack.Path, "title", track.Title)
+			return SkipTrack, nil, nil
+		}
 		// Fast paths apply only when force-queuing is off. "skip" discards the duplicate
 		// regardless of metadata (nothing enters the library). "replace" only auto-replaces a
 		// complete track; an incomplete one is queued instead so it can't silently overwrite
@@ -198,6 +288,17 @@ func (e *DirectoryImportTask) importFile(ctx context.Context, track *music.Track
 	return newPath, nil
 }
 
+// removeConverted removes the original of a converted file once the converted file is in the
+// library, when the import moves files.
+func (e *DirectoryImportTask) removeConverted(conversion *Conversion, moveFiles bool, logger *slog.Logger) {
+	if conversion == nil || !moveFiles {
+		return
+	}
+	if err := os.Remove(conversion.File); err != nil {
+		logger.Warn("Service.runDirectoryImport: failed to remove the converted original file", "path", conversion.File, "error", err)
+	}
+}
+
 func (e *DirectoryImportTask) findDuplicateTrack(ctx context.Context, trackToImport *music.Track, fingerprint string, logger *slog.Logger) (*music.Track, error) {
 	trackID := music.GenerateTrackID(fingerprint)
 	duplicateTrack, err := e.service.library.GetTrack(ctx, trackID)
@@ -228,151 +329,213 @@ func (e *DirectoryImportTask) findDuplicateTrack(ctx context.Context, trackToImp
 					return nil, err
 				}
 			}
+			// FindTrackByPath doesn't load the album, which is needed to honour album locks
+			if duplicateTrack != nil {
+				if full, err := e.service.library.GetTrack(ctx, duplicateTrack.ID); err == nil && full != nil {
+					duplicateTrack = full
+				}
+			}
 		}
 	}
 	return duplicateTrack, nil
 }
 
-func (e *DirectoryImportTask) runDirectoryImport(ctx context.Context, pathToImport string, progressUpdater func(int, string), logger *slog.Logger, job *music.Job) (ImportStats, error) {
+// runDirectoryImport imports every supported file under pathToImport. remote is non-nil
+// for imports of downloaded URLs: their files are always moved out of the staging
+// directory and missing tags are filled in from the remote item (e.g. a feed entry).
+// Files are imported as the walk finds them, so memory stays flat however large the directory is.
+func (e *DirectoryImportTask) runDirectoryImport(ctx context.Context, pathToImport string, progressUpdater func(int, string), logger *slog.Logger, job *music.Job, report *ImportReport, remote map[string]remoteItem) (ImportStats, error) {
 	logger.Info("Service.runDirectoryImport: starting import", "path", pathToImport)
 	var stats ImportStats
-	moveFiles := e.service.config.Get().Import.Move
+	moveFiles := e.service.config.Get().Import.Move || remote != nil
 	config := e.service.config.Get().Import
 
-	// Count total files first for progress tracking and logging purposes
-	totalFiles := countSupportedFiles(pathToImport)
-	logger.Info("Service.runDirectoryImport: found files to process", "total", totalFiles)
-
+	queuedOriginals := e.service.queuedOriginals()
+	files, walkErr := walkSupportedFiles(ctx, pathToImport, config.Convert, logger)
 	processedFiles := 0
-	err := filepath.Walk(pathToImport, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			logger.Error("Service.runDirectoryImport: could not walk root dir", "error", err)
-			return err
+	for file := range files {
+		if ctx.Err() != nil {
+			// Drain the walker so it can exit, it stops on its own once the context is done
+			continue
+		}
+		if queuedOriginals[file.path] {
+			logger.Info("Service.runDirectoryImport: skipping file, its conversion is waiting in the queue", "path", file.path)
+			report.Add(file.path, OutcomeSkipped, "conversion waiting in the queue", "")
+			processedFiles++
+			continue
+		}
+		logger.Info("Service.runDirectoryImport: processing file", "trackToImport", file.path)
+
+		// A converted file goes through the import in place of the original, which the report
+		// keeps referring to
+		path, conversion, err := e.service.convertForImport(ctx, file.path, job.ID, logger)
+		if conversion != nil {
+			stats.Converted++
+			report.AddConversion(*conversion)
 		}
-		if !info.IsDir() {
-			ext := strings.ToLower(filepath.Ext(path))
-			if !supportedExtensions[ext] {
-				logger.Debug("Service.runDirectoryImport: skipping unsupported file", "path", path, "extension", ext)
-				return nil // Skip unsupported files
+		var trackToImport *music.Track
+		if err == nil {
+			trackToImport, err = e.service.metadataReader.ReadFileTags(ctx, path)
+		}
+		if err != nil || file.size == 0 {
+			logger.Warn("Service.runDirectoryImport: could not read metadata from file", "path", path, "error", err)
+			stats.Errors++
+			// Create minimal track and add to queue.
+			nullTrackForQueue := music.Track{}
+			nullTrackForQueue.Title = path
+			nullTrackForQueue.Path = path
+			nullTrackForQueue.EnsureMetadataDefaults(true, true, true, true, true)
+			nullTrackForQueue.ID = generateTrackIDFromPath(path) // ID generate for queue duplicates.
+			// err can be nil here when metadata read succeeded but the file is zero bytes.
+			errMsg := "file is empty (zero bytes)"
+			if err != nil {
+				errMsg = err.Error()
+			}
+			report.AddFailure(file.path, errMsg)
+			if err := e.addTrackToQueue(&nullTrackForQueue, []music.QueueItemType{FailedImport}, job.ID, nil, logger, withConvertedFrom(map[string]string{"error": errMsg}, conversion)); err != nil {
+				logger.Error("Service.runDirectoryImport: failed to add metadata-failed track to queue", "error", err)
 			}
+			processedFiles++
+			continue
+		}
+		slog.Info("Read metadata from file", "path", path, "track", trackToImport)
 
-			logger.Info("Service.runDirectoryImport: processing file", "trackToImport", path)
+		item, isRemote := remote[file.path]
+		if isRemote {
+			item.fillMissingTags(trackToImport)
+		}
 
-			trackToImport, err := e.service.metadataReader.ReadFileTags(ctx, path)
-			if err != nil || info.Size() == 0 {
-				logger.Warn("Service.runDirectoryImport: could not read metadata from file", "path", path, "error", err)
-				stats.Errors++
-				// Create minimal track and add to queue.
-				nullTrackForQueue := music.Track{}
-				nullTrackForQueue.Title = path
-				nullTrackForQueue.Path = path
-				nullTrackForQueue.EnsureMetadataDefaults(true, true, true, true, true)
-				nullTrackForQueue.ID = generateTrackIDFromPath(path) // ID generate for queue duplicates.
-				// err can be nil here when metadata read succeeded but the file is zero bytes.
-				errMsg := "file is empty (zero bytes)"
-				if err != nil {
-					errMsg = err.Error()
-				}
-				if err := e.addTrackToQueue(&nullTrackForQueue, []music.QueueItemType{FailedImport}, job.ID, nil, logger, map[string]string{"error": errMsg}); err != nil {
-					logger.Error("Service.runDirectoryImport: failed to add metadata-failed track to queue", "error", err)
-				}
-				processedFiles++
-				return nil
+		// "Song (Live)" is stored as the title "Song" with the version "Live"
+		trackToImport.SplitTitleVersion()
+
+		if e.service.config.Get().Metadata.Normalization.OnImport {
+			for _, change := range e.service.normalizer.NormalizeTrack(trackToImport) {
+				logger.Info("Normalized tag", "path", path, "field", change.Field, "old", change.Old, "new", change.New, "color", "violet")
 			}
-			slog.Info("Read metadata from file", "path", path, "track", trackToImport)
+		}
+
+		// User rules see the tags as read, before any fallback defaults are applied
+		e.service.rules.ApplyImportRules(trackToImport, logger)
 
-			// Apply default metadata if configured to allow missing metadata
-			amm := config.AllowMissingMetadata
-			trackToImport.EnsureMetadataDefaults(amm.Artist, amm.Album, amm.Title, amm.Year, amm.Genre)
+		// Apply default metadata if configured to allow missing metadata
+		amm := config.AllowMissingMetadata
+		trackToImport.EnsureMetadataDefaults(amm.Artist, amm.Album, amm.Title, amm.Year, amm.Genre)
 
-			// Set source data for local file
+		// Set source data for local file
+		trackToImport.MetadataSource = music.MetadataSource{
+			Source:            "LocalFile",
+			MetadataSourceURL: file.path,
+		}
+		if isRemote {
 			trackToImport.MetadataSource = music.MetadataSource{
-				Source:            "LocalFile",
-				MetadataSourceURL: path,
+				Source:            "URL",
+				MetadataSourceURL: item.URL,
 			}
+		}
+		// Queued tracks keep it too, so they're linked to this job when imported from the queue
+		trackToImport.SetAddedBy(job)
 
-			fingerprint, err := e.service.fingerprintReader.GenerateFingerprint(ctx, path)
-			if err != nil {
-				logger.Warn("Service.runDirectoryImport: failed to generate fingerprint, falling back to metadata", "error", err, "trackToImport", path)
+		fingerprint, err := e.service.fingerprintReader.GenerateFingerprint(ctx, path)
+		if err != nil {
+			logger.Warn("Service.runDirectoryImport: failed to generate fingerprint, falling back to metadata", "error", err, "trackToImport", path)
+			stats.Errors++
+			// Set track ID from path and add to queue for manual review
+			trackToImport.ID = generateTrackIDFromPath(path)
+			report.AddFailure(file.path, "fingerprint failed: "+err.Error())
+			if err := e.addTrackToQueue(trackToImport, []music.QueueItemType{FailedImport}, job.ID, nil, logger, withConvertedFrom(map[string]string{"error": err.Error()}, conversion)); err != nil {
+				logger.Error("Service.runDirectoryImport: failed to add fingerprint-failed track to queue", "error", err)
+			}
+			processedFiles++
+			continue
+		}
+		slog.Info("Generated fingerprint for track", "path", path, "track", trackToImport, "fingerprint", fingerprint[:min(15, len(fingerprint))])
+		slog.Debug("Generated fingerprint for track", "path", path, "track", trackToImport, "fingerprint", fingerprint)
+		trackToImport.ChromaprintFingerprint = fingerprint
+		trackToImport.ID = music.GenerateTrackID(fingerprint)
+		slog.Info("Generated track id", "id", trackToImport.ID)
+		duplicateTrack, err := e.findDuplicateTrack(ctx, trackToImport, fingerprint, logger)
+		if err != nil {
+			logger.Error("Service.runDirectoryImport: failed to find duplicate track", "error", err)
+			stats.Errors++
+			report.AddFailure(file.path, "duplicate check failed: "+err.Error())
+			if err := e.addTrackToQueue(trackToImport, []music.QueueItemType{FailedImport}, job.ID, nil, logger, withConvertedFrom(map[string]string{"error": err.Error()}, conversion)); err != nil {
+				logger.Error("Service.runDirectoryImport: failed to add database-error track to queue", "error", err)
+			}
+			processedFiles++
+			continue
+		}
+		// Converted files are always moved out of the staging directory
+		move := moveFiles || conversion != nil
+		var action ImportAction
+		var queueTypes []music.QueueItemType
+		var itemMetadata map[string]string
+		action, queueTypes, itemMetadata = determineAction(trackToImport, duplicateTrack, config, logger)
+
+		switch action {
+		case SkipTrack:
+			stats.Skipped++
+			if conversion != nil {
+				os.Remove(path)
+			}
+			destination := ""
+			if duplicateTrack != nil {
+				destination = duplicateTrack.Path
+			}
+			report.Add(file.path, OutcomeSkipped, "duplicate", destination)
+			logger.Info("Service.runDirectoryImport: Skipping duplicate track", "reason", "track already exists", "duplicate_path", path, "title", trackToImport.Title, "color", "blue")
+		case QueueTrack:
+			if err := e.addTrackToQueue(trackToImport, queueTypes, job.ID, duplicateTrack, logger, withConvertedFrom(itemMetadata, conversion)); err != nil {
 				stats.Errors++
-				// Set track ID from path and add to queue for manual review
-				trackToImport.ID = generateTrackIDFromPath(path)
-				if err := e.addTrackToQueue(trackToImport, []music.QueueItemType{FailedImport}, job.ID, nil, logger, map[string]string{"error": err.Error()}); err != nil {
-					logger.Error("Service.runDirectoryImport: failed to add fingerprint-failed track to queue", "error", err)
-				}
-				processedFiles++
-				return nil
+				report.AddFailure(file.path, err.Error())
+			} else {
+				stats.Queued++
+				report.Add(file.path, OutcomeQueued, queueReason(queueTypes), path)
+				logger.Info("Service.runDirectoryImport: track queued as duplicate", "reason", "duplicate track found", "duplicate_path", path, "title", trackToImport.Title, "color", "violet")
 			}
-			slog.Info("Generated fingerprint for track", "path", path, "track", trackToImport, "fingerprint", fingerprint[:min(15, len(fingerprint))])
-			slog.Debug("Generated fingerprint for track", "path", path, "track", trackToImport, "fingerprint", fingerprint)
-			trackToImport.ChromaprintFingerprint = fingerprint
-			trackToImport.ID = music.GenerateTrackID(fingerprint)
-			slog.Info("Generated track id", "id", trackToImport.ID)
-			duplicateTrack, err := e.findDuplicateTrack(ctx, trackToImport, fingerprint, logger)
-			if err != nil {
-				logger.Error("Service.runDirectoryImport: failed to find duplicate track", "error", err)
+		case ReplaceTrack:
+			if err := e.service.replaceTrack(ctx, trackToImport, duplicateTrack, move, logger); err != nil {
+				logger.Error("Service.runDirectoryImport: failed to replace track", "error", err)
 				stats.Errors++
-				if err := e.addTrackToQueue(trackToImport, []music.QueueItemType{FailedImport}, job.ID, nil, logger, map[string]string{"error": err.Error()}); err != nil {
-					logger.Error("Service.runDirectoryImport: failed to add database-error track to queue", "error", err)
+				report.AddFailure(file.path, err.Error())
+				// Add failed track to queue for manual review
+				if err := e.addTrackToQueue(trackToImport, []music.QueueItemType{FailedImport}, job.ID, duplicateTrack, logger, withConvertedFrom(map[string]string{"error": err.Error()}, conversion)); err != nil {
+					logger.Error("Service.runDirectoryImport: failed to add failed replace track to queue", "error", err)
 				}
-				processedFiles++
-				return nil
+			} else {
+				stats.TracksImported++
+				report.Add(file.path, OutcomeReplaced, "", duplicateTrack.Path)
+				e.removeConverted(conversion, moveFiles, logger)
+				logger.Info("Service.runDirectoryImport: duplicate track replaced", "title", trackToImport.Title, "color", "orange")
 			}
-			var action ImportAction
-			var queueTypes []music.QueueItemType
-			var itemMetadata map[string]string
-			action, queueTypes, itemMetadata = determineAction(trackToImport, duplicateTrack, config, logger)
-
-			switch action {
-			case SkipTrack:
-				stats.Skipped++
-				logger.Info("Service.runDirectoryImport: Skipping duplicate track", "reason", "track already exists", "duplicate_path", path, "title", trackToImport.Title, "color", "blue")
-			case QueueTrack:
-				if err := e.addTrackToQueue(trackToImport, queueTypes, job.ID, duplicateTrack, logger, itemMetadata); err != nil {
-					stats.Errors++
-				} else {
-					stats.Queued++
-					logger.Info("Service.runDirectoryImport: track queued as duplicate", "reason", "duplicate track found", "duplicate_path", path, "title", trackToImport.Title, "color", "violet")
-				}
-			case ReplaceTrack:
-				if err := e.service.replaceTrack(ctx, trackToImport, duplicateTrack, moveFiles, logger); err != nil {
-					logger.Error("Service.runDirectoryImport: failed to replace track", "error", err)
-					stats.Errors++
-					// Add failed track to queue for manual review
-					if err := e.addTrackToQueue(trackToImport, []music.QueueItemType{FailedImport}, job.ID, duplicateTrack, logger, map[string]string{"error": err.Error()}); err != nil {
-						logger.Error("Service.runDirectoryImport: failed to add failed replace track to queue", "error", err)
-					}
-				} else {
-					stats.TracksImported++
-					logger.Info("Service.runDirectoryImport: duplicate track replaced", "title", trackToImport.Title, "color", "orange")
-				}
-			case ImportTrack:
-				// determineAction already validated required metadata and applied any
-				// permitted fallback defaults, so the track is ready to import here.
-				if err := e.service.importTrack(ctx, trackToImport, moveFiles, logger); err != nil {
-					logger.Error("Service.runDirectoryImport: failed to import track", "error", err, "title", trackToImport.Title, "path", trackToImport.Path)
-					stats.Errors++
-					// Add failed track to queue for manual review
-					if err := e.addTrackToQueue(trackToImport, []music.QueueItemType{FailedImport}, job.ID, nil, logger, map[string]string{"error": err.Error()}); err != nil {
-						logger.Error("Service.runDirectoryImport: failed to add failed import track to queue", "error", err)
-					}
-				} else {
-					stats.TracksImported++
-					logger.Info("Service.runDirectoryImport: Track Imported", "title", trackToImport.Title, "color", "green")
+		case ImportTrack:
+			// determineAction already validated required metadata and applied any
+			// permitted fallback defaults, so the track is ready to import here.
+			if err := e.service.importTrack(ctx, trackToImport, move, logger); err != nil {
+				logger.Error("Service.runDirectoryImport: failed to import track", "error", err, "title", trackToImport.Title, "path", trackToImport.Path)
+				stats.Errors++
+				report.AddFailure(file.path, err.Error())
+				// Add failed track to queue for manual review
+				if err := e.addTrackToQueue(trackToImport, []music.QueueItemType{FailedImport}, job.ID, nil, logger, withConvertedFrom(map[string]string{"error": err.Error()}, conversion)); err != nil {
+					logger.Error("Service.runDirectoryImport: failed to add failed import track to queue", "error", err)
 				}
+			} else {
+				stats.TracksImported++
+				report.Add(file.path, OutcomeImported, "", trackToImport.Path)
+				e.removeConverted(conversion, moveFiles, logger)
+				logger.Info("Service.runDirectoryImport: Track Imported", "title", trackToImport.Title, "color", "green")
 			}
+		}
 
-			// Update progress after processing each file
-			processedFiles++
-			if progressUpdater != nil && totalFiles > 0 {
-				progress := min((processedFiles*100)/totalFiles, 100)
-				progressUpdater(progress, fmt.Sprintf("Processed: %s", filepath.Base(path)))
-			}
+		// Update progress after processing each file
+		processedFiles++
+		if progressUpdater != nil {
+			progressUpdater(file.progress, fmt.Sprintf("Processed %d files: %s", processedFiles, filepath.Base(file.path)))
 		}
-		return nil
-	})
+	}
+	err := <-walkErr
+	logger.Info("Service.runDirectoryImport: walk finished", "files", processedFiles)
 
-	if progressUpdater != nil && err == nil && totalFiles > 0 {
+	if progressUpdater != nil && err == nil {
 		progressUpdater(100, "Import completed")
 	}
 
@@ -1,11 +1,17 @@
 package importing
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io/fs"
 	"log/slog"
 	"net/url"
+	"os"
+	"path/filepath"
 	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/contre95/soulsolid/src/features/hosting/respond"
@@ -27,6 +33,10 @@ type queueItemView struct {
 	Track        *music.Track
 	ItemMetadata map[string]string
 
+	// What the queue shows of the files to review them without opening them
+	File     filePreview
+	Existing *filePreview // the library file a duplicate matched, nil otherwise
+
 	// Status flags (derived from the item's types; may be true in combination)
 	IsDuplicate       bool
 	IsMissingMetadata bool
@@ -42,6 +52,54 @@ type queueItemView struct {
 	BlockReason    string // tooltip shown on disabled import/replace buttons
 }
 
+// filePreview is the audio properties of a file shown as badges in the queue.
+type filePreview struct {
+	Format     string
+	Bitrate    int // kbps
+	SampleRate int // Hz
+	BitDepth   int
+	Duration   int // seconds
+	Size       int64
+}
+
+// KHz returns the sample rate in kHz, e.g. "44.1".
+func (p filePreview) KHz() string {
+	return strconv.FormatFloat(float64(p.SampleRate)/1000, 'f', -1, 64)
+}
+
+// previewOf describes a track's file. Missing properties stay zero and are not shown.
+func previewOf(track *music.Track) filePreview {
+	preview := filePreview{
+		Format:     track.Format,
+		Bitrate:    track.Bitrate,
+		SampleRate: track.SampleRate,
+		BitDepth:   track.BitDepth,
+		Duration:   track.Metadata.Duration,
+	}
+	if preview.Format == "" {
+		preview.Format = strings.TrimPrefix(strings.ToLower(filepath.Ext(track.Path)), ".")
+	}
+	if info, err := os.Stat(track.Path); err == nil {
+		preview.Size = info.Size()
+	}
+	return preview
+}
+
+// addPreview describes the files of a queue item, with the library file it duplicates.
+func (h *Handler) addPreview(ctx context.Context, view *queueItemView) {
+	view.File = previewOf(view.Track)
+	path := view.ItemMetadata["duplicate_path"]
+	if !view.IsDuplicate || path == "" {
+		return
+	}
+	existing := h.service.GetDuplicateTrack(ctx, path)
+	if existing == nil {
+		existing = &music.Track{Path: path}
+	}
+	preview := previewOf(existing)
+	view.Existing = &preview
+}
+
 // groupView is a view model for grouped queue items
 type groupView struct {
 	Items         []queueItemView
@@ -114,6 +172,14 @@ func (h *Handler) ImportDirectory(c *fiber.Ctx) error {
 	jobID, err := h.service.ImportDirectory(c.Context(), req.DirectoryPath)
 	if err != nil {
 		slog.Error("Error importing directory", "error", err)
+		switch {
+		case errors.Is(err, fs.ErrPermission):
+			return respond.ToastErr(c, fiber.StatusForbidden, err.Error())
+		case errors.Is(err, fs.ErrNotExist):
+			return respond.ToastErr(c, fiber.StatusBadRequest, err.Error())
+		case errors.Is(err, music.ErrMaintenance):
+			return respond.ToastErr(c, fiber.StatusServiceUnavailable, err.Error())
+		}
 		return respond.ToastErr(c, fiber.StatusInternalServerError, "Failed to start sync job")
 	}
 	slog.Info("ImportDirectory: directory import started", "jobID", jobID)
@@ -121,6 +187,46 @@ func (h *Handler) ImportDirectory(c *fiber.Ctx) error {
 	return respond.ToastJob(c, jobID, "Directory import started!")
 }
 
+// ImportURLs is the handler for importing files from a list of remote URLs or a feed.
+func (h *Handler) ImportURLs(c *fiber.Ctx) error {
+	var req struct {
+		URLs    string `json:"urls" form:"urls"` // one URL per line
+		FeedURL string `json:"feedUrl" form:"feedUrl"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return respond.ToastErr(c, fiber.StatusBadRequest, "Cannot parse request body")
+	}
+	var urls []string
+	for line := range strings.Lines(req.URLs) {
+		if u := strings.TrimSpace(line); u != "" {
+			urls = append(urls, u)
+		}
+	}
+	jobID, err := h.service.ImportURLs(c.Context(), urls, strings.TrimSpace(req.FeedURL))
+	if err != nil {
+		slog.Error("Error importing URLs", "error", err)
+		return respond.ToastErr(c, fiber.StatusBadRequest, "Failed to start URL import: "+err.Error())
+	}
+	slog.Info("ImportURLs: URL import started", "jobID", jobID)
+	c.Response().Header.Set("HX-Trigger", "jobStarted,queueUpdated,refreshImportQueueBadge")
+	return respond.ToastJob(c, jobID, "URL import started!")
+}
+
+// RollbackImport starts a job removing everything an import job added. The files are moved to
+// the trash path unless trash is "false".
+func (h *Handler) RollbackImport(c *fiber.Ctx) error {
+	importJobID := c.Params("id")
+	trash := c.FormValue("trash") != "false"
+	jobID, err := h.service.RollbackImport(c.Context(), importJobID, trash)
+	if err != nil {
+		slog.Error("Error rolling back import", "importJob", importJobID, "error", err)
+		return respond.ToastErr(c, fiber.StatusBadRequest, "Failed to start rollback: "+err.Error())
+	}
+	slog.Info("RollbackImport: rollback started", "jobID", jobID, "importJob", importJobID, "trash", trash)
+	c.Response().Header.Set("HX-Trigger", "jobStarted,queueUpdated,refreshImportQueueBadge")
+	return respond.ToastJob(c, jobID, "Rollback started!")
+}
+
 // ProcessQueueItem handles import/cancel actions for individual queue items
 func (h *Handler) ProcessQueueItem(c *fiber.Ctx) error {
 	itemID := c.Params("id")
@@ -153,6 +259,24 @@ func (h *Handler) QueueCount(c *fiber.Ctx) error {
 	return respond.Text(c, "queue_count", count, formatted)
 }
 
+// GetQueueCard renders the dashboard card summarizing the import queue.
+func (h *Handler) GetQueueCard(c *fiber.Ctx) error {
+	return respond.Partial(c, "importing/queue_card", fiber.Map{
+		"Count": len(h.service.GetQueuedItems()),
+		"Aging": h.service.GetQueueAging(),
+	})
+}
+
+// ExpireQueue resolves the queue items older than the configured expiry age right away.
+func (h *Handler) ExpireQueue(c *fiber.Ctx) error {
+	if h.service.GetQueueAging().ExpireAfterDays <= 0 {
+		return respond.ToastErr(c, fiber.StatusBadRequest, "Queue expiry is disabled")
+	}
+	expired := h.service.ExpireQueueItems(c.Context())
+	c.Response().Header.Set("HX-Trigger", "queueUpdated,refreshImportQueueBadge")
+	return respond.ToastOk(c, fmt.Sprintf("Expired %d queue item(s)", expired))
+}
+
 // ClearQueue handles clearing all items from the import queue
 func (h *Handler) ClearQueue(c *fiber.Ctx) error {
 	err := h.service.ClearQueue()
@@ -175,6 +299,60 @@ func (h *Handler) PruneDownloadPath(c *fiber.Ctx) error {
 	return respond.ToastOk(c, "Download path pruned and queue cleared successfully")
 }
 
+// ScanLeftovers starts a job looking for files in the download path already in the library.
+func (h *Handler) ScanLeftovers(c *fiber.Ctx) error {
+	jobID, err := h.service.ScanLeftovers(c.Context())
+	if err != nil {
+		slog.Error("Error starting leftovers scan", "error", err)
+		if errors.Is(err, music.ErrMaintenance) {
+			return respond.ToastErr(c, fiber.StatusServiceUnavailable, err.Error())
+		}
+		return respond.ToastErr(c, fiber.StatusInternalServerError, "Failed to start leftovers scan")
+	}
+	c.Response().Header.Set("HX-Trigger", "jobStarted,leftoversChanged")
+	return respond.ToastJob(c, jobID, "Leftovers scan started!")
+}
+
+// CleanLeftovers deletes the leftovers found by the last scan.
+func (h *Handler) CleanLeftovers(c *fiber.Ctx) error {
+	deleted, freed, err := h.service.CleanLeftovers(c.Context())
+	if err != nil {
+		slog.Error("Failed to clean leftovers", "error", err)
+		return respond.ToastErr(c, fiber.StatusBadRequest, "Failed to clean leftovers: "+err.Error())
+	}
+	c.Response().Header.Set("HX-Trigger", "leftoversChanged,queueUpdated,refreshImportQueueBadge")
+	return respond.ToastOk(c, fmt.Sprintf("Deleted %d leftover(s), %.1f MB freed", deleted, float64(freed)/(1<<20)))
+}
+
+// ApplyRetention starts a job applying the retention policy to the download path.
+func (h *Handler) ApplyRetention(c *fiber.Ctx) error {
+	jobID, err := h.service.ApplyRetention(c.Context())
+	if err != nil {
+		slog.Error("Error starting retention job", "error", err)
+		if errors.Is(err, music.ErrMaintenance) {
+			return respond.ToastErr(c, fiber.StatusServiceUnavailable, err.Error())
+		}
+		return respond.ToastErr(c, fiber.StatusInternalServerError, "Failed to start retention job")
+	}
+	c.Response().Header.Set("HX-Trigger", "jobStarted")
+	return respond.ToastJob(c, jobID, "Retention started!")
+}
+
+// GetLeftovers renders the leftovers card with the result of the last scan.
+func (h *Handler) GetLeftovers(c *fiber.Ctx) error {
+	scan := h.service.GetLeftovers()
+	size := ""
+	if scan != nil {
+		size = fmt.Sprintf("%.1f MB", float64(scan.Bytes)/(1<<20))
+	}
+	return respond.Partial(c, "importing/leftovers", fiber.Map{
+		"Scan":      scan,
+		"Size":      size,
+		"Scanning":  h.service.IsScanningLeftovers(),
+		"Retention": h.service.config.Get().Import.Retention,
+	})
+}
+
 // ToggleWatcher toggles the file system watcher on/off
 func (h *Handler) ToggleWatcher(c *fiber.Ctx) error {
 	action := c.FormValue("action")
@@ -224,6 +402,14 @@ func (h *Handler) GetWatcherToggleState(c *fiber.Ctx) error {
 	})
 }
 
+// GetWatcherDiagnostics renders the watcher internals: watched paths, event counters and what
+// became of the recently created files, or returns them as JSON.
+func (h *Handler) GetWatcherDiagnostics(c *fiber.Ctx) error {
+	return respond.Partial(c, "importing/watcher_diagnostics", fiber.Map{
+		"Diagnostics": h.service.GetWatcherDiagnostics(),
+	})
+}
+
 // UI Hanlders
 // GetDirectoryForm renders the directory import form
 func (h *Handler) GetDirectoryForm(c *fiber.Ctx) error {
@@ -265,6 +451,9 @@ func (h *Handler) RenderQueueItems(c *fiber.Ctx) error {
 	if len(queueItems) > 10 {
 		queueItems = queueItems[:10]
 	}
+	for i := range queueItems {
+		h.addPreview(c.Context(), &queueItems[i])
+	}
 
 	return respond.Partial(c, "importing/queue_items", fiber.Map{
 		"QueueItems": queueItems,
@@ -281,6 +470,7 @@ func (h *Handler) GetQueueHeader(c *fiber.Ctx) error {
 
 	return respond.Partial(c, "importing/queue_header", fiber.Map{
 		"QueueCount": queueCount,
+		"Aging":      h.service.GetQueueAging(),
 	})
 }
 
@@ -361,6 +551,7 @@ func (h *Handler) RenderGroupedQueueItems(c *fiber.Ctx) error {
 				slog.Error("Failed to convert queue item", "error", err, "itemID", item.ID)
 				continue
 			}
+			h.addPreview(c.Context(), &view)
 			viewItems = append(viewItems, view)
 			// Only surface the bulk replace button when at least one item can
 			// actually be replaced; a duplicate that is missing metadata or failed
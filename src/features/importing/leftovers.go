@@ -0,0 +1,261 @@
+package importing
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/contre95/soulsolid/src/music"
+)
+
+// Leftover is a file in the download path with the same content as a file in the library,
+// usually left behind by an import that copied it.
+type Leftover struct {
+	Path        string `json:"path"`
+	LibraryPath string `json:"library_path"`
+	Size        int64  `json:"size"`
+}
+
+// LeftoverScan is the result of the last leftovers scan.
+type LeftoverScan struct {
+	Leftovers []Leftover `json:"leftovers"`
+	Scanned   int        `json:"scanned"` // music files found in the download path
+	Bytes     int64      `json:"bytes"`   // total size of the leftovers
+	ScannedAt time.Time  `json:"scanned_at"`
+}
+
+// leftovers holds the last scan, shared by the scan job and the cleanup.
+type leftovers struct {
+	mu   sync.Mutex
+	scan *LeftoverScan
+}
+
+// ScanLeftovers starts a job looking for files in the download path already in the library.
+func (s *Service) ScanLeftovers(ctx context.Context) (string, error) {
+	jobID, err := s.jobService.StartJob("download_leftovers", "Download Leftovers Scan", map[string]any{})
+	if err != nil {
+		slog.Error("Service.ScanLeftovers: failed to start job", "error", err)
+		return "", fmt.Errorf("failed to start leftovers scan job: %w", err)
+	}
+	return jobID, nil
+}
+
+// GetLeftovers returns the last leftovers scan, nil if none ran since startup.
+func (s *Service) GetLeftovers() *LeftoverScan {
+	s.leftovers.mu.Lock()
+	defer s.leftovers.mu.Unlock()
+	return s.leftovers.scan
+}
+
+// IsScanningLeftovers reports whether a leftovers scan is pending or running.
+func (s *Service) IsScanningLeftovers() bool {
+	for _, job := range s.jobService.GetJobs() {
+		if job.Type == "download_leftovers" && (job.Status == music.JobStatusPending || job.Status == music.JobStatusRunning) {
+			return true
+		}
+	}
+	return false
+}
+
+// CleanLeftovers deletes the leftovers found by the last scan and drops their queue items. A file
+// whose size changed since the scan is kept. It returns how many files were deleted and their size.
+func (s *Service) CleanLeftovers(ctx context.Context) (int, int64, error) {
+	s.leftovers.mu.Lock()
+	defer s.leftovers.mu.Unlock()
+	if s.leftovers.scan == nil || len(s.leftovers.scan.Leftovers) == 0 {
+		return 0, 0, fmt.Errorf("no leftovers to clean, scan the download path first")
+	}
+	queued := map[string]string{}
+	for id, item := range s.queue.GetAll() {
+		if item.Track != nil {
+			queued[item.Track.Path] = id
+		}
+	}
+	deleted, freed := 0, int64(0)
+	var kept []Leftover
+	for _, l := range s.leftovers.scan.Leftovers {
+		info, err := os.Stat(l.Path)
+		if err != nil || info.Size() != l.Size {
+			continue
+		}
+		if err := s.fileManager.DeleteTrack(ctx, l.Path); err != nil {
+			slog.Warn("Failed to delete leftover", "path", l.Path, "error", err)
+			kept = append(kept, l)
+			continue
+		}
+		if id, ok := queued[l.Path]; ok {
+			if err := s.queue.Remove(id); err != nil {
+				slog.Warn("Failed to remove leftover from the queue", "path", l.Path, "error", err)
+			}
+		}
+		deleted++
+		freed += l.Size
+	}
+	s.leftovers.scan.Leftovers = kept
+	s.leftovers.scan.Bytes -= freed
+	slog.Info("Download leftovers cleaned", "deleted", deleted, "bytes", freed)
+	return deleted, freed, nil
+}
+
+// LeftoversTask implements jobs.Task for scanning the download path for leftovers.
+type LeftoversTask struct {
+	service *Service
+}
+
+// NewLeftoversTask creates a new LeftoversTask.
+func NewLeftoversTask(service *Service) *LeftoversTask {
+	return &LeftoversTask{service: service}
+}
+
+// MetadataKeys returns the required metadata keys for a leftovers scan.
+func (t *LeftoversTask) MetadataKeys() []string {
+	return []string{}
+}
+
+// Execute compares the music files of the download path with the library.
+func (t *LeftoversTask) Execute(ctx context.Context, job *music.Job, progressUpdater func(int, string)) (map[string]any, error) {
+	cfg := t.service.config.Get()
+	logger := job.Logger
+
+	progressUpdater(0, "Listing download path")
+	// Each tree skips the other, when one is inside it its files are not compared with themselves
+	downloadPath, libraryPath := filepath.Clean(cfg.DownloadPath), filepath.Clean(cfg.LibraryPath)
+	downloads, err := musicFilesBySize(ctx, downloadPath, libraryPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list download path: %w", err)
+	}
+	progressUpdater(10, "Listing library")
+	matcher, err := newContentMatcher(ctx, libraryPath, downloadPath)
+	if err != nil {
+		return nil, err
+	}
+
+	scan := &LeftoverScan{ScannedAt: time.Now()}
+	for _, paths := range downloads {
+		scan.Scanned += len(paths)
+	}
+	done := 0
+	for size, paths := range downloads {
+		for _, path := range paths {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			done++
+			progressUpdater(10+done*90/max(scan.Scanned, 1), "Checking "+filepath.Base(path))
+			libPath, err := matcher.match(path, size)
+			if err != nil {
+				logger.Warn("Failed to hash file", "path", path, "error", err)
+				continue
+			}
+			if libPath != "" {
+				logger.Info("Found leftover", "path", path, "library", libPath, "color", "orange")
+				scan.Leftovers = append(scan.Leftovers, Leftover{Path: path, LibraryPath: libPath, Size: size})
+				scan.Bytes += size
+			}
+		}
+	}
+
+	t.service.leftovers.mu.Lock()
+	t.service.leftovers.scan = scan
+	t.service.leftovers.mu.Unlock()
+
+	msg := fmt.Sprintf("Found %d leftovers (%.1f MB) in %d files", len(scan.Leftovers), float64(scan.Bytes)/(1<<20), scan.Scanned)
+	progressUpdater(100, msg)
+	return map[string]any{"msg": msg, "leftovers": len(scan.Leftovers)}, nil
+}
+
+// Cleanup does nothing, the scan leaves no temporary files.
+func (t *LeftoversTask) Cleanup(job *music.Job) error {
+	return nil
+}
+
+// musicFilesBySize lists the supported music files under root grouped by size, leaving the skip
+// subtree out.
+func musicFilesBySize(ctx context.Context, root, skip string) (map[int64][]string, error) {
+	files := map[int64][]string{}
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if d.IsDir() && path == skip {
+			return filepath.SkipDir
+		}
+		if d.IsDir() || !isSupportedFile(path) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		files[info.Size()] = append(files[info.Size()], path)
+		return nil
+	})
+	return files, err
+}
+
+// contentMatcher finds the library file with the same content as a file. Files are compared by
+// size first, and only library files matching a size are hashed, once each.
+type contentMatcher struct {
+	library map[int64][]string
+	hashes  map[string]string
+}
+
+// newContentMatcher lists the files of the library, leaving the download path out when it's
+// inside the library.
+func newContentMatcher(ctx context.Context, libraryPath, downloadPath string) (*contentMatcher, error) {
+	library, err := musicFilesBySize(ctx, libraryPath, downloadPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list library: %w", err)
+	}
+	return &contentMatcher{library: library, hashes: map[string]string{}}, nil
+}
+
+// match returns the library file with the same content as path, empty when there is none.
+func (m *contentMatcher) match(path string, size int64) (string, error) {
+	candidates := m.library[size]
+	if len(candidates) == 0 {
+		return "", nil
+	}
+	hash, err := hashFile(path)
+	if err != nil {
+		return "", err
+	}
+	for _, libPath := range candidates {
+		libHash, ok := m.hashes[libPath]
+		if !ok {
+			if libHash, err = hashFile(libPath); err != nil {
+				slog.Warn("Failed to hash library file", "path", libPath, "error", err)
+			}
+			m.hashes[libPath] = libHash
+		}
+		if libHash != "" && libHash == hash {
+			return libPath, nil
+		}
+	}
+	return "", nil
+}
+
+// hashFile returns the hex SHA-256 of a file's content.
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
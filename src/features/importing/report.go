@@ -0,0 +1,232 @@
+package importing
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/contre95/soulsolid/src/features/jobs"
+	"github.com/contre95/soulsolid/src/music"
+)
+
+// reportListLimit bounds how many failures and conversions a report keeps in memory, the others
+// are only counted. Every file is still listed in the files of the report, written as it goes.
+const reportListLimit = 200
+
+// ReportOutcome describes what happened to a single file during a directory import.
+type ReportOutcome string
+
+const (
+	OutcomeImported ReportOutcome = "imported"
+	OutcomeReplaced ReportOutcome = "replaced"
+	OutcomeQueued   ReportOutcome = "queued"
+	OutcomeSkipped  ReportOutcome = "skipped"
+	OutcomeFailed   ReportOutcome = "failed"
+)
+
+// ReportEntry is a file that failed to import.
+type ReportEntry struct {
+	File   string `json:"file"`
+	Reason string `json:"reason"`
+}
+
+// ReportFile is one processed file of an import, with where it went.
+type ReportFile struct {
+	File        string        `json:"file"`
+	Outcome     ReportOutcome `json:"outcome"`
+	Reason      string        `json:"reason,omitempty"`
+	Destination string        `json:"destination,omitempty"`
+}
+
+// ImportReport summarizes a directory import job: its stats, the files that failed and the
+// files converted before import, both lists capped at reportListLimit. Every processed file is
+// streamed to a JSON Lines file next to the report, so memory stays flat however large the import.
+type ImportReport struct {
+	JobID              string        `json:"jobId"`
+	Path               string        `json:"path"`
+	StartedAt          time.Time     `json:"startedAt"`
+	FinishedAt         time.Time     `json:"finishedAt"`
+	Stats              ImportStats   `json:"stats"`
+	Files              string        `json:"files,omitempty"` // path of the JSON Lines file listing every processed file
+	Failures           []ReportEntry `json:"failures"`
+	OmittedFailures    int           `json:"omittedFailures"` // failures past the limit, counted only
+	Conversions        []Conversion  `json:"conversions"`
+	OmittedConversions int           `json:"omittedConversions"`
+
+	files *os.File
+	w     *bufio.Writer
+}
+
+// startReport creates the report of an import job with its files opened in the reports
+// directory. A report whose files can't be opened only has the stats, failures and conversions.
+func (s *Service) startReport(jobID, path string, logger *slog.Logger) *ImportReport {
+	report := NewImportReport(jobID, path)
+	if err := report.OpenFiles(s.reportDir()); err != nil {
+		logger.Warn("Failed to open the files of the import report", "error", err)
+	}
+	return report
+}
+
+// reportDir is the directory import reports are written to.
+func (s *Service) reportDir() string {
+	return filepath.Join(s.config.Get().Jobs.LogPath, "reports")
+}
+
+// NewImportReport creates an empty report for the given job and import path.
+func NewImportReport(jobID, path string) *ImportReport {
+	return &ImportReport{
+		JobID:       jobID,
+		Path:        path,
+		StartedAt:   time.Now(),
+		Failures:    []ReportEntry{},
+		Conversions: []Conversion{},
+	}
+}
+
+// name is the base name of the report files.
+func (r *ImportReport) name() string {
+	return fmt.Sprintf("%s-%s-report", r.StartedAt.Format("2006-01-02"), r.JobID)
+}
+
+// OpenFiles creates the file of dir every processed file is written to. Without it the report
+// only has the stats, failures and conversions.
+func (r *ImportReport) OpenFiles(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create report directory: %w", err)
+	}
+	path := filepath.Join(dir, r.name()+"-files.jsonl")
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create report files: %w", err)
+	}
+	r.files, r.w, r.Files = f, bufio.NewWriter(f), path
+	return nil
+}
+
+// Add records what happened to a processed file. It is a no-op on a nil report.
+func (r *ImportReport) Add(file string, outcome ReportOutcome, reason, destination string) {
+	if r == nil || r.w == nil {
+		return
+	}
+	line, err := json.Marshal(ReportFile{File: file, Outcome: outcome, Reason: reason, Destination: destination})
+	if err == nil {
+		r.w.Write(append(line, '\n'))
+	}
+}
+
+// AddFailure records a file that failed to import. It is a no-op on a nil report.
+func (r *ImportReport) AddFailure(file, reason string) {
+	if r == nil {
+		return
+	}
+	r.Add(file, OutcomeFailed, reason, "")
+	if len(r.Failures) >= reportListLimit {
+		r.OmittedFailures++
+		return
+	}
+	r.Failures = append(r.Failures, ReportEntry{File: file, Reason: reason})
+}
+
+// AddConversion records a file converted before it was imported. It is a no-op on a nil report.
+func (r *ImportReport) AddConversion(conversion Conversion) {
+	if r == nil {
+		return
+	}
+	if len(r.Conversions) >= reportListLimit {
+		r.OmittedConversions++
+		return
+	}
+	r.Conversions = append(r.Conversions, conversion)
+}
+
+// queueReason names the reasons a file was queued for review.
+func queueReason(types []music.QueueItemType) string {
+	names := make([]string, len(types))
+	for i, t := range types {
+		names[i] = string(t)
+	}
+	return strings.Join(names, ", ")
+}
+
+// Close flushes and closes the files of the report. It can be called more than once.
+func (r *ImportReport) Close() error {
+	if r == nil || r.files == nil {
+		return nil
+	}
+	err := r.w.Flush()
+	if cerr := r.files.Close(); err == nil {
+		err = cerr
+	}
+	r.files, r.w = nil, nil
+	return err
+}
+
+// Entries reads back the processed files of the report, one at a time, for the HTML report.
+func (r *ImportReport) Entries() iter.Seq[ReportFile] {
+	return func(yield func(ReportFile) bool) {
+		if r.Files == "" {
+			return
+		}
+		f, err := os.Open(r.Files)
+		if err != nil {
+			slog.Warn("Failed to read report files", "path", r.Files, "error", err)
+			return
+		}
+		defer f.Close()
+		dec := json.NewDecoder(f)
+		for {
+			var entry ReportFile
+			if err := dec.Decode(&entry); err != nil {
+				return
+			}
+			if !yield(entry) {
+				return
+			}
+		}
+	}
+}
+
+var reportTemplate = jobs.NewReportTemplate(`{{ define "title" }}Import report {{ .JobID }}{{ end }}
+{{ define "body" }}<style>
+.imported { color: #15803d; } .replaced { color: #c2410c; } .queued { color: #7e22ce; }
+.skipped { color: #1d4ed8; } .failed { color: #b91c1c; }
+</style>
+<h1>Import report</h1>
+<p><strong>Path:</strong> {{ .Path }}<br>
+<strong>Job:</strong> {{ .JobID }}<br>
+<strong>Started:</strong> {{ .StartedAt.Format "2006-01-02 15:04:05" }}<br>
+<strong>Finished:</strong> {{ .FinishedAt.Format "2006-01-02 15:04:05" }}</p>
+<p>{{ .Stats.TracksImported }} imported, {{ .Stats.Queued }} queued, {{ .Stats.Skipped }} skipped, {{ .Stats.Errors }} errors{{ if .Stats.Converted }}, {{ .Stats.Converted }} converted{{ end }}</p>
+{{ if .Files }}<h2>Files</h2>
+<table>
+<tr><th>File</th><th>Outcome</th><th>Reason</th><th>Destination</th></tr>
+{{ range .Entries }}<tr><td>{{ .File }}</td><td class="{{ .Outcome }}">{{ .Outcome }}</td><td>{{ .Reason }}</td><td>{{ .Destination }}</td></tr>
+{{ end }}</table>
+{{ else if .Failures }}<h2>Failures</h2>
+<table>
+<tr><th>File</th><th>Reason</th></tr>
+{{ range .Failures }}<tr><td>{{ .File }}</td><td>{{ .Reason }}</td></tr>
+{{ end }}</table>
+{{ if .OmittedFailures }}<p>{{ .OmittedFailures }} more failures are only listed in the job logs.</p>{{ end }}
+{{ end }}{{ if .Conversions }}<h2>Conversions</h2>
+<table>
+<tr><th>File</th><th>From</th><th>To</th><th>Bitrate</th></tr>
+{{ range .Conversions }}<tr><td>{{ .File }}</td><td>{{ .From }}</td><td>{{ .To }}</td><td>{{ if .Bitrate }}{{ .Bitrate }} kbps{{ end }}</td></tr>
+{{ end }}</table>
+{{ if .OmittedConversions }}<p>{{ .OmittedConversions }} more conversions are only listed in the job logs.</p>{{ end }}
+{{ end }}{{ end }}`)
+
+// Save closes the files of the report, then writes the report as JSON and HTML into dir and
+// returns both paths.
+func (r *ImportReport) Save(dir string) (string, string, error) {
+	if err := r.Close(); err != nil {
+		return "", "", fmt.Errorf("failed to write report files: %w", err)
+	}
+	return jobs.SaveReport(dir, r.name(), r, reportTemplate)
+}
@@ -0,0 +1,269 @@
+package importing
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/contre95/soulsolid/src/music"
+)
+
+const (
+	// retentionInterval is how often the retention policy of the download path runs.
+	retentionInterval = 24 * time.Hour
+	gigabyte          = 1 << 30
+)
+
+// RetentionStats counts the files the retention policy deleted, by the rule that deleted them.
+type RetentionStats struct {
+	Imported int   `json:"imported"` // already in the library and older than imported_after_days
+	Stale    int   `json:"stale"`    // older than stale_after_days
+	Size     int   `json:"size"`     // oldest files deleted to bring the folder under max_size_gb
+	Free     int   `json:"free"`     // oldest files deleted to free min_free_gb on the disk
+	Queued   int   `json:"queued"`   // files kept because they wait in the review queue
+	Bytes    int64 `json:"bytes"`    // total size deleted
+	Errors   int   `json:"errors"`
+}
+
+// stagedFile is a file of the download path considered by the retention policy.
+type stagedFile struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// ApplyRetention starts a job applying the retention policy to the download path.
+func (s *Service) ApplyRetention(ctx context.Context) (string, error) {
+	jobID, err := s.jobService.StartJob("download_retention", "Download Retention", map[string]any{})
+	if err != nil {
+		slog.Error("Service.ApplyRetention: failed to start job", "error", err)
+		return "", fmt.Errorf("failed to start retention job: %w", err)
+	}
+	return jobID, nil
+}
+
+// watchRetention applies the retention policy once a day while it's enabled.
+func (s *Service) watchRetention() {
+	ticker := time.NewTicker(retentionInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if s.paused.Load() || !s.config.Get().Import.Retention.Enabled {
+			continue
+		}
+		if _, err := s.ApplyRetention(context.Background()); err != nil {
+			slog.Error("Failed to start scheduled retention job", "error", err)
+		}
+	}
+}
+
+// RetentionTask implements jobs.Task for applying the retention policy to the download path.
+type RetentionTask struct {
+	service *Service
+}
+
+// NewRetentionTask creates a new RetentionTask.
+func NewRetentionTask(service *Service) *RetentionTask {
+	return &RetentionTask{service: service}
+}
+
+// MetadataKeys returns the required metadata keys for a retention job.
+func (t *RetentionTask) MetadataKeys() []string {
+	return []string{}
+}
+
+// Execute deletes the files of the download path matching the retention policy: imported files
+// and stale files first, then the oldest files until the size and free space limits are met.
+// Files waiting in the review queue are never deleted.
+func (t *RetentionTask) Execute(ctx context.Context, job *music.Job, progressUpdater func(int, string)) (map[string]any, error) {
+	cfg := t.service.config.Get()
+	policy := cfg.Import.Retention
+	logger := job.Logger
+	downloadPath, libraryPath := filepath.Clean(cfg.DownloadPath), filepath.Clean(cfg.LibraryPath)
+	if downloadPath == libraryPath || strings.HasPrefix(downloadPath, libraryPath+string(filepath.Separator)) {
+		return nil, fmt.Errorf("the download path %s is inside the library, refusing to apply retention", downloadPath)
+	}
+
+	progressUpdater(0, "Listing download path")
+	queued := map[string]bool{}
+	for _, item := range t.service.queue.GetAll() {
+		if item.Track != nil {
+			queued[item.Track.Path] = true
+		}
+	}
+	var files []stagedFile
+	var stats RetentionStats
+	err := filepath.WalkDir(downloadPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			// A library inside the download path is never part of it
+			if path == libraryPath {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if queued[path] {
+			stats.Queued++
+			return nil
+		}
+		files = append(files, stagedFile{path: path, size: info.Size(), modTime: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list download path: %w", err)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+
+	remove := func(f stagedFile, reason string) bool {
+		if err := os.Remove(f.path); err != nil {
+			logger.Warn("Failed to delete file", "path", f.path, "error", err)
+			stats.Errors++
+			return false
+		}
+		logger.Info("Deleted file", "path", f.path, "reason", reason, "color", "orange")
+		stats.Bytes += f.size
+		return true
+	}
+
+	progressUpdater(20, "Deleting imported and stale files")
+	imported, err := t.importedFiles(ctx, files, policy.ImportedAfterDays, libraryPath, downloadPath)
+	if err != nil {
+		return nil, err
+	}
+	staleBefore := time.Now().AddDate(0, 0, -policy.StaleAfterDays)
+	var kept []stagedFile
+	for _, f := range files {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		switch {
+		case imported[f.path]:
+			if remove(f, "imported") {
+				stats.Imported++
+				continue
+			}
+		case policy.StaleAfterDays > 0 && f.modTime.Before(staleBefore):
+			if remove(f, "stale") {
+				stats.Stale++
+				continue
+			}
+		}
+		kept = append(kept, f)
+	}
+
+	progressUpdater(70, "Applying size limits")
+	var total int64
+	for _, f := range kept {
+		total += f.size
+	}
+	var excess int64
+	if policy.MaxSizeGB > 0 {
+		excess = total - int64(policy.MaxSizeGB)*gigabyte
+	}
+	var missing int64
+	if policy.MinFreeGB > 0 {
+		var stat syscall.Statfs_t
+		if err := syscall.Statfs(downloadPath, &stat); err != nil {
+			logger.Warn("Failed to stat the download path filesystem", "error", err)
+		} else {
+			missing = int64(policy.MinFreeGB)*gigabyte - int64(stat.Bavail)*int64(stat.Bsize)
+		}
+	}
+	for _, f := range kept {
+		if excess <= 0 && missing <= 0 {
+			break
+		}
+		reason := "max size"
+		if excess <= 0 {
+			reason = "min free space"
+		}
+		if !remove(f, reason) {
+			continue
+		}
+		if excess > 0 {
+			stats.Size++
+		} else {
+			stats.Free++
+		}
+		excess -= f.size
+		missing -= f.size
+	}
+	if missing > 0 {
+		logger.Warn("Not enough files in the download path to reach the free space limit", "missingBytes", missing)
+	}
+	removeEmptyDirs(downloadPath, libraryPath)
+
+	deleted := stats.Imported + stats.Stale + stats.Size + stats.Free
+	msg := fmt.Sprintf("Deleted %d files (%.1f MB): %d imported, %d stale, %d over the size limit, %d for free space", deleted, float64(stats.Bytes)/(1<<20), stats.Imported, stats.Stale, stats.Size, stats.Free)
+	if stats.Queued > 0 {
+		msg += fmt.Sprintf(", %d queued files kept", stats.Queued)
+	}
+	progressUpdater(100, msg)
+	slog.Info("Download retention applied", "deleted", deleted, "bytes", stats.Bytes)
+	result := map[string]any{"msg": msg, "retention": stats}
+	if stats.Errors > 0 {
+		return result, fmt.Errorf("%w: %d errors", music.ErrJobPartialSuccess, stats.Errors)
+	}
+	return result, nil
+}
+
+// importedFiles returns the music files older than days with the same content as a library file.
+func (t *RetentionTask) importedFiles(ctx context.Context, files []stagedFile, days int, libraryPath, downloadPath string) (map[string]bool, error) {
+	imported := map[string]bool{}
+	if days <= 0 {
+		return imported, nil
+	}
+	matcher, err := newContentMatcher(ctx, libraryPath, downloadPath)
+	if err != nil {
+		return nil, err
+	}
+	before := time.Now().AddDate(0, 0, -days)
+	for _, f := range files {
+		if !f.modTime.Before(before) || !isSupportedFile(f.path) {
+			continue
+		}
+		if libPath, err := matcher.match(f.path, f.size); err == nil && libPath != "" {
+			imported[f.path] = true
+		}
+	}
+	return imported, nil
+}
+
+// removeEmptyDirs removes the directories left empty under root, keeping root itself and
+// leaving the skip subtree untouched.
+func removeEmptyDirs(root, skip string) {
+	var dirs []string
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() || path == root {
+			return nil
+		}
+		if path == skip {
+			return filepath.SkipDir
+		}
+		dirs = append(dirs, path)
+		return nil
+	})
+	// Deepest first, so parents emptied by their children are removed too
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if entries, err := os.ReadDir(dirs[i]); err == nil && len(entries) == 0 {
+			os.Remove(dirs[i])
+		}
+	}
+}
+
+// Cleanup does nothing, the retention job leaves no temporary files.
+func (t *RetentionTask) Cleanup(job *music.Job) error {
+	return nil
+}
@@ -0,0 +1,246 @@
+package importing
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/contre95/soulsolid/src/music"
+)
+
+// defaultTrashPath is used when import.trash_path is not set.
+const defaultTrashPath = "./trash"
+
+// RollbackStats counts what a rollback removed.
+type RollbackStats struct {
+	Tracks  int `json:"tracks"`
+	Albums  int `json:"albums"`
+	Artists int `json:"artists"`
+	Queued  int `json:"queued"` // queue items of the import dropped from the review queue
+	Locked  int `json:"locked"` // locked tracks left in the library
+	Errors  int `json:"errors"`
+}
+
+// RollbackImport starts a job removing everything the import job importJobID added to the
+// library. With trash, the files are moved under the configured trash path instead of deleted.
+func (s *Service) RollbackImport(ctx context.Context, importJobID string, trash bool) (string, error) {
+	count, err := s.library.GetTracksFilteredCount(ctx, &music.TrackFilter{AddedByJob: importJobID})
+	if err != nil {
+		return "", fmt.Errorf("failed to count the tracks of job %s: %w", importJobID, err)
+	}
+	if count == 0 && len(s.queuedBy(importJobID)) == 0 {
+		return "", fmt.Errorf("job %s added no tracks", importJobID)
+	}
+	jobID, err := s.jobService.StartJob("rollback_import", "Rollback Import", map[string]any{
+		"import_job": importJobID,
+		"trash":      trash,
+	})
+	if err != nil {
+		slog.Error("Service.RollbackImport: failed to start job", "error", err)
+		return "", fmt.Errorf("failed to start rollback job: %w", err)
+	}
+	return jobID, nil
+}
+
+// queuedBy returns the IDs of the queue items queued by the given job.
+func (s *Service) queuedBy(jobID string) []string {
+	var ids []string
+	for id, item := range s.queue.GetAll() {
+		if item.JobID == jobID {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// RollbackImportTask implements jobs.Task for rolling back an import job.
+type RollbackImportTask struct {
+	service *Service
+}
+
+// NewRollbackImportTask creates a new RollbackImportTask.
+func NewRollbackImportTask(service *Service) *RollbackImportTask {
+	return &RollbackImportTask{service: service}
+}
+
+// MetadataKeys returns the required metadata keys for a rollback job.
+func (t *RollbackImportTask) MetadataKeys() []string {
+	return []string{"import_job", "trash"}
+}
+
+// Execute removes the tracks the import added, the albums and artists created for them once
+// they're empty, and the import's items left in the review queue.
+func (t *RollbackImportTask) Execute(ctx context.Context, job *music.Job, progressUpdater func(int, string)) (map[string]any, error) {
+	importJobID, _ := job.Metadata["import_job"].(string)
+	trash, _ := job.Metadata["trash"].(bool)
+	s := t.service
+	logger := job.Logger
+
+	filter := &music.TrackFilter{AddedByJob: importJobID}
+	total, err := s.library.GetTracksFilteredCount(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count tracks: %w", err)
+	}
+	tracks, err := s.library.GetTracksFilteredPaginated(ctx, total, 0, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tracks: %w", err)
+	}
+
+	var stats RollbackStats
+	albums, artists := map[string]bool{}, map[string]bool{}
+	for i, track := range tracks {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		progressUpdater(i*100/max(len(tracks), 1), fmt.Sprintf("Removing %s", track.Title))
+		if track.IsLocked() {
+			logger.Info("Keeping locked track", "title", track.Title, "path", track.Path)
+			stats.Locked++
+			continue
+		}
+		// The file goes first, a track is only removed from the library once its file is gone
+		trashed, err := t.removeFile(ctx, track.Path, importJobID, trash)
+		if err != nil {
+			logger.Error("Failed to remove track file, keeping the track", "title", track.Title, "path", track.Path, "error", err)
+			stats.Errors++
+			continue
+		}
+		if err := s.library.DeleteTrack(ctx, track.ID); err != nil {
+			logger.Error("Failed to remove track", "title", track.Title, "error", err)
+			stats.Errors++
+			t.restoreFile(ctx, trashed, track.Path, logger)
+			continue
+		}
+		if track.Album != nil {
+			albums[track.Album.ID] = true
+			for _, role := range track.Album.Artists {
+				if role.Artist != nil {
+					artists[role.Artist.ID] = true
+				}
+			}
+		}
+		for _, role := range track.Artists {
+			if role.Artist != nil {
+				artists[role.Artist.ID] = true
+			}
+		}
+		logger.Info("Removed track", "title", track.Title, "path", track.Path, "color", "orange")
+		stats.Tracks++
+	}
+
+	for albumID := range albums {
+		album, err := s.library.GetAlbum(ctx, albumID)
+		if err != nil || album == nil {
+			continue
+		}
+		if addedBy, _ := album.AddedBy(); addedBy != importJobID {
+			continue
+		}
+		left, err := s.library.GetTracksFilteredCount(ctx, &music.TrackFilter{AlbumIDs: []string{albumID}})
+		if err != nil || left > 0 {
+			continue
+		}
+		if err := s.library.DeleteAlbum(ctx, albumID); err != nil {
+			logger.Error("Failed to remove album", "album", album.Title, "error", err)
+			stats.Errors++
+			continue
+		}
+		logger.Info("Removed album", "album", album.Title, "color", "orange")
+		stats.Albums++
+	}
+
+	// Artists left without tracks and albums only came with the import, followed ones are kept
+	for artistID := range artists {
+		artist, err := s.library.GetArtist(ctx, artistID)
+		if err != nil || artist == nil || artist.IsFollowed() {
+			continue
+		}
+		tracksLeft, err := s.library.GetTracksFilteredCount(ctx, &music.TrackFilter{ArtistIDs: []string{artistID}})
+		if err != nil || tracksLeft > 0 {
+			continue
+		}
+		albumsLeft, err := s.library.GetAlbumsFilteredCount(ctx, "", []string{artistID})
+		if err != nil || albumsLeft > 0 {
+			continue
+		}
+		if err := s.library.DeleteArtist(ctx, artistID); err != nil {
+			logger.Error("Failed to remove artist", "artist", artist.Name, "error", err)
+			stats.Errors++
+			continue
+		}
+		logger.Info("Removed artist", "artist", artist.Name, "color", "orange")
+		stats.Artists++
+	}
+
+	for _, id := range s.queuedBy(importJobID) {
+		if err := s.queue.Remove(id); err == nil {
+			stats.Queued++
+		}
+	}
+
+	action := "deleted"
+	if trash {
+		action = "moved to " + t.trashDir(importJobID)
+	}
+	msg := fmt.Sprintf("Removed %d tracks, %d albums and %d artists, files %s", stats.Tracks, stats.Albums, stats.Artists, action)
+	if stats.Queued > 0 {
+		msg += fmt.Sprintf(", %d queue items dropped", stats.Queued)
+	}
+	if stats.Locked > 0 {
+		msg += fmt.Sprintf(", %d locked tracks kept", stats.Locked)
+	}
+	progressUpdater(100, msg)
+	slog.Info("Import rolled back", "importJob", importJobID, "tracks", stats.Tracks, "albums", stats.Albums, "artists", stats.Artists, "trash", trash)
+	result := map[string]any{"msg": msg, "rollback": stats}
+	if stats.Errors > 0 {
+		return result, fmt.Errorf("%w: %d errors", music.ErrJobPartialSuccess, stats.Errors)
+	}
+	return result, nil
+}
+
+// removeFile deletes a track's file, or moves it under <trash_path>/<import job>/ keeping its
+// path relative to the library, and returns where it was moved to. A file already gone is
+// not an error.
+func (t *RollbackImportTask) removeFile(ctx context.Context, path, importJobID string, trash bool) (string, error) {
+	fm := t.service.fileManager
+	if !trash {
+		return "", fm.DeleteTrack(ctx, path)
+	}
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+	cfg := t.service.config.Get()
+	rel, err := filepath.Rel(cfg.LibraryPath, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		rel = filepath.Base(path)
+	}
+	return fm.MoveTrackFile(ctx, path, filepath.Join(t.trashDir(importJobID), rel))
+}
+
+// restoreFile moves a trashed file back into the library after its track couldn't be removed.
+// Deleted files can't be restored, the track is left without its file.
+func (t *RollbackImportTask) restoreFile(ctx context.Context, trashed, path string, logger *slog.Logger) {
+	if trashed == "" {
+		logger.Warn("Track kept without its deleted file", "path", path)
+		return
+	}
+	if _, err := t.service.fileManager.MoveTrackFile(ctx, trashed, path); err != nil {
+		logger.Error("Failed to move the track file back from the trash", "trashed", trashed, "path", path, "error", err)
+	}
+}
+
+// trashDir returns the directory the files of a rolled back import are moved to.
+func (t *RollbackImportTask) trashDir(importJobID string) string {
+	return filepath.Join(cmp.Or(t.service.config.Get().Import.TrashPath, defaultTrashPath), importJobID)
+}
+
+// Cleanup does nothing, the rollback leaves no temporary files.
+func (t *RollbackImportTask) Cleanup(job *music.Job) error {
+	return nil
+}
@@ -17,12 +17,21 @@ func RegisterRoutes(app *fiber.App, service *Service) {
 	importGroup.Get("/queue/header", handler.GetQueueHeader)
 	importGroup.Get("/queue/:id/artwork", handler.ServeQueueItemArtwork)
 	importGroup.Post("/directory", handler.ImportDirectory)
+	importGroup.Post("/urls", handler.ImportURLs)
+	importGroup.Post("/jobs/:id/rollback", handler.RollbackImport)
 	importGroup.Post("/queue/:id/:action", handler.ProcessQueueItem)
 	importGroup.Post("/queue/group/:groupType/:groupKey/:action", handler.ProcessQueueGroup)
 	importGroup.Post("/queue/clear", handler.ClearQueue)
+	importGroup.Post("/queue/expire", handler.ExpireQueue)
 	importGroup.Post("/prune/download-path", handler.PruneDownloadPath)
+	importGroup.Get("/leftovers", handler.GetLeftovers)
+	importGroup.Post("/leftovers/scan", handler.ScanLeftovers)
+	importGroup.Post("/leftovers/clean", handler.CleanLeftovers)
+	importGroup.Post("/retention/run", handler.ApplyRetention)
 	importGroup.Get("/queue/count", handler.QueueCount)
+	importGroup.Get("/queue/card", handler.GetQueueCard)
 	importGroup.Post("/watcher/toggle", handler.ToggleWatcher)
 	importGroup.Get("/watcher/status", handler.GetWatcherStatus)
 	importGroup.Get("/watcher/toggle-state", handler.GetWatcherToggleState)
+	importGroup.Get("/watcher/diagnostics", handler.GetWatcherDiagnostics)
 }
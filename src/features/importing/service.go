@@ -2,11 +2,15 @@ package importing
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io/fs"
 	"log/slog"
+	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/contre95/soulsolid/src/features/config"
@@ -16,6 +20,9 @@ import (
 var supportedExtensions = map[string]bool{
 	".mp3":  true,
 	".flac": true,
+	".wav":  true,
+	".aif":  true,
+	".aiff": true,
 }
 
 // ImportStats contains statistics about the import process
@@ -26,6 +33,23 @@ type ImportStats struct {
 	ArtistsImported int `json:"artistsImported"`
 	Skipped         int `json:"skipped"`
 	Queued          int `json:"queued"`
+	Converted       int `json:"converted"`
+}
+
+// ImportRules rewrites a track's tags right before it's imported, e.g. with user automation rules.
+type ImportRules interface {
+	ApplyImportRules(track *music.Track, logger *slog.Logger) []string
+}
+
+// TagNormalizer cleans up a track's tag text, e.g. casing and whitespace, before it's imported.
+type TagNormalizer interface {
+	NormalizeTrack(track *music.Track) []music.TagChange
+}
+
+// AlbumObserver is told about every album that gets its first track imported, e.g. to notify
+// the followers of its artists.
+type AlbumObserver interface {
+	AlbumImported(ctx context.Context, album *music.Album)
 }
 
 // Service is the domain service for the organizing feature.
@@ -38,10 +62,18 @@ type Service struct {
 	jobService        music.JobService // TODO: Move this to domain job service
 	queue             music.Queue
 	watcher           Watcher
+	rules             ImportRules
+	normalizer        TagNormalizer
+	albums            AlbumObserver
+	transcoder        Transcoder
+	paused            atomic.Bool // maintenance mode: no watcher and no queue aging
+	resumeWatcher     bool        // the watcher was running when maintenance mode started
+	leftovers         leftovers
+	watchImports      watchImports
 }
 
 // NewService creates a new organizing service.
-func NewService(lib music.Library, tagReader TagReader, fingerprintReader FingerprintProvider, fileManager music.FileManager, cfg *config.Manager, jobService music.JobService, queue music.Queue, watcher Watcher) *Service {
+func NewService(lib music.Library, tagReader TagReader, fingerprintReader FingerprintProvider, fileManager music.FileManager, cfg *config.Manager, jobService music.JobService, queue music.Queue, watcher Watcher, rules ImportRules, normalizer TagNormalizer, albums AlbumObserver, transcoder Transcoder) *Service {
 	s := &Service{
 		config:            cfg,
 		library:           lib,
@@ -51,18 +83,27 @@ func NewService(lib music.Library, tagReader TagReader, fingerprintReader Finger
 		jobService:        jobService,
 		queue:             queue,
 		watcher:           watcher,
+		rules:             rules,
+		normalizer:        normalizer,
+		albums:            albums,
+		transcoder:        transcoder,
 	}
 	if s.config.Get().Import.AutoStartWatcher {
 		if err := s.StartWatcher(); err != nil {
 			slog.Error("Failed to auto-start watcher", "error", err)
 		}
 	}
+	go s.watchQueueAge()
+	go s.watchRetention()
 	return s
 }
 
 // ImportDirectory starts a job to import all files from a directory recursively.
 func (s *Service) ImportDirectory(ctx context.Context, pathToImport string) (string, error) {
 	slog.Debug("ImportDirectory service called", "path", pathToImport)
+	if err := s.preflight(pathToImport); err != nil {
+		return "", err
+	}
 	jobID, err := s.jobService.StartJob("directory_import", "Directory Import", map[string]any{
 		"path": pathToImport,
 	})
@@ -73,6 +114,53 @@ func (s *Service) ImportDirectory(ctx context.Context, pathToImport string) (str
 	return jobID, nil
 }
 
+// preflight checks that the files under pathToImport can be read, and removed when imports move
+// them, and that the library is writable, so permission problems fail before the job starts
+// instead of on every file.
+func (s *Service) preflight(pathToImport string) error {
+	if err := config.CheckReadable(pathToImport); err != nil {
+		return fmt.Errorf("import path: %w", err)
+	}
+	if s.config.Get().Import.Move {
+		if err := config.CheckWritable(pathToImport); err != nil {
+			return fmt.Errorf("import path (files are moved out of it): %w", err)
+		}
+	}
+	if err := config.CheckWritable(s.config.Get().LibraryPath); err != nil {
+		return fmt.Errorf("library path: %w", err)
+	}
+	return nil
+}
+
+// ImportURLs starts a job that downloads the given direct audio URLs, and the audio
+// enclosures of feedURL when set, to a staging area and imports them.
+func (s *Service) ImportURLs(ctx context.Context, urls []string, feedURL string) (string, error) {
+	slog.Debug("ImportURLs service called", "urls", len(urls), "feed", feedURL)
+	for _, u := range append(slices.Clone(urls), feedURL) {
+		if u != "" && !strings.HasPrefix(u, "http://") && !strings.HasPrefix(u, "https://") {
+			return "", fmt.Errorf("invalid URL %q: only http(s) URLs are supported", u)
+		}
+	}
+	if len(urls) == 0 && feedURL == "" {
+		return "", fmt.Errorf("no URLs to import")
+	}
+	if err := config.CheckWritable(s.config.Get().DownloadPath); err != nil {
+		return "", fmt.Errorf("download path: %w", err)
+	}
+	if err := config.CheckWritable(s.config.Get().LibraryPath); err != nil {
+		return "", fmt.Errorf("library path: %w", err)
+	}
+	jobID, err := s.jobService.StartJob("url_import", "URL Import", map[string]any{
+		"urls": urls,
+		"feed": feedURL,
+	})
+	if err != nil {
+		slog.Error("Service.ImportURLs: failed to start job", "error", err)
+		return "", fmt.Errorf("failed to start URL import job: %w", err)
+	}
+	return jobID, nil
+}
+
 // GetQueuedItems returns all items in the queue
 func (s *Service) GetQueuedItems() map[string]music.QueueItem {
 	return s.queue.GetAll()
@@ -99,6 +187,16 @@ func (s *Service) GetPendingTrackArtwork(itemID string) ([]byte, string, error)
 	return s.metadataReader.ReadArtwork(item.Track.Path)
 }
 
+// GetDuplicateTrack returns the library track at path, the one a duplicate queue item matched.
+// It returns nil when the track is no longer in the library.
+func (s *Service) GetDuplicateTrack(ctx context.Context, path string) *music.Track {
+	track, err := s.library.FindTrackByPath(ctx, path)
+	if err != nil {
+		return nil
+	}
+	return track
+}
+
 // ClearQueue removes all items from the queue
 func (s *Service) ClearQueue() error {
 	return s.queue.Clear()
@@ -131,12 +229,30 @@ func (s *Service) PruneDownloadPath(ctx context.Context) error {
 // handleFileEvent handles file system events from the watcher
 func (s *Service) handleFileEvent(event FileEvent) {
 	slog.Info("Received file event", "path", event.Path, "type", event.EventType)
+	batch := &WatchBatch{Time: event.Timestamp, Files: event.Files}
+	conv := s.config.Get().Import.Convert
+	staged := 0
+	for _, file := range event.Files {
+		if s.isConverted(file) {
+			staged++
+			batch.Skipped = append(batch.Skipped, file)
+		} else if !isSupportedFile(file) && !convertible(file, conv) {
+			batch.Skipped = append(batch.Skipped, file)
+		}
+	}
+	s.watchImports.add(batch)
+	// Files converted by an import job are imported by that job
+	if staged == len(event.Files) {
+		s.watchImports.finish(batch, "skipped: files converted by an import job", "")
+		return
+	}
 	const waitInterval = 5 * time.Second
 	const maxWait = 5 * time.Minute
 	start := time.Now()
 	for s.jobsAreRunning() {
 		if time.Since(start) > maxWait {
 			slog.Info("Timed out waiting for jobs to finish, skipping watch-triggered import")
+			s.watchImports.finish(batch, fmt.Sprintf("skipped: other jobs still running after %s", maxWait), "")
 			return
 		}
 		slog.Info("Still waiting for jobs to finish")
@@ -145,13 +261,18 @@ func (s *Service) handleFileEvent(event FileEvent) {
 	jobID, err := s.ImportDirectory(context.Background(), s.config.Get().DownloadPath)
 	if err != nil {
 		slog.Error("Failed to start watch-triggered import job", "error", err)
+		s.watchImports.finish(batch, "failed: "+err.Error(), "")
 		return
 	}
+	s.watchImports.finish(batch, "import started", jobID)
 	slog.Info("Watch-triggered import job started", "jobID", jobID, "path", event.Path)
 }
 
 // StartWatcher starts the file system watcher
 func (s *Service) StartWatcher() error {
+	if s.paused.Load() {
+		return music.ErrMaintenance
+	}
 	if s.watcher.IsRunning() {
 		return fmt.Errorf("watcher is already running")
 	}
@@ -194,6 +315,31 @@ func (s *Service) GetWatcherStatus() bool {
 	return s.watcher.IsRunning()
 }
 
+// GetWatcherDiagnostics returns the watcher internals and the imports it triggered.
+func (s *Service) GetWatcherDiagnostics() WatcherDiagnostics {
+	return WatcherDiagnostics{WatcherStats: s.watcher.Stats(), Imports: s.watchImports.list()}
+}
+
+// Pause stops the watcher and the queue aging for maintenance mode. The watcher is started
+// again on Resume if it was running.
+func (s *Service) Pause() {
+	s.paused.Store(true)
+	s.resumeWatcher = s.watcher.IsRunning()
+	if s.resumeWatcher {
+		s.StopWatcher()
+	}
+}
+
+// Resume restarts what Pause stopped.
+func (s *Service) Resume() {
+	s.paused.Store(false)
+	if s.resumeWatcher {
+		if err := s.StartWatcher(); err != nil {
+			slog.Error("Failed to restart watcher after maintenance", "error", err)
+		}
+	}
+}
+
 // jobsAreRunning checks if there are any running jobs
 func (s *Service) jobsAreRunning() bool {
 	jobs := s.jobService.GetJobs()
@@ -226,7 +372,17 @@ func (s *Service) ProcessQueueItem(ctx context.Context, itemID string, action st
 	track := item.Track
 	switch action {
 	case "cancel":
-		return s.queue.Remove(itemID)
+		if err := s.queue.Remove(itemID); err != nil {
+			return err
+		}
+		// The original stays where it was, only the converted copy staged for the queue goes
+		if s.isConverted(track.Path) {
+			if err := os.Remove(track.Path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				slog.Warn("Failed to remove the converted file", "path", track.Path, "error", err)
+			}
+		}
+		s.removeQueuedOriginal(item, false)
+		return nil
 	case "replace":
 		// For replace action, we need to find the existing track to replace
 		// Use fingerprint to find the existing track
@@ -239,23 +395,35 @@ func (s *Service) ProcessQueueItem(ctx context.Context, itemID string, action st
 		} else if existingTrack == nil {
 			return fmt.Errorf("no existing track found with matching ID for replacement")
 		}
-		move := s.config.Get().Import.Move
+		move := s.config.Get().Import.Move || s.isConverted(track.Path)
 		if err := s.replaceTrack(ctx, track, existingTrack, move, nil); err != nil {
 			return fmt.Errorf("failed to replace track: %w", err)
 		}
-		return s.queue.Remove(itemID)
+		if err := s.queue.Remove(itemID); err != nil {
+			return err
+		}
+		s.removeQueuedOriginal(item, s.config.Get().Import.Move)
+		return nil
 	case "import":
-		moveFiles := s.config.Get().Import.Move
+		moveFiles := s.config.Get().Import.Move || s.isConverted(track.Path)
 		if err := s.importTrack(ctx, track, moveFiles, nil); err != nil {
 			return fmt.Errorf("failed to import track: %w", err)
 		}
-		return s.queue.Remove(itemID)
+		if err := s.queue.Remove(itemID); err != nil {
+			return err
+		}
+		s.removeQueuedOriginal(item, s.config.Get().Import.Move)
+		return nil
 	case "delete":
-		// Delete the file from the import location
+		// Delete the file from the import location, and the original it was converted from
 		if err := s.fileManager.DeleteTrack(ctx, track.Path); err != nil {
 			return fmt.Errorf("failed to delete track file: %w", err)
 		}
-		return s.queue.Remove(itemID)
+		if err := s.queue.Remove(itemID); err != nil {
+			return err
+		}
+		s.removeQueuedOriginal(item, true)
+		return nil
 	default:
 		return fmt.Errorf("Invalid action %s. Should be one of %s", action, "import,replace,cancel,delete")
 	}
@@ -327,18 +495,13 @@ func (s *Service) replaceTrack(ctx context.Context, newTrack, existingTrack *mus
 		logger = slog.Default()
 	}
 	// First organize the new file to library location
-	var newPath string
-	var err error
-	if move {
-		newPath, err = s.fileManager.MoveTrackToLibrary(ctx, newTrack)
-	} else {
-		newPath, err = s.fileManager.CopyTrackToLibrary(ctx, newTrack)
-	}
+	newPath, err := s.organizeTrack(ctx, newTrack, move, logger)
 	if err != nil {
 		return fmt.Errorf("could not organize replacement track: %w", err)
 	}
 	oldPath := existingTrack.Path
 	existingTrack.Path = newPath
+	existingTrack.Format = newTrack.Format
 	existingTrack.Metadata = newTrack.Metadata
 	existingTrack.Title = newTrack.Title
 	existingTrack.TitleVersion = newTrack.TitleVersion
@@ -368,6 +531,38 @@ func (s *Service) replaceTrack(ctx context.Context, newTrack, existingTrack *mus
 	return nil
 }
 
+// ReplaceTrackFile replaces the file of an existing library track with the file at filePath
+// (e.g. a higher quality copy fetched again from the track's source), keeping the track's ID and source.
+func (s *Service) ReplaceTrackFile(ctx context.Context, trackID, filePath string, logger *slog.Logger) error {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	existingTrack, err := s.library.GetTrack(ctx, trackID)
+	if err != nil {
+		return fmt.Errorf("failed to get track %s: %w", trackID, err)
+	}
+	if existingTrack == nil {
+		return fmt.Errorf("track %s not found", trackID)
+	}
+	// The tags of the new file replace the track's, which a lock protects
+	if existingTrack.IsLocked() {
+		return fmt.Errorf("%w: unlock %q to replace its file", music.ErrTrackLocked, existingTrack.Title)
+	}
+	newTrack, err := s.metadataReader.ReadFileTags(ctx, filePath)
+	if err != nil {
+		return fmt.Errorf("failed to read tags from %s: %w", filePath, err)
+	}
+	newTrack.Path = filePath
+	newTrack.SplitTitleVersion()
+	existingTrack.Format = newTrack.Format
+	existingTrack.Bitrate = newTrack.Bitrate
+	existingTrack.SampleRate = newTrack.SampleRate
+	existingTrack.BitDepth = newTrack.BitDepth
+	existingTrack.Channels = newTrack.Channels
+	logger.Info("Service.ReplaceTrackFile: replacing track file", "trackID", trackID, "oldPath", existingTrack.Path, "newFile", filePath)
+	return s.replaceTrack(ctx, newTrack, existingTrack, true, logger)
+}
+
 // populateTrackArtistsAndAlbum populates the Artists and Album fields of a track with database references
 func (s *Service) populateTrackArtistsAndAlbum(ctx context.Context, track *music.Track, logger *slog.Logger) error {
 	// Create/find artist if it doesn't exist
@@ -414,25 +609,36 @@ func (s *Service) populateTrackArtistsAndAlbum(ctx context.Context, track *music
 	return nil
 }
 
+// markAlbumAddedBy links an album created for track to the job that added the track.
+func (s *Service) markAlbumAddedBy(ctx context.Context, track *music.Track, logger *slog.Logger) {
+	jobID, source := track.AddedBy()
+	if jobID == "" {
+		return
+	}
+	if track.Album.Attributes == nil {
+		track.Album.Attributes = make(map[string]string)
+	}
+	for key, value := range map[string]string{music.AddedByJobAttribute: jobID, music.AddedSourceAttribute: source} {
+		if err := s.library.SetAlbumAttribute(ctx, track.Album.ID, key, value); err != nil {
+			logger.Warn("Service.importTrack: failed to link album to its job", "error", err, "album", track.Album.Title)
+			return
+		}
+		track.Album.Attributes[key] = value
+	}
+}
+
 // importTrack handles the import process for a track (generic method used by both directory import and queue processing)
 func (s *Service) importTrack(ctx context.Context, track *music.Track, move bool, logger *slog.Logger) error {
 	if logger == nil {
 		logger = slog.Default()
 	}
-	var newPath string
-	var err error
-
 	// Fill any permitted missing metadata fields with fallback defaults before
 	// building the destination path, so fallback artist/album/title/year/genre
 	// values feed path resolution in MoveTrackToLibrary/CopyTrackToLibrary.
 	amm := s.config.Get().Import.AllowMissingMetadata
 	track.EnsureMetadataDefaults(amm.Artist, amm.Album, amm.Title, amm.Year, amm.Genre)
 
-	if move {
-		newPath, err = s.fileManager.MoveTrackToLibrary(ctx, track)
-	} else {
-		newPath, err = s.fileManager.CopyTrackToLibrary(ctx, track)
-	}
+	newPath, err := s.organizeTrack(ctx, track, move, logger)
 	if err != nil {
 		logger.Error("Service.importTrack: could not organize track", "error", err, "title", track.Title)
 		return fmt.Errorf("could not organize track: %w", err)
@@ -487,10 +693,17 @@ func (s *Service) importTrack(ctx context.Context, track *music.Track, move bool
 	}
 
 	// Add track to database
+	newAlbum := track.Album != nil && track.Album.TrackCount == 0
 	if err := s.library.AddTrack(ctx, track); err != nil {
 		logger.Error("Service.importTrack: failed to add track to database", "error", err, "title", track.Title)
 		return fmt.Errorf("failed to add track to database: %w", err)
 	}
+	if newAlbum {
+		s.markAlbumAddedBy(ctx, track, logger)
+		if s.albums != nil {
+			s.albums.AlbumImported(ctx, track.Album)
+		}
+	}
 
 	return nil
 }
@@ -0,0 +1,272 @@
+package importing
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/contre95/soulsolid/src/music"
+)
+
+// downloadClient fetches remote audio files and feeds. The timeout is generous because a
+// single lossless file can take minutes on a slow link, but still bounds a hung server.
+var downloadClient = &http.Client{Timeout: 30 * time.Minute}
+
+// audioMimeExtensions maps the content types of supported audio files to their extension,
+// for URLs whose path doesn't end in one (e.g. /episode?id=3).
+var audioMimeExtensions = map[string]string{
+	"audio/mpeg":   ".mp3",
+	"audio/mp3":    ".mp3",
+	"audio/flac":   ".flac",
+	"audio/x-flac": ".flac",
+	"audio/wav":    ".wav",
+	"audio/x-wav":  ".wav",
+	"audio/aiff":   ".aiff",
+	"audio/x-aiff": ".aiff",
+}
+
+// remoteItem is a single file to download, with the metadata known about it up front.
+// Feed entries carry a title, artist and album; bare URLs only carry the URL.
+type remoteItem struct {
+	URL    string
+	Title  string
+	Artist string
+	Album  string
+	Year   int
+}
+
+// fillMissingTags copies the item's metadata into the fields the file's own tags left empty.
+func (item remoteItem) fillMissingTags(track *music.Track) {
+	if strings.TrimSpace(track.Title) == "" && item.Title != "" {
+		track.Title = item.Title
+	}
+	if len(track.Artists) == 0 && item.Artist != "" {
+		track.Artists = []music.ArtistRole{{Artist: &music.Artist{Name: item.Artist}, Role: "main"}}
+	}
+	if (track.Album == nil || strings.TrimSpace(track.Album.Title) == "") && item.Album != "" {
+		track.Album = &music.Album{Title: item.Album, Artists: track.Artists}
+	}
+	if track.Metadata.Year == 0 && item.Year > 0 {
+		track.Metadata.Year = item.Year
+	}
+}
+
+// rssFeed is the subset of an RSS 2.0 (podcast-style) feed needed to import its enclosures.
+type rssFeed struct {
+	Channel struct {
+		Title  string `xml:"title"`
+		Author string `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd author"`
+		Items  []struct {
+			Title     string `xml:"title"`
+			Author    string `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd author"`
+			PubDate   string `xml:"pubDate"`
+			Enclosure struct {
+				URL  string `xml:"url,attr"`
+				Type string `xml:"type,attr"`
+			} `xml:"enclosure"`
+		} `xml:"item"`
+	} `xml:"channel"`
+}
+
+// parseFeed extracts the audio enclosures of an RSS feed. The channel title is used as the
+// album and the channel (or item) author as the artist.
+func parseFeed(r io.Reader) ([]remoteItem, error) {
+	var feed rssFeed
+	if err := xml.NewDecoder(r).Decode(&feed); err != nil {
+		return nil, fmt.Errorf("failed to parse feed: %w", err)
+	}
+	var items []remoteItem
+	for _, entry := range feed.Channel.Items {
+		if entry.Enclosure.URL == "" {
+			continue
+		}
+		item := remoteItem{
+			URL:    entry.Enclosure.URL,
+			Title:  strings.TrimSpace(entry.Title),
+			Artist: strings.TrimSpace(feed.Channel.Author),
+			Album:  strings.TrimSpace(feed.Channel.Title),
+		}
+		if author := strings.TrimSpace(entry.Author); author != "" {
+			item.Artist = author
+		}
+		if published, err := time.Parse(time.RFC1123Z, entry.PubDate); err == nil {
+			item.Year = published.Year()
+		} else if published, err := time.Parse(time.RFC1123, entry.PubDate); err == nil {
+			item.Year = published.Year()
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// URLImportTask implements jobs.Task for importing files downloaded from remote URLs.
+// Files are downloaded to a per-job staging directory inside the download path and then
+// go through the same pipeline as a directory import.
+type URLImportTask struct {
+	DirectoryImportTask
+}
+
+// NewURLImportTask creates a new URLImportTask.
+func NewURLImportTask(service *Service) *URLImportTask {
+	return &URLImportTask{DirectoryImportTask{service: service}}
+}
+
+// MetadataKeys returns the required metadata keys for a URL import job.
+func (t *URLImportTask) MetadataKeys() []string {
+	return []string{"urls", "feed"}
+}
+
+// Execute downloads every URL (and feed enclosure) and imports the downloaded files.
+func (t *URLImportTask) Execute(ctx context.Context, job *music.Job, progressUpdater func(int, string)) (map[string]any, error) {
+	urls, _ := job.Metadata["urls"].([]string)
+	feedURL, _ := job.Metadata["feed"].(string)
+
+	var items []remoteItem
+	for _, u := range urls {
+		items = append(items, remoteItem{URL: u})
+	}
+	if feedURL != "" {
+		progressUpdater(0, "Fetching feed...")
+		feedItems, err := t.fetchFeed(ctx, feedURL)
+		if err != nil {
+			return nil, err
+		}
+		job.Logger.Info("Service.runURLImport: feed parsed", "feed", feedURL, "items", len(feedItems))
+		items = append(items, feedItems...)
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("no URLs to import")
+	}
+
+	label := fmt.Sprintf("%d URL(s)", len(items))
+	if feedURL != "" {
+		label = feedURL
+	}
+	report := t.service.startReport(job.ID, label, job.Logger)
+	defer report.Close()
+	staging := filepath.Join(t.service.config.Get().DownloadPath, ".url-import", job.ID)
+	if err := os.MkdirAll(staging, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+
+	// First half of the progress bar is downloading, the second half importing.
+	remote := map[string]remoteItem{}
+	downloadErrors := 0
+	for i, item := range items {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		progressUpdater(i*50/len(items), fmt.Sprintf("Downloading %d/%d", i+1, len(items)))
+		filePath, err := downloadRemoteFile(ctx, item.URL, staging, i)
+		if err != nil {
+			job.Logger.Warn("Service.runURLImport: failed to download file", "url", item.URL, "error", err, "color", "red")
+			report.AddFailure(item.URL, "download failed: "+err.Error())
+			downloadErrors++
+			continue
+		}
+		job.Logger.Info("Service.runURLImport: downloaded file", "url", item.URL, "path", filePath)
+		remote[filePath] = item
+	}
+
+	stats, err := t.runDirectoryImport(ctx, staging, func(progress int, msg string) {
+		progressUpdater(50+progress/2, msg)
+	}, job.Logger, job, report, remote)
+	if err != nil {
+		return nil, fmt.Errorf("failed to import downloaded files: %w", err)
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	stats.Errors += downloadErrors
+
+	return t.finishImport(job, report, stats, "URL import")
+}
+
+// Cleanup removes the staging directory once nothing in it is left for the queue.
+// Files that ended up in the review queue keep it (and their directory) alive.
+func (t *URLImportTask) Cleanup(job *music.Job) error {
+	t.service.removeConvertedDir(job.ID)
+	staging := filepath.Join(t.service.config.Get().DownloadPath, ".url-import", job.ID)
+	entries, err := os.ReadDir(staging)
+	if err != nil || len(entries) > 0 {
+		return nil
+	}
+	return os.Remove(staging)
+}
+
+// fetchFeed downloads and parses a podcast-style RSS feed.
+func (t *URLImportTask) fetchFeed(ctx context.Context, feedURL string) ([]remoteItem, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create feed request: %w", err)
+	}
+	resp, err := downloadClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch feed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("feed request failed with status %d", resp.StatusCode)
+	}
+	return parseFeed(resp.Body)
+}
+
+// downloadRemoteFile downloads rawURL into dir and returns the file path. Files that are not
+// a supported audio format, by URL extension or content type, are rejected.
+// index keeps file names unique when several URLs end in the same name.
+func downloadRemoteFile(ctx context.Context, rawURL, dir string, index int) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := downloadClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("request failed with status %d", resp.StatusCode)
+	}
+
+	name := path.Base(u.Path)
+	ext := strings.ToLower(path.Ext(name))
+	if !supportedExtensions[ext] {
+		mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+		mimeExt, ok := audioMimeExtensions[mediaType]
+		if !ok {
+			return "", fmt.Errorf("unsupported file type %q", resp.Header.Get("Content-Type"))
+		}
+		name = strings.TrimSuffix(name, path.Ext(name)) + mimeExt
+	}
+	if name == "" || name == "/" || name == "." || strings.HasPrefix(name, ".") {
+		name = "download" + name
+	}
+	filePath := filepath.Join(dir, fmt.Sprintf("%03d-%s", index+1, name))
+
+	file, err := os.Create(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to create file: %w", err)
+	}
+	if _, err := io.Copy(file, resp.Body); err != nil {
+		file.Close()
+		os.Remove(filePath)
+		return "", fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return "", fmt.Errorf("failed to write file: %w", err)
+	}
+	return filePath, nil
+}
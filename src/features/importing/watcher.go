@@ -2,15 +2,20 @@ package importing
 
 import (
 	"context"
+	"sync"
 	"time"
 )
 
+// recentImports is how many watch-triggered batches the diagnostics remember.
+const recentImports = 50
+
 // Watcher defines the interface for file system watchers
 type Watcher interface {
 	Start(ctx context.Context, watchPath string) error
 	Stop()
 	GetEventChan() <-chan FileEvent
 	IsRunning() bool
+	Stats() WatcherStats
 }
 
 // FileEventType represents the type of file system event
@@ -25,6 +30,76 @@ const (
 // FileEvent represents a file system event
 type FileEvent struct {
 	Path      string
+	Files     []string // every file created during the debounce window, Path is the last one
 	EventType FileEventType
 	Timestamp time.Time
 }
+
+// WatcherStats is a snapshot of the watcher internals, kept since the process started to
+// debug files that never got imported.
+type WatcherStats struct {
+	Running      bool           `json:"running"`
+	Root         string         `json:"root"`
+	StartedAt    time.Time      `json:"started_at"`
+	WatchedPaths []string       `json:"watched_paths"`
+	Events       map[string]int `json:"events"`  // fsnotify operation -> events received
+	Batches      int            `json:"batches"` // debounced batches emitted
+	Dropped      int            `json:"dropped"` // batches dropped while the previous one was handled
+	Errors       int            `json:"errors"`
+	LastError    string         `json:"last_error,omitempty"`
+	Recent       []WatchedFile  `json:"recent"` // newest first
+}
+
+// WatchedFile is a file or directory the watcher saw created, and what became of it.
+type WatchedFile struct {
+	Time    time.Time `json:"time"`
+	Path    string    `json:"path"`
+	Outcome string    `json:"outcome"` // batched, watched or skipped
+	Reason  string    `json:"reason,omitempty"`
+}
+
+// WatchBatch is a debounced batch of created files and what the importing service did with it.
+type WatchBatch struct {
+	Time    time.Time `json:"time"`
+	Files   []string  `json:"files"`
+	Skipped []string  `json:"skipped,omitempty"` // unsupported files the import will leave alone
+	Outcome string    `json:"outcome"`
+	JobID   string    `json:"job_id,omitempty"`
+}
+
+// WatcherDiagnostics is what the watcher diagnostics page shows.
+type WatcherDiagnostics struct {
+	WatcherStats
+	Imports []WatchBatch `json:"imports"` // newest first
+}
+
+// watchImports keeps the latest batches handled from the watcher.
+type watchImports struct {
+	mu      sync.Mutex
+	batches []*WatchBatch
+}
+
+// add records a batch as waiting for the running jobs.
+func (w *watchImports) add(batch *WatchBatch) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	batch.Outcome = "waiting for running jobs"
+	w.batches = append([]*WatchBatch{batch}, w.batches[:min(len(w.batches), recentImports-1)]...)
+}
+
+// finish records what became of a batch.
+func (w *watchImports) finish(batch *WatchBatch, outcome, jobID string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	batch.Outcome, batch.JobID = outcome, jobID
+}
+
+func (w *watchImports) list() []WatchBatch {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	batches := make([]WatchBatch, len(w.batches))
+	for i, batch := range w.batches {
+		batches[i] = *batch
+	}
+	return batches
+}
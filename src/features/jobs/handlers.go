@@ -2,7 +2,9 @@ package jobs
 
 import (
 	"fmt"
+	"math"
 	"os"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -60,6 +62,9 @@ func (h *Handler) HandleJobStatus(c *fiber.Ctx) error {
 			"logs":     fmt.Sprintf("%s/jobs/%s/logs", baseURL, job.ID),
 		},
 	}
+	if _, ok := job.Metadata["report_json"]; ok {
+		response.Links["report"] = fmt.Sprintf("%s/jobs/%s/report?fmt=json", baseURL, job.ID)
+	}
 
 	return c.JSON(response)
 }
@@ -106,6 +111,33 @@ func (h *Handler) HandleJobLogs(c *fiber.Ctx) error {
 	}
 }
 
+// HandleJobReport serves the report artifact a job persisted, as HTML by default, JSON with
+// ?fmt=json or the JSON Lines list of files with ?fmt=files. Reports stay on disk after their
+// job is cleared, they're then found by job ID in the reports directory.
+func (h *Handler) HandleJobReport(c *fiber.Ctx) error {
+	jobID := c.Params("id")
+	key, suffix := "report_html", ".html"
+	switch c.Query("fmt") {
+	case "json":
+		key, suffix = "report_json", ".json"
+	case "files":
+		key, suffix = "report_files", "-files.jsonl"
+	}
+	var reportPath string
+	if job, exists := h.service.GetJob(jobID); exists {
+		reportPath, _ = job.Metadata[key].(string)
+	} else {
+		reportPath = FindReport(h.service.ReportDir(), jobID, suffix)
+	}
+	if reportPath == "" {
+		return c.Status(404).SendString("No report for this job.")
+	}
+	if c.Query("download") == "true" {
+		return c.Download(reportPath)
+	}
+	return c.SendFile(reportPath)
+}
+
 func (h *Handler) HandleJobProgress(c *fiber.Ctx) error {
 	jobID := c.Params("id")
 	job, exists := h.service.GetJob(jobID)
@@ -193,7 +225,7 @@ func (h *Handler) HandleActiveJob(c *fiber.Ctx) error {
 	// Filter out completed/failed/cancelled jobs to show only active ones
 	activeJobs := make([]*music.Job, 0)
 	for _, job := range jobs {
-		if job.Status == music.JobStatusRunning || job.Status == music.JobStatusPending {
+		if job.Status == music.JobStatusRunning || job.Status == music.JobStatusPending || job.Status == music.JobStatusWaitingNetwork {
 			activeJobs = append(activeJobs, job)
 		}
 	}
@@ -252,7 +284,7 @@ func (h *Handler) HandleJobsCount(c *fiber.Ctx) error {
 	count := 0
 
 	for _, job := range jobs {
-		if filter == "active" && (job.Status == music.JobStatusRunning || job.Status == music.JobStatusPending) {
+		if filter == "active" && (job.Status == music.JobStatusRunning || job.Status == music.JobStatusPending || job.Status == music.JobStatusWaitingNetwork) {
 			count++
 		} else if filter == "all" {
 			count++
@@ -265,3 +297,72 @@ func (h *Handler) HandleJobsCount(c *fiber.Ctx) error {
 	}
 	return respond.Text(c, "jobs_count", count, formatted)
 }
+
+// timelineWindows are the ranges the timeline can show.
+var timelineWindows = map[string]time.Duration{
+	"1h":  time.Hour,
+	"6h":  6 * time.Hour,
+	"24h": 24 * time.Hour,
+	"7d":  7 * 24 * time.Hour,
+}
+
+// TimelineBar is a job drawn on the timeline. Positions and widths are percentages of the window.
+type TimelineBar struct {
+	Timing
+	WaitLeft  float64
+	WaitWidth float64
+	RunLeft   float64
+	RunWidth  float64
+	Wait      time.Duration
+	Run       time.Duration
+	WaitedOn  []string // types of the jobs that ran while this one was waiting
+}
+
+// HandleJobTimeline renders a Gantt chart of the jobs queued in the selected window, showing
+// how long each one waited and ran, and which jobs it waited on.
+func (h *Handler) HandleJobTimeline(c *fiber.Ctx) error {
+	windowName := c.Query("window", "24h")
+	window, ok := timelineWindows[windowName]
+	if !ok {
+		return respond.ToastErr(c, fiber.StatusBadRequest, "window must be one of 1h, 6h, 24h or 7d")
+	}
+	now := time.Now()
+	start := now.Add(-window)
+	position := func(t time.Time) float64 {
+		return math.Max(0, float64(t.Sub(start))/float64(window)*100)
+	}
+
+	timings := h.service.Timeline(window)
+	bars := make([]TimelineBar, 0, len(timings))
+	for _, t := range timings {
+		bar := TimelineBar{Timing: t, Wait: t.Wait(now).Round(time.Second), Run: t.Run(now).Round(time.Second)}
+		waitEnd := t.CreatedAt.Add(t.Wait(now))
+		bar.WaitLeft = position(t.CreatedAt)
+		bar.WaitWidth = position(waitEnd) - bar.WaitLeft
+		if !t.StartedAt.IsZero() {
+			bar.RunLeft = position(t.StartedAt)
+			bar.RunWidth = position(t.StartedAt.Add(t.Run(now))) - bar.RunLeft
+		}
+		for _, other := range timings {
+			if bar.Wait == 0 {
+				break
+			}
+			if other.ID == t.ID || other.StartedAt.IsZero() || slices.Contains(bar.WaitedOn, other.Type) {
+				continue
+			}
+			otherEnd := other.StartedAt.Add(other.Run(now))
+			if other.StartedAt.Before(waitEnd) && otherEnd.After(t.CreatedAt) {
+				bar.WaitedOn = append(bar.WaitedOn, other.Type)
+			}
+		}
+		bars = append(bars, bar)
+	}
+
+	return respond.Partial(c, "jobs/timeline", fiber.Map{
+		"Bars":    bars,
+		"Window":  windowName,
+		"Windows": []string{"1h", "6h", "24h", "7d"},
+		"Start":   start,
+		"End":     now,
+	})
+}
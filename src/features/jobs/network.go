@@ -0,0 +1,161 @@
+package jobs
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/contre95/soulsolid/src/music"
+)
+
+const (
+	// minCheckInterval keeps a misconfigured check interval from hammering the check URL.
+	minCheckInterval = 10 * time.Second
+	// defaultCheckURL is checked when no check URL is configured.
+	defaultCheckURL = "http://connectivitycheck.gstatic.com/generate_204"
+)
+
+// networkErrorMessages are the connectivity failures recognized in error messages, for plugins
+// that flatten the underlying error into a string.
+var networkErrorMessages = []string{
+	"no such host",
+	"connection refused",
+	"connection reset",
+	"network is unreachable",
+	"no route to host",
+	"i/o timeout",
+	"tls handshake timeout",
+	"temporary failure in name resolution",
+	"server misbehaving",
+}
+
+// isNetworkError reports whether err comes from a lost connection rather than the job itself.
+func isNetworkError(err error) bool {
+	if errors.Is(err, music.ErrNetworkUnavailable) {
+		return true
+	}
+	var dnsErr *net.DNSError
+	var opErr *net.OpError
+	if errors.As(err, &dnsErr) || errors.As(err, &opErr) {
+		return true
+	}
+	for _, errno := range []syscall.Errno{syscall.ECONNREFUSED, syscall.ECONNRESET, syscall.ENETUNREACH, syscall.EHOSTUNREACH, syscall.ETIMEDOUT} {
+		if errors.Is(err, errno) {
+			return true
+		}
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	for _, m := range networkErrorMessages {
+		if strings.Contains(msg, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// parkForNetwork puts a job that failed for lack of connectivity in the waiting for network state,
+// unless it was resumed too many times already. It reports whether the job was parked.
+func (s *Service) parkForNetwork(job *music.Job, err error) bool {
+	offline := s.config.Get().Jobs.Offline
+	if !offline.Enabled || !isNetworkError(err) {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	retries, _ := job.Metadata["network_retries"].(int)
+	if job.Cancelled || (offline.MaxRetries > 0 && retries >= offline.MaxRetries) {
+		return false
+	}
+	if job.Metadata == nil {
+		job.Metadata = make(map[string]any)
+	}
+	job.Metadata["network_retries"] = retries + 1
+	job.Status = music.JobStatusWaitingNetwork
+	job.Message = "Waiting for network: " + err.Error()
+	job.UpdatedAt = time.Now()
+	if job.Logger != nil {
+		job.Logger.Warn("Job lost connectivity, waiting for the network", "error", err, "retry", retries+1)
+	}
+	slog.Warn("Job waiting for network", "jobID", job.ID, "type", job.Type, "error", err)
+	return true
+}
+
+// watchNetwork checks the connectivity while jobs wait for the network and queues them again once
+// the check succeeds.
+func (s *Service) watchNetwork() {
+	for {
+		offline := s.config.Get().Jobs.Offline
+		time.Sleep(max(time.Duration(offline.CheckInterval)*time.Second, minCheckInterval))
+		if !s.hasJobsWaitingNetwork() {
+			continue
+		}
+		url := cmp.Or(offline.CheckURL, defaultCheckURL)
+		if err := checkConnectivity(url); err != nil {
+			slog.Debug("Still offline", "url", url, "error", err)
+			continue
+		}
+		s.resumeJobsWaitingNetwork()
+	}
+}
+
+func (s *Service) hasJobsWaitingNetwork() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, job := range s.jobs {
+		if job.Status == music.JobStatusWaitingNetwork {
+			return true
+		}
+	}
+	return false
+}
+
+// resumeJobsWaitingNetwork moves the waiting jobs back to the pending queue. They keep their
+// creation time, so they run before the jobs queued while they waited.
+func (s *Service) resumeJobsWaitingNetwork() {
+	s.mu.Lock()
+	resumed := 0
+	for _, job := range s.jobs {
+		if job.Status != music.JobStatusWaitingNetwork {
+			continue
+		}
+		job.Status = music.JobStatusPending
+		job.Message = "Network is back, waiting to resume"
+		job.UpdatedAt = time.Now()
+		if job.Logger != nil {
+			job.Logger.Info("Network is back, resuming job")
+		}
+		resumed++
+	}
+	running := s.isAnyJobRunning()
+	s.mu.Unlock()
+	slog.Info("Network is back, resuming jobs", "count", resumed)
+	if !running {
+		s.startNextPendingJob()
+	}
+}
+
+// checkConnectivity reports an error unless url answers, whatever its status code.
+func checkConnectivity(url string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
@@ -0,0 +1,84 @@
+package jobs
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// reportLayout is the page every job report is rendered in. A report template defines the
+// "title" and "body" templates it fills.
+const reportLayout = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{ template "title" . }}</title>
+<style>
+body { font-family: sans-serif; margin: 2rem; color: #1f2937; }
+table { border-collapse: collapse; width: 100%; font-size: 0.85rem; }
+th, td { border: 1px solid #e5e7eb; padding: 0.35rem 0.5rem; text-align: left; vertical-align: top; }
+th { background: #f3f4f6; }
+</style>
+</head>
+<body>
+{{ template "body" . }}
+</body>
+</html>
+`
+
+// NewReportTemplate parses the HTML of a job report, which defines a "title" and a "body"
+// template, into the layout shared by all reports. It panics on a template error.
+func NewReportTemplate(body string) *template.Template {
+	return template.Must(template.Must(template.New("report").Parse(reportLayout)).Parse(body))
+}
+
+// ReportDir is the directory the job reports are written to.
+func (s *Service) ReportDir() string {
+	return filepath.Join(s.config.Get().Jobs.LogPath, "reports")
+}
+
+// FindReport returns the report of a job in dir whose name ends with suffix, e.g. ".html", empty
+// when there is none. Report names hold the job ID between their date and their kind.
+func FindReport(dir, jobID, suffix string) string {
+	if jobID == "" || strings.ContainsAny(jobID, `*?[]\/`) {
+		return ""
+	}
+	matches, _ := filepath.Glob(filepath.Join(dir, "*-"+jobID+"-*"+suffix))
+	if len(matches) == 0 {
+		return ""
+	}
+	return matches[0]
+}
+
+// SaveReport writes a job report into dir as name.json and as name.html rendered with tmpl,
+// and returns both paths. The paths are stored in the job metadata as report_json and
+// report_html for HandleJobReport to serve them.
+func SaveReport(dir, name string, report any, tmpl *template.Template) (string, string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", "", fmt.Errorf("failed to create report directory: %w", err)
+	}
+	base := filepath.Join(dir, name)
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encode report: %w", err)
+	}
+	jsonPath := base + ".json"
+	if err := os.WriteFile(jsonPath, data, 0644); err != nil {
+		return "", "", fmt.Errorf("failed to write report: %w", err)
+	}
+
+	htmlPath := base + ".html"
+	f, err := os.Create(htmlPath)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create html report: %w", err)
+	}
+	defer f.Close()
+	if err := tmpl.Execute(f, report); err != nil {
+		return "", "", fmt.Errorf("failed to render html report: %w", err)
+	}
+	return jsonPath, htmlPath, nil
+}
@@ -14,9 +14,11 @@ func RegisterRoutes(app *fiber.App, service *Service) {
 	jobs.Post("/clear-finished", handler.HandleClearFinishedJobs)
 	jobs.Get("/count", handler.HandleJobsCount)
 	jobs.Get("/all", handler.HandleJobList)
+	jobs.Get("/timeline", handler.HandleJobTimeline)
 	jobs.Post("/start/:type", handler.HandleStartJob)
 	jobs.Get("/:id", handler.HandleJobStatus)
 	jobs.Get("/:id/progress", handler.HandleJobProgress)
 	jobs.Get("/:id/logs", handler.HandleJobLogs)
+	jobs.Get("/:id/report", handler.HandleJobReport)
 	jobs.Post("/:id/cancel", handler.HandleCancelJob)
 }
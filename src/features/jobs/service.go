@@ -10,6 +10,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"syscall"
@@ -106,19 +107,36 @@ func (h *BaseTaskHandler) Cancel(jobID string) error {
 	return nil
 }
 
+// JobObserver is notified once a job reaches a terminal state. duration covers only
+// the time the job spent running, not the time it waited in the pending queue.
+type JobObserver interface {
+	JobFinished(job *music.Job, duration time.Duration)
+}
+
 type Service struct {
-	jobs     map[string]*music.Job
-	handlers map[string]TaskHandler
-	mu       sync.RWMutex
-	config   *config.Manager
+	jobs      map[string]*music.Job
+	handlers  map[string]TaskHandler
+	observers []JobObserver
+	mu        sync.RWMutex
+	config    *config.Manager
+	timingsMu sync.Mutex
+	timings   []Timing        // finished jobs, oldest first, persisted to timeline.jsonl
+	paused    bool            // maintenance mode: no new jobs are accepted or started
+	held      map[string]bool // job types kept pending, e.g. the downloads while paused
 }
 
 func NewService(cfg *config.Manager) *Service {
-	return &Service{
+	s := &Service{
 		jobs:     make(map[string]*music.Job),
 		handlers: make(map[string]TaskHandler),
 		config:   cfg,
+		held:     make(map[string]bool),
 	}
+	if err := s.loadTimings(); err != nil {
+		slog.Warn("Failed to load job timeline, starting fresh", "path", s.timelinePath(), "error", err)
+	}
+	go s.watchNetwork()
+	return s
 }
 
 func (s *Service) RegisterHandler(jobType string, handler TaskHandler) {
@@ -127,7 +145,89 @@ func (s *Service) RegisterHandler(jobType string, handler TaskHandler) {
 	s.handlers[jobType] = handler
 }
 
+// AddObserver registers an observer notified whenever a job finishes.
+func (s *Service) AddObserver(observer JobObserver) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.observers = append(s.observers, observer)
+}
+
+// Pause stops accepting new jobs and starting the pending ones, for maintenance mode.
+// The running job is left to finish.
+func (s *Service) Pause() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.paused = true
+}
+
+// Resume accepts new jobs again and starts the oldest pending one.
+func (s *Service) Resume() {
+	s.mu.Lock()
+	s.paused = false
+	running := s.isAnyJobRunning()
+	s.mu.Unlock()
+	if !running {
+		s.startNextPendingJob()
+	}
+}
+
+// heldMessage is shown on the pending jobs of a held type.
+const heldMessage = "On hold until resumed"
+
+// Hold keeps the jobs of the given types pending, new ones are still accepted. A running job
+// of those types is left to finish.
+func (s *Service) Hold(jobTypes ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, jobType := range jobTypes {
+		s.held[jobType] = true
+	}
+	for _, job := range s.jobs {
+		if job.Status == music.JobStatusPending && s.held[job.Type] {
+			job.Message = heldMessage
+		}
+	}
+}
+
+// Release starts the jobs of the given types again, in the order they were queued.
+func (s *Service) Release(jobTypes ...string) {
+	s.mu.Lock()
+	for _, jobType := range jobTypes {
+		delete(s.held, jobType)
+	}
+	for _, job := range s.jobs {
+		if job.Status == music.JobStatusPending && !s.held[job.Type] && job.Message == heldMessage {
+			job.Message = ""
+		}
+	}
+	running := s.isAnyJobRunning()
+	s.mu.Unlock()
+	if !running {
+		s.startNextPendingJob()
+	}
+}
+
+// Held reports whether the jobs of a type are kept pending.
+func (s *Service) Held(jobType string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.held[jobType]
+}
+
+// Paused reports whether the queue is paused for maintenance mode.
+func (s *Service) Paused() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.paused
+}
+
 func (s *Service) StartJob(jobType string, name string, metadata map[string]any) (string, error) {
+	s.mu.RLock()
+	paused := s.paused
+	s.mu.RUnlock()
+	if paused {
+		return "", music.ErrMaintenance
+	}
 	// Create a copy of jobType to prevent potential memory sharing issues
 	jobTypeCopy := strings.Clone(jobType)
 	job := &music.Job{
@@ -163,7 +263,10 @@ func (s *Service) StartJob(jobType string, name string, metadata map[string]any)
 	s.jobs[job.ID] = job
 
 	// Check if we can start this job immediately
-	if !s.isAnyJobRunning() {
+	if s.held[job.Type] {
+		job.Message = heldMessage
+		s.mu.Unlock()
+	} else if !s.isAnyJobRunning() {
 		job.Status = music.JobStatusRunning
 		s.mu.Unlock()
 		go s.executeJob(job)
@@ -182,8 +285,10 @@ func (s *Service) executeJob(job *music.Job) {
 	}
 	progressChan := make(chan music.JobProgress, 10)
 	ctx, cancel := context.WithCancel(context.Background())
+	startedAt := time.Now()
 	s.mu.Lock()
 	job.CancelFunc = cancel
+	job.StartedAt = startedAt
 	s.mu.Unlock()
 	s.updateJobStatus(job.ID, music.JobStatusRunning, "Starting...")
 	// Goroutine to listen for progress updates
@@ -205,6 +310,12 @@ func (s *Service) executeJob(job *music.Job) {
 	}
 	s.mu.Unlock()
 
+	if err != nil && !cancelled && !errors.Is(err, context.Canceled) && !errors.Is(err, music.ErrJobPartialSuccess) && s.parkForNetwork(job, err) {
+		// Not finished: watchNetwork queues it again once the connection is back
+		s.startNextPendingJob()
+		return
+	}
+
 	switch {
 	case errors.Is(err, context.Canceled) || cancelled:
 		s.updateJobStatus(job.ID, music.JobStatusCancelled, "Job cancelled")
@@ -218,6 +329,8 @@ func (s *Service) executeJob(job *music.Job) {
 	// Read the job back as a snapshot so the webhook doesn't touch shared state.
 	if snap, ok := s.GetJob(job.ID); ok {
 		s.executeWebhook(snap)
+		s.notifyObservers(snap, time.Since(startedAt))
+		s.recordTiming(snap)
 	}
 	// After job completes, check for pending jobs
 	s.startNextPendingJob()
@@ -233,6 +346,9 @@ func (s *Service) updateJobStatus(jobID string, status music.JobStatus, message
 		if status == music.JobStatusCompleted {
 			job.Progress = 100
 		}
+		if status != music.JobStatusPending && status != music.JobStatusRunning && status != music.JobStatusWaitingNetwork && job.FinishedAt.IsZero() {
+			job.FinishedAt = job.UpdatedAt
+		}
 	}
 }
 
@@ -270,11 +386,20 @@ func (s *Service) CancelJob(jobID string) error {
 		return errors.New("job not found")
 	}
 
+	waiting := job.Status == music.JobStatusWaitingNetwork
+
 	// Mark job as cancelled and update status
 	job.Cancelled = true
 	job.Status = music.JobStatusCancelled
 	job.Message = "Job cancelled"
 	job.UpdatedAt = time.Now()
+	if job.FinishedAt.IsZero() {
+		job.FinishedAt = job.UpdatedAt
+		if job.StartedAt.IsZero() || waiting {
+			// Never started or parked, so executeJob won't record it
+			go s.recordTiming(snapshotJob(job))
+		}
+	}
 
 	if job.CancelFunc != nil {
 		job.CancelFunc()
@@ -340,10 +465,13 @@ func (s *Service) isAnyJobRunning() bool {
 func (s *Service) startNextPendingJob() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	if s.paused {
+		return
+	}
 	// Find the oldest pending job
 	var nextJob *music.Job
 	for _, job := range s.jobs {
-		if job.Status == music.JobStatusPending {
+		if job.Status == music.JobStatusPending && !s.held[job.Type] {
 			if nextJob == nil || job.CreatedAt.Before(nextJob.CreatedAt) {
 				nextJob = job
 			}
@@ -371,6 +499,16 @@ func (s *Service) CleanupOldJobs(maxAge time.Duration) {
 	}
 }
 
+// notifyObservers hands a finished job snapshot to every registered observer
+func (s *Service) notifyObservers(job *music.Job, duration time.Duration) {
+	s.mu.RLock()
+	observers := slices.Clone(s.observers)
+	s.mu.RUnlock()
+	for _, observer := range observers {
+		observer.JobFinished(job, duration)
+	}
+}
+
 // executeWebhook executes the configured webhook command for job completion
 func (s *Service) executeWebhook(job *music.Job) {
 	if !s.config.Get().Jobs.Webhooks.Enabled {
@@ -78,6 +78,8 @@ func (h *TelegramHandler) getJobStatusEmoji(status music.JobStatus) string {
 		return "❌"
 	case music.JobStatusCancelled:
 		return "🚫"
+	case music.JobStatusWaitingNetwork:
+		return "📡"
 	default:
 		return "❓"
 	}
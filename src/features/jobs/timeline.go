@@ -0,0 +1,167 @@
+package jobs
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/contre95/soulsolid/src/music"
+)
+
+// timelineKeep is the number of finished jobs kept in the timeline.
+const timelineKeep = 500
+
+// Timing is when a job was queued, started and finished. Jobs run one at a time, so the
+// time between CreatedAt and StartedAt is spent waiting for the jobs before it.
+type Timing struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	Name       string          `json:"name"`
+	Status     music.JobStatus `json:"status"`
+	CreatedAt  time.Time       `json:"created_at"`
+	StartedAt  time.Time       `json:"started_at,omitzero"`  // zero for jobs cancelled while pending
+	FinishedAt time.Time       `json:"finished_at,omitzero"` // zero for jobs still pending or running
+}
+
+// Wait returns how long the job waited in the queue, up to now for pending jobs.
+func (t Timing) Wait(now time.Time) time.Duration {
+	switch {
+	case !t.StartedAt.IsZero():
+		return t.StartedAt.Sub(t.CreatedAt)
+	case !t.FinishedAt.IsZero():
+		return t.FinishedAt.Sub(t.CreatedAt)
+	default:
+		return now.Sub(t.CreatedAt)
+	}
+}
+
+// Run returns how long the job ran, up to now for running jobs.
+func (t Timing) Run(now time.Time) time.Duration {
+	switch {
+	case t.StartedAt.IsZero():
+		return 0
+	case t.FinishedAt.IsZero():
+		return now.Sub(t.StartedAt)
+	default:
+		return t.FinishedAt.Sub(t.StartedAt)
+	}
+}
+
+func timingOf(job *music.Job) Timing {
+	return Timing{
+		ID:         job.ID,
+		Type:       job.Type,
+		Name:       job.Name,
+		Status:     job.Status,
+		CreatedAt:  job.CreatedAt,
+		StartedAt:  job.StartedAt,
+		FinishedAt: job.FinishedAt,
+	}
+}
+
+func (s *Service) timelinePath() string {
+	return filepath.Join(s.config.Get().Jobs.LogPath, "timeline.jsonl")
+}
+
+// Timeline returns the jobs queued in the last window, finished ones included, oldest first.
+// Finished jobs come from the persisted timeline, so they survive restarts and cleared job lists.
+func (s *Service) Timeline(window time.Duration) []Timing {
+	since := time.Now().Add(-window)
+	seen := map[string]bool{}
+	var timings []Timing
+	s.timingsMu.Lock()
+	for _, t := range s.timings {
+		if t.FinishedAt.After(since) {
+			timings = append(timings, t)
+			seen[t.ID] = true
+		}
+	}
+	s.timingsMu.Unlock()
+	for _, job := range s.GetJobs() {
+		// Jobs that just finished may not be recorded yet
+		if !seen[job.ID] && (job.FinishedAt.IsZero() || job.FinishedAt.After(since)) {
+			timings = append(timings, timingOf(job))
+		}
+	}
+	slices.SortFunc(timings, func(a, b Timing) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	return timings
+}
+
+// recordTiming appends a finished job to the persisted timeline.
+func (s *Service) recordTiming(job *music.Job) {
+	timing := timingOf(job)
+	s.timingsMu.Lock()
+	defer s.timingsMu.Unlock()
+	s.timings = append(s.timings, timing)
+	if len(s.timings) > 2*timelineKeep {
+		// Compact the file instead of letting it grow forever
+		s.timings = slices.Clone(s.timings[len(s.timings)-timelineKeep:])
+		if err := s.writeTimings(); err != nil {
+			slog.Warn("Failed to compact job timeline", "path", s.timelinePath(), "error", err)
+		}
+		return
+	}
+	if err := s.appendTiming(timing); err != nil {
+		slog.Warn("Failed to persist job timing", "path", s.timelinePath(), "jobID", job.ID, "error", err)
+	}
+}
+
+func (s *Service) appendTiming(timing Timing) error {
+	if err := os.MkdirAll(filepath.Dir(s.timelinePath()), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.timelinePath(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return json.NewEncoder(f).Encode(timing)
+}
+
+func (s *Service) writeTimings() error {
+	tmp := s.timelinePath() + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	for _, t := range s.timings {
+		if err := enc.Encode(t); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.timelinePath())
+}
+
+// loadTimings reads the last timelineKeep jobs of the persisted timeline.
+func (s *Service) loadTimings() error {
+	f, err := os.Open(s.timelinePath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var t Timing
+		if err := json.Unmarshal(scanner.Bytes(), &t); err != nil {
+			continue // skip a line cut by a crash
+		}
+		s.timings = append(s.timings, t)
+	}
+	if len(s.timings) > timelineKeep {
+		s.timings = s.timings[len(s.timings)-timelineKeep:]
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,81 @@
+package library
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+
+	library "github.com/contre95/soulsolid/src/music"
+)
+
+// Notifier delivers follow notifications, e.g. to Telegram chats.
+type Notifier interface {
+	Notify(message string)
+}
+
+// AddNotifier registers a notifier told about new albums of followed artists.
+func (s *Service) AddNotifier(n Notifier) {
+	s.notifiersMu.Lock()
+	defer s.notifiersMu.Unlock()
+	s.notifiers = append(s.notifiers, n)
+}
+
+// SetArtistFollowed follows or unfollows an artist.
+func (s *Service) SetArtistFollowed(ctx context.Context, id string, followed bool) error {
+	slog.Debug("SetArtistFollowed service called", "id", id, "followed", followed)
+	artist, err := s.library.GetArtist(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get artist: %w", err)
+	}
+	if artist == nil {
+		return fmt.Errorf("artist not found: %s", id)
+	}
+	value := ""
+	if followed {
+		value = "true"
+	}
+	if err := s.library.SetArtistAttribute(ctx, id, library.FollowedAttribute, value); err != nil {
+		slog.Error("SetArtistFollowed failed", "id", id, "error", err)
+		return err
+	}
+	return nil
+}
+
+// GetFollowedArtists returns the followed artists, ordered by name.
+func (s *Service) GetFollowedArtists(ctx context.Context) ([]*library.Artist, error) {
+	return s.library.GetArtistsWithAttribute(ctx, library.FollowedAttribute, "true")
+}
+
+// AlbumImported notifies the new album when one of its artists is followed. The importer calls
+// it once per album, when its first track is imported, whatever the import came from.
+func (s *Service) AlbumImported(ctx context.Context, album *library.Album) {
+	var followed []string
+	for _, role := range album.Artists {
+		if role.Artist == nil {
+			continue
+		}
+		artist, err := s.library.GetArtist(ctx, role.Artist.ID)
+		if err != nil {
+			slog.Warn("Failed to check if album artist is followed", "artistID", role.Artist.ID, "error", err)
+			continue
+		}
+		if artist.IsFollowed() {
+			followed = append(followed, artist.Name)
+		}
+	}
+	if len(followed) == 0 {
+		return
+	}
+
+	link := strings.TrimRight(s.configManager.Get().Server.PublicURL, "/") + "/library?query=" + url.QueryEscape(album.Title)
+	message := fmt.Sprintf("🆕 New album by %s: %s\n%s", strings.Join(followed, ", "), album.Title, link)
+	slog.Info("Notifying new album of followed artist", "album", album.Title, "artists", followed)
+	s.notifiersMu.Lock()
+	notifiers := append([]Notifier(nil), s.notifiers...)
+	s.notifiersMu.Unlock()
+	for _, n := range notifiers {
+		n.Notify(message)
+	}
+}
@@ -1,13 +1,19 @@
 package library
 
 import (
+	"encoding/csv"
+	"errors"
 	"fmt"
 	"log/slog"
 	"math"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/contre95/soulsolid/src/features/hosting/respond"
+	"github.com/contre95/soulsolid/src/features/ui"
 	"github.com/contre95/soulsolid/src/music"
 	"github.com/gofiber/fiber/v2"
 )
@@ -57,6 +63,9 @@ func (h *Handler) RenderLibrarySection(c *fiber.Ctx) error {
 		"DefaultDownloadPath": h.service.configManager.Get().DownloadPath,
 		"SearchArtists":       artists,
 		"SearchAlbums":        albums,
+		"Query":               c.Query("query"),
+		"AddedByJob":          c.Query("added_by_job"),
+		"NotVerified":         c.Query("not_verified"),
 	})
 }
 
@@ -213,6 +222,9 @@ func (h *Handler) GetLibraryTable(c *fiber.Ctx) error {
 		"SearchArtists": artists,
 		"SearchAlbums":  albums,
 		"Genres":        genres,
+		"Query":         c.Query("query"),
+		"AddedByJob":    c.Query("added_by_job"),
+		"NotVerified":   c.Query("not_verified"),
 	})
 }
 
@@ -223,9 +235,21 @@ type SearchResult struct {
 	PrimaryName string // Artist name, Album title, Track title
 	Secondary   string // Artist ID, Album artist names, Track artist names
 	Tertiary    string // "", Album year, Track album title
-	Duration    int    // Track duration in seconds (for tracks only)
+	Duration    int    // Duration in seconds (total of the tracks for albums and artists)
+	TrackCount  int    // Number of tracks (albums and artists only)
 	ImageURL    string // Image for display
 	Path        string // File path (tracks only) — used to stream via /stream?path=
+	Locked      bool   // Whether the track or album itself is locked
+	Notes       string // Free-text notes of the track or album
+	Followed    bool   // Whether the artist is followed (artists only)
+	AddedByJob  string // ID of the job that added the track or album, if recorded
+	AddedSource string // Badge label of that job's type, e.g. "Import"
+	// Optional table columns, see ui.TableColumns
+	Bitrate int       // kbps (tracks only)
+	Year    int       // Release year (tracks and albums)
+	Added   time.Time // When it was added to the library (tracks and albums)
+	Genre   string    // Tracks only
+	Plays   int       // Times streamed (tracks only), loaded only while the column is shown
 }
 
 // parseBoolFilter converts "true"/"false" query params to *bool; anything else returns nil.
@@ -257,14 +281,23 @@ func trackToSearchResult(track *music.Track) SearchResult {
 	if track.Album != nil {
 		albumTitle = track.Album.Title
 	}
+	jobID, source := track.AddedBy()
 	return SearchResult{
 		Type:        "track",
 		ID:          track.ID,
-		PrimaryName: track.Title,
+		PrimaryName: track.FullTitle(),
 		Secondary:   artistNames.String(),
 		Tertiary:    albumTitle,
 		Duration:    track.Metadata.Duration,
 		Path:        track.Path,
+		Locked:      track.Attributes[music.LockedAttribute] == "true",
+		Notes:       track.Notes(),
+		AddedByJob:  jobID,
+		AddedSource: music.AddedSourceLabel(source),
+		Bitrate:     track.Bitrate,
+		Year:        track.Metadata.Year,
+		Added:       track.AddedDate,
+		Genre:       track.Metadata.Genre,
 	}
 }
 
@@ -280,16 +313,26 @@ func albumToSearchResult(album *music.Album) SearchResult {
 		}
 		artistNames.WriteString(ar.Artist.Name)
 	}
-	year := ""
+	year, releaseYear := "", 0
 	if !album.ReleaseDate.IsZero() {
-		year = fmt.Sprintf("%d", album.ReleaseDate.Year())
+		releaseYear = album.ReleaseDate.Year()
+		year = fmt.Sprintf("%d", releaseYear)
 	}
+	jobID, source := album.AddedBy()
 	return SearchResult{
 		Type:        "album",
 		ID:          album.ID,
 		PrimaryName: album.Title,
 		Secondary:   artistNames.String(),
 		Tertiary:    year,
+		Duration:    album.TotalDuration,
+		TrackCount:  album.TrackCount,
+		Locked:      album.IsLocked(),
+		Notes:       album.Notes(),
+		AddedByJob:  jobID,
+		AddedSource: music.AddedSourceLabel(source),
+		Year:        releaseYear,
+		Added:       album.AddedDate,
 	}
 }
 
@@ -300,6 +343,8 @@ func artistToSearchResult(artist *music.Artist) SearchResult {
 		ID:          artist.ID,
 		PrimaryName: artist.Name,
 		Secondary:   artist.ID,
+		Duration:    artist.TotalDuration,
+		TrackCount:  artist.TrackCount,
 	}
 }
 
@@ -310,19 +355,18 @@ func (h *Handler) GetUnifiedSearch(c *fiber.Ctx) error {
 	query := strings.TrimSpace(c.Query("query", ""))
 	page := c.QueryInt("page", 1)
 	limit := c.QueryInt("limit", 50)
-	genre := c.Query("genre", "")
-	hasAcoustID := parseBoolFilter(c.Query("has_acoustid", ""))
-	lyricsFilter := c.Query("lyrics_filter", "")
-	lyricsText := strings.TrimSpace(c.Query("lyrics_text", ""))
-	addedAfter := strings.TrimSpace(c.Query("added_after", ""))
-	addedBefore := strings.TrimSpace(c.Query("added_before", ""))
 
 	var results []SearchResult
 	var totalCount int
 
 	offset := (page - 1) * limit
 
-	hasActiveFilters := genre != "" || hasAcoustID != nil || lyricsFilter != "" || lyricsText != "" || addedAfter != "" || addedBefore != ""
+	filter, hasActiveFilters := searchFilter(c)
+
+	// API clients page with stable cursors; offsets are kept for the HTMX tables.
+	if c.Get("HX-Request") != "true" {
+		return h.getSearchPage(c, query, c.Query("cursor"), limit, filter)
+	}
 
 	if query == "" && !hasActiveFilters {
 		// Browse-all: paginated tracks only.
@@ -349,15 +393,7 @@ func (h *Handler) GetUnifiedSearch(c *fiber.Ctx) error {
 	} else {
 		// Search/filter: albums → artists → tracks order.
 		// Artist/album matches only apply to a text query and are capped (not paginated).
-		trackFilter := &music.TrackFilter{
-			TextSearch:   query,
-			Genre:        genre,
-			HasAcoustID:  hasAcoustID,
-			LyricsFilter: lyricsFilter,
-			LyricsText:   lyricsText,
-			AddedAfter:   addedAfter,
-			AddedBefore:  addedBefore,
-		}
+		trackFilter := filter
 		trackCount, err := h.service.GetTracksFilteredCount(c.Context(), trackFilter)
 		if err != nil {
 			slog.Error("Error counting tracks", "error", err)
@@ -419,6 +455,8 @@ func (h *Handler) GetUnifiedSearch(c *fiber.Ctx) error {
 		}
 	}
 
+	h.markFollowed(c, results)
+	h.markPlays(c, results)
 	pagination := NewPagination(page, limit, totalCount)
 
 	return respond.Partial(c, "library/unified_search_list", fiber.Map{
@@ -428,6 +466,149 @@ func (h *Handler) GetUnifiedSearch(c *fiber.Ctx) error {
 	})
 }
 
+// getSearchPage answers API clients of the unified search. Artist and album matches are capped
+// and only returned with the first page; tracks are paged with the cursor from the previous response.
+func (h *Handler) getSearchPage(c *fiber.Ctx, query, cursor string, limit int, trackFilter *music.TrackFilter) error {
+	if limit < 1 || limit > 500 {
+		return respond.ToastErr(c, fiber.StatusBadRequest, "limit must be between 1 and 500")
+	}
+	results := []SearchResult{}
+	if query != "" && cursor == "" {
+		albums, err := h.service.SearchAlbums(c.Context(), query, 20, 0)
+		if err != nil {
+			slog.Error("Error searching albums", "error", err)
+		}
+		for _, album := range albums {
+			results = append(results, albumToSearchResult(album))
+		}
+		artists, err := h.service.GetArtistsFilteredPaginated(c.Context(), 20, 0, query)
+		if err != nil {
+			slog.Error("Error searching artists", "error", err)
+		}
+		for _, artist := range artists {
+			results = append(results, artistToSearchResult(artist))
+		}
+	}
+	page, err := h.service.GetTracksPage(c.Context(), limit, cursor, trackFilter)
+	if err != nil {
+		if errors.Is(err, music.ErrInvalidCursor) {
+			return respond.ToastErr(c, fiber.StatusBadRequest, err.Error())
+		}
+		return respond.ToastErr(c, fiber.StatusInternalServerError, "Error loading tracks")
+	}
+	for _, track := range page.Tracks {
+		results = append(results, trackToSearchResult(track))
+	}
+	h.markFollowed(c, results)
+	h.markPlays(c, results)
+	return c.JSON(fiber.Map{
+		"Results":        results,
+		"Query":          query,
+		"NextCursor":     page.NextCursor,
+		"Total":          page.Total,
+		"TotalEstimated": page.TotalEstimated,
+	})
+}
+
+// searchFilter builds the track filter of the library search from the query, nil when
+// nothing is searched or filtered, and reports whether filters other than the text are set.
+func searchFilter(c *fiber.Ctx) (*music.TrackFilter, bool) {
+	filter := &music.TrackFilter{
+		TextSearch:      strings.TrimSpace(c.Query("query", "")),
+		Genre:           c.Query("genre", ""),
+		HasAcoustID:     parseBoolFilter(c.Query("has_acoustid", "")),
+		LyricsFilter:    c.Query("lyrics_filter", ""),
+		LyricsText:      strings.TrimSpace(c.Query("lyrics_text", "")),
+		AddedAfter:      strings.TrimSpace(c.Query("added_after", "")),
+		AddedBefore:     strings.TrimSpace(c.Query("added_before", "")),
+		AddedByJob:      strings.TrimSpace(c.Query("added_by_job", "")),
+		ExcludeExplicit: ui.FamilyFilter(c),
+	}
+	// not_verified is a number of months without verification, or "problems"
+	switch notVerified := c.Query("not_verified", ""); notVerified {
+	case "":
+	case "problems":
+		filter.VerificationProblems = true
+	default:
+		if months, err := strconv.Atoi(notVerified); err == nil && months > 0 {
+			filter.NotVerifiedSince = time.Now().AddDate(0, -months, 0).UTC().Format("2006-01-02")
+		}
+	}
+	filtered := filter.Genre != "" || filter.HasAcoustID != nil || filter.LyricsFilter != "" || filter.LyricsText != "" ||
+		filter.AddedAfter != "" || filter.AddedBefore != "" || filter.AddedByJob != "" || filter.ExcludeExplicit ||
+		filter.NotVerifiedSince != "" || filter.VerificationProblems
+	if filter.TextSearch == "" && !filtered {
+		return nil, false
+	}
+	return filter, filtered
+}
+
+// markPlays sets the play counts of the track results, when the requesting user shows them.
+func (h *Handler) markPlays(c *fiber.Ctx, results []SearchResult) {
+	if !ui.Columns(c)["plays"] {
+		return
+	}
+	var ids []string
+	for _, r := range results {
+		if r.Type == "track" {
+			ids = append(ids, r.ID)
+		}
+	}
+	plays, err := h.service.GetPlayCounts(c.Context(), ids)
+	if err != nil {
+		return
+	}
+	for i := range results {
+		results[i].Plays = plays[results[i].ID]
+	}
+}
+
+// markFollowed flags the followed artists among the results.
+func (h *Handler) markFollowed(c *fiber.Ctx, results []SearchResult) {
+	if !slices.ContainsFunc(results, func(r SearchResult) bool { return r.Type == "artist" }) {
+		return
+	}
+	followed, err := h.service.GetFollowedArtists(c.Context())
+	if err != nil {
+		slog.Error("Error loading followed artists", "error", err)
+		return
+	}
+	for i := range results {
+		results[i].Followed = results[i].Type == "artist" && slices.ContainsFunc(followed, func(a *music.Artist) bool { return a.ID == results[i].ID })
+	}
+}
+
+// GetRecentAdditions renders the dashboard card with the latest tracks added to the library.
+func (h *Handler) GetRecentAdditions(c *fiber.Ctx) error {
+	var filter *music.TrackFilter
+	if ui.FamilyFilter(c) {
+		filter = &music.TrackFilter{ExcludeExplicit: true}
+	}
+	page, err := h.service.GetTracksPage(c.Context(), 5, "", filter)
+	if err != nil {
+		return respond.ToastErr(c, fiber.StatusInternalServerError, "Failed to load recent additions")
+	}
+	results := make([]SearchResult, 0, len(page.Tracks))
+	for _, track := range page.Tracks {
+		results = append(results, trackToSearchResult(track))
+	}
+	return respond.Partial(c, "library/recent_card", fiber.Map{"Results": results})
+}
+
+// Suggest returns typeahead name matches for the library search box and the download query field.
+func (h *Handler) Suggest(c *fiber.Ctx) error {
+	query := c.Query("q", c.Query("query"))
+	limit := min(max(c.QueryInt("limit", 5), 1), 20)
+	suggestions, err := h.service.Suggest(c.Context(), query, limit, ui.FamilyFilter(c))
+	if err != nil {
+		return respond.ToastErr(c, fiber.StatusInternalServerError, "Failed to get suggestions")
+	}
+	return respond.Partial(c, "library/suggestions", fiber.Map{
+		"Query":       query,
+		"Suggestions": suggestions,
+	})
+}
+
 // GetLibraryFileTree returns a tree structure of the library path.
 func (h *Handler) GetLibraryFileTree(c *fiber.Ctx) error {
 	slog.Debug("GetLibraryFileTree handler called")
@@ -477,6 +658,291 @@ func (h *Handler) DeleteAlbum(c *fiber.Ctx) error {
 	return respond.ToastOk(c, "Album deleted successfully")
 }
 
+// ExportLevelingProfile serves the library's volume-leveling profile as a JSON download.
+func (h *Handler) ExportLevelingProfile(c *fiber.Ctx) error {
+	slog.Debug("ExportLevelingProfile handler called")
+	profile, err := h.service.GetLevelingProfile(c.Context())
+	if err != nil {
+		slog.Error("Failed to build leveling profile", "error", err)
+		return respond.ToastErr(c, fiber.StatusInternalServerError, "Failed to build leveling profile")
+	}
+	return respond.Resource(c, "application/json", fmt.Sprintf("%s/library/leveling/export", c.BaseURL()), func() error {
+		c.Set("Content-Disposition", "attachment; filename=\"soulsolid-leveling.json\"")
+		return c.JSON(profile)
+	})
+}
+
+// exportBatch is the number of tracks read at once by the CSV export.
+const exportBatch = 500
+
+// ExportTracks downloads the tracks matching the library search as CSV, with the optional
+// columns the requesting user shows (query: the library search filters).
+func (h *Handler) ExportTracks(c *fiber.Ctx) error {
+	filter, _ := searchFilter(c)
+	columns := ui.Columns(c)
+	header := []string{"id", "title", "artist", "album", "duration"}
+	for _, col := range ui.TableColumns {
+		if columns[col.Key] {
+			header = append(header, col.Key)
+		}
+	}
+
+	c.Set("Content-Type", "text/csv")
+	c.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="library-%s.csv"`, time.Now().Format("2006-01-02")))
+	w := csv.NewWriter(c)
+	w.Write(header)
+	cursor := ""
+	for {
+		page, err := h.service.GetTracksPage(c.Context(), exportBatch, cursor, filter)
+		if err != nil {
+			slog.Error("Failed to export tracks", "error", err)
+			return respond.ToastErr(c, fiber.StatusInternalServerError, "Failed to export tracks")
+		}
+		results := make([]SearchResult, 0, len(page.Tracks))
+		for _, track := range page.Tracks {
+			results = append(results, trackToSearchResult(track))
+		}
+		h.markPlays(c, results)
+		for _, r := range results {
+			row := []string{r.ID, r.PrimaryName, r.Secondary, r.Tertiary, strconv.Itoa(r.Duration)}
+			for _, key := range header[5:] {
+				row = append(row, r.column(key))
+			}
+			w.Write(row)
+		}
+		if cursor = page.NextCursor; cursor == "" {
+			break
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// column formats an optional table column of a result for the CSV export.
+func (r SearchResult) column(key string) string {
+	switch key {
+	case "bitrate":
+		if r.Bitrate > 0 {
+			return strconv.Itoa(r.Bitrate)
+		}
+	case "year":
+		if r.Year > 0 {
+			return strconv.Itoa(r.Year)
+		}
+	case "added":
+		if !r.Added.IsZero() {
+			return r.Added.Format(time.RFC3339)
+		}
+	case "genre":
+		return r.Genre
+	case "plays":
+		return strconv.Itoa(r.Plays)
+	}
+	return ""
+}
+
+// SetTrackLocked locks or unlocks a track and re-renders its lock toggle.
+func (h *Handler) SetTrackLocked(c *fiber.Ctx) error {
+	trackID := c.Params("trackId")
+	if trackID == "" {
+		return respond.ToastErr(c, fiber.StatusBadRequest, "Track ID is required")
+	}
+	locked := c.FormValue("locked") == "true"
+	if err := h.service.SetTrackLocked(c.Context(), trackID, locked); err != nil {
+		slog.Error("Failed to update track lock", "error", err, "trackId", trackID)
+		return respond.ToastErr(c, fiber.StatusInternalServerError, "Failed to update track lock")
+	}
+	return respond.Partial(c, "library/lock_button", fiber.Map{"Type": "track", "ID": trackID, "Locked": locked})
+}
+
+// SetAlbumLocked locks or unlocks an album and re-renders its lock toggle.
+func (h *Handler) SetAlbumLocked(c *fiber.Ctx) error {
+	albumID := c.Params("albumId")
+	if albumID == "" {
+		return respond.ToastErr(c, fiber.StatusBadRequest, "Album ID is required")
+	}
+	locked := c.FormValue("locked") == "true"
+	if err := h.service.SetAlbumLocked(c.Context(), albumID, locked); err != nil {
+		slog.Error("Failed to update album lock", "error", err, "albumId", albumID)
+		return respond.ToastErr(c, fiber.StatusInternalServerError, "Failed to update album lock")
+	}
+	return respond.Partial(c, "library/lock_button", fiber.Map{"Type": "album", "ID": albumID, "Locked": locked})
+}
+
+// GetAlbumNotes renders the notes editor of an album.
+func (h *Handler) GetAlbumNotes(c *fiber.Ctx) error {
+	albumID := c.Params("albumId")
+	album, err := h.service.GetAlbum(c.Context(), albumID)
+	if err != nil || album == nil {
+		return respond.ToastErr(c, fiber.StatusNotFound, "Album not found")
+	}
+	return respond.Partial(c, "library/notes_form", fiber.Map{"Type": "album", "ID": albumID, "Notes": album.Notes(), "MaxLength": music.MaxNotesLength})
+}
+
+// SetAlbumNotes saves the notes of an album.
+func (h *Handler) SetAlbumNotes(c *fiber.Ctx) error {
+	albumID := c.Params("albumId")
+	if albumID == "" {
+		return respond.ToastErr(c, fiber.StatusBadRequest, "Album ID is required")
+	}
+	if err := h.service.SetAlbumNotes(c.Context(), albumID, c.FormValue("notes")); err != nil {
+		slog.Error("Failed to update album notes", "error", err, "albumId", albumID)
+		return respond.ToastErr(c, fiber.StatusBadRequest, "Failed to update album notes: "+err.Error())
+	}
+	return respond.ToastOk(c, "Album notes saved")
+}
+
+// SetArtistFollowed follows or unfollows an artist and re-renders its follow button.
+func (h *Handler) SetArtistFollowed(c *fiber.Ctx) error {
+	artistID := c.Params("artistId")
+	if artistID == "" {
+		return respond.ToastErr(c, fiber.StatusBadRequest, "Artist ID is required")
+	}
+	followed := c.FormValue("followed") == "true"
+	if err := h.service.SetArtistFollowed(c.Context(), artistID, followed); err != nil {
+		slog.Error("Failed to update artist follow", "error", err, "artistId", artistID)
+		return respond.ToastErr(c, fiber.StatusInternalServerError, "Failed to update artist follow")
+	}
+	return respond.Partial(c, "library/follow_button", fiber.Map{"ID": artistID, "Followed": followed})
+}
+
+// GetFollowedArtists returns the followed artists.
+func (h *Handler) GetFollowedArtists(c *fiber.Ctx) error {
+	artists, err := h.service.GetFollowedArtists(c.Context())
+	if err != nil {
+		slog.Error("Error loading followed artists", "error", err)
+		return respond.ToastErr(c, fiber.StatusInternalServerError, "Failed to load followed artists")
+	}
+	results := make([]SearchResult, 0, len(artists))
+	for _, artist := range artists {
+		result := artistToSearchResult(artist)
+		result.Followed = true
+		results = append(results, result)
+	}
+	return c.JSON(results)
+}
+
+// GetTrackAppearances renders the albums a track appears on besides its primary one.
+func (h *Handler) GetTrackAppearances(c *fiber.Ctx) error {
+	trackID := c.Params("trackId")
+	return h.renderTrackAppearances(c, trackID)
+}
+
+func (h *Handler) renderTrackAppearances(c *fiber.Ctx, trackID string) error {
+	albums, err := h.service.GetTrackAppearances(c.Context(), trackID)
+	if err != nil {
+		return respond.ToastErr(c, fiber.StatusInternalServerError, "Failed to load album appearances")
+	}
+	return respond.Partial(c, "library/track_appearances", fiber.Map{"TrackID": trackID, "Appearances": albums})
+}
+
+// GetAppearanceCandidates renders the albums matching the query that a track can be added to.
+func (h *Handler) GetAppearanceCandidates(c *fiber.Ctx) error {
+	trackID := c.Params("trackId")
+	query := strings.TrimSpace(c.Query("q"))
+	candidates := []*music.Album{}
+	if query != "" {
+		track, err := h.service.GetTrack(c.Context(), trackID)
+		if err != nil || track == nil {
+			return respond.ToastErr(c, fiber.StatusNotFound, "Track not found")
+		}
+		appearances, err := h.service.GetTrackAppearances(c.Context(), trackID)
+		if err != nil {
+			return respond.ToastErr(c, fiber.StatusInternalServerError, "Failed to load album appearances")
+		}
+		linked := map[string]bool{}
+		if track.Album != nil {
+			linked[track.Album.ID] = true
+		}
+		for _, album := range appearances {
+			linked[album.ID] = true
+		}
+		albums, err := h.service.SearchAlbums(c.Context(), query, 8, 0)
+		if err != nil {
+			return respond.ToastErr(c, fiber.StatusInternalServerError, "Failed to search albums")
+		}
+		for _, album := range albums {
+			if !linked[album.ID] {
+				candidates = append(candidates, album)
+			}
+		}
+	}
+	return respond.Partial(c, "library/appearance_candidates", fiber.Map{"TrackID": trackID, "Query": query, "Albums": candidates})
+}
+
+// AddTrackAppearance adds a track to another album and re-renders its appearances.
+func (h *Handler) AddTrackAppearance(c *fiber.Ctx) error {
+	trackID := c.Params("trackId")
+	albumID := c.FormValue("album_id")
+	if albumID == "" {
+		return respond.ToastErr(c, fiber.StatusBadRequest, "Album ID is required")
+	}
+	if err := h.service.AddTrackAppearance(c.Context(), trackID, albumID); err != nil {
+		if errors.Is(err, music.ErrPrimaryAlbum) {
+			return respond.ToastErr(c, fiber.StatusConflict, "The track is already on this album")
+		}
+		slog.Error("Failed to add track appearance", "error", err, "trackId", trackID, "albumId", albumID)
+		return respond.ToastErr(c, fiber.StatusInternalServerError, "Failed to add track to album")
+	}
+	return h.renderTrackAppearances(c, trackID)
+}
+
+// RemoveTrackAppearance removes a track from an album it appears on and re-renders its appearances.
+func (h *Handler) RemoveTrackAppearance(c *fiber.Ctx) error {
+	trackID := c.Params("trackId")
+	albumID := c.Params("albumId")
+	if err := h.service.RemoveTrackAppearance(c.Context(), trackID, albumID); err != nil {
+		switch {
+		case errors.Is(err, music.ErrPrimaryAlbum):
+			return respond.ToastErr(c, fiber.StatusConflict, "The primary album can't be removed, make another album primary first")
+		case errors.Is(err, music.ErrNotAnAppearance):
+			return respond.ToastErr(c, fiber.StatusNotFound, err.Error())
+		}
+		slog.Error("Failed to remove track appearance", "error", err, "trackId", trackID, "albumId", albumID)
+		return respond.ToastErr(c, fiber.StatusInternalServerError, "Failed to remove track from album")
+	}
+	return h.renderTrackAppearances(c, trackID)
+}
+
+// SetTrackPrimaryAlbum makes one of the albums a track appears on its primary album.
+func (h *Handler) SetTrackPrimaryAlbum(c *fiber.Ctx) error {
+	trackID := c.Params("trackId")
+	albumID := c.Params("albumId")
+	if err := h.service.SetTrackPrimaryAlbum(c.Context(), trackID, albumID); err != nil {
+		switch {
+		case errors.Is(err, music.ErrTrackLocked):
+			return respond.ToastErr(c, fiber.StatusConflict, "Track is locked")
+		case errors.Is(err, music.ErrNotAnAppearance):
+			return respond.ToastErr(c, fiber.StatusNotFound, err.Error())
+		}
+		slog.Error("Failed to set primary album", "error", err, "trackId", trackID, "albumId", albumID)
+		return respond.ToastErr(c, fiber.StatusInternalServerError, "Failed to set primary album")
+	}
+	return h.renderTrackAppearances(c, trackID)
+}
+
+// GetTrackVersions renders the versions of a track's song held in the library, e.g. live or remastered.
+func (h *Handler) GetTrackVersions(c *fiber.Ctx) error {
+	trackID := c.Params("trackId")
+	versions, err := h.service.GetTrackVersions(c.Context(), trackID)
+	if err != nil {
+		slog.Error("Failed to get track versions", "error", err, "trackId", trackID)
+		return respond.ToastErr(c, fiber.StatusInternalServerError, "Failed to load track versions")
+	}
+	return respond.Partial(c, "library/track_versions", fiber.Map{"TrackID": trackID, "Versions": versions})
+}
+
+// GetArtistVersions renders the songs of an artist held in more than one version.
+func (h *Handler) GetArtistVersions(c *fiber.Ctx) error {
+	artistID := c.Params("artistId")
+	songs, err := h.service.GetArtistVersions(c.Context(), artistID)
+	if err != nil {
+		slog.Error("Failed to get artist versions", "error", err, "artistId", artistID)
+		return respond.ToastErr(c, fiber.StatusInternalServerError, "Failed to load artist versions")
+	}
+	return respond.Partial(c, "library/artist_versions", fiber.Map{"ArtistID": artistID, "Songs": songs})
+}
+
 // DeleteArtist deletes an artist from the library.
 func (h *Handler) DeleteArtist(c *fiber.Ctx) error {
 	slog.Debug("DeleteArtist handler called", "artistId", c.Params("artistId"))
@@ -527,9 +993,20 @@ func (h *Handler) RenderTrackOverviewPanel(c *fiber.Ctx) error {
 		lyricsPreview = strings.Join(lines, "\n")
 	}
 
+	albumNotes := ""
+	if track.Album != nil {
+		if album, err := h.service.GetAlbum(c.Context(), track.Album.ID); err == nil {
+			albumNotes = album.Notes()
+		}
+	}
+
+	addedByJob, source := track.AddedBy()
 	return respond.Partial(c, "library/track_overview_panel", fiber.Map{
 		"Track":         track,
+		"AlbumNotes":    albumNotes,
 		"Artists":       artistNames.String(),
 		"LyricsPreview": lyricsPreview,
+		"AddedByJob":    addedByJob,
+		"AddedSource":   music.AddedSourceLabel(source),
 	})
 }
@@ -0,0 +1,90 @@
+package library
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	library "github.com/contre95/soulsolid/src/music"
+)
+
+// LevelingProfile is a per-album volume-leveling profile built from the library's ReplayGain data.
+// It lets players and devices without ReplayGain support apply consistent playback levels.
+type LevelingProfile struct {
+	GeneratedAt time.Time       `json:"generatedAt"`
+	Albums      []AlbumLeveling `json:"albums"`
+	Skipped     int             `json:"skipped"` // albums without any track gain
+}
+
+// AlbumLeveling holds the album gain and the per-track adjustments of one album.
+type AlbumLeveling struct {
+	AlbumID   string          `json:"albumId"`
+	Title     string          `json:"title"`
+	Artist    string          `json:"artist"`
+	AlbumGain float64         `json:"albumGain"`
+	Scale     float64         `json:"scale"` // linear volume factor for AlbumGain
+	Tracks    []TrackLeveling `json:"tracks"`
+}
+
+// TrackLeveling holds the gain values of one track.
+type TrackLeveling struct {
+	TrackID   string  `json:"trackId"`
+	Title     string  `json:"title"`
+	Path      string  `json:"path"`
+	TrackGain float64 `json:"trackGain"`
+	HasGain   bool    `json:"hasGain"`
+}
+
+// GetLevelingProfile computes album gains for every album in the library.
+func (s *Service) GetLevelingProfile(ctx context.Context) (*LevelingProfile, error) {
+	slog.Debug("GetLevelingProfile service called")
+	albums, err := s.library.GetAlbums(ctx)
+	if err != nil {
+		slog.Error("GetLevelingProfile: failed to get albums", "error", err)
+		return nil, fmt.Errorf("failed to get albums: %w", err)
+	}
+
+	profile := &LevelingProfile{GeneratedAt: time.Now(), Albums: []AlbumLeveling{}}
+	for _, album := range albums {
+		filter := &library.TrackFilter{AlbumIDs: []string{album.ID}}
+		count, err := s.library.GetTracksFilteredCount(ctx, filter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count tracks of album %s: %w", album.ID, err)
+		}
+		tracks, err := s.library.GetTracksFilteredPaginated(ctx, count, 0, filter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tracks of album %s: %w", album.ID, err)
+		}
+		gain, ok := library.AlbumGain(tracks)
+		if !ok {
+			profile.Skipped++
+			continue
+		}
+
+		entry := AlbumLeveling{
+			AlbumID:   album.ID,
+			Title:     album.Title,
+			AlbumGain: math.Round(gain*100) / 100,
+			Scale:     math.Round(math.Pow(10, gain/20)*10000) / 10000,
+			Tracks:    make([]TrackLeveling, 0, len(tracks)),
+		}
+		if len(album.Artists) > 0 && album.Artists[0].Artist != nil {
+			entry.Artist = album.Artists[0].Artist.Name
+		}
+		for _, t := range tracks {
+			entry.Tracks = append(entry.Tracks, TrackLeveling{
+				TrackID:   t.ID,
+				Title:     t.Title,
+				Path:      t.Path,
+				TrackGain: t.Metadata.Gain,
+				HasGain:   t.Metadata.Gain != 0,
+			})
+		}
+		profile.Albums = append(profile.Albums, entry)
+	}
+
+	slog.Debug("GetLevelingProfile completed", "albums", len(profile.Albums), "skipped", profile.Skipped)
+	return profile, nil
+}
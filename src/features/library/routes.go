@@ -9,6 +9,7 @@ func RegisterRoutes(app *fiber.App, service *Service) {
 	handler := NewHandler(service)
 
 	app.Get("/library", handler.RenderLibrarySection)
+	app.Get("/api/v1/suggest", handler.Suggest)
 
 	library := app.Group("/library")
 	library.Get("/table", handler.GetLibraryTable)
@@ -18,10 +19,26 @@ func RegisterRoutes(app *fiber.App, service *Service) {
 	library.Get("/albums/count", handler.GetAlbumsCount)
 	library.Get("/tracks/count", handler.GetTracksCount)
 	library.Get("/storage/size", handler.GetStorageSize)
+	library.Get("/artists/followed", handler.GetFollowedArtists)
+	library.Get("/artists/:artistId/versions", handler.GetArtistVersions)
 	library.Get("/artists/:id", handler.GetArtist)
 	library.Get("/albums/:id", handler.GetAlbum)
 	library.Get("/tracks/:id", handler.GetTrack)
 	library.Get("/tree", handler.GetLibraryFileTree)
+	library.Get("/recent", handler.GetRecentAdditions)
+	library.Get("/leveling/export", handler.ExportLevelingProfile)
+	library.Get("/export", handler.ExportTracks)
+	library.Post("/tracks/:trackId/lock", handler.SetTrackLocked)
+	library.Post("/albums/:albumId/lock", handler.SetAlbumLocked)
+	library.Get("/albums/:albumId/notes", handler.GetAlbumNotes)
+	library.Put("/albums/:albumId/notes", handler.SetAlbumNotes)
+	library.Post("/artists/:artistId/follow", handler.SetArtistFollowed)
+	library.Get("/tracks/:trackId/albums", handler.GetTrackAppearances)
+	library.Get("/tracks/:trackId/versions", handler.GetTrackVersions)
+	library.Get("/tracks/:trackId/albums/candidates", handler.GetAppearanceCandidates)
+	library.Post("/tracks/:trackId/albums", handler.AddTrackAppearance)
+	library.Post("/tracks/:trackId/albums/:albumId/primary", handler.SetTrackPrimaryAlbum)
+	library.Delete("/tracks/:trackId/albums/:albumId", handler.RemoveTrackAppearance)
 	library.Delete("/tracks/:trackId", handler.DeleteTrack)
 	library.Delete("/albums/:albumId", handler.DeleteAlbum)
 	library.Delete("/artists/:artistId", handler.DeleteArtist)
@@ -8,6 +8,8 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
+	"sync"
 
 	"github.com/contre95/soulsolid/src/features/config"
 	library "github.com/contre95/soulsolid/src/music"
@@ -19,6 +21,8 @@ type Service struct {
 	library       library.Library
 	configManager *config.Manager
 	fileManager   library.FileManager
+	notifiersMu   sync.Mutex
+	notifiers     []Notifier
 }
 
 // NewService creates a new library service.
@@ -143,6 +147,62 @@ func (s *Service) GetTracksFilteredPaginated(ctx context.Context, limit, offset
 	return tracks, nil
 }
 
+// TrackPage is a cursor-paginated page of tracks for API clients.
+type TrackPage struct {
+	Tracks         []*library.Track
+	NextCursor     string // empty on the last page
+	Total          int
+	TotalEstimated bool // Total is an upper bound rather than an exact count
+}
+
+// GetTracksPage returns a page of tracks after the given cursor (empty for the first page).
+// A nil filter pages through the whole library and only estimates the total, which stays cheap on
+// libraries with hundreds of thousands of tracks.
+func (s *Service) GetTracksPage(ctx context.Context, limit int, cursor string, filter *library.TrackFilter) (*TrackPage, error) {
+	slog.Debug("GetTracksPage service called", "limit", limit, "cursor", cursor, "filter", filter)
+	var after *library.TrackCursor
+	if cursor != "" {
+		var err error
+		if after, err = library.DecodeTrackCursor(cursor); err != nil {
+			return nil, err
+		}
+	}
+	page := &TrackPage{}
+	var err error
+	if filter == nil {
+		page.Total, err = s.library.EstimateTracksCount(ctx)
+		page.TotalEstimated = true
+		filter = &library.TrackFilter{}
+	} else {
+		page.Total, err = s.library.GetTracksFilteredCount(ctx, filter)
+	}
+	if err != nil {
+		slog.Error("GetTracksPage: failed to count tracks", "error", err)
+		return nil, err
+	}
+	tracks, next, err := s.library.GetTracksAfter(ctx, limit, after, filter)
+	if err != nil {
+		slog.Error("GetTracksPage failed", "error", err)
+		return nil, err
+	}
+	page.Tracks = tracks
+	if next != nil {
+		page.NextCursor = next.Encode()
+	}
+	slog.Debug("GetTracksPage completed", "count", len(tracks), "hasNext", next != nil)
+	return page, nil
+}
+
+// GetPlayCounts returns how many times each of the tracks was streamed.
+func (s *Service) GetPlayCounts(ctx context.Context, trackIDs []string) (map[string]int, error) {
+	counts, err := s.library.GetPlayCounts(ctx, trackIDs)
+	if err != nil {
+		slog.Error("GetPlayCounts failed", "count", len(trackIDs), "error", err)
+		return nil, err
+	}
+	return counts, nil
+}
+
 // GetTracksFilteredCount returns the filtered count of tracks in the library.
 func (s *Service) GetTracksFilteredCount(ctx context.Context, filter *library.TrackFilter) (int, error) {
 	slog.Debug("GetTracksFilteredCount service called", "filter", filter)
@@ -256,6 +316,22 @@ func (s *Service) GetGenres(ctx context.Context) ([]string, error) {
 	return s.library.GetGenres(ctx)
 }
 
+// Suggest returns typeahead matches for the start of an artist, album or track name.
+// The query is trimmed and its whitespace collapsed; queries shorter than two characters return nothing.
+// With excludeExplicit, explicit tracks aren't suggested.
+func (s *Service) Suggest(ctx context.Context, query string, limit int, excludeExplicit bool) ([]library.NameSuggestion, error) {
+	prefix := strings.Join(strings.Fields(query), " ")
+	if len([]rune(prefix)) < 2 {
+		return []library.NameSuggestion{}, nil
+	}
+	suggestions, err := s.library.SuggestNames(ctx, prefix, limit, excludeExplicit)
+	if err != nil {
+		slog.Error("Suggest failed", "error", err)
+		return nil, err
+	}
+	return suggestions, nil
+}
+
 // SearchAlbums returns albums matching query using a single lightweight JOIN query.
 func (s *Service) SearchAlbums(ctx context.Context, query string, limit, offset int) ([]*library.Album, error) {
 	slog.Debug("SearchAlbums service called", "query", query, "limit", limit, "offset", offset)
@@ -366,8 +442,11 @@ func (s *Service) DeleteAlbum(ctx context.Context, id string) error {
 		return err
 	}
 
-	// Delete track files from filesystem
+	// Delete track files from filesystem, tracks that only appeared on the album are kept
 	for _, track := range albumTracks {
+		if track.Album == nil || track.Album.ID != id {
+			continue
+		}
 		if err := s.fileManager.DeleteTrack(ctx, track.Path); err != nil {
 			slog.Warn("Failed to delete track file from filesystem", "path", track.Path, "error", err)
 			// Don't return error here - database deletion succeeded, file deletion is secondary
@@ -392,6 +471,138 @@ func (s *Service) GetTrack(ctx context.Context, id string) (*library.Track, erro
 	return track, nil
 }
 
+// SetTrackLocked locks or unlocks a single track.
+func (s *Service) SetTrackLocked(ctx context.Context, id string, locked bool) error {
+	slog.Debug("SetTrackLocked service called", "id", id, "locked", locked)
+	track, err := s.library.GetTrack(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get track: %w", err)
+	}
+	if track == nil {
+		return fmt.Errorf("track not found: %s", id)
+	}
+	track.Attributes = setLockedAttribute(track.Attributes, locked)
+	if err := s.library.UpdateTrack(ctx, track); err != nil {
+		slog.Error("SetTrackLocked failed", "id", id, "error", err)
+		return err
+	}
+	return nil
+}
+
+// GetTrackAppearances returns the albums a track appears on besides its primary album.
+func (s *Service) GetTrackAppearances(ctx context.Context, trackID string) ([]*library.Album, error) {
+	albums, err := s.library.GetTrackAppearances(ctx, trackID)
+	if err != nil {
+		slog.Error("GetTrackAppearances failed", "trackID", trackID, "error", err)
+		return nil, err
+	}
+	return albums, nil
+}
+
+// AddTrackAppearance adds a track to another album, e.g. a compilation, without moving its file.
+func (s *Service) AddTrackAppearance(ctx context.Context, trackID, albumID string) error {
+	slog.Debug("AddTrackAppearance service called", "trackID", trackID, "albumID", albumID)
+	track, err := s.library.GetTrack(ctx, trackID)
+	if err != nil {
+		return fmt.Errorf("failed to get track: %w", err)
+	}
+	if track == nil {
+		return fmt.Errorf("track not found: %s", trackID)
+	}
+	album, err := s.library.GetAlbum(ctx, albumID)
+	if err != nil {
+		return fmt.Errorf("failed to get album: %w", err)
+	}
+	if album == nil {
+		return fmt.Errorf("album not found: %s", albumID)
+	}
+	if track.Album != nil && track.Album.ID == albumID {
+		return library.ErrPrimaryAlbum
+	}
+	return s.library.AddTrackAppearance(ctx, trackID, albumID)
+}
+
+// RemoveTrackAppearance removes a track from an album it appears on. The track and its file are kept.
+func (s *Service) RemoveTrackAppearance(ctx context.Context, trackID, albumID string) error {
+	slog.Debug("RemoveTrackAppearance service called", "trackID", trackID, "albumID", albumID)
+	return s.library.RemoveTrackAppearance(ctx, trackID, albumID)
+}
+
+// SetTrackPrimaryAlbum makes one of the albums a track appears on its primary album. Only the
+// database is changed, the file is moved under the new album by the next reorganization.
+func (s *Service) SetTrackPrimaryAlbum(ctx context.Context, trackID, albumID string) error {
+	slog.Debug("SetTrackPrimaryAlbum service called", "trackID", trackID, "albumID", albumID)
+	track, err := s.library.GetTrack(ctx, trackID)
+	if err != nil {
+		return fmt.Errorf("failed to get track: %w", err)
+	}
+	if track == nil {
+		return fmt.Errorf("track not found: %s", trackID)
+	}
+	if track.IsLocked() {
+		return library.ErrTrackLocked
+	}
+	return s.library.SetTrackPrimaryAlbum(ctx, trackID, albumID)
+}
+
+// SetAlbumLocked locks or unlocks an album, which also locks all of its tracks.
+func (s *Service) SetAlbumLocked(ctx context.Context, id string, locked bool) error {
+	slog.Debug("SetAlbumLocked service called", "id", id, "locked", locked)
+	album, err := s.library.GetAlbum(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get album: %w", err)
+	}
+	if album == nil {
+		return fmt.Errorf("album not found: %s", id)
+	}
+	album.Attributes = setLockedAttribute(album.Attributes, locked)
+	if err := s.library.UpdateAlbum(ctx, album); err != nil {
+		slog.Error("SetAlbumLocked failed", "id", id, "error", err)
+		return err
+	}
+	return nil
+}
+
+// SetAlbumNotes replaces the notes of an album, empty notes remove them.
+func (s *Service) SetAlbumNotes(ctx context.Context, id, notes string) error {
+	notes = strings.TrimSpace(notes)
+	if err := library.ValidateNotes(notes); err != nil {
+		return err
+	}
+	album, err := s.library.GetAlbum(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get album: %w", err)
+	}
+	if album == nil {
+		return fmt.Errorf("album not found: %s", id)
+	}
+	if album.Attributes == nil {
+		album.Attributes = make(map[string]string)
+	}
+	if notes != "" {
+		album.Attributes[library.NotesAttribute] = notes
+	} else {
+		delete(album.Attributes, library.NotesAttribute)
+	}
+	if err := s.library.UpdateAlbum(ctx, album); err != nil {
+		slog.Error("SetAlbumNotes failed", "id", id, "error", err)
+		return err
+	}
+	return nil
+}
+
+func setLockedAttribute(attributes map[string]string, locked bool) map[string]string {
+	if attributes == nil {
+		attributes = make(map[string]string)
+	}
+	if locked {
+		attributes[library.LockedAttribute] = "true"
+	} else {
+		delete(attributes, library.LockedAttribute)
+	}
+	return attributes
+}
+
 // GetLibraryTrackPath returns the file path of an imported library track.
 func (s *Service) GetLibraryTrackPath(ctx context.Context, trackID string) (string, error) {
 	track, err := s.library.GetTrack(ctx, trackID)
@@ -3,8 +3,10 @@ package library
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"strings"
 
+	library "github.com/contre95/soulsolid/src/music"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
@@ -39,9 +41,69 @@ func (h *TelegramHandler) GetCommands() map[string]string {
 	}
 }
 
-// HandleCallback handles callback queries for this feature (library has no callbacks)
+// HandleCallback handles the "Send audio" button of inline results
 func (h *TelegramHandler) HandleCallback(bot *tgbotapi.BotAPI, callback *tgbotapi.CallbackQuery) bool {
-	return false // Library feature doesn't handle any callbacks
+	trackID, ok := strings.CutPrefix(callback.Data, "lib_audio_")
+	if !ok {
+		return false // Not handled by this feature
+	}
+
+	track, err := h.service.GetTrack(context.Background(), trackID)
+	if err != nil {
+		bot.Request(tgbotapi.NewCallbackWithAlert(callback.ID, "❌ Track not found"))
+		return true
+	}
+
+	// Inline messages may live in chats the bot isn't part of, so the file goes to the private chat
+	audio := tgbotapi.NewAudio(callback.From.ID, tgbotapi.FilePath(track.Path))
+	audio.Title = track.Title
+	audio.Performer = telegramArtists(track.Artists)
+	audio.Duration = track.Metadata.Duration
+	if _, err := bot.Send(audio); err != nil {
+		slog.Error("Failed to send track audio", "trackID", trackID, "error", err)
+		bot.Request(tgbotapi.NewCallbackWithAlert(callback.ID, "❌ Failed to send the track, make sure you started a chat with the bot"))
+		return true
+	}
+	bot.Request(tgbotapi.NewCallback(callback.ID, "🎧 Sent to your chat with the bot"))
+	return true
+}
+
+// HandleInlineQuery returns the library tracks matching query as inline articles
+func (h *TelegramHandler) HandleInlineQuery(query string) ([]any, error) {
+	tracks, err := h.service.GetTracksFilteredPaginated(context.Background(), 20, 0, &library.TrackFilter{TextSearch: query})
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]any, 0, len(tracks))
+	for _, track := range tracks {
+		artists := telegramArtists(track.Artists)
+		description := artists
+		text := fmt.Sprintf("🎵 %s\n👤 %s", track.Title, artists)
+		if track.Album != nil && track.Album.Title != "" {
+			description += " — " + track.Album.Title
+			text += "\n💿 " + track.Album.Title
+		}
+		article := tgbotapi.NewInlineQueryResultArticle("lib_"+track.ID, track.Title, text)
+		article.Description = description
+		keyboard := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🎧 Send audio", "lib_audio_"+track.ID),
+		))
+		article.ReplyMarkup = &keyboard
+		results = append(results, article)
+	}
+	return results, nil
+}
+
+// telegramArtists joins the artist names of roles
+func telegramArtists(roles []library.ArtistRole) string {
+	names := make([]string, 0, len(roles))
+	for _, role := range roles {
+		if role.Artist != nil {
+			names = append(names, role.Artist.Name)
+		}
+	}
+	return strings.Join(names, ", ")
 }
 
 // handleStats shows library statistics
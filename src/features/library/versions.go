@@ -0,0 +1,94 @@
+package library
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	library "github.com/contre95/soulsolid/src/music"
+)
+
+// maxArtistTracks bounds the tracks of an artist looked at when grouping versions.
+const maxArtistTracks = 10000
+
+// SongVersions are the tracks of an artist that are versions of the same song, e.g. the
+// studio recording, a live take and a remaster.
+type SongVersions struct {
+	Title  string           `json:"title"`
+	Tracks []*library.Track `json:"tracks"`
+}
+
+// GetTrackVersions returns every version of the track's song by the same artists, the track included.
+func (s *Service) GetTrackVersions(ctx context.Context, trackID string) ([]*library.Track, error) {
+	track, err := s.library.GetTrack(ctx, trackID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get track: %w", err)
+	}
+	if track == nil {
+		return nil, fmt.Errorf("track not found: %s", trackID)
+	}
+	var artistIDs []string
+	for _, role := range track.Artists {
+		if role.Artist != nil && role.Role == "main" {
+			artistIDs = append(artistIDs, role.Artist.ID)
+		}
+	}
+	if len(artistIDs) == 0 {
+		return []*library.Track{track}, nil
+	}
+	title, _ := library.ParseTitleVersion(track.Title)
+	candidates, err := s.library.GetTracksFilteredPaginated(ctx, maxArtistTracks, 0, &library.TrackFilter{Title: title, ArtistIDs: artistIDs})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tracks: %w", err)
+	}
+	key := track.SongKey()
+	versions := []*library.Track{}
+	for _, candidate := range candidates {
+		if candidate.SongKey() == key {
+			versions = append(versions, candidate)
+		}
+	}
+	sortVersions(versions)
+	return versions, nil
+}
+
+// GetArtistVersions returns the songs of an artist the library holds more than one version of.
+func (s *Service) GetArtistVersions(ctx context.Context, artistID string) ([]SongVersions, error) {
+	tracks, err := s.library.GetTracksFilteredPaginated(ctx, maxArtistTracks, 0, &library.TrackFilter{ArtistIDs: []string{artistID}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tracks: %w", err)
+	}
+	groups := map[string]*SongVersions{}
+	var keys []string
+	for _, track := range tracks {
+		key := track.SongKey()
+		group, ok := groups[key]
+		if !ok {
+			title, _ := library.ParseTitleVersion(track.Title)
+			group = &SongVersions{Title: title}
+			groups[key] = group
+			keys = append(keys, key)
+		}
+		group.Tracks = append(group.Tracks, track)
+	}
+	slices.Sort(keys)
+	songs := []SongVersions{}
+	for _, key := range keys {
+		if group := groups[key]; len(group.Tracks) > 1 {
+			sortVersions(group.Tracks)
+			songs = append(songs, *group)
+		}
+	}
+	return songs, nil
+}
+
+// sortVersions puts the original first, then the versions by name and year.
+func sortVersions(tracks []*library.Track) {
+	slices.SortStableFunc(tracks, func(a, b *library.Track) int {
+		if c := strings.Compare(strings.ToLower(a.TitleVersion), strings.ToLower(b.TitleVersion)); c != 0 {
+			return c
+		}
+		return a.Metadata.Year - b.Metadata.Year
+	})
+}
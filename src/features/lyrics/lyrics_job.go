@@ -110,6 +110,14 @@ func (t *LyricsJobTask) Execute(ctx context.Context, job *music.Job, progressUpd
 			skipExisting, _ := job.Metadata["skip_existing"].(bool)
 			overrideNoQueue, _ := job.Metadata["override_no_queue"].(bool)
 
+			// Locked tracks keep their hand-curated lyrics
+			if track.IsLocked() {
+				job.Logger.Info("Track is locked - skipping", "trackID", track.ID, "title", track.Title, "color", "gray")
+				skipped++
+				processed++
+				continue
+			}
+
 			// Skip tracks that already have lyrics if option is enabled.
 			// Skips instrumentals (has_lyrics=false) and tracks with existing lyrics content.
 			if skipExisting && (!track.HasLyrics || track.Metadata.Lyrics != "") {
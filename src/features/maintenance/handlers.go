@@ -0,0 +1,50 @@
+package maintenance
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/contre95/soulsolid/src/features/hosting/respond"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Handler handles HTTP requests for the maintenance feature.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new maintenance handler.
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// GetStatus renders the maintenance banner shown on every page, empty while maintenance mode is off.
+func (h *Handler) GetStatus(c *fiber.Ctx) error {
+	return respond.Partial(c, "maintenance/banner", fiber.Map{"Status": h.service.GetStatus()})
+}
+
+// GetPanel renders the maintenance mode toggle of the settings section.
+func (h *Handler) GetPanel(c *fiber.Ctx) error {
+	return respond.Partial(c, "cards/maintenance", fiber.Map{"Status": h.service.GetStatus()})
+}
+
+// Enable turns maintenance mode on, with an optional reason shown in the banner.
+func (h *Handler) Enable(c *fiber.Ctx) error {
+	reason := strings.TrimSpace(c.FormValue("reason"))
+	slog.Info("Enabling maintenance mode", "reason", reason)
+	if err := h.service.Enable(reason); err != nil {
+		return respond.ToastErr(c, fiber.StatusConflict, err.Error())
+	}
+	c.Set("HX-Trigger", "maintenanceChanged")
+	return respond.ToastOk(c, "Maintenance mode on")
+}
+
+// Disable turns maintenance mode off.
+func (h *Handler) Disable(c *fiber.Ctx) error {
+	slog.Info("Disabling maintenance mode")
+	if err := h.service.Disable(); err != nil {
+		return respond.ToastErr(c, fiber.StatusConflict, err.Error())
+	}
+	c.Set("HX-Trigger", "maintenanceChanged")
+	return respond.ToastOk(c, "Maintenance mode off, everything resumed")
+}
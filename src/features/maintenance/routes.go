@@ -0,0 +1,13 @@
+package maintenance
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// RegisterRoutes registers the maintenance routes with the Fiber app.
+func RegisterRoutes(app *fiber.App, handler *Handler) {
+	app.Get("/maintenance", handler.GetStatus)
+	app.Get("/maintenance/panel", handler.GetPanel)
+	app.Post("/maintenance/enable", handler.Enable)
+	app.Post("/maintenance/disable", handler.Disable)
+}
@@ -0,0 +1,133 @@
+package maintenance
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/contre95/soulsolid/src/music"
+)
+
+var (
+	// ErrAlreadyEnabled is returned when maintenance mode is turned on twice.
+	ErrAlreadyEnabled = errors.New("maintenance mode is already on")
+	// ErrNotEnabled is returned when maintenance mode is turned off while it's off.
+	ErrNotEnabled = errors.New("maintenance mode is not on")
+)
+
+// Pausable is a scheduler, watcher or queue that stops while maintenance mode is on.
+type Pausable interface {
+	Pause()
+	Resume()
+}
+
+// Notifier sends a message to the user, e.g. the Telegram bot.
+type Notifier interface {
+	Notify(message string)
+}
+
+// JobLister lists the jobs, to tell whether one is still running when maintenance mode starts.
+type JobLister interface {
+	GetJobs() []*music.Job
+}
+
+// Status is the state of maintenance mode.
+type Status struct {
+	Enabled     bool      `json:"enabled"`
+	Reason      string    `json:"reason,omitempty"`
+	Since       time.Time `json:"since,omitzero"`
+	RunningJobs int       `json:"running_jobs"` // jobs started before maintenance mode that haven't finished yet
+}
+
+// Service turns maintenance mode on and off, pausing and resuming every registered Pausable.
+type Service struct {
+	mu        sync.Mutex
+	enabled   bool
+	reason    string
+	since     time.Time
+	jobs      JobLister
+	pausables []Pausable
+	notifiers []Notifier
+}
+
+// NewService creates a maintenance service pausing the given pausables, in order. They are
+// resumed in the reverse order, so the job queue should come first to stop before and restart
+// after the watchers that feed it.
+func NewService(jobs JobLister, pausables ...Pausable) *Service {
+	return &Service{jobs: jobs, pausables: pausables}
+}
+
+// AddNotifier registers a notifier told when maintenance mode starts and ends.
+func (s *Service) AddNotifier(n Notifier) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.notifiers = append(s.notifiers, n)
+}
+
+// GetStatus returns the current state of maintenance mode.
+func (s *Service) GetStatus() Status {
+	s.mu.Lock()
+	status := Status{Enabled: s.enabled, Reason: s.reason, Since: s.since}
+	s.mu.Unlock()
+	if status.Enabled {
+		for _, job := range s.jobs.GetJobs() {
+			if job.Status == music.JobStatusRunning {
+				status.RunningJobs++
+			}
+		}
+	}
+	return status
+}
+
+// Enable turns maintenance mode on: new jobs are refused, pending jobs stay queued and the
+// watchers and schedulers stop. A job already running is left to finish.
+func (s *Service) Enable(reason string) error {
+	s.mu.Lock()
+	if s.enabled {
+		s.mu.Unlock()
+		return ErrAlreadyEnabled
+	}
+	s.enabled, s.reason, s.since = true, reason, time.Now()
+	for _, p := range s.pausables {
+		p.Pause()
+	}
+	s.mu.Unlock()
+	slog.Warn("Maintenance mode on", "reason", reason)
+	message := "🛠️ Maintenance mode is on, jobs, watchers and schedulers are paused"
+	if reason != "" {
+		message += ": " + reason
+	}
+	s.notify(message)
+	return nil
+}
+
+// Disable turns maintenance mode off, resuming everything Enable paused and starting the jobs
+// queued in the meantime.
+func (s *Service) Disable() error {
+	s.mu.Lock()
+	if !s.enabled {
+		s.mu.Unlock()
+		return ErrNotEnabled
+	}
+	duration := time.Since(s.since).Round(time.Second)
+	s.enabled, s.reason, s.since = false, "", time.Time{}
+	for _, p := range slices.Backward(s.pausables) {
+		p.Resume()
+	}
+	s.mu.Unlock()
+	slog.Info("Maintenance mode off", "duration", duration)
+	s.notify(fmt.Sprintf("✅ Maintenance mode is off after %s, everything is running again", duration))
+	return nil
+}
+
+func (s *Service) notify(message string) {
+	s.mu.Lock()
+	notifiers := append([]Notifier(nil), s.notifiers...)
+	s.mu.Unlock()
+	for _, n := range notifiers {
+		n.Notify(message)
+	}
+}
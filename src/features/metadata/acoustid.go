@@ -10,4 +10,21 @@ type ChromaprintAcoustID interface {
 	CompareChromaprints(cp1, cp2 string) (float64, error)
 	// LookupAcoustID looks up AcoustID using a chromaprint fingerprint and duration
 	LookupAcoustID(ctx context.Context, chromaprint string, duration int) (string, error)
+	// LookupRecordings looks up the AcoustID matches of a chromaprint with their recordings
+	LookupRecordings(ctx context.Context, chromaprint string, duration int) ([]AcoustIDMatch, error)
+}
+
+// AcoustIDMatch is an AcoustID matching a fingerprint, with the recordings linked to it
+type AcoustIDMatch struct {
+	ID         string              `json:"id"`
+	Score      float64             `json:"score"`
+	Recordings []AcoustIDRecording `json:"recordings,omitempty"`
+}
+
+// AcoustIDRecording is a MusicBrainz recording linked to an AcoustID
+type AcoustIDRecording struct {
+	ID       string   `json:"id"`
+	Title    string   `json:"title,omitempty"`
+	Artists  []string `json:"artists,omitempty"`
+	Duration int      `json:"duration,omitempty"`
 }
@@ -79,6 +79,13 @@ func (t *AcoustIDJobTask) Execute(ctx context.Context, job *music.Job, progressU
 			progress := (processed * 100) / totalTracks
 			progressUpdater(progress, fmt.Sprintf("Processing track %d/%d: %s", processed+1, totalTracks, track.Title))
 
+			// Locked tracks must not have their tags rewritten
+			if track.IsLocked() {
+				job.Logger.Info("Skipping locked track", "trackID", track.ID, "title", track.Title, "color", "gray")
+				skipped++
+				continue
+			}
+
 			// Skip tracks that already have AcoustID
 			acoustID := ""
 			if track.Attributes != nil {
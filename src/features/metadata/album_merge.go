@@ -0,0 +1,292 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/contre95/soulsolid/src/music"
+)
+
+// AlbumCandidate is one of the albums of a duplicate group.
+type AlbumCandidate struct {
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	Year      int       `json:"year,omitempty"`
+	Tracks    int       `json:"tracks"`
+	Locked    bool      `json:"locked"`
+	AddedDate time.Time `json:"-"`
+}
+
+// DuplicateAlbums is a group of albums that look like the same release. The first album is the
+// one to keep, the others are merged into it.
+type DuplicateAlbums struct {
+	Title  string           `json:"title"`
+	Artist string           `json:"artist"`
+	Albums []AlbumCandidate `json:"albums"`
+}
+
+// FindDuplicateAlbums groups the albums with the same artists and the same title once casing,
+// punctuation and spacing are ignored. Albums from different years are different releases, so
+// groups with more than one known year are left out.
+func (s *Service) FindDuplicateAlbums(ctx context.Context) ([]DuplicateAlbums, error) {
+	albums, err := s.libraryRepo.GetAlbums(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get albums: %w", err)
+	}
+	groups := map[string][]*music.Album{}
+	var keys []string
+	for _, album := range albums {
+		title := albumMergeKey(album.Title)
+		if title == "" {
+			continue
+		}
+		var artistIDs []string
+		for _, role := range album.Artists {
+			if role.Artist != nil && (role.Role == "main" || role.Role == "") {
+				artistIDs = append(artistIDs, role.Artist.ID)
+			}
+		}
+		sort.Strings(artistIDs)
+		key := title + "\x00" + strings.Join(artistIDs, ",")
+		if groups[key] == nil {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], album)
+	}
+
+	duplicates := []DuplicateAlbums{}
+	for _, key := range keys {
+		group := groups[key]
+		if len(group) < 2 {
+			continue
+		}
+		years := map[int]bool{}
+		for _, album := range group {
+			if !album.ReleaseDate.IsZero() {
+				years[album.ReleaseDate.Year()] = true
+			}
+		}
+		if len(years) > 1 {
+			continue
+		}
+		dup := DuplicateAlbums{}
+		for _, album := range group {
+			candidate := AlbumCandidate{ID: album.ID, Title: album.Title, Tracks: album.TrackCount, Locked: album.IsLocked(), AddedDate: album.AddedDate}
+			if !album.ReleaseDate.IsZero() {
+				candidate.Year = album.ReleaseDate.Year()
+			}
+			dup.Albums = append(dup.Albums, candidate)
+		}
+		sort.SliceStable(dup.Albums, func(i, j int) bool { return keepBefore(dup.Albums[i], dup.Albums[j]) })
+		dup.Title = dup.Albums[0].Title
+		if len(group[0].Artists) > 0 && group[0].Artists[0].Artist != nil {
+			dup.Artist = group[0].Artists[0].Artist.Name
+		}
+		duplicates = append(duplicates, dup)
+	}
+	sort.Slice(duplicates, func(i, j int) bool {
+		return strings.ToLower(duplicates[i].Artist+duplicates[i].Title) < strings.ToLower(duplicates[j].Artist+duplicates[j].Title)
+	})
+	return duplicates, nil
+}
+
+// keepBefore orders the candidates of a group by how good they are to keep: locked albums first,
+// then albums with a year, with the most tracks, and the oldest.
+func keepBefore(a, b AlbumCandidate) bool {
+	if a.Locked != b.Locked {
+		return a.Locked
+	}
+	if (a.Year != 0) != (b.Year != 0) {
+		return a.Year != 0
+	}
+	if a.Tracks != b.Tracks {
+		return a.Tracks > b.Tracks
+	}
+	return a.AddedDate.Before(b.AddedDate)
+}
+
+// albumMergeKey reduces an album title to lowercase letters and digits separated by single spaces.
+func albumMergeKey(title string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(title) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			space = false
+			b.WriteRune(r)
+		default:
+			space = true
+		}
+	}
+	return b.String()
+}
+
+// MergeAlbums merges the duplicate album into the kept one and deletes it. The kept album fills
+// its empty fields, artists and attributes from the duplicate, the duplicate's tracks are moved to
+// it and retagged unless locked, and its cover is embedded in the kept tracks that have none. Files
+// stay where they are until the next reorganization. It returns how many tracks were moved.
+func (s *Service) MergeAlbums(ctx context.Context, keepID, duplicateID string) (int, error) {
+	if keepID == duplicateID {
+		return 0, fmt.Errorf("cannot merge an album into itself")
+	}
+	keep, err := s.libraryRepo.GetAlbum(ctx, keepID)
+	if err != nil || keep == nil {
+		return 0, fmt.Errorf("album not found: %s", keepID)
+	}
+	duplicate, err := s.libraryRepo.GetAlbum(ctx, duplicateID)
+	if err != nil || duplicate == nil {
+		return 0, fmt.Errorf("album not found: %s", duplicateID)
+	}
+	if duplicate.IsLocked() {
+		return 0, fmt.Errorf("%w: unlock %q to merge it", music.ErrTrackLocked, duplicate.Title)
+	}
+
+	keptTracks, err := s.albumTracks(ctx, keep.ID)
+	if err != nil {
+		return 0, err
+	}
+	duplicateTracks, err := s.albumTracks(ctx, duplicate.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	// Carry the duplicate's cover over when the kept album has none
+	var cover []byte
+	if primary := primaryTracks(keptTracks, keep.ID); len(primary) > 0 {
+		if data, _, err := s.tagReader.ReadArtwork(primary[0].Path); err != nil || len(data) == 0 {
+			for _, track := range primaryTracks(duplicateTracks, duplicate.ID) {
+				if data, _, err := s.tagReader.ReadArtwork(track.Path); err == nil && len(data) > 0 {
+					cover = data
+					break
+				}
+			}
+		}
+	}
+
+	mergeAlbumFields(keep, duplicate)
+	keep.ModifiedDate = time.Now()
+
+	// The file tags are written first, the library is then updated in one transaction so a failed
+	// merge leaves both albums as they were
+	var moved []*music.Track
+	for _, dup := range primaryTracks(duplicateTracks, duplicate.ID) {
+		track, err := s.trackOnAlbum(ctx, dup.ID, keep)
+		if err != nil {
+			return 0, err
+		}
+		moved = append(moved, track)
+	}
+	if err := s.libraryRepo.MergeAlbum(ctx, keep, moved, duplicate.ID); err != nil {
+		return 0, fmt.Errorf("failed to merge album: %w", err)
+	}
+
+	if len(cover) > 0 {
+		for _, track := range primaryTracks(keptTracks, keep.ID) {
+			if track.IsLocked() {
+				continue
+			}
+			if err := s.SetTrackArtwork(ctx, track.ID, cover); err != nil {
+				slog.Warn("Failed to embed merged album cover", "trackID", track.ID, "error", err)
+			}
+		}
+	}
+
+	slog.Info("Merged duplicate album", "kept", keep.ID, "title", keep.Title, "deleted", duplicate.ID, "tracks", len(moved))
+	return len(moved), nil
+}
+
+// trackOnAlbum returns the track with album as its primary album, rewriting its file tags unless
+// the track is locked. The library is left to the caller.
+func (s *Service) trackOnAlbum(ctx context.Context, trackID string, album *music.Album) (*music.Track, error) {
+	track, err := s.GetTrackFileTags(ctx, trackID)
+	if err != nil {
+		return nil, err
+	}
+	track.Album = album
+	if track.Metadata.Year == 0 && !album.ReleaseDate.IsZero() {
+		track.Metadata.Year = album.ReleaseDate.Year()
+	}
+	if !track.IsLocked() {
+		if err := s.tagWriter.WriteFileTags(ctx, track.Path, track); err != nil {
+			return nil, fmt.Errorf("failed to write tags of track %s: %w", trackID, err)
+		}
+	}
+	track.ModifiedDate = time.Now()
+	return track, nil
+}
+
+// albumTracks returns the tracks of an album, appearances included.
+func (s *Service) albumTracks(ctx context.Context, albumID string) ([]*music.Track, error) {
+	filter := &music.TrackFilter{AlbumIDs: []string{albumID}}
+	count, err := s.libraryRepo.GetTracksFilteredCount(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count album tracks: %w", err)
+	}
+	tracks, err := s.libraryRepo.GetTracksFilteredPaginated(ctx, count, 0, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get album tracks: %w", err)
+	}
+	return tracks, nil
+}
+
+// primaryTracks returns the tracks whose primary album is albumID.
+func primaryTracks(tracks []*music.Track, albumID string) []*music.Track {
+	return slices.DeleteFunc(slices.Clone(tracks), func(t *music.Track) bool { return t.Album == nil || t.Album.ID != albumID })
+}
+
+// mergeAlbumFields fills the empty fields of keep from duplicate and adds its missing artists and
+// attributes. Values already set on keep win.
+func mergeAlbumFields(keep, duplicate *music.Album) {
+	if keep.Type == "" {
+		keep.Type = duplicate.Type
+	}
+	if keep.ReleaseDate.IsZero() {
+		keep.ReleaseDate = duplicate.ReleaseDate
+	}
+	for _, field := range []struct {
+		keep      *string
+		duplicate string
+	}{
+		{&keep.ReleaseGroupID, duplicate.ReleaseGroupID},
+		{&keep.Label, duplicate.Label},
+		{&keep.CatalogNumber, duplicate.CatalogNumber},
+		{&keep.Country, duplicate.Country},
+		{&keep.Status, duplicate.Status},
+		{&keep.Barcode, duplicate.Barcode},
+		{&keep.Genre, duplicate.Genre},
+	} {
+		if *field.keep == "" {
+			*field.keep = field.duplicate
+		}
+	}
+	for _, role := range duplicate.Artists {
+		if role.Artist == nil {
+			continue
+		}
+		if !slices.ContainsFunc(keep.Artists, func(r music.ArtistRole) bool {
+			return r.Artist != nil && r.Artist.ID == role.Artist.ID && r.Role == role.Role
+		}) {
+			keep.Artists = append(keep.Artists, role)
+		}
+	}
+	for key, value := range duplicate.Attributes {
+		if key == music.LockedAttribute {
+			continue
+		}
+		if keep.Attributes == nil {
+			keep.Attributes = map[string]string{}
+		}
+		if _, ok := keep.Attributes[key]; !ok {
+			keep.Attributes[key] = value
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/contre95/soulsolid/src/music"
+)
+
+// maxArtworkBytes caps the size of covers that are uploaded or fetched from providers.
+const maxArtworkBytes = 10 << 20
+
+// artworkClient fetches covers from public addresses only, the URLs come from forms and
+// providers so they mustn't reach the host or the local network, redirects included.
+var artworkClient = &http.Client{
+	Transport: &http.Transport{
+		DialContext:         (&net.Dialer{Timeout: 10 * time.Second, Control: dialPublicOnly}).DialContext,
+		TLSHandshakeTimeout: 10 * time.Second,
+	},
+}
+
+// dialPublicOnly refuses connections to loopback, private, link-local and unspecified addresses.
+// It runs once the host is resolved, so a name resolving to one of them is refused too.
+func dialPublicOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	ip = ip.Unmap()
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified() {
+		return fmt.Errorf("artwork address %s is not public", ip)
+	}
+	return nil
+}
+
+// SetTrackArtwork embeds a cover in a single track's file. Unlike album artwork it only affects this
+// track, which is how singles and other tracks without an album get a cover.
+func (s *Service) SetTrackArtwork(ctx context.Context, trackID string, data []byte) error {
+	if err := validateArtwork(data); err != nil {
+		return err
+	}
+	track, err := s.GetTrackFileTags(ctx, trackID)
+	if err != nil {
+		return err
+	}
+	if track.IsLocked() {
+		return fmt.Errorf("%w: unlock it to change its artwork", music.ErrTrackLocked)
+	}
+	track.ArtworkData = data
+	if err := s.tagWriter.WriteFileTags(ctx, track.Path, track); err != nil {
+		return fmt.Errorf("failed to embed artwork: %w", err)
+	}
+	slog.Info("Embedded track artwork", "trackID", trackID, "bytes", len(data))
+	return nil
+}
+
+// FetchTrackArtwork downloads the cover at imageURL, e.g. a provider's thumbnail, and embeds it in the track.
+func (s *Service) FetchTrackArtwork(ctx context.Context, trackID, imageURL string) error {
+	data, err := fetchArtwork(ctx, imageURL)
+	if err != nil {
+		return err
+	}
+	return s.SetTrackArtwork(ctx, trackID, data)
+}
+
+// StartArtworkUpgrade starts a job that replaces album covers below the configured size with larger ones from the providers.
+func (s *Service) StartArtworkUpgrade(ctx context.Context) (string, error) {
+	jobID, err := s.jobService.StartJob("upgrade_artwork", "Upgrade Album Artwork", map[string]any{})
+	if err != nil {
+		return "", fmt.Errorf("failed to start artwork upgrade job: %w", err)
+	}
+	return jobID, nil
+}
+
+// fetchArtwork downloads an image from an http(s) URL on a public address.
+func fetchArtwork(ctx context.Context, imageURL string) ([]byte, error) {
+	if !strings.HasPrefix(imageURL, "http://") && !strings.HasPrefix(imageURL, "https://") {
+		return nil, fmt.Errorf("invalid artwork URL %q", imageURL)
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, imageURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create artwork request: %w", err)
+	}
+	req.Header.Set("User-Agent", "SoulSolid/1.0")
+	resp, err := artworkClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch artwork: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch artwork: %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxArtworkBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read artwork: %w", err)
+	}
+	return data, validateArtwork(data)
+}
+
+func validateArtwork(data []byte) error {
+	if len(data) == 0 {
+		return fmt.Errorf("artwork is empty")
+	}
+	if len(data) > maxArtworkBytes {
+		return fmt.Errorf("artwork exceeds %d MB", maxArtworkBytes>>20)
+	}
+	if mimeType := http.DetectContentType(data); !strings.HasPrefix(mimeType, "image/") {
+		return fmt.Errorf("artwork is not an image (%s)", mimeType)
+	}
+	return nil
+}
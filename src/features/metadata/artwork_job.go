@@ -0,0 +1,329 @@
+package metadata
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	_ "image/jpeg" // register decoders for image.DecodeConfig
+	_ "image/png"
+	"log/slog"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/contre95/soulsolid/src/features/jobs"
+	"github.com/contre95/soulsolid/src/music"
+)
+
+// defaultArtworkMinSize is used when downloaders.artwork.upgrade.min_size is not set.
+const defaultArtworkMinSize = 600
+
+// maxArtworkCandidates bounds how many covers are downloaded per provider for one album.
+const maxArtworkCandidates = 3
+
+// folderCoverNames are the sidecar images checked for an album's current cover, best first.
+var folderCoverNames = []string{"folder.jpg", "folder.png", "cover.jpg", "cover.png"}
+
+// ArtworkUpgrade is an album whose cover was replaced by a larger one.
+type ArtworkUpgrade struct {
+	AlbumID  string `json:"albumId"`
+	Album    string `json:"album"`
+	Artist   string `json:"artist"`
+	OldSize  string `json:"oldSize"` // e.g. "300x300", empty when the album had no cover
+	NewSize  string `json:"newSize"`
+	Provider string `json:"provider"`
+	Tracks   int    `json:"tracks"` // tracks the new cover was embedded in
+}
+
+// ArtworkUpgradeReport lists the albums upgraded by an artwork upgrade job.
+type ArtworkUpgradeReport struct {
+	JobID      string           `json:"jobId"`
+	MinSize    int              `json:"minSize"`
+	StartedAt  time.Time        `json:"startedAt"`
+	FinishedAt time.Time        `json:"finishedAt"`
+	Checked    int              `json:"checked"`
+	NotFound   int              `json:"notFound"` // low resolution albums no provider had a larger cover for
+	Errors     int              `json:"errors"`
+	Upgraded   []ArtworkUpgrade `json:"upgraded"`
+}
+
+var artworkReportTemplate = jobs.NewReportTemplate(`{{ define "title" }}Artwork upgrade report {{ .JobID }}{{ end }}
+{{ define "body" }}<h1>Artwork upgrade report</h1>
+<p><strong>Job:</strong> {{ .JobID }}<br>
+<strong>Minimum size:</strong> {{ .MinSize }}px<br>
+<strong>Started:</strong> {{ .StartedAt.Format "2006-01-02 15:04:05" }}<br>
+<strong>Finished:</strong> {{ .FinishedAt.Format "2006-01-02 15:04:05" }}</p>
+<p>{{ len .Upgraded }} upgraded, {{ .NotFound }} without a larger cover, {{ .Errors }} errors, {{ .Checked }} albums checked</p>
+<table>
+<tr><th>Album</th><th>Artist</th><th>Old</th><th>New</th><th>Provider</th><th>Tracks</th></tr>
+{{ range .Upgraded }}<tr><td>{{ .Album }}</td><td>{{ .Artist }}</td><td>{{ or .OldSize "none" }}</td><td>{{ .NewSize }}</td><td>{{ .Provider }}</td><td>{{ .Tracks }}</td></tr>
+{{ end }}</table>
+{{ end }}`)
+
+// Save writes the report as JSON and HTML into dir and returns both paths.
+func (r *ArtworkUpgradeReport) Save(dir string) (string, string, error) {
+	return jobs.SaveReport(dir, fmt.Sprintf("%s-%s-artwork", r.StartedAt.Format("2006-01-02"), r.JobID), r, artworkReportTemplate)
+}
+
+// ArtworkUpgradeJobTask replaces low resolution album covers with larger ones from the metadata providers
+type ArtworkUpgradeJobTask struct {
+	service *Service
+}
+
+// NewArtworkUpgradeJobTask creates a new artwork upgrade job task
+func NewArtworkUpgradeJobTask(service *Service) *ArtworkUpgradeJobTask {
+	return &ArtworkUpgradeJobTask{
+		service: service,
+	}
+}
+
+// MetadataKeys returns the required metadata keys for artwork upgrade jobs
+func (t *ArtworkUpgradeJobTask) MetadataKeys() []string {
+	return []string{}
+}
+
+// Execute checks the cover of every album and upgrades the ones below the configured size
+func (t *ArtworkUpgradeJobTask) Execute(ctx context.Context, job *music.Job, progressUpdater func(int, string)) (map[string]any, error) {
+	cfg := t.service.configManager.Get()
+	minSize := cfg.Downloaders.Artwork.Upgrade.MinSize
+	if minSize <= 0 {
+		minSize = defaultArtworkMinSize
+	}
+	albums, err := t.service.libraryRepo.GetAlbums(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get albums: %w", err)
+	}
+	job.Logger.Info("Starting artwork upgrade", "albums", len(albums), "minSize", minSize, "color", "blue")
+
+	report := &ArtworkUpgradeReport{JobID: job.ID, MinSize: minSize, StartedAt: time.Now(), Upgraded: []ArtworkUpgrade{}}
+	for i, album := range albums {
+		if err := ctx.Err(); err != nil {
+			job.Logger.Info("Artwork upgrade cancelled", "checked", report.Checked, "upgraded", len(report.Upgraded), "color", "orange")
+			return nil, err
+		}
+		progressUpdater((i*100)/len(albums), fmt.Sprintf("Checking album %d/%d: %s", i+1, len(albums), album.Title))
+		report.Checked++
+		upgrade, err := t.upgradeAlbum(ctx, job, album, minSize)
+		switch {
+		case err != nil:
+			job.Logger.Warn("Failed to upgrade album artwork", "albumID", album.ID, "album", album.Title, "error", err, "color", "red")
+			report.Errors++
+		case upgrade != nil && upgrade.Provider == "":
+			report.NotFound++
+		case upgrade != nil:
+			job.Logger.Info("Upgraded album artwork", "album", album.Title, "old", upgrade.OldSize, "new", upgrade.NewSize, "provider", upgrade.Provider, "color", "green")
+			report.Upgraded = append(report.Upgraded, *upgrade)
+		}
+	}
+
+	report.FinishedAt = time.Now()
+	finalMsg := fmt.Sprintf("Artwork upgrade completed: %d album(s) upgraded, %d without a larger cover, %d errors", len(report.Upgraded), report.NotFound, report.Errors)
+	job.Logger.Info("Artwork upgrade completed", "upgraded", len(report.Upgraded), "notFound", report.NotFound, "errors", report.Errors, "color", "green")
+	progressUpdater(100, fmt.Sprintf("Done — %d album(s) upgraded, %d errors", len(report.Upgraded), report.Errors))
+
+	result := map[string]any{
+		"checked":  report.Checked,
+		"upgraded": len(report.Upgraded),
+		"notFound": report.NotFound,
+		"errors":   report.Errors,
+		"msg":      finalMsg,
+	}
+	if jsonPath, htmlPath, err := report.Save(filepath.Join(cfg.Jobs.LogPath, "reports")); err != nil {
+		job.Logger.Warn("Failed to save artwork upgrade report", "error", err)
+	} else {
+		result["report_json"] = jsonPath
+		result["report_html"] = htmlPath
+	}
+	if report.Errors > 0 {
+		return result, fmt.Errorf("%w: %d album(s) failed", music.ErrJobPartialSuccess, report.Errors)
+	}
+	return result, nil
+}
+
+// upgradeAlbum returns nil when the album cover is large enough, an upgrade without a provider
+// when no provider had a larger one, and the applied upgrade otherwise.
+func (t *ArtworkUpgradeJobTask) upgradeAlbum(ctx context.Context, job *music.Job, album *music.Album, minSize int) (*ArtworkUpgrade, error) {
+	tracks, err := t.service.libraryRepo.GetTracksFilteredPaginated(ctx, 1000, 0, &music.TrackFilter{AlbumIDs: []string{album.ID}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get album tracks: %w", err)
+	}
+	// Tracks appearing on the album keep the cover of their primary album
+	tracks = slices.DeleteFunc(tracks, func(track *music.Track) bool { return track.Album == nil || track.Album.ID != album.ID })
+	if len(tracks) == 0 {
+		return nil, nil
+	}
+
+	current := t.currentCoverSize(tracks[0].Path)
+	if min(current.X, current.Y) >= minSize {
+		return nil, nil
+	}
+	upgrade := &ArtworkUpgrade{AlbumID: album.ID, Album: album.Title, Artist: albumArtist(tracks[0])}
+	if current.X > 0 {
+		upgrade.OldSize = fmt.Sprintf("%dx%d", current.X, current.Y)
+	}
+
+	data, size, provider := t.findLargerCover(ctx, job, tracks[0], current)
+	if data == nil {
+		job.Logger.Info("No larger cover found", "album", album.Title, "current", upgrade.OldSize, "color", "orange")
+		return upgrade, nil
+	}
+	upgrade.NewSize = fmt.Sprintf("%dx%d", size.X, size.Y)
+	upgrade.Provider = provider
+
+	// The full resolution cover is kept next to the files, the embedded copy follows the embedded artwork settings
+	if err := writeFolderCover(filepath.Dir(tracks[0].Path), data); err != nil {
+		return nil, err
+	}
+	for _, track := range tracks {
+		if track.IsLocked() {
+			continue
+		}
+		if err := t.service.SetTrackArtwork(ctx, track.ID, data); err != nil {
+			return nil, fmt.Errorf("failed to embed artwork in %s: %w", track.Path, err)
+		}
+		upgrade.Tracks++
+	}
+	return upgrade, nil
+}
+
+// currentCoverSize returns the size of the largest of the embedded cover and the folder sidecar.
+func (t *ArtworkUpgradeJobTask) currentCoverSize(trackPath string) image.Point {
+	var best image.Point
+	if data, _, err := t.service.tagReader.ReadArtwork(trackPath); err == nil && len(data) > 0 {
+		best = imageSize(data)
+	}
+	for _, name := range folderCoverNames {
+		data, err := os.ReadFile(filepath.Join(filepath.Dir(trackPath), name))
+		if err != nil {
+			continue
+		}
+		if size := imageSize(data); min(size.X, size.Y) > min(best.X, best.Y) {
+			best = size
+		}
+	}
+	return best
+}
+
+// findLargerCover asks every enabled provider for the album and downloads the covers of the results of
+// the same release, returning the largest one bigger than current along with its size and provider.
+func (t *ArtworkUpgradeJobTask) findLargerCover(ctx context.Context, job *music.Job, track *music.Track, current image.Point) ([]byte, image.Point, string) {
+	params := t.service.searchParamsFor(track)
+	var bestData []byte
+	var bestSize image.Point
+	var bestProvider string
+	seen := map[string]bool{}
+	for _, name := range slices.Sorted(maps.Keys(t.service.metadataProviders)) {
+		provider := t.service.metadataProviders[name]
+		if provider == nil || !provider.IsEnabled() {
+			continue
+		}
+		results, err := provider.SearchTracks(ctx, params)
+		if err != nil {
+			job.Logger.Warn("Provider search failed", "provider", name, "album", params.Album, "error", err)
+			continue
+		}
+		candidates := 0
+		for _, result := range results {
+			if result.Thumbnail == "" || seen[result.Thumbnail] || candidates >= maxArtworkCandidates {
+				continue
+			}
+			if !sameRelease(result, track) {
+				job.Logger.Debug("Skipping cover of another release", "provider", name, "album", params.Album, "url", result.Thumbnail)
+				continue
+			}
+			seen[result.Thumbnail] = true
+			candidates++
+			data, err := fetchArtwork(ctx, result.Thumbnail)
+			if err != nil {
+				job.Logger.Debug("Failed to fetch cover", "provider", name, "url", result.Thumbnail, "error", err)
+				continue
+			}
+			size := imageSize(data)
+			if min(size.X, size.Y) > min(current.X, current.Y) && min(size.X, size.Y) > min(bestSize.X, bestSize.Y) {
+				bestData, bestSize, bestProvider = data, size, name
+			}
+		}
+	}
+	return bestData, bestSize, bestProvider
+}
+
+// sameRelease reports whether a provider result is on the album of track: the album titles match and
+// an artist of the result's album or track matches one of track's, so another release's cover isn't taken.
+func sameRelease(result, track *music.Track) bool {
+	if result.Album == nil || track.Album == nil {
+		return false
+	}
+	if key := albumMergeKey(track.Album.Title); key == "" || albumMergeKey(result.Album.Title) != key {
+		return false
+	}
+	artists := map[string]bool{}
+	for _, name := range releaseArtists(track) {
+		artists[albumMergeKey(name)] = true
+	}
+	for _, name := range releaseArtists(result) {
+		if key := albumMergeKey(name); key != "" && artists[key] {
+			return true
+		}
+	}
+	return false
+}
+
+// releaseArtists returns the names of the album artists and the artists of track.
+func releaseArtists(track *music.Track) []string {
+	var names []string
+	roles := track.Artists
+	if track.Album != nil {
+		roles = append(slices.Clone(track.Album.Artists), roles...)
+	}
+	for _, role := range roles {
+		if role.Artist != nil {
+			names = append(names, role.Artist.Name)
+		}
+	}
+	return names
+}
+
+// writeFolderCover stores the cover as the album folder image, replacing smaller sidecars.
+func writeFolderCover(dir string, data []byte) error {
+	ext := ".jpg"
+	if http.DetectContentType(data) == "image/png" {
+		ext = ".png"
+	}
+	for _, name := range folderCoverNames[:2] {
+		if filepath.Ext(name) != ext {
+			if err := os.Remove(filepath.Join(dir, name)); err != nil && !os.IsNotExist(err) {
+				slog.Warn("Failed to remove old folder artwork", "dir", dir, "name", name, "error", err)
+			}
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "folder"+ext), data, 0644); err != nil {
+		return fmt.Errorf("failed to write folder artwork: %w", err)
+	}
+	return nil
+}
+
+// imageSize returns the dimensions of an encoded image, zero when it can't be decoded.
+func imageSize(data []byte) image.Point {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return image.Point{}
+	}
+	return image.Point{X: cfg.Width, Y: cfg.Height}
+}
+
+func albumArtist(track *music.Track) string {
+	if track.Album != nil && len(track.Album.Artists) > 0 && track.Album.Artists[0].Artist != nil {
+		return track.Album.Artists[0].Artist.Name
+	}
+	return ""
+}
+
+// Cleanup performs cleanup after artwork upgrade job completion
+func (t *ArtworkUpgradeJobTask) Cleanup(job *music.Job) error {
+	slog.Debug("Cleaning up artwork upgrade job", "jobID", job.ID)
+	return nil
+}
@@ -0,0 +1,162 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/contre95/soulsolid/src/music"
+)
+
+const (
+	// maxCompareCandidates caps the tracks suggested for a fingerprint comparison.
+	maxCompareCandidates = 10
+	// sameRecording is the similarity from which two fingerprints are taken for the same audio,
+	// re-encodes and remasters of one recording usually score above it.
+	sameRecording = 0.7
+	// similarRecording is the similarity from which two fingerprints share part of their audio,
+	// like an edit or an extended version of the same recording.
+	similarRecording = 0.4
+)
+
+// AcoustID lookup statuses of a fingerprint.
+const (
+	LookupMatched       = "matched"
+	LookupNoMatch       = "no match"
+	LookupFailed        = "failed"
+	LookupDisabled      = "disabled"
+	LookupNoFingerprint = "no fingerprint"
+)
+
+// FingerprintDetails is the fingerprint of a track with its live AcoustID lookup.
+type FingerprintDetails struct {
+	TrackID      string          `json:"track_id"`
+	Title        string          `json:"title"`
+	Fingerprint  string          `json:"fingerprint,omitempty"`
+	AcoustID     string          `json:"acoustid,omitempty"` // the AcoustID stored on the track
+	LookupStatus string          `json:"lookup_status"`
+	LookupError  string          `json:"lookup_error,omitempty"`
+	StoredMatch  bool            `json:"stored_match"` // whether the stored AcoustID is among the matches
+	Matches      []AcoustIDMatch `json:"matches,omitempty"`
+	Candidates   []*music.Track  `json:"-"` // tracks with a similar title to compare with
+}
+
+// FingerprintComparison is how alike the audio of two tracks is.
+type FingerprintComparison struct {
+	TrackID      string  `json:"track_id"`
+	OtherID      string  `json:"other_id"`
+	OtherTitle   string  `json:"other_title"`
+	Similarity   float64 `json:"similarity"`
+	Verdict      string  `json:"verdict"`
+	SameAcoustID bool    `json:"same_acoustid"`
+	Computed     bool    `json:"computed"` // a fingerprint was computed for this comparison, not stored
+}
+
+// Percent returns the similarity as a whole percentage.
+func (c *FingerprintComparison) Percent() int {
+	return int(c.Similarity*100 + 0.5)
+}
+
+// GetFingerprintDetails returns the fingerprint of a track, looks it up on AcoustID and lists
+// the tracks with a similar title to compare it with. A failed lookup is reported in the
+// details rather than as an error.
+func (s *Service) GetFingerprintDetails(ctx context.Context, trackID string) (*FingerprintDetails, error) {
+	track, err := s.libraryRepo.GetTrack(ctx, trackID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get track: %w", err)
+	}
+	if track == nil {
+		return nil, fmt.Errorf("track not found: %s", trackID)
+	}
+	details := &FingerprintDetails{
+		TrackID:     track.ID,
+		Title:       track.Title,
+		Fingerprint: track.ChromaprintFingerprint,
+		AcoustID:    track.Attributes["acoustid"],
+	}
+
+	provider, exists := s.configManager.Get().Metadata.Providers["acoustid"]
+	switch {
+	case details.Fingerprint == "":
+		details.LookupStatus = LookupNoFingerprint
+	case !exists || !provider.Enabled:
+		details.LookupStatus = LookupDisabled
+	default:
+		lookupCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+		defer cancel()
+		matches, err := s.chromaprintAcoustID.LookupRecordings(lookupCtx, details.Fingerprint, track.Metadata.Duration)
+		switch {
+		case err != nil:
+			details.LookupStatus = LookupFailed
+			details.LookupError = err.Error()
+		case len(matches) == 0:
+			details.LookupStatus = LookupNoMatch
+		default:
+			details.LookupStatus = LookupMatched
+			details.Matches = matches
+		}
+		for _, match := range matches {
+			if match.ID == details.AcoustID {
+				details.StoredMatch = true
+			}
+		}
+	}
+
+	if title := track.Title; title != "" {
+		candidates, err := s.libraryRepo.GetTracksFilteredPaginated(ctx, maxCompareCandidates+1, 0, &music.TrackFilter{Title: title})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tracks with a similar title: %w", err)
+		}
+		for _, candidate := range candidates {
+			if candidate.ID != track.ID && len(details.Candidates) < maxCompareCandidates {
+				details.Candidates = append(details.Candidates, candidate)
+			}
+		}
+	}
+	return details, nil
+}
+
+// CompareTrackFingerprints compares the fingerprints of two tracks. A track without a stored
+// fingerprint has one computed for the comparison, without storing it.
+func (s *Service) CompareTrackFingerprints(ctx context.Context, trackID, otherID string) (*FingerprintComparison, error) {
+	if trackID == otherID {
+		return nil, fmt.Errorf("cannot compare a track with itself")
+	}
+	track, err := s.libraryRepo.GetTrack(ctx, trackID)
+	if err != nil || track == nil {
+		return nil, fmt.Errorf("track not found: %s", trackID)
+	}
+	other, err := s.libraryRepo.GetTrack(ctx, otherID)
+	if err != nil || other == nil {
+		return nil, fmt.Errorf("track not found: %s", otherID)
+	}
+
+	comparison := &FingerprintComparison{TrackID: track.ID, OtherID: other.ID, OtherTitle: other.Title}
+	fingerprints := make([]string, 2)
+	for i, t := range []*music.Track{track, other} {
+		fingerprints[i] = t.ChromaprintFingerprint
+		if fingerprints[i] != "" {
+			continue
+		}
+		if fingerprints[i], _, err = s.chromaprintAcoustID.GenerateChromaprint(ctx, t.Path); err != nil {
+			return nil, fmt.Errorf("failed to compute the fingerprint of %s: %w", t.Title, err)
+		}
+		comparison.Computed = true
+	}
+
+	comparison.Similarity, err = s.chromaprintAcoustID.CompareChromaprints(fingerprints[0], fingerprints[1])
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare fingerprints: %w", err)
+	}
+	switch {
+	case comparison.Similarity >= sameRecording:
+		comparison.Verdict = "Same recording"
+	case comparison.Similarity >= similarRecording:
+		comparison.Verdict = "Partly the same audio, likely another version or edit"
+	default:
+		comparison.Verdict = "Different recordings"
+	}
+	acoustID := track.Attributes["acoustid"]
+	comparison.SameAcoustID = acoustID != "" && acoustID == other.Attributes["acoustid"]
+	return comparison, nil
+}
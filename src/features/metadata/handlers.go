@@ -1,8 +1,11 @@
 package metadata
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"mime/multipart"
 	"strings"
 
 	"github.com/contre95/soulsolid/src/features/hosting/respond"
@@ -120,6 +123,9 @@ func (h *Handler) RenderTagEditor(c *fiber.Ctx) error {
 		"Albums":                albums,
 		"SelectedAlbumArtistID": selectedAlbumArtistID,
 		"SelectedArtistIDs":     selectedArtistIDs,
+		"CustomFields":          h.service.CustomFields(),
+		"NotesComment":          h.service.NotesComment(),
+		"MaxNotesLength":        music.MaxNotesLength,
 	})
 }
 
@@ -178,6 +184,48 @@ func (h *Handler) ServeArtwork(c *fiber.Ctx) error {
 	})
 }
 
+// SetArtwork embeds a cover in a single track, from an uploaded "artwork" file or an image "url".
+func (h *Handler) SetArtwork(c *fiber.Ctx) error {
+	trackID := c.Params("trackId")
+	if trackID == "" {
+		return respond.ToastErr(c, fiber.StatusBadRequest, "Track ID is required")
+	}
+
+	var err error
+	if file, ferr := c.FormFile("artwork"); ferr == nil {
+		var data []byte
+		data, err = readFormFile(file)
+		if err == nil {
+			err = h.service.SetTrackArtwork(c.Context(), trackID, data)
+		}
+	} else if imageURL := c.FormValue("url"); imageURL != "" {
+		err = h.service.FetchTrackArtwork(c.Context(), trackID, imageURL)
+	} else {
+		return respond.ToastErr(c, fiber.StatusBadRequest, "An artwork file or URL is required")
+	}
+	if err != nil {
+		slog.Error("Failed to set track artwork", "trackId", trackID, "error", err)
+		status := fiber.StatusUnprocessableEntity
+		if errors.Is(err, music.ErrTrackLocked) {
+			status = fiber.StatusConflict
+		}
+		return respond.ToastErr(c, status, fmt.Sprintf("Failed to set artwork: %v", err))
+	}
+	return respond.ToastOk(c, "Artwork embedded")
+}
+
+func readFormFile(file *multipart.FileHeader) ([]byte, error) {
+	if file.Size > maxArtworkBytes {
+		return nil, fmt.Errorf("artwork exceeds %d MB", maxArtworkBytes>>20)
+	}
+	f, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
 // FetchFromProvider handles fetching metadata from any provider and rendering the form
 func (h *Handler) FetchFromProvider(c *fiber.Ctx) error {
 	trackID := c.Params("trackId")
@@ -292,6 +340,9 @@ func (h *Handler) FetchFromProvider(c *fiber.Ctx) error {
 			"ProviderColors":        providerColors,
 			"SelectedAlbumArtistID": selectedAlbumArtistID,
 			"SelectedArtistIDs":     selectedArtistIDs,
+			"CustomFields":          h.service.CustomFields(),
+			"NotesComment":          h.service.NotesComment(),
+			"MaxNotesLength":        music.MaxNotesLength,
 		})
 	}
 
@@ -389,6 +440,9 @@ func (h *Handler) FetchFromProvider(c *fiber.Ctx) error {
 		"ProviderColors":        providerColors,
 		"SelectedAlbumArtistID": selectedAlbumArtistID,
 		"SelectedArtistIDs":     selectedArtistIDs,
+		"CustomFields":          h.service.CustomFields(),
+		"NotesComment":          h.service.NotesComment(),
+		"MaxNotesLength":        music.MaxNotesLength,
 	})
 }
 
@@ -557,6 +611,9 @@ func (h *Handler) SelectTrackFromResults(c *fiber.Ctx) error {
 		"Albums":                albums,
 		"SelectedAlbumArtistID": selectedAlbumArtistID,
 		"SelectedArtistIDs":     selectedArtistIDs,
+		"CustomFields":          h.service.CustomFields(),
+		"NotesComment":          h.service.NotesComment(),
+		"MaxNotesLength":        music.MaxNotesLength,
 		"FromProvider":          providerName,
 		"ProviderColors":        providerColors,
 	})
@@ -602,6 +659,36 @@ func (h *Handler) ViewFingerprint(c *fiber.Ctx) error {
 	return respond.Text(c, "fingerprint", track.ChromaprintFingerprint)
 }
 
+// GetFingerprintDetails shows the fingerprint of a track with its AcoustID matches
+func (h *Handler) GetFingerprintDetails(c *fiber.Ctx) error {
+	trackID := c.Params("trackId")
+	details, err := h.service.GetFingerprintDetails(c.Context(), trackID)
+	if err != nil {
+		slog.Error("Failed to get fingerprint details", "error", err, "trackId", trackID)
+		return respond.ToastErr(c, fiber.StatusInternalServerError, "Failed to get fingerprint details: "+err.Error())
+	}
+	return respond.Partial(c, "tag/fingerprint", fiber.Map{
+		"Details": details,
+	})
+}
+
+// CompareFingerprints compares the fingerprint of a track with the one of another track
+func (h *Handler) CompareFingerprints(c *fiber.Ctx) error {
+	trackID := c.Params("trackId")
+	otherID := strings.TrimSpace(c.Query("with"))
+	if otherID == "" {
+		return respond.ToastErr(c, fiber.StatusBadRequest, "A track to compare with is required")
+	}
+	comparison, err := h.service.CompareTrackFingerprints(c.Context(), trackID, otherID)
+	if err != nil {
+		slog.Error("Failed to compare fingerprints", "error", err, "trackId", trackID, "with", otherID)
+		return respond.ToastErr(c, fiber.StatusInternalServerError, "Failed to compare fingerprints: "+err.Error())
+	}
+	return respond.Partial(c, "tag/fingerprint_compare", fiber.Map{
+		"Comparison": comparison,
+	})
+}
+
 // GetMetadataProviders returns metadata provider buttons for HTMX or provider list as JSON.
 func (h *Handler) GetMetadataProviders(c *fiber.Ctx) error {
 	trackID := c.Params("trackId")
@@ -701,6 +788,18 @@ func (h *Handler) UpdateTags(c *fiber.Ctx) error {
 	if sourceURL := c.FormValue("source_url"); sourceURL != "" {
 		formData["source_url"] = sourceURL
 	}
+	if artworkURL := c.FormValue("artwork_url"); artworkURL != "" {
+		formData["artwork_url"] = artworkURL
+	}
+	// Custom fields left out of the form are kept, an empty one is cleared
+	for _, key := range h.service.CustomFields() {
+		if c.Context().PostArgs().Has("attr." + key) {
+			formData["attr."+key] = c.FormValue("attr." + key)
+		}
+	}
+	if c.Context().PostArgs().Has("notes") {
+		formData["notes"] = c.FormValue("notes")
+	}
 
 	slog.Debug("Parsed form data", "formData", formData)
 
@@ -731,8 +830,105 @@ func (h *Handler) StartAcoustIDAnalysis(c *fiber.Ctx) error {
 	return respond.ToastJob(c, jobID, "AcoustID analysis started successfully")
 }
 
+// PreviewNormalization lists the tag changes the normalization job would make
+func (h *Handler) PreviewNormalization(c *fiber.Ctx) error {
+	changes, err := h.service.PreviewNormalization(c.Context())
+	if err != nil {
+		slog.Error("Failed to preview tag normalization", "error", err)
+		return respond.ToastErr(c, fiber.StatusInternalServerError, "Failed to preview normalization: "+err.Error())
+	}
+	return respond.Partial(c, "tag/normalize_preview", fiber.Map{
+		"Changes": changes,
+		"Limit":   maxNormalizePreview,
+	})
+}
+
+// StartNormalization handles starting the tag normalization job
+func (h *Handler) StartNormalization(c *fiber.Ctx) error {
+	jobID, err := h.service.StartNormalization(c.Context())
+	if err != nil {
+		slog.Error("Failed to start tag normalization", "error", err)
+		return respond.ToastErr(c, fiber.StatusInternalServerError, "Failed to start normalization: "+err.Error())
+	}
+	c.Set("HX-Trigger", "refreshJobList")
+	return respond.ToastJob(c, jobID, "Tag normalization started")
+}
+
+// StartArtworkUpgrade handles starting the album artwork upgrade job
+func (h *Handler) StartArtworkUpgrade(c *fiber.Ctx) error {
+	jobID, err := h.service.StartArtworkUpgrade(c.Context())
+	if err != nil {
+		slog.Error("Failed to start artwork upgrade", "error", err)
+		return respond.ToastErr(c, fiber.StatusInternalServerError, "Failed to start artwork upgrade: "+err.Error())
+	}
+	c.Set("HX-Trigger", "refreshJobList")
+	return respond.ToastJob(c, jobID, "Artwork upgrade started")
+}
+
+// GetDuplicateAlbums lists the groups of albums that look like the same release
+func (h *Handler) GetDuplicateAlbums(c *fiber.Ctx) error {
+	duplicates, err := h.service.FindDuplicateAlbums(c.Context())
+	if err != nil {
+		slog.Error("Failed to find duplicate albums", "error", err)
+		return respond.ToastErr(c, fiber.StatusInternalServerError, "Failed to find duplicate albums: "+err.Error())
+	}
+	return respond.Partial(c, "tag/duplicate_albums", fiber.Map{
+		"Duplicates": duplicates,
+	})
+}
+
+// MergeAlbum merges the album into the one given by the "into" form value and deletes it
+func (h *Handler) MergeAlbum(c *fiber.Ctx) error {
+	albumID := c.Params("albumId")
+	into := c.FormValue("into")
+	if into == "" {
+		return respond.ToastErr(c, fiber.StatusBadRequest, "Missing the album to merge into")
+	}
+	moved, err := h.service.MergeAlbums(c.Context(), into, albumID)
+	if err != nil {
+		slog.Error("Failed to merge albums", "albumID", albumID, "into", into, "error", err)
+		status := fiber.StatusInternalServerError
+		if errors.Is(err, music.ErrTrackLocked) {
+			status = fiber.StatusConflict
+		}
+		return respond.ToastErr(c, status, "Failed to merge albums: "+err.Error())
+	}
+	c.Set("HX-Trigger", "refreshDuplicateAlbums")
+	return respond.ToastOk(c, fmt.Sprintf("Albums merged, %d tracks moved", moved))
+}
+
+// GetSwappedTracks lists the tracks whose title and artist look swapped
+func (h *Handler) GetSwappedTracks(c *fiber.Ctx) error {
+	swapped, err := h.service.FindSwappedTracks(c.Context())
+	if err != nil {
+		slog.Error("Failed to find swapped tracks", "error", err)
+		return respond.ToastErr(c, fiber.StatusInternalServerError, "Failed to find swapped tracks: "+err.Error())
+	}
+	return respond.Partial(c, "tag/swapped_tracks", fiber.Map{
+		"Swapped": swapped,
+	})
+}
+
+// FixSwappedTrack swaps the title and the artist of a track
+func (h *Handler) FixSwappedTrack(c *fiber.Ctx) error {
+	trackID := c.Params("trackId")
+	title, artist, err := h.service.FixSwappedTrack(c.Context(), trackID)
+	if err != nil {
+		slog.Error("Failed to swap track title and artist", "trackID", trackID, "error", err)
+		status := fiber.StatusInternalServerError
+		if errors.Is(err, music.ErrTrackLocked) {
+			status = fiber.StatusConflict
+		}
+		return respond.ToastErr(c, status, "Failed to swap title and artist: "+err.Error())
+	}
+	return respond.ToastOk(c, fmt.Sprintf("Swapped, now \"%s\" by %s", title, artist))
+}
+
 // RenderMetadataAnalysisSection renders the metadata analysis section page
 func (h *Handler) RenderMetadataAnalysisSection(c *fiber.Ctx) error {
 	slog.Debug("Rendering metadata analysis section")
-	return respond.Section(c, "analyze_metadata", fiber.Map{"Title": "Metadata Analysis"})
+	return respond.Section(c, "analyze_metadata", fiber.Map{
+		"Title":          "Metadata Analysis",
+		"MinArtworkSize": h.service.configManager.Get().Downloaders.Artwork.Upgrade.MinSize,
+	})
 }
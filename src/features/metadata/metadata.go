@@ -14,6 +14,7 @@ type SearchParams struct {
 	Title       string
 	Year        int
 	AcoustID    string
+	Release     ReleasePreference // Used by providers that pick one of several releases for a match
 }
 
 // MetadataProvider defines the interface for fetching metadata from external services
@@ -0,0 +1,248 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/contre95/soulsolid/src/features/config"
+	"github.com/contre95/soulsolid/src/music"
+)
+
+// maxNormalizePreview caps the number of changes returned by PreviewNormalization.
+const maxNormalizePreview = 50
+
+// featPattern matches the featuring forms inside a title, e.g. "(ft. X)" or "Song featuring X".
+var featPattern = regexp.MustCompile(`(?i)([\s(\[])(featuring|feat\.?|ft\.?)\s+`)
+
+// wordPattern matches the words of a tag, keeping the original spacing between them intact.
+var wordPattern = regexp.MustCompile(`\S+`)
+
+// smallWords stay lowercase in title case unless they start or end the title.
+var smallWords = map[string]bool{
+	"a": true, "an": true, "and": true, "as": true, "at": true, "but": true, "by": true, "for": true,
+	"from": true, "in": true, "into": true, "nor": true, "of": true, "on": true, "or": true, "the": true,
+	"to": true, "vs": true, "vs.": true, "with": true, "feat.": true, "ft.": true,
+}
+
+var straightQuotes = strings.NewReplacer("‘", "'", "’", "'", "‚", "'", "‛", "'", "“", `"`, "”", `"`, "„", `"`, "‟", `"`)
+
+// Normalizer rewrites tag text with the configured normalization rules.
+// Artist names are left alone since renaming them would require merging artists.
+type Normalizer struct {
+	config *config.Manager
+}
+
+// NewNormalizer creates a new tag normalizer.
+func NewNormalizer(cfg *config.Manager) *Normalizer {
+	return &Normalizer{config: cfg}
+}
+
+// NormalizeTrack normalizes the track and album titles in place and returns the changes.
+func (n *Normalizer) NormalizeTrack(track *music.Track) []music.TagChange {
+	rules := n.config.Get().Metadata.Normalization
+	original := track.Title
+	var changes []music.TagChange
+	if title := normalizeText(track.Title, rules); title != track.Title {
+		changes = append(changes, music.TagChange{TrackID: track.ID, Track: original, Field: "title", Old: track.Title, New: title})
+		track.Title = title
+	}
+	if track.Album != nil {
+		if album := normalizeText(track.Album.Title, rules); album != track.Album.Title {
+			changes = append(changes, music.TagChange{TrackID: track.ID, Track: original, Field: "album", Old: track.Album.Title, New: album})
+			track.Album.Title = album
+		}
+	}
+	return changes
+}
+
+// normalizeText applies the rules to a single tag value.
+func normalizeText(s string, rules config.Normalization) string {
+	if s == "" {
+		return s
+	}
+	if rules.TrimWhitespace {
+		s = strings.Join(strings.Fields(s), " ")
+	}
+	if rules.Feat != "" {
+		s = featPattern.ReplaceAllString(s, "${1}"+strings.ReplaceAll(rules.Feat, "$", "$$")+" ")
+	}
+	switch rules.Quotes {
+	case "straight":
+		s = straightQuotes.Replace(s)
+	case "smart":
+		s = smartQuotes(s)
+	}
+	switch rules.Casing {
+	case "title":
+		s = titleCase(s)
+	case "sentence":
+		s = sentenceCase(s)
+	}
+	return s
+}
+
+// smartQuotes turns straight quotes into curly ones, opening them at the start of a word.
+func smartQuotes(s string) string {
+	var b strings.Builder
+	prev := ' '
+	for _, r := range s {
+		opening := unicode.IsSpace(prev) || strings.ContainsRune("([{", prev)
+		switch {
+		case r == '\'' && opening:
+			b.WriteRune('‘')
+		case r == '\'':
+			b.WriteRune('’')
+		case r == '"' && opening:
+			b.WriteRune('“')
+		case r == '"':
+			b.WriteRune('”')
+		default:
+			b.WriteRune(r)
+		}
+		prev = r
+	}
+	return b.String()
+}
+
+// titleCase capitalizes every word but the small ones. Words with capitals after their
+// first letter, e.g. "AC/DC" or "McCartney", are kept as they are.
+func titleCase(s string) string {
+	words := wordPattern.FindAllStringIndex(s, -1)
+	var b strings.Builder
+	last := 0
+	for i, loc := range words {
+		b.WriteString(s[last:loc[0]])
+		word := s[loc[0]:loc[1]]
+		inner := i > 0 && i < len(words)-1 && !strings.HasPrefix(word, "(") && !strings.HasPrefix(word, "[")
+		switch {
+		case inner && smallWords[strings.ToLower(word)] && onlyFirstUpper(word):
+			word = strings.ToLower(word)
+		case isLower(word):
+			word = upperFirst(word)
+		}
+		b.WriteString(word)
+		last = loc[1]
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// sentenceCase capitalizes the first word and lowercases the capitalized ones after it.
+// Acronyms, mixed case words and "I" are kept.
+func sentenceCase(s string) string {
+	first := true
+	return wordPattern.ReplaceAllStringFunc(s, func(word string) string {
+		if first {
+			first = false
+			return upperFirst(word)
+		}
+		if word == "I" || strings.HasPrefix(word, "I'") || strings.HasPrefix(word, "I’") || !onlyFirstUpper(word) {
+			return word
+		}
+		return strings.ToLower(word)
+	})
+}
+
+// onlyFirstUpper reports whether the first letter of word is the only uppercase one.
+func onlyFirstUpper(word string) bool {
+	seenLetter := false
+	for _, r := range word {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		if seenLetter && unicode.IsUpper(r) {
+			return false
+		}
+		seenLetter = true
+	}
+	return true
+}
+
+func isLower(word string) bool {
+	return strings.ToLower(word) == word
+}
+
+// upperFirst uppercases the first letter of word, skipping leading punctuation such as "(".
+func upperFirst(word string) string {
+	for i, r := range word {
+		if unicode.IsLetter(r) {
+			return word[:i] + string(unicode.ToUpper(r)) + word[i+len(string(r)):]
+		}
+	}
+	return word
+}
+
+// PreviewNormalization returns the changes the normalize job would make, up to maxNormalizePreview of them.
+func (s *Service) PreviewNormalization(ctx context.Context) ([]music.TagChange, error) {
+	total, err := s.libraryRepo.GetTracksCount(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tracks count: %w", err)
+	}
+	changes := []music.TagChange{}
+	// An album change shows up for each of its tracks, only the first one is listed.
+	albums := map[string]bool{}
+	batchSize := 100
+	for offset := 0; offset < total && len(changes) < maxNormalizePreview; offset += batchSize {
+		tracks, err := s.libraryRepo.GetTracksPaginated(ctx, batchSize, offset)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tracks batch (offset %d): %w", offset, err)
+		}
+		for _, track := range tracks {
+			if track.IsLocked() {
+				continue
+			}
+			for _, change := range s.normalizer.NormalizeTrack(track) {
+				if change.Field == "album" {
+					if albums[change.Old] {
+						continue
+					}
+					albums[change.Old] = true
+				}
+				changes = append(changes, change)
+			}
+		}
+	}
+	if len(changes) > maxNormalizePreview {
+		changes = changes[:maxNormalizePreview]
+	}
+	return changes, nil
+}
+
+// NormalizeTrackTags normalizes a library track, writing the new tags to its file and the database.
+// It returns the changes, none when the track was already normalized.
+func (s *Service) NormalizeTrackTags(ctx context.Context, trackID string) ([]music.TagChange, error) {
+	track, err := s.GetTrackFileTags(ctx, trackID)
+	if err != nil {
+		return nil, err
+	}
+	changes := s.normalizer.NormalizeTrack(track)
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	if err := s.tagWriter.WriteFileTags(ctx, track.Path, track); err != nil {
+		return nil, fmt.Errorf("failed to write tags to file: %w", err)
+	}
+	if track.Album != nil && track.Album.ID != "" {
+		if err := s.libraryRepo.UpdateAlbum(ctx, track.Album); err != nil {
+			return nil, fmt.Errorf("failed to update album in database: %w", err)
+		}
+	}
+	if err := s.libraryRepo.UpdateTrack(ctx, track); err != nil {
+		return nil, fmt.Errorf("failed to update track in database: %w", err)
+	}
+	slog.Debug("Normalized track tags", "trackID", trackID, "changes", len(changes))
+	return changes, nil
+}
+
+// StartNormalization starts a job that normalizes the tags of every unlocked track in the library.
+func (s *Service) StartNormalization(ctx context.Context) (string, error) {
+	jobID, err := s.jobService.StartJob("normalize_tags", "Normalize Tags", map[string]any{})
+	if err != nil {
+		return "", fmt.Errorf("failed to start normalization job: %w", err)
+	}
+	return jobID, nil
+}
@@ -0,0 +1,89 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/contre95/soulsolid/src/music"
+)
+
+// NormalizeJobTask rewrites the tags of the library with the normalization rules
+type NormalizeJobTask struct {
+	service *Service
+}
+
+// NewNormalizeJobTask creates a new tag normalization job task
+func NewNormalizeJobTask(service *Service) *NormalizeJobTask {
+	return &NormalizeJobTask{
+		service: service,
+	}
+}
+
+// MetadataKeys returns the required metadata keys for normalization jobs
+func (t *NormalizeJobTask) MetadataKeys() []string {
+	return []string{}
+}
+
+// Execute normalizes every unlocked track of the library
+func (t *NormalizeJobTask) Execute(ctx context.Context, job *music.Job, progressUpdater func(int, string)) (map[string]any, error) {
+	totalTracks, err := t.service.libraryRepo.GetTracksCount(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tracks count: %w", err)
+	}
+	job.Logger.Info("Starting tag normalization", "totalTracks", totalTracks, "color", "blue")
+
+	processed, normalized, skipped, errors := 0, 0, 0, 0
+	batchSize := 100
+	for offset := 0; offset < totalTracks; offset += batchSize {
+		tracks, err := t.service.libraryRepo.GetTracksPaginated(ctx, batchSize, offset)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tracks batch (offset %d): %w", offset, err)
+		}
+		for _, track := range tracks {
+			if err := ctx.Err(); err != nil {
+				job.Logger.Info("Tag normalization cancelled", "processed", processed, "normalized", normalized, "color", "orange")
+				return nil, err
+			}
+			processed++
+			progressUpdater((processed*100)/totalTracks, fmt.Sprintf("Normalizing track %d/%d: %s", processed, totalTracks, track.Title))
+
+			// Locked tracks must not have their tags rewritten
+			if track.IsLocked() {
+				skipped++
+				continue
+			}
+			changes, err := t.service.NormalizeTrackTags(ctx, track.ID)
+			if err != nil {
+				job.Logger.Warn("Failed to normalize track", "trackID", track.ID, "title", track.Title, "error", err, "color", "red")
+				errors++
+				continue
+			}
+			if len(changes) == 0 {
+				continue
+			}
+			for _, change := range changes {
+				job.Logger.Info("Normalized tag", "trackID", track.ID, "field", change.Field, "old", change.Old, "new", change.New, "color", "green")
+			}
+			normalized++
+		}
+	}
+
+	finalMsg := fmt.Sprintf("Tag normalization completed: %d track(s) normalized, %d locked skipped, %d errors", normalized, skipped, errors)
+	job.Logger.Info("Tag normalization completed", "normalized", normalized, "skipped", skipped, "errors", errors, "color", "green")
+	progressUpdater(100, fmt.Sprintf("Done — %d track(s) normalized, %d errors", normalized, errors))
+
+	return map[string]any{
+		"totalTracks": totalTracks,
+		"normalized":  normalized,
+		"skipped":     skipped,
+		"errors":      errors,
+		"msg":         finalMsg,
+	}, nil
+}
+
+// Cleanup performs cleanup after normalization job completion
+func (t *NormalizeJobTask) Cleanup(job *music.Job) error {
+	slog.Debug("Cleaning up tag normalization job", "jobID", job.ID)
+	return nil
+}
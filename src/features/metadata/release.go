@@ -0,0 +1,43 @@
+package metadata
+
+import (
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/contre95/soulsolid/src/music"
+)
+
+// ReleasePreference describes the preferred release of a match, see config.ReleasePreference.
+type ReleasePreference struct {
+	Countries []string // ISO 3166 codes, most preferred first
+	Status    string   // e.g. "official" or "promotion", empty accepts any status
+}
+
+// Rank scores a release by its country and status, lower is better. A status mismatch
+// outweighs the country, so an official release from any country beats a preferred country's promo.
+func (p ReleasePreference) Rank(country, status string) int {
+	rank := slices.IndexFunc(p.Countries, func(c string) bool { return strings.EqualFold(c, country) })
+	if rank < 0 {
+		rank = len(p.Countries)
+	}
+	if p.Status != "" && !strings.EqualFold(p.Status, status) {
+		rank += len(p.Countries) + 1
+	}
+	return rank
+}
+
+// rankTracks orders provider matches by the release preference of their albums, keeping the
+// provider's order for matches that rank the same. Callers taking the first match get the preferred one.
+func rankTracks(tracks []*music.Track, pref ReleasePreference) {
+	if len(pref.Countries) == 0 && pref.Status == "" {
+		return
+	}
+	rank := func(t *music.Track) int {
+		if t.Album == nil {
+			return pref.Rank("", "")
+		}
+		return pref.Rank(t.Album.Country, t.Album.Status)
+	}
+	sort.SliceStable(tracks, func(i, j int) bool { return rank(tracks[i]) < rank(tracks[j]) })
+}
@@ -11,8 +11,11 @@ func RegisterRoutes(app *fiber.App, service *Service) {
 	tag := app.Group("/tag")
 	tag.Get("/:trackId/metadata", handler.GetMetadataProviders)
 	tag.Get("/:trackId/artwork", handler.ServeArtwork)
+	tag.Post("/:trackId/artwork", handler.SetArtwork)
 	tag.Get("/:trackId/fingerprint", handler.CalculateFingerprint)
 	tag.Get("/:trackId/fingerprint/view", handler.ViewFingerprint)
+	tag.Get("/:trackId/fingerprint/details", handler.GetFingerprintDetails)
+	tag.Get("/:trackId/fingerprint/compare", handler.CompareFingerprints)
 	tag.Get("/:trackId/search/:provider", handler.SearchTracksFromProvider)
 	tag.Get("/:trackId/select/:provider", handler.SelectTrackFromResults)
 	tag.Get("/:trackId", handler.RenderTagEditor)
@@ -21,6 +24,13 @@ func RegisterRoutes(app *fiber.App, service *Service) {
 
 	analyze := app.Group("/analyze")
 	analyze.Post("/acoustid", handler.StartAcoustIDAnalysis)
+	analyze.Get("/normalize/preview", handler.PreviewNormalization)
+	analyze.Post("/normalize", handler.StartNormalization)
+	analyze.Post("/artwork", handler.StartArtworkUpgrade)
+	analyze.Get("/albums/duplicates", handler.GetDuplicateAlbums)
+	analyze.Post("/albums/:albumId/merge", handler.MergeAlbum)
+	analyze.Get("/tracks/swapped", handler.GetSwappedTracks)
+	analyze.Post("/tracks/:trackId/swap", handler.FixSwappedTrack)
 
 	app.Get("/analyze/metadata", handler.RenderMetadataAnalysisSection)
 }
@@ -26,6 +26,7 @@ type Service struct {
 	chromaprintAcoustID ChromaprintAcoustID
 	configManager       *config.Manager
 	jobService          music.JobService
+	normalizer          *Normalizer
 }
 
 // NewService creates a new tag service
@@ -38,6 +39,7 @@ func NewService(tagWriter TagWriter, tagReader TagReader, libraryRepo music.Libr
 		metadataProviders:   metadataProviders,
 		chromaprintAcoustID: chromaprintAcoustID,
 		jobService:          jobService,
+		normalizer:          NewNormalizer(cfgManager),
 	}
 }
 
@@ -140,6 +142,14 @@ func (s *Service) UpdateTrackTags(ctx context.Context, trackID string, formData
 		slog.Info("Created new album in database", "albumID", updatedTrack.Album.ID, "title", updatedTrack.Album.Title)
 	}
 
+	// Embed the provider's cover in the track when requested, e.g. for singles without album artwork
+	if artworkURL := formData["artwork_url"]; artworkURL != "" {
+		updatedTrack.ArtworkData, err = fetchArtwork(ctx, artworkURL)
+		if err != nil {
+			return fmt.Errorf("failed to get artwork: %w", err)
+		}
+	}
+
 	// Write tags to file
 	err = s.tagWriter.WriteFileTags(ctx, track.Path, updatedTrack)
 	if err != nil {
@@ -166,6 +176,16 @@ func (s *Service) UpdateTrackTags(ctx context.Context, trackID string, formData
 	return nil
 }
 
+// CustomFields returns the track attributes embedded in the files as custom tag fields.
+func (s *Service) CustomFields() []string {
+	return s.tagWriter.CustomFields()
+}
+
+// NotesComment reports whether the track notes are written to the comment tag of the files.
+func (s *Service) NotesComment() bool {
+	return s.tagWriter.NotesComment()
+}
+
 // buildTrackFromFormData builds a Track struct from form data
 func (s *Service) buildTrackFromFormData(ctx context.Context, originalTrack *music.Track, formData map[string]string) (*music.Track, error) {
 	track := &music.Track{
@@ -309,6 +329,34 @@ func (s *Service) buildTrackFromFormData(ctx context.Context, originalTrack *mus
 		track.Attributes = make(map[string]string)
 		maps.Copy(track.Attributes, originalTrack.Attributes)
 	}
+	for _, key := range s.CustomFields() {
+		value, ok := formData["attr."+key]
+		if !ok {
+			continue
+		}
+		if track.Attributes == nil {
+			track.Attributes = make(map[string]string)
+		}
+		if value = strings.TrimSpace(value); value != "" {
+			track.Attributes[key] = value
+		} else {
+			delete(track.Attributes, key)
+		}
+	}
+	if notes, ok := formData["notes"]; ok {
+		notes = strings.TrimSpace(notes)
+		if err := music.ValidateNotes(notes); err != nil {
+			return nil, err
+		}
+		if track.Attributes == nil {
+			track.Attributes = make(map[string]string)
+		}
+		if notes != "" {
+			track.Attributes[music.NotesAttribute] = notes
+		} else {
+			delete(track.Attributes, music.NotesAttribute)
+		}
+	}
 	// Set HasLyrics based on form data (checkbox)
 	track.HasLyrics = formData["has_lyrics"] == "true"
 	// Preserve other fields not in form
@@ -453,26 +501,11 @@ func (s *Service) SearchTrackMetadata(ctx context.Context, trackID string, provi
 	if track == nil {
 		return nil, fmt.Errorf("track not found: %s", trackID)
 	}
-
-	// Build search parameters from current track data
-	acoustID := ""
-	if track.Attributes != nil {
-		acoustID = track.Attributes["acoustid"]
-	}
-	searchParams := SearchParams{
-		TrackID:  track.ID,
-		Title:    track.Title,
-		Year:     track.Metadata.Year,
-		AcoustID: acoustID,
+	if track.IsLocked() {
+		return nil, fmt.Errorf("%w: unlock it to fetch provider metadata", music.ErrTrackLocked)
 	}
 
-	// Add album and album artist if available
-	if track.Album != nil {
-		searchParams.Album = track.Album.Title
-		if len(track.Album.Artists) > 0 && track.Album.Artists[0].Artist != nil {
-			searchParams.AlbumArtist = track.Album.Artists[0].Artist.Name
-		}
-	}
+	searchParams := s.searchParamsFor(track)
 
 	// Find the specific provider
 	targetProvider, exists := s.metadataProviders[providerName]
@@ -501,9 +534,37 @@ func (s *Service) SearchTrackMetadata(ctx context.Context, trackID string, provi
 		tracks[i] = s.matchArtistsWithDatabase(ctx, resultTrack)
 	}
 
+	// Preferred releases first, FetchFromProvider applies the first match
+	rankTracks(tracks, searchParams.Release)
+
 	return tracks, nil
 }
 
+// searchParamsFor builds the provider search parameters from the library data of a track.
+func (s *Service) searchParamsFor(track *music.Track) SearchParams {
+	acoustID := ""
+	if track.Attributes != nil {
+		acoustID = track.Attributes["acoustid"]
+	}
+	release := s.configManager.Get().Metadata.ReleasePreference
+	searchParams := SearchParams{
+		TrackID:  track.ID,
+		Title:    track.Title,
+		Year:     track.Metadata.Year,
+		AcoustID: acoustID,
+		Release:  ReleasePreference{Countries: release.Countries, Status: release.Status},
+	}
+
+	// Add album and album artist if available
+	if track.Album != nil {
+		searchParams.Album = track.Album.Title
+		if len(track.Album.Artists) > 0 && track.Album.Artists[0].Artist != nil {
+			searchParams.AlbumArtist = track.Album.Artists[0].Artist.Name
+		}
+	}
+	return searchParams
+}
+
 // MergeFetchedData merges fetched metadata with existing track data
 // Prioritizes keeping the maximum amount of tags by preserving existing values when fetched values are empty
 func (s *Service) MergeFetchedData(existing, fetched *music.Track) *music.Track {
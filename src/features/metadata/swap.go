@@ -0,0 +1,214 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/contre95/soulsolid/src/music"
+)
+
+// maxSwapLookups caps the suspects checked against the metadata providers in one scan, each one
+// costs two searches per provider.
+const maxSwapLookups = 20
+
+// versionMarker matches the version suffixes found in titles, like "(Radio Edit)" or "[Live]".
+var versionMarker = regexp.MustCompile(`(?i)[(\[][^)\]]*\b(remix|mix|edit|version|live|remaster(ed)?|acoustic|instrumental|demo)\b[^)\]]*[)\]]`)
+
+// SwappedTrack is a track whose title and artist tags look swapped.
+type SwappedTrack struct {
+	ID        string   `json:"id"`
+	Title     string   `json:"title"`
+	Artist    string   `json:"artist"`
+	Album     string   `json:"album,omitempty"`
+	Reasons   []string `json:"reasons"`
+	Confirmed string   `json:"confirmed,omitempty"` // the metadata provider listing the swapped pair
+	Locked    bool     `json:"locked"`
+}
+
+// FindSwappedTracks lists the tracks whose title and artist look swapped, a common mistake of
+// downloaded files. The library patterns pick the suspects: a title that is the name of an artist
+// with other tracks while the track's own artist has none, or an artist name carrying a version
+// suffix like titles do. The first suspects are then looked up on the enabled metadata providers;
+// a provider listing the tags as they are clears a suspect, one listing them swapped confirms it.
+func (s *Service) FindSwappedTracks(ctx context.Context) ([]SwappedTrack, error) {
+	tracks, err := s.libraryRepo.GetTracks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tracks: %w", err)
+	}
+	artistTracks := map[string]int{}
+	artistNames := map[string]string{}
+	for _, track := range tracks {
+		for _, artist := range mainArtists(track) {
+			key := albumMergeKey(artist.Name)
+			artistTracks[key]++
+			artistNames[key] = artist.Name
+		}
+	}
+
+	suspects := []SwappedTrack{}
+	for _, track := range tracks {
+		artists := mainArtists(track)
+		if len(artists) != 1 || strings.TrimSpace(track.Title) == "" {
+			continue
+		}
+		artistKey, titleKey := albumMergeKey(artists[0].Name), albumMergeKey(track.Title)
+		if titleKey == "" || titleKey == artistKey {
+			continue
+		}
+		var reasons []string
+		if others := artistTracks[titleKey]; others > 0 && artistTracks[artistKey] == 1 {
+			reasons = append(reasons, fmt.Sprintf("The title is the name of %s, an artist with %d other track(s), while %s has no other track", artistNames[titleKey], others, artists[0].Name))
+		}
+		if versionMarker.MatchString(artists[0].Name) && !versionMarker.MatchString(track.Title) {
+			reasons = append(reasons, "The artist name has a version suffix, like titles do")
+		}
+		if len(reasons) == 0 {
+			continue
+		}
+		suspect := SwappedTrack{ID: track.ID, Title: track.Title, Artist: artists[0].Name, Reasons: reasons, Locked: track.IsLocked()}
+		if track.Album != nil {
+			suspect.Album = track.Album.Title
+		}
+		suspects = append(suspects, suspect)
+	}
+
+	lookupCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	swapped := []SwappedTrack{}
+	for i, suspect := range suspects {
+		if i < maxSwapLookups {
+			if cleared := s.lookupSwap(lookupCtx, &suspect); cleared {
+				continue
+			}
+		}
+		swapped = append(swapped, suspect)
+	}
+	sort.SliceStable(swapped, func(i, j int) bool { return swapped[i].Confirmed != "" && swapped[j].Confirmed == "" })
+	return swapped, nil
+}
+
+// lookupSwap searches the enabled metadata providers for the suspect as tagged and swapped. It
+// reports whether a provider lists the tags as they are, and records the provider listing them
+// swapped.
+func (s *Service) lookupSwap(ctx context.Context, suspect *SwappedTrack) bool {
+	names := make([]string, 0, len(s.metadataProviders))
+	for name, provider := range s.metadataProviders {
+		if provider != nil && provider.IsEnabled() {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		provider := s.metadataProviders[name]
+		asTagged, err := provider.SearchTracks(ctx, SearchParams{Title: suspect.Title, AlbumArtist: suspect.Artist})
+		if err != nil {
+			slog.Debug("Swap lookup failed", "provider", name, "track", suspect.ID, "error", err)
+			continue
+		}
+		if containsPair(asTagged, suspect.Title, suspect.Artist) {
+			return true
+		}
+		reversed, err := provider.SearchTracks(ctx, SearchParams{Title: suspect.Artist, AlbumArtist: suspect.Title})
+		if err != nil {
+			slog.Debug("Swap lookup failed", "provider", name, "track", suspect.ID, "error", err)
+			continue
+		}
+		if containsPair(reversed, suspect.Artist, suspect.Title) {
+			suspect.Confirmed = name
+			return false
+		}
+	}
+	return false
+}
+
+// containsPair reports whether one of the provider results is title by artist.
+func containsPair(results []*music.Track, title, artist string) bool {
+	titleKey, artistKey := albumMergeKey(title), albumMergeKey(artist)
+	for _, result := range results {
+		if albumMergeKey(result.Title) != titleKey {
+			continue
+		}
+		for _, role := range result.Artists {
+			if role.Artist != nil && albumMergeKey(role.Artist.Name) == artistKey {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// FixSwappedTrack swaps the title and the artist of a track and rewrites its file tags. The
+// artist is matched with a library artist of the same name, or created. The album artist is
+// swapped too when the album holds no other track. It returns the new title and artist.
+func (s *Service) FixSwappedTrack(ctx context.Context, trackID string) (string, string, error) {
+	track, err := s.GetTrackFileTags(ctx, trackID)
+	if err != nil {
+		return "", "", err
+	}
+	if track.IsLocked() {
+		return "", "", fmt.Errorf("%w: unlock it to swap its title and artist", music.ErrTrackLocked)
+	}
+	artists := mainArtists(track)
+	if len(artists) != 1 {
+		return "", "", fmt.Errorf("track %s has %d main artists, only tracks with one can be swapped", trackID, len(artists))
+	}
+	oldArtist, newTitle := artists[0], artists[0].Name
+	newArtist, err := s.libraryRepo.FindOrCreateArtist(ctx, strings.TrimSpace(track.Title))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get artist: %w", err)
+	}
+	for i, role := range track.Artists {
+		if role.Artist != nil && role.Artist.ID == oldArtist.ID {
+			track.Artists[i].Artist = newArtist
+		}
+	}
+	track.Title = newTitle
+
+	albumSwapped := false
+	if track.Album != nil && !track.Album.IsLocked() {
+		albumTracks, err := s.albumTracks(ctx, track.Album.ID)
+		if err != nil {
+			return "", "", err
+		}
+		if len(albumTracks) <= 1 {
+			for i, role := range track.Album.Artists {
+				if role.Artist != nil && role.Artist.ID == oldArtist.ID {
+					track.Album.Artists[i].Artist = newArtist
+					albumSwapped = true
+				}
+			}
+		}
+	}
+
+	if err := s.tagWriter.WriteFileTags(ctx, track.Path, track); err != nil {
+		return "", "", fmt.Errorf("failed to write tags of track %s: %w", trackID, err)
+	}
+	if albumSwapped {
+		if err := s.libraryRepo.UpdateAlbum(ctx, track.Album); err != nil {
+			return "", "", fmt.Errorf("failed to update album: %w", err)
+		}
+	}
+	track.ModifiedDate = time.Now()
+	if err := s.libraryRepo.UpdateTrack(ctx, track); err != nil {
+		return "", "", fmt.Errorf("failed to update track %s: %w", trackID, err)
+	}
+	slog.Info("Swapped track title and artist", "trackID", trackID, "title", track.Title, "artist", newArtist.Name, "album", albumSwapped)
+	return track.Title, newArtist.Name, nil
+}
+
+// mainArtists returns the main artists of a track.
+func mainArtists(track *music.Track) []*music.Artist {
+	var artists []*music.Artist
+	for _, role := range track.Artists {
+		if role.Artist != nil && (role.Role == "main" || role.Role == "") && !slices.Contains(artists, role.Artist) {
+			artists = append(artists, role.Artist)
+		}
+	}
+	return artists
+}
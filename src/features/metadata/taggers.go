@@ -17,4 +17,6 @@ type TagReader interface {
 // NOTE: Similar and atm using the same implementation of https://github.com/contre95/soulsolid/blob/f3b8b31c9e5fea2d53dfae36d435152272608f6f/src/features/downloading/tagger.go?plain=1#L9-L12
 type TagWriter interface {
 	WriteFileTags(ctx context.Context, filePath string, track *music.Track) error
+	CustomFields() []string
+	NotesComment() bool
 }
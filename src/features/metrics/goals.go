@@ -0,0 +1,110 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Goal criteria. Each one is measured per track with the same conditions as the completeness metrics.
+const (
+	GoalTagged   = "tagged"   // genre and year set
+	GoalLyrics   = "lyrics"   // lyrics present
+	GoalAcoustID = "acoustid" // AcoustID present
+	GoalISRC     = "isrc"     // ISRC present
+)
+
+// GoalCriteria lists the supported criteria with their display labels.
+var GoalCriteria = map[string]string{
+	GoalTagged:   "Tag",
+	GoalLyrics:   "Get lyrics for",
+	GoalAcoustID: "Fingerprint (AcoustID)",
+	GoalISRC:     "Find ISRCs for",
+}
+
+// Goal is a library challenge, e.g. "tag 100% of 90s tracks" or "get lyrics for every track in a playlist".
+// Scope fields narrow the tracks the goal applies to; empty ones mean the whole library.
+type Goal struct {
+	ID         string    `json:"id"`
+	Criterion  string    `json:"criterion"`
+	Decade     int       `json:"decade,omitempty"` // e.g. 1990 for the 90s
+	Genre      string    `json:"genre,omitempty"`
+	PlaylistID string    `json:"playlistId,omitempty"`
+	Target     int       `json:"target"` // percentage of scoped tracks, 1-100
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// Title returns a human readable description of the goal.
+func (g *Goal) Title() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %d%% of ", GoalCriteria[g.Criterion], g.Target)
+	switch {
+	case g.Decade > 0 && g.Genre != "":
+		fmt.Fprintf(&b, "%ds %s tracks", g.Decade, g.Genre)
+	case g.Decade > 0:
+		fmt.Fprintf(&b, "%ds tracks", g.Decade)
+	case g.Genre != "":
+		fmt.Fprintf(&b, "%s tracks", g.Genre)
+	default:
+		b.WriteString("the library")
+	}
+	if g.PlaylistID != "" {
+		b.WriteString(" in a playlist")
+	}
+	return b.String()
+}
+
+// GoalProgress is a goal together with its current progress.
+type GoalProgress struct {
+	Goal     *Goal `json:"goal"`
+	Done     int   `json:"done"`
+	Total    int   `json:"total"`
+	Percent  int   `json:"percent"`
+	Achieved bool  `json:"achieved"`
+}
+
+// AddGoal validates and stores a new goal.
+func (s *Service) AddGoal(ctx context.Context, goal *Goal) error {
+	if _, ok := GoalCriteria[goal.Criterion]; !ok {
+		return fmt.Errorf("unknown goal criterion %q", goal.Criterion)
+	}
+	if goal.Target < 1 || goal.Target > 100 {
+		return fmt.Errorf("goal target must be between 1 and 100")
+	}
+	if goal.Decade < 0 || goal.Decade%10 != 0 {
+		return fmt.Errorf("decade must be a year ending in 0")
+	}
+	goal.ID = uuid.New().String()
+	goal.CreatedAt = time.Now()
+	return s.metrics.AddGoal(ctx, goal)
+}
+
+// DeleteGoal removes a goal.
+func (s *Service) DeleteGoal(ctx context.Context, id string) error {
+	return s.metrics.DeleteGoal(ctx, id)
+}
+
+// GetGoalsProgress returns every goal with its current progress.
+func (s *Service) GetGoalsProgress(ctx context.Context) ([]GoalProgress, error) {
+	goals, err := s.metrics.GetGoals(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get goals: %w", err)
+	}
+	progress := make([]GoalProgress, 0, len(goals))
+	for _, g := range goals {
+		done, total, err := s.metrics.GetGoalProgress(ctx, g)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute progress of goal %s: %w", g.ID, err)
+		}
+		p := GoalProgress{Goal: g, Done: done, Total: total}
+		if total > 0 {
+			p.Percent = done * 100 / total
+		}
+		p.Achieved = total > 0 && p.Percent >= g.Target
+		progress = append(progress, p)
+	}
+	return progress, nil
+}
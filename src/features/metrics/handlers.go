@@ -2,6 +2,9 @@ package metrics
 
 import (
 	"log/slog"
+	"sort"
+	"strconv"
+	"time"
 
 	"github.com/contre95/soulsolid/src/features/hosting/respond"
 	"github.com/gofiber/fiber/v2"
@@ -27,7 +30,12 @@ func (h *Handler) GetMetricsOverview(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusInternalServerError).SendString("Error loading metrics")
 	}
 
-	return respond.Partial(c, "metrics/overview", fiber.Map{"Metrics": metrics})
+	status, err := h.service.GetStatus(c.Context())
+	if err != nil {
+		slog.Warn("Error loading metrics status", "error", err)
+	}
+
+	return respond.Partial(c, "metrics/overview", fiber.Map{"Metrics": metrics, "Status": status})
 }
 
 // GetGenreChartHTML returns genre chart as HTML fragment for HTMX.
@@ -82,63 +90,27 @@ func (h *Handler) GetFormatChartHTML(c *fiber.Ctx) error {
 func (h *Handler) GetMetadataChartHTML(c *fiber.Ctx) error {
 	slog.Debug("GetMetadataChartHTML handler called")
 
-	totalTracks, err := h.service.metrics.GetTotalTracks(c.Context())
+	coverage, err := h.service.GetFieldCoverage(c.Context())
 	if err != nil {
-		slog.Error("Error getting total tracks", "error", err)
+		slog.Error("Error getting field coverage", "error", err)
 		return c.Status(fiber.StatusInternalServerError).SendString("Error loading chart data")
 	}
 
-	if totalTracks == 0 {
+	if coverage.Total == 0 {
 		return respond.Partial(c, "metrics/charts/metadata_hbars", fiber.Map{
 			"ChartData": nil,
 		})
 	}
 
-	// Get counts for each metadata field
-	isrcCount, err := h.service.metrics.GetTracksWithISRC(c.Context())
-	if err != nil {
-		slog.Error("Error getting ISRC count", "error", err)
-		isrcCount = 0
-	}
-	bpmCount, err := h.service.metrics.GetTracksWithValidBPM(c.Context())
-	if err != nil {
-		slog.Error("Error getting BPM count", "error", err)
-		bpmCount = 0
-	}
-	yearCount, err := h.service.metrics.GetTracksWithValidYear(c.Context())
-	if err != nil {
-		slog.Error("Error getting year count", "error", err)
-		yearCount = 0
-	}
-	genreCount, err := h.service.metrics.GetTracksWithValidGenre(c.Context())
-	if err != nil {
-		slog.Error("Error getting genre count", "error", err)
-		genreCount = 0
-	}
-	lyricsStats, err := h.service.metrics.GetLyricsStats(c.Context())
-	if err != nil {
-		slog.Error("Error getting lyrics stats", "error", err)
-		lyricsStats = LyricsStats{}
-	}
-	acoustIDCount, err := h.service.metrics.GetTracksWithAcoustID(c.Context())
-	if err != nil {
-		slog.Error("Error getting AcoustID count", "error", err)
-		acoustIDCount = 0
-	}
-	chromaprintCount, err := h.service.metrics.GetTracksWithChromaprint(c.Context())
-	if err != nil {
-		slog.Error("Error getting Chromaprint count", "error", err)
-		chromaprintCount = 0
-	}
-
 	// Calculate percentages
-	isrcPct := float64(isrcCount) / float64(totalTracks) * 100
-	bpmPct := float64(bpmCount) / float64(totalTracks) * 100
-	yearPct := float64(yearCount) / float64(totalTracks) * 100
-	genrePct := float64(genreCount) / float64(totalTracks) * 100
-	lyricsPct := float64(lyricsStats.WithLyrics) / float64(totalTracks) * 100
-	acoustIDPct := float64(acoustIDCount) / float64(totalTracks) * 100
-	chromaprintPct := float64(chromaprintCount) / float64(totalTracks) * 100
+	totalTracks := float64(coverage.Total)
+	isrcPct := float64(coverage.ISRC) / totalTracks * 100
+	bpmPct := float64(coverage.BPM) / totalTracks * 100
+	yearPct := float64(coverage.Year) / totalTracks * 100
+	genrePct := float64(coverage.Genre) / totalTracks * 100
+	lyricsPct := float64(coverage.Lyrics) / totalTracks * 100
+	acoustIDPct := float64(coverage.AcoustID) / totalTracks * 100
+	chromaprintPct := float64(coverage.Chromaprint) / totalTracks * 100
 
 	labels := []string{"ISRC", "BPM", "Year", "Genre", "Lyrics", "AcoustID", "Fingerprint"}
 	data := []float64{isrcPct, bpmPct, yearPct, genrePct, lyricsPct, acoustIDPct, chromaprintPct}
@@ -153,3 +125,88 @@ func (h *Handler) GetMetadataChartHTML(c *fiber.Ctx) error {
 		"ChartData": chartData,
 	})
 }
+
+// GetStorageForecast renders the storage forecast dashboard card.
+func (h *Handler) GetStorageForecast(c *fiber.Ctx) error {
+	slog.Debug("GetStorageForecast handler called")
+	forecast, err := h.service.GetStorageForecast(c.Context())
+	if err != nil {
+		slog.Error("Error loading storage forecast", "error", err)
+		return respond.ToastErr(c, fiber.StatusInternalServerError, "Error loading storage forecast")
+	}
+	data := fiber.Map{"Forecast": forecast}
+	if forecast.Latest != nil {
+		data["Library"] = FormatBytes(forecast.Latest.LibraryBytes)
+		data["Free"] = FormatBytes(forecast.Latest.FreeBytes)
+		data["Total"] = FormatBytes(forecast.Latest.TotalBytes)
+		data["Growth"] = FormatBytes(forecast.GrowthPerDay)
+	}
+	return respond.Partial(c, "metrics/storage_forecast", data)
+}
+
+// GetGoals renders the goals dashboard card.
+func (h *Handler) GetGoals(c *fiber.Ctx) error {
+	slog.Debug("GetGoals handler called")
+	return h.renderGoals(c)
+}
+
+// CreateGoal stores a new goal and re-renders the goals card.
+func (h *Handler) CreateGoal(c *fiber.Ctx) error {
+	goal := &Goal{
+		Criterion:  c.FormValue("criterion"),
+		Decade:     parseFormInt(c.FormValue("decade")),
+		Genre:      c.FormValue("genre"),
+		PlaylistID: c.FormValue("playlistId"),
+		Target:     parseFormInt(c.FormValue("target")),
+	}
+	if err := h.service.AddGoal(c.Context(), goal); err != nil {
+		slog.Error("Failed to add goal", "error", err)
+		return respond.ToastErr(c, fiber.StatusBadRequest, "Failed to add goal: "+err.Error())
+	}
+	return h.renderGoals(c)
+}
+
+// DeleteGoal removes a goal and re-renders the goals card.
+func (h *Handler) DeleteGoal(c *fiber.Ctx) error {
+	if err := h.service.DeleteGoal(c.Context(), c.Params("id")); err != nil {
+		slog.Error("Failed to delete goal", "error", err, "id", c.Params("id"))
+		return respond.ToastErr(c, fiber.StatusInternalServerError, "Failed to delete goal")
+	}
+	return h.renderGoals(c)
+}
+
+func (h *Handler) renderGoals(c *fiber.Ctx) error {
+	goals, err := h.service.GetGoalsProgress(c.Context())
+	if err != nil {
+		slog.Error("Error loading goals", "error", err)
+		return c.Status(fiber.StatusInternalServerError).SendString("Error loading goals")
+	}
+
+	// Genres come from the cached genre metrics, so the list is as fresh as the last metrics run.
+	genreMetrics, err := h.service.getMetricsByType(c.Context(), "genre_counts")
+	if err != nil {
+		slog.Warn("Failed to get genres for goals form", "error", err)
+	}
+	genres := make([]string, 0, len(genreMetrics))
+	for _, m := range genreMetrics {
+		genres = append(genres, m.Key)
+	}
+	sort.Strings(genres)
+
+	var decades []int
+	for d := (time.Now().Year() / 10) * 10; d >= 1950; d -= 10 {
+		decades = append(decades, d)
+	}
+
+	return respond.Partial(c, "metrics/goals", fiber.Map{
+		"Goals":    goals,
+		"Criteria": GoalCriteria,
+		"Genres":   genres,
+		"Decades":  decades,
+	})
+}
+
+func parseFormInt(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
@@ -1,6 +1,9 @@
 package metrics
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // LibraryMetrics provides analytics and reporting functionality for the music library.
 type LibraryMetrics interface {
@@ -36,6 +39,16 @@ type LibraryMetrics interface {
 	StoreMetric(ctx context.Context, metricType, key string, value int) error
 	GetStoredMetrics(ctx context.Context, metricType string) ([]StoredMetric, error)
 	ClearStoredMetrics(ctx context.Context) error
+
+	// Goals storage and progress
+	AddGoal(ctx context.Context, goal *Goal) error
+	GetGoals(ctx context.Context) ([]*Goal, error)
+	DeleteGoal(ctx context.Context, id string) error
+	GetGoalProgress(ctx context.Context, goal *Goal) (done, total int, err error)
+
+	// Daily disk usage samples, one per day
+	RecordDiskUsage(ctx context.Context, usage *DiskUsage) error
+	GetDiskUsage(ctx context.Context, since time.Time) ([]DiskUsage, error)
 }
 
 // MetadataCompletenessStats represents the completeness of metadata across tracks.
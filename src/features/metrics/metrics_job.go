@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/contre95/soulsolid/src/music"
 )
@@ -61,6 +62,19 @@ func (t *MetricsCalculationTask) Execute(ctx context.Context, job *music.Job, pr
 		return nil, fmt.Errorf("failed to calculate year distribution: %w", err)
 	}
 
+	// Calculate and store the totals and the field coverage
+	if err := t.calculateAndStoreTotals(ctx, progressUpdater); err != nil {
+		return nil, fmt.Errorf("failed to calculate totals: %w", err)
+	}
+
+	// Mark the metrics as computed, the library changes are applied to them from now on
+	if err := t.storeMetric(ctx, "status", "changes", 0); err != nil {
+		return nil, fmt.Errorf("failed to store metrics status: %w", err)
+	}
+	if err := t.storeMetric(ctx, "status", "computed_at", int(time.Now().Unix())); err != nil {
+		return nil, fmt.Errorf("failed to store metrics status: %w", err)
+	}
+
 	progressUpdater(100, "Metrics calculation completed")
 	slog.Info("Metrics calculation completed successfully")
 
@@ -176,6 +190,34 @@ func (t *MetricsCalculationTask) calculateAndStoreYearDistribution(ctx context.C
 	return nil
 }
 
+// calculateAndStoreTotals calculates and stores the library totals and the field coverage.
+func (t *MetricsCalculationTask) calculateAndStoreTotals(ctx context.Context, progressUpdater func(int, string)) error {
+	progressUpdater(97, "Counting totals and field coverage")
+
+	totals := map[string]func(context.Context) (int, error){
+		"tracks":  t.metrics.GetTotalTracks,
+		"artists": t.metrics.GetTotalArtists,
+		"albums":  t.metrics.GetTotalAlbums,
+	}
+	for key, count := range totals {
+		value, err := count(ctx)
+		if err != nil {
+			return err
+		}
+		if err := t.storeMetric(ctx, "totals", key, value); err != nil {
+			return err
+		}
+	}
+
+	for key, value := range countFieldCoverage(ctx, t.metrics).fields() {
+		if err := t.storeMetric(ctx, "field_coverage", key, *value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // storeMetric stores a metric in the database.
 func (t *MetricsCalculationTask) storeMetric(ctx context.Context, metricType, key string, value int) error {
 	return t.metrics.StoreMetric(ctx, metricType, key, value)
@@ -0,0 +1,124 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+const (
+	// recomputeCheckInterval is how often the nightly recomputation schedule is checked.
+	recomputeCheckInterval = 10 * time.Minute
+	// staleAfter is the age past which the stored metrics are flagged as stale, a nightly run
+	// was missed.
+	staleAfter = 26 * time.Hour
+)
+
+// Jobs starts the full recomputation of the metrics.
+type Jobs interface {
+	StartJob(jobType string, name string, metadata map[string]any) (string, error)
+}
+
+// Status tells how fresh the stored metrics are. The counts and distributions follow every
+// library change; the metadata completeness and field coverage wait for the next recomputation.
+type Status struct {
+	Computed   bool      `json:"computed"`
+	ComputedAt time.Time `json:"computed_at,omitzero"`
+	Changes    int       `json:"changes"` // library changes applied incrementally since ComputedAt
+	Stale      bool      `json:"stale"`
+	NextRun    time.Time `json:"next_run,omitzero"` // zero when the nightly recomputation is disabled
+}
+
+// Age returns how long ago the metrics were computed, e.g. "3 hours".
+func (st *Status) Age() string {
+	age := time.Since(st.ComputedAt)
+	switch {
+	case age < time.Minute:
+		return "less than a minute"
+	case age < time.Hour:
+		return plural(int(age.Minutes()), "minute")
+	case age < 48*time.Hour:
+		return plural(int(age.Hours()), "hour")
+	default:
+		return plural(int(age.Hours()/24), "day")
+	}
+}
+
+func plural(n int, unit string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", unit)
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
+
+// GetStatus reads when the stored metrics were last computed and how many changes were
+// applied to them since.
+func (s *Service) GetStatus(ctx context.Context) (*Status, error) {
+	stored, err := s.metrics.GetStoredMetrics(ctx, "status")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get metrics status: %w", err)
+	}
+	status := &Status{}
+	for _, m := range stored {
+		switch m.Key {
+		case "computed_at":
+			status.Computed = true
+			status.ComputedAt = time.Unix(int64(m.Value), 0)
+		case "changes":
+			status.Changes = m.Value
+		}
+	}
+	status.Stale = !status.Computed || time.Since(status.ComputedAt) > staleAfter
+	if hour := s.configManager.Get().Metrics.RecomputeHour; hour >= 0 {
+		now := time.Now()
+		status.NextRun = time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, time.Local)
+		if !status.NextRun.After(now) {
+			status.NextRun = status.NextRun.AddDate(0, 0, 1)
+		}
+	}
+	return status, nil
+}
+
+// Recompute starts the job recomputing every stored metric from the library.
+func (s *Service) Recompute() (string, error) {
+	jobID, err := s.jobs.StartJob("calculate_metrics", "Metrics Calculation", map[string]any{})
+	if err != nil {
+		return "", fmt.Errorf("failed to start metrics calculation: %w", err)
+	}
+	return jobID, nil
+}
+
+// watchRecompute recomputes the metrics once a night at the configured hour, and at the first
+// check when they were never computed.
+func (s *Service) watchRecompute() {
+	var lastDay string
+	ticker := time.NewTicker(recomputeCheckInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		hour := s.configManager.Get().Metrics.RecomputeHour
+		if hour < 0 || s.paused.Load() {
+			continue
+		}
+		status, err := s.GetStatus(context.Background())
+		if err != nil {
+			slog.Warn("Failed to check the metrics status", "error", err)
+			continue
+		}
+		// A run missed while paused or down is caught up on the same day
+		now := time.Now()
+		today := now.Format(dayLayout)
+		scheduled := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, time.Local)
+		due := !now.Before(scheduled) && status.ComputedAt.Before(scheduled)
+		if (!due && status.Computed) || lastDay == today {
+			continue
+		}
+		jobID, err := s.Recompute()
+		if err != nil {
+			slog.Error("Failed to start the nightly metrics recomputation", "error", err)
+			continue
+		}
+		lastDay = today
+		slog.Info("Started the nightly metrics recomputation", "jobID", jobID, "changes", status.Changes)
+	}
+}
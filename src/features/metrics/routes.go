@@ -12,4 +12,8 @@ func RegisterRoutes(app *fiber.App, handler *Handler) {
 	metrics.Get("/charts/year", handler.GetYearChartHTML)
 	metrics.Get("/charts/format", handler.GetFormatChartHTML)
 	metrics.Get("/charts/metadata", handler.GetMetadataChartHTML)
+	metrics.Get("/storage/forecast", handler.GetStorageForecast)
+	metrics.Get("/goals", handler.GetGoals)
+	metrics.Post("/goals", handler.CreateGoal)
+	metrics.Delete("/goals/:id", handler.DeleteGoal)
 }
@@ -3,6 +3,8 @@ package metrics
 import (
 	"context"
 	"log/slog"
+	"sync"
+	"sync/atomic"
 
 	"github.com/contre95/soulsolid/src/features/config"
 )
@@ -10,15 +12,23 @@ import (
 // Service provides metrics functionality for the music library.
 type Service struct {
 	metrics       LibraryMetrics
+	jobs          Jobs
 	configManager *config.Manager
+	notifiersMu   sync.Mutex
+	notifiers     []Notifier
+	paused        atomic.Bool // maintenance mode: no disk usage sampling nor recomputation
 }
 
 // NewService creates a new metrics service.
-func NewService(metrics LibraryMetrics, cfgManager *config.Manager) *Service {
-	return &Service{
+func NewService(metrics LibraryMetrics, jobs Jobs, cfgManager *config.Manager) *Service {
+	s := &Service{
 		metrics:       metrics,
+		jobs:          jobs,
 		configManager: cfgManager,
 	}
+	go s.watchDiskUsage()
+	go s.watchRecompute()
+	return s
 }
 
 // Metric represents a single metric data point.
@@ -40,29 +50,30 @@ type MetricsData struct {
 	TotalAlbums          int      `json:"total_albums"`
 }
 
-// GetAllMetrics retrieves all stored metrics from the database.
+// GetAllMetrics retrieves all stored metrics from the database. The totals are counted live
+// until the metrics are computed for the first time.
 func (s *Service) GetAllMetrics(ctx context.Context) (*MetricsData, error) {
 	data := &MetricsData{}
 
-	// Get basic counts using LibraryMetrics interface
-	if totalTracks, err := s.metrics.GetTotalTracks(ctx); err != nil {
-		slog.Warn("Failed to get track count", "error", err)
-	} else {
-		data.TotalTracks = totalTracks
+	totals, err := s.getMetricsByType(ctx, "totals")
+	if err != nil {
+		slog.Warn("Failed to get stored totals", "error", err)
 	}
-	if totalArtists, err := s.metrics.GetTotalArtists(ctx); err != nil {
-		slog.Warn("Failed to get artist count", "error", err)
-	} else {
-		data.TotalArtists = totalArtists
+	if len(totals) == 0 {
+		s.countTotals(ctx, data)
 	}
-	if totalAlbums, err := s.metrics.GetTotalAlbums(ctx); err != nil {
-		slog.Warn("Failed to get album count", "error", err)
-	} else {
-		data.TotalAlbums = totalAlbums
+	for _, m := range totals {
+		switch m.Key {
+		case "tracks":
+			data.TotalTracks = m.Value
+		case "artists":
+			data.TotalArtists = m.Value
+		case "albums":
+			data.TotalAlbums = m.Value
+		}
 	}
 
 	// Get stored metrics
-	var err error
 	data.GenreCounts, err = s.getMetricsByType(ctx, "genre_counts")
 	if err != nil {
 		slog.Warn("Failed to get genre counts", "error", err)
@@ -91,6 +102,91 @@ func (s *Service) GetAllMetrics(ctx context.Context) (*MetricsData, error) {
 	return data, nil
 }
 
+// FieldCoverage counts the tracks having each metadata field.
+type FieldCoverage struct {
+	Total       int `json:"total"`
+	ISRC        int `json:"isrc"`
+	BPM         int `json:"bpm"`
+	Year        int `json:"year"`
+	Genre       int `json:"genre"`
+	Lyrics      int `json:"lyrics"`
+	AcoustID    int `json:"acoustid"`
+	Chromaprint int `json:"chromaprint"`
+}
+
+// fields maps the stored metric keys to the counters.
+func (f *FieldCoverage) fields() map[string]*int {
+	return map[string]*int{
+		"total": &f.Total, "isrc": &f.ISRC, "bpm": &f.BPM, "year": &f.Year, "genre": &f.Genre,
+		"lyrics": &f.Lyrics, "acoustid": &f.AcoustID, "chromaprint": &f.Chromaprint,
+	}
+}
+
+// GetFieldCoverage returns the field coverage stored by the last recomputation, or counts it
+// live when the metrics were never computed.
+func (s *Service) GetFieldCoverage(ctx context.Context) (*FieldCoverage, error) {
+	stored, err := s.getMetricsByType(ctx, "field_coverage")
+	if err != nil {
+		return nil, err
+	}
+	if len(stored) == 0 {
+		return countFieldCoverage(ctx, s.metrics), nil
+	}
+	coverage := &FieldCoverage{}
+	fields := coverage.fields()
+	for _, m := range stored {
+		if field, ok := fields[m.Key]; ok {
+			*field = m.Value
+		}
+	}
+	return coverage, nil
+}
+
+// countFieldCoverage counts the tracks having each metadata field. A failed count is logged
+// and left at 0.
+func countFieldCoverage(ctx context.Context, metrics LibraryMetrics) *FieldCoverage {
+	coverage := &FieldCoverage{}
+	count := func(name string, field *int, get func(context.Context) (int, error)) {
+		n, err := get(ctx)
+		if err != nil {
+			slog.Error("Error getting field count", "field", name, "error", err)
+			return
+		}
+		*field = n
+	}
+	count("total", &coverage.Total, metrics.GetTotalTracks)
+	count("ISRC", &coverage.ISRC, metrics.GetTracksWithISRC)
+	count("BPM", &coverage.BPM, metrics.GetTracksWithValidBPM)
+	count("year", &coverage.Year, metrics.GetTracksWithValidYear)
+	count("genre", &coverage.Genre, metrics.GetTracksWithValidGenre)
+	count("lyrics", &coverage.Lyrics, func(ctx context.Context) (int, error) {
+		stats, err := metrics.GetLyricsStats(ctx)
+		return stats.WithLyrics, err
+	})
+	count("AcoustID", &coverage.AcoustID, metrics.GetTracksWithAcoustID)
+	count("Chromaprint", &coverage.Chromaprint, metrics.GetTracksWithChromaprint)
+	return coverage
+}
+
+// countTotals counts the tracks, artists and albums of the library live.
+func (s *Service) countTotals(ctx context.Context, data *MetricsData) {
+	if totalTracks, err := s.metrics.GetTotalTracks(ctx); err != nil {
+		slog.Warn("Failed to get track count", "error", err)
+	} else {
+		data.TotalTracks = totalTracks
+	}
+	if totalArtists, err := s.metrics.GetTotalArtists(ctx); err != nil {
+		slog.Warn("Failed to get artist count", "error", err)
+	} else {
+		data.TotalArtists = totalArtists
+	}
+	if totalAlbums, err := s.metrics.GetTotalAlbums(ctx); err != nil {
+		slog.Warn("Failed to get album count", "error", err)
+	} else {
+		data.TotalAlbums = totalAlbums
+	}
+}
+
 // convertMapToMetrics converts a map[string]int to []Metric
 func convertMapToMetrics(data map[string]int, metricType string) []Metric {
 	metrics := make([]Metric, 0, len(data))
@@ -0,0 +1,223 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"math"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+const (
+	// diskUsageInterval is how often the sampler checks whether today's sample is taken.
+	diskUsageInterval = time.Hour
+	// forecastWindow is how far back the growth rate is measured.
+	forecastWindow = 30 * 24 * time.Hour
+	dayLayout      = "2006-01-02"
+)
+
+// Notifier sends a message to the user, e.g. the Telegram bot.
+type Notifier interface {
+	Notify(message string)
+}
+
+// DiskUsage is the daily sample of the library size and the space left on its disk.
+type DiskUsage struct {
+	Day          time.Time `json:"day"`
+	LibraryBytes int64     `json:"library_bytes"`
+	FreeBytes    int64     `json:"free_bytes"`
+	TotalBytes   int64     `json:"total_bytes"`
+}
+
+// StorageForecast estimates when the disk holding the library runs out of space if the
+// library keeps growing at the rate of the last samples.
+type StorageForecast struct {
+	Latest       *DiskUsage  `json:"latest,omitempty"`
+	Samples      []DiskUsage `json:"samples"`
+	GrowthPerDay int64       `json:"growth_per_day"` // bytes, 0 when the library is not growing
+	DaysLeft     int         `json:"days_left"`      // -1 when there is no estimate
+	FullOn       time.Time   `json:"full_on,omitzero"`
+	AlertDays    int         `json:"alert_days"`
+	Alert        bool        `json:"alert"`
+}
+
+// UsedPercent returns how full the disk is.
+func (f *StorageForecast) UsedPercent() int {
+	if f.Latest == nil || f.Latest.TotalBytes == 0 {
+		return 0
+	}
+	return int((f.Latest.TotalBytes - f.Latest.FreeBytes) * 100 / f.Latest.TotalBytes)
+}
+
+// AddNotifier registers a notifier for the storage alerts.
+func (s *Service) AddNotifier(n Notifier) {
+	s.notifiersMu.Lock()
+	defer s.notifiersMu.Unlock()
+	s.notifiers = append(s.notifiers, n)
+}
+
+// SampleDiskUsage measures the library and its disk and stores it as today's sample.
+func (s *Service) SampleDiskUsage(ctx context.Context) (*DiskUsage, error) {
+	libraryPath := s.configManager.Get().LibraryPath
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(libraryPath, &stat); err != nil {
+		return nil, fmt.Errorf("failed to stat filesystem of %s: %w", libraryPath, err)
+	}
+	size, err := dirSize(libraryPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to measure %s: %w", libraryPath, err)
+	}
+	now := time.Now()
+	usage := &DiskUsage{
+		Day:          time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local),
+		LibraryBytes: size,
+		FreeBytes:    int64(stat.Bavail) * int64(stat.Bsize),
+		TotalBytes:   int64(stat.Blocks) * int64(stat.Bsize),
+	}
+	if err := s.metrics.RecordDiskUsage(ctx, usage); err != nil {
+		return nil, fmt.Errorf("failed to store disk usage: %w", err)
+	}
+	return usage, nil
+}
+
+// GetStorageForecast fits a line through the recent samples of the library size and projects
+// it against the free space of the latest sample.
+func (s *Service) GetStorageForecast(ctx context.Context) (*StorageForecast, error) {
+	samples, err := s.metrics.GetDiskUsage(ctx, time.Now().Add(-forecastWindow))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get disk usage: %w", err)
+	}
+	forecast := &StorageForecast{
+		Samples:   samples,
+		DaysLeft:  -1,
+		AlertDays: s.configManager.Get().Storage.AlertDays,
+	}
+	if len(samples) == 0 {
+		return forecast, nil
+	}
+	forecast.Latest = &samples[len(samples)-1]
+	forecast.GrowthPerDay = growthPerDay(samples)
+	if forecast.GrowthPerDay > 0 {
+		forecast.DaysLeft = int(forecast.Latest.FreeBytes / forecast.GrowthPerDay)
+		forecast.FullOn = forecast.Latest.Day.AddDate(0, 0, forecast.DaysLeft)
+	}
+	forecast.Alert = forecast.AlertDays > 0 && forecast.DaysLeft >= 0 && forecast.DaysLeft <= forecast.AlertDays
+	return forecast, nil
+}
+
+// growthPerDay returns the least squares slope of the library size in bytes per day.
+func growthPerDay(samples []DiskUsage) int64 {
+	if len(samples) < 2 {
+		return 0
+	}
+	first := samples[0].Day
+	var n, sumX, sumY, sumXY, sumXX float64
+	for _, u := range samples {
+		x := u.Day.Sub(first).Hours() / 24
+		y := float64(u.LibraryBytes)
+		n++
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return 0
+	}
+	slope := (n*sumXY - sumX*sumY) / denominator
+	if slope <= 0 {
+		return 0
+	}
+	return int64(math.Round(slope))
+}
+
+// watchDiskUsage takes a disk usage sample once a day and alerts when the forecast drops
+// under the configured threshold. The alert is sent once until the forecast recovers.
+func (s *Service) watchDiskUsage() {
+	var lastDay string
+	alerted := false
+	ticker := time.NewTicker(diskUsageInterval)
+	defer ticker.Stop()
+	for ; true; <-ticker.C {
+		today := time.Now().Format(dayLayout)
+		if today == lastDay || s.paused.Load() {
+			continue
+		}
+		ctx := context.Background()
+		if _, err := s.SampleDiskUsage(ctx); err != nil {
+			slog.Warn("Failed to sample disk usage", "error", err)
+			continue
+		}
+		lastDay = today
+		forecast, err := s.GetStorageForecast(ctx)
+		if err != nil {
+			slog.Warn("Failed to forecast storage", "error", err)
+			continue
+		}
+		if !forecast.Alert {
+			alerted = false
+			continue
+		}
+		slog.Warn("Library disk is running out of space", "daysLeft", forecast.DaysLeft, "freeBytes", forecast.Latest.FreeBytes)
+		if !alerted {
+			alerted = true
+			s.notify(fmt.Sprintf("💾 The library disk will be full in about %d days (%s free, growing %s/day)",
+				forecast.DaysLeft, FormatBytes(forecast.Latest.FreeBytes), FormatBytes(forecast.GrowthPerDay)))
+		}
+	}
+}
+
+// Pause stops the disk usage sampling and the nightly recomputation for maintenance mode.
+func (s *Service) Pause() { s.paused.Store(true) }
+
+// Resume restarts the disk usage sampling and the nightly recomputation, catching up on a missed
+// sample or run.
+func (s *Service) Resume() { s.paused.Store(false) }
+
+func (s *Service) notify(message string) {
+	s.notifiersMu.Lock()
+	notifiers := append([]Notifier(nil), s.notifiers...)
+	s.notifiersMu.Unlock()
+	for _, n := range notifiers {
+		n.Notify(message)
+	}
+}
+
+func dirSize(root string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+		return nil
+	})
+	return size, err
+}
+
+// FormatBytes formats a size in decimal units, e.g. "1.2 GB".
+func FormatBytes(size int64) string {
+	switch {
+	case size >= 1_000_000_000_000:
+		return fmt.Sprintf("%.1f TB", float64(size)/math.Pow(10, 12))
+	case size >= 1_000_000_000:
+		return fmt.Sprintf("%.1f GB", float64(size)/math.Pow(10, 9))
+	case size >= 1_000_000:
+		return fmt.Sprintf("%.1f MB", float64(size)/math.Pow(10, 6))
+	case size >= 1_000:
+		return fmt.Sprintf("%.1f KB", float64(size)/math.Pow(10, 3))
+	default:
+		return fmt.Sprintf("%d B", size)
+	}
+}
@@ -0,0 +1,123 @@
+package migration
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/contre95/soulsolid/src/features/config"
+	"github.com/contre95/soulsolid/src/features/hosting/respond"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Handler handles the export and import of the instance.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new migration handler.
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// GetPanel renders the migration card of the settings section.
+func (h *Handler) GetPanel(c *fiber.Ctx) error {
+	return respond.Partial(c, "cards/migration", fiber.Map{
+		"Staged": h.service.Staged(),
+		"Admin":  h.service.config.IsAdmin(config.RequestUser(c)),
+	})
+}
+
+// RequireAdmin lets only the admins of server.admins through. An import replaces the whole
+// config, so unlike a settings change it can't wait for an admin's approval.
+func (h *Handler) RequireAdmin(c *fiber.Ctx) error {
+	return h.requireAdmin(c, "only admins can import an instance")
+}
+
+// RequireAdminForSecrets lets only admins export the secrets, anyone can export without them.
+func (h *Handler) RequireAdminForSecrets(c *fiber.Ctx) error {
+	if !c.QueryBool("secrets") {
+		return c.Next()
+	}
+	return h.requireAdmin(c, "only admins can export the secrets")
+}
+
+func (h *Handler) requireAdmin(c *fiber.Ctx, message string) error {
+	if !h.service.config.IsAdmin(config.RequestUser(c)) {
+		return respond.ToastErr(c, fiber.StatusForbidden, message)
+	}
+	return c.Next()
+}
+
+// Export sends the bundle of the instance (query: secrets).
+func (h *Handler) Export(c *fiber.Ctx) error {
+	includeSecrets := c.QueryBool("secrets")
+	url := fmt.Sprintf("%s/migration/export?secrets=%t", c.BaseURL(), includeSecrets)
+	return respond.Resource(c, "application/zip", url, func() error {
+		f, err := os.CreateTemp("", "soulsolid-export-*.zip")
+		if err != nil {
+			return respond.ToastErr(c, fiber.StatusInternalServerError, "Failed to create export: "+err.Error())
+		}
+		// Unlinked right away, the open file is read until sent and the space freed once closed
+		os.Remove(f.Name())
+		if _, err := h.service.Export(c.Context(), f, includeSecrets); err != nil {
+			f.Close()
+			slog.Error("Failed to export instance", "error", err)
+			return respond.ToastErr(c, fiber.StatusInternalServerError, "Failed to export: "+err.Error())
+		}
+		size, err := f.Seek(0, io.SeekCurrent)
+		if err == nil {
+			_, err = f.Seek(0, io.SeekStart)
+		}
+		if err != nil {
+			f.Close()
+			return respond.ToastErr(c, fiber.StatusInternalServerError, "Failed to export: "+err.Error())
+		}
+		c.Set("Content-Type", "application/zip")
+		c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"soulsolid-export-%s.zip\"", time.Now().Format("20060102-150405")))
+		// The file is closed by fasthttp once it's sent
+		return c.SendStream(f, int(size))
+	})
+}
+
+// Import stages an uploaded bundle (form: bundle), applied at the next restart.
+func (h *Handler) Import(c *fiber.Ctx) error {
+	file, err := c.FormFile("bundle")
+	if err != nil {
+		return respond.ToastErr(c, fiber.StatusBadRequest, "No export file uploaded")
+	}
+	upload, err := os.CreateTemp("", "soulsolid-import-*.zip")
+	if err != nil {
+		return respond.ToastErr(c, fiber.StatusInternalServerError, "Failed to receive export: "+err.Error())
+	}
+	upload.Close()
+	defer os.Remove(upload.Name())
+	if err := c.SaveFile(file, upload.Name()); err != nil {
+		return respond.ToastErr(c, fiber.StatusInternalServerError, "Failed to receive export: "+err.Error())
+	}
+	staged, err := h.service.Stage(upload.Name())
+	if err != nil {
+		slog.Error("Failed to stage instance import", "file", file.Filename, "error", err)
+		return respond.ToastErr(c, fiber.StatusBadRequest, err.Error())
+	}
+	c.Set("HX-Trigger", "migrationChanged")
+	if c.Get("HX-Request") != "true" {
+		return c.JSON(staged)
+	}
+	msg := "Import staged, restart Soulsolid to apply it"
+	if len(staged.Warnings) > 0 {
+		msg = fmt.Sprintf("%s (%d warnings, see Settings)", msg, len(staged.Warnings))
+	}
+	return respond.ToastOk(c, msg)
+}
+
+// CancelImport discards the staged import.
+func (h *Handler) CancelImport(c *fiber.Ctx) error {
+	if err := h.service.CancelStaged(); err != nil {
+		return respond.ToastErr(c, fiber.StatusInternalServerError, "Failed to cancel import: "+err.Error())
+	}
+	c.Set("HX-Trigger", "migrationChanged")
+	return respond.ToastOk(c, "Staged import discarded")
+}
@@ -0,0 +1,343 @@
+package migration
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/contre95/soulsolid/src/features/config"
+	"gopkg.in/yaml.v3"
+)
+
+// backupSuffix is appended to the files an import replaces, followed by when the import was
+// staged. They are kept until removed by hand.
+const backupSuffix = ".before-restore"
+
+// stagedFile marks a complete staging folder, it holds the manifest and the warnings of the import.
+const stagedFile = "staged.json"
+
+// Staged is a bundle staged for import, with what needs attention before the restart applying it.
+type Staged struct {
+	Manifest *Manifest `json:"manifest"`
+	Warnings []string  `json:"warnings"`
+	StagedAt time.Time `json:"staged_at"`
+}
+
+// stagingDir is where a bundle is unpacked until the next start applies it. It sits next to
+// the config so it lands on the same volume.
+func stagingDir(configPath string) string {
+	return filepath.Join(filepath.Dir(configPath), "restore")
+}
+
+// Stage checks a bundle and unpacks it for the next start to apply. Nothing in use is
+// replaced while running: the database, the config and the job files are swapped before they
+// are opened. A bundle staged earlier is discarded.
+func (s *Service) Stage(bundlePath string) (*Staged, error) {
+	r, err := zip.OpenReader(bundlePath)
+	if err != nil {
+		return nil, fmt.Errorf("not a Soulsolid export: %w", err)
+	}
+	defer r.Close()
+
+	entries := map[string]*zip.File{}
+	for _, f := range r.File {
+		entries[f.Name] = f
+	}
+	manifest, err := readManifest(entries[manifestFile])
+	if err != nil {
+		return nil, err
+	}
+	names := map[string]bool{}
+	for _, file := range manifest.Files {
+		if !bundledName(file.Name) {
+			return nil, fmt.Errorf("unexpected file %q in bundle", file.Name)
+		}
+		if entries[file.Name] == nil {
+			return nil, fmt.Errorf("bundle is missing %s", file.Name)
+		}
+		names[file.Name] = true
+	}
+	for _, required := range []string{databaseFile, configFile} {
+		if !names[required] {
+			return nil, fmt.Errorf("bundle is missing %s", required)
+		}
+	}
+
+	dir := stagingDir(s.config.Path())
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, fmt.Errorf("failed to clear previous import: %w", err)
+	}
+	for _, file := range manifest.Files {
+		if file.Name == manifestFile {
+			continue
+		}
+		if err := unpack(entries[file.Name], file, filepath.Join(dir, filepath.FromSlash(file.Name))); err != nil {
+			os.RemoveAll(dir)
+			return nil, err
+		}
+	}
+	// Written last, a staging folder without it is never applied
+	staged := &Staged{Manifest: manifest, Warnings: stagingWarnings(manifest), StagedAt: time.Now()}
+	data, err := json.Marshal(staged)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, stagedFile), data, 0600); err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("failed to stage import: %w", err)
+	}
+	slog.Info("Staged instance import", "created", manifest.CreatedAt, "files", len(manifest.Files), "dir", dir)
+	return staged, nil
+}
+
+// CancelStaged discards a staged import.
+func (s *Service) CancelStaged() error {
+	return os.RemoveAll(stagingDir(s.config.Path()))
+}
+
+// Staged returns the import waiting for a restart, nil when there is none.
+func (s *Service) Staged() *Staged {
+	staged, err := readStaged(stagingDir(s.config.Path()))
+	if err != nil {
+		return nil
+	}
+	return staged
+}
+
+func readManifest(f *zip.File) (*Manifest, error) {
+	if f == nil {
+		return nil, fmt.Errorf("not a Soulsolid export: %s is missing", manifestFile)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	var manifest Manifest
+	if err := json.NewDecoder(io.LimitReader(rc, 16<<20)).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	if manifest.Version != bundleVersion {
+		return nil, fmt.Errorf("bundle version %d is not supported by this version of Soulsolid, which reads version %d", manifest.Version, bundleVersion)
+	}
+	return &manifest, nil
+}
+
+func readStaged(dir string) (*Staged, error) {
+	data, err := os.ReadFile(filepath.Join(dir, stagedFile))
+	if err != nil {
+		return nil, err
+	}
+	var staged Staged
+	if err := json.Unmarshal(data, &staged); err != nil || staged.Manifest == nil {
+		return nil, fmt.Errorf("invalid staged import in %s", dir)
+	}
+	return &staged, nil
+}
+
+// bundledName reports whether name is one of the entries a bundle holds, so an entry can't be
+// unpacked outside the staging folder.
+func bundledName(name string) bool {
+	if !filepath.IsLocal(name) || strings.Contains(name, `\`) {
+		return false
+	}
+	switch name {
+	case manifestFile, configFile, historyFile, databaseFile, diagnosticsFile:
+		return true
+	}
+	for _, dir := range []string{jobsDir, playlistsDir} {
+		if rest, ok := strings.CutPrefix(name, dir); ok && rest != "" && !strings.Contains(rest, "/") {
+			return true
+		}
+	}
+	return false
+}
+
+// unpack copies an entry of the bundle to dest, checking it against the manifest.
+func unpack(f *zip.File, file BundleFile, dest string) error {
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", file.Name, err)
+	}
+	defer rc.Close()
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(out, hash), rc)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to unpack %s: %w", file.Name, err)
+	}
+	if size != file.Size || hex.EncodeToString(hash.Sum(nil)) != file.SHA256 {
+		return fmt.Errorf("%s doesn't match its checksum, the bundle is corrupted", file.Name)
+	}
+	return nil
+}
+
+// stagingWarnings lists what the bundle can't bring along: the folders that must be copied or
+// mounted on this machine and the secrets that must be set again.
+func stagingWarnings(manifest *Manifest) []string {
+	warnings := []string{}
+	for _, location := range manifest.Locations {
+		if location.Path == "" {
+			continue
+		}
+		if _, err := os.Stat(location.Path); err != nil {
+			warnings = append(warnings, fmt.Sprintf("The %s folder %s is missing on this machine, copy or mount it before restarting", location.Name, location.Path))
+			continue
+		}
+		if files, _ := measure(location.Path); files < location.Files {
+			warnings = append(warnings, fmt.Sprintf("The %s folder %s holds %d files, %d when exported", location.Name, location.Path, files, location.Files))
+		}
+	}
+	if len(manifest.ExcludedSecrets) > 0 {
+		warnings = append(warnings, fmt.Sprintf("Secrets were left out of the export, set them again after the restart: %s", strings.Join(manifest.ExcludedSecrets, ", ")))
+	}
+	return warnings
+}
+
+// restoredPaths are the settings of the bundled config telling where the restored files go.
+type restoredPaths struct {
+	Database struct {
+		Path string `yaml:"path"`
+	} `yaml:"database"`
+	Jobs struct {
+		LogPath string `yaml:"log_path"`
+	} `yaml:"jobs"`
+	Diagnostics struct {
+		Path string `yaml:"path"`
+	} `yaml:"diagnostics"`
+}
+
+// ApplyStaged applies the import staged next to the config file, if any, and reports whether
+// there was one. It runs at startup before the config and the database are opened. Every
+// replaced file is kept with a ".before-restore-<staged at>" suffix, so every import keeps its own
+// backups. A failed import is retried at the next start, its first backups are never overwritten.
+func ApplyStaged(configPath string) (bool, error) {
+	dir := stagingDir(configPath)
+	staged, err := readStaged(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	manifest := staged.Manifest
+	stagedAt := staged.StagedAt
+	if stagedAt.IsZero() {
+		stagedAt = manifest.CreatedAt
+	}
+	suffix := backupSuffix + "-" + stagedAt.Format("20060102-150405")
+
+	var paths restoredPaths
+	data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(configFile)))
+	if err != nil {
+		return false, fmt.Errorf("failed to read staged config: %w", err)
+	}
+	if err := yaml.Unmarshal(data, &paths); err != nil {
+		return false, fmt.Errorf("failed to parse staged config: %w", err)
+	}
+	if paths.Database.Path == "" {
+		return false, fmt.Errorf("staged config has no database path")
+	}
+
+	// The config goes last: until it's in place a failed import leaves the old instance as it was
+	var files []BundleFile
+	for _, file := range manifest.Files {
+		if file.Name != configFile {
+			files = append(files, file)
+		}
+	}
+	for _, file := range append(files, BundleFile{Name: configFile}) {
+		dest := ""
+		switch {
+		case file.Name == configFile:
+			dest = configPath
+		case file.Name == historyFile:
+			dest = config.HistoryFile(configPath)
+		case file.Name == databaseFile:
+			dest = paths.Database.Path
+			// A journal left by the old database would be replayed into the restored one
+			for _, journal := range []string{"-wal", "-shm", "-journal"} {
+				if err := backup(dest+journal, suffix); err != nil {
+					return false, err
+				}
+				if err := os.Remove(dest + journal); err != nil && !errors.Is(err, fs.ErrNotExist) {
+					return false, err
+				}
+			}
+		case file.Name == diagnosticsFile:
+			dest = paths.Diagnostics.Path
+		case strings.HasPrefix(file.Name, jobsDir) && paths.Jobs.LogPath != "":
+			dest = filepath.Join(paths.Jobs.LogPath, strings.TrimPrefix(file.Name, jobsDir))
+		}
+		if dest == "" {
+			continue // playlists come back with the database
+		}
+		if err := backup(dest, suffix); err != nil {
+			return false, err
+		}
+		if err := copyFile(filepath.Join(dir, filepath.FromSlash(file.Name)), dest); err != nil {
+			return false, fmt.Errorf("failed to restore %s: %w", file.Name, err)
+		}
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return false, fmt.Errorf("failed to remove staged import: %w", err)
+	}
+	slog.Info("Applied instance import, replaced files were kept with the "+suffix+" suffix", "created", manifest.CreatedAt, "files", len(manifest.Files), "database", paths.Database.Path)
+	return true, nil
+}
+
+// backup copies path aside with suffix, unless a retry of the same import already did. It copies
+// rather than renames, so a config file bind-mounted into a container can be replaced in place.
+func backup(path, suffix string) error {
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if _, err := os.Stat(path + suffix); err == nil {
+		return nil
+	}
+	if err := copyFile(path, path+suffix); err != nil {
+		return fmt.Errorf("failed to back up %s: %w", path, err)
+	}
+	return nil
+}
+
+// copyFile copies rather than renames, the destination may be on another volume.
+func copyFile(src, dest string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
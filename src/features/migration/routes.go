@@ -0,0 +1,14 @@
+package migration
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// RegisterRoutes registers the migration routes with the Fiber app.
+func RegisterRoutes(app *fiber.App, handler *Handler) {
+	group := app.Group("/migration")
+	group.Get("/panel", handler.GetPanel)
+	group.Get("/export", handler.RequireAdminForSecrets, handler.Export)
+	group.Post("/import", handler.RequireAdmin, handler.Import)
+	group.Delete("/import", handler.RequireAdmin, handler.CancelImport)
+}
@@ -0,0 +1,286 @@
+package migration
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/contre95/soulsolid/src/features/config"
+	"github.com/contre95/soulsolid/src/music"
+	"gopkg.in/yaml.v3"
+)
+
+// bundleVersion is bumped when the layout of the bundle changes in a way older instances can't import.
+const bundleVersion = 1
+
+// Names of the entries of a bundle. Job files keep their names under jobsDir, playlists are
+// named after the playlist under playlistsDir.
+const (
+	manifestFile    = "manifest.json"
+	configFile      = "config/config.yaml"
+	historyFile     = "config/config_history.jsonl"
+	databaseFile    = "database/library.db"
+	diagnosticsFile = "diagnostics/usage.json"
+	jobsDir         = "jobs/"
+	playlistsDir    = "playlists/"
+)
+
+// Manifest describes a bundle. It is the last entry written and the first one read.
+type Manifest struct {
+	Version         int          `json:"version"`
+	CreatedAt       time.Time    `json:"created_at"`
+	SecretsIncluded bool         `json:"secrets_included"`
+	ExcludedSecrets []string     `json:"excluded_secrets,omitempty"` // settings emptied in the bundled config
+	Files           []BundleFile `json:"files"`
+	Locations       []Location   `json:"locations"`
+}
+
+// BundleFile is an entry of the bundle, checked against its checksum on import.
+type BundleFile struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Location is a folder too big to be bundled, like the library. The manifest keeps what it
+// held so the import can tell whether it was copied or mounted on the new machine.
+type Location struct {
+	Name  string `json:"name"`
+	Path  string `json:"path"`
+	Files int    `json:"files"`
+	Bytes int64  `json:"bytes"`
+}
+
+// Snapshotter copies the database while it's in use.
+type Snapshotter interface {
+	Snapshot(ctx context.Context, dest string) error
+}
+
+// Playlists exports the playlists as M3U files, bundled for use outside Soulsolid. The import
+// restores them from the database.
+type Playlists interface {
+	GetAllPlaylists(ctx context.Context) ([]*music.Playlist, error)
+	ExportM3U(ctx context.Context, playlistID, filePath string) error
+}
+
+// Service exports the state of the instance to a single bundle and stages bundles for import,
+// to move an instance to another machine or volume. The music itself is not bundled.
+type Service struct {
+	config    *config.Manager
+	db        Snapshotter
+	playlists Playlists
+}
+
+// NewService creates a new migration service.
+func NewService(cfg *config.Manager, db Snapshotter, playlists Playlists) *Service {
+	return &Service{config: cfg, db: db, playlists: playlists}
+}
+
+// bundleWriter adds entries to a bundle and records them for the manifest.
+type bundleWriter struct {
+	zip   *zip.Writer
+	files []BundleFile
+}
+
+func (b *bundleWriter) add(name string, r io.Reader) error {
+	w, err := b.zip.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now()})
+	if err != nil {
+		return err
+	}
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(w, hash), r)
+	if err != nil {
+		return fmt.Errorf("failed to bundle %s: %w", name, err)
+	}
+	b.files = append(b.files, BundleFile{Name: name, Size: size, SHA256: hex.EncodeToString(hash.Sum(nil))})
+	return nil
+}
+
+func (b *bundleWriter) addFile(name, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return b.add(name, f)
+}
+
+// Export writes a bundle of the database, the config, the job history, the diagnostics and
+// the playlists to w. Without includeSecrets the secret settings of the config are emptied and
+// the config history, which holds whole configs, is left out.
+func (s *Service) Export(ctx context.Context, w io.Writer, includeSecrets bool) (*Manifest, error) {
+	cfg := s.config.Get()
+	work, err := os.MkdirTemp("", "soulsolid-export-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create work folder: %w", err)
+	}
+	defer os.RemoveAll(work)
+
+	manifest := &Manifest{Version: bundleVersion, CreatedAt: time.Now(), SecretsIncluded: includeSecrets}
+	bundle := &bundleWriter{zip: zip.NewWriter(w)}
+
+	snapshot := filepath.Join(work, "library.db")
+	if err := s.db.Snapshot(ctx, snapshot); err != nil {
+		return nil, err
+	}
+	if err := bundle.addFile(databaseFile, snapshot); err != nil {
+		return nil, err
+	}
+
+	if includeSecrets {
+		// The file as written, so !env_var references stay references
+		if err := bundle.addFile(configFile, s.config.Path()); err != nil {
+			return nil, fmt.Errorf("failed to bundle config: %w", err)
+		}
+		if err := bundle.addFile(historyFile, config.HistoryFile(s.config.Path())); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("failed to bundle config history: %w", err)
+		}
+	} else {
+		clean, stripped, err := config.StripSecrets(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to strip secrets: %w", err)
+		}
+		var buf bytes.Buffer
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(clean); err != nil {
+			return nil, fmt.Errorf("failed to encode config: %w", err)
+		}
+		if err := bundle.add(configFile, &buf); err != nil {
+			return nil, err
+		}
+		manifest.ExcludedSecrets = stripped
+	}
+
+	if err := s.addJobFiles(bundle, cfg.Jobs.LogPath); err != nil {
+		return nil, err
+	}
+	if cfg.Diagnostics.Path != "" {
+		if err := bundle.addFile(diagnosticsFile, cfg.Diagnostics.Path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("failed to bundle diagnostics: %w", err)
+		}
+	}
+	if err := s.addPlaylists(ctx, bundle, work); err != nil {
+		return nil, err
+	}
+
+	manifest.Locations = locations(cfg)
+	manifest.Files = bundle.files
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := bundle.add(manifestFile, bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	if err := bundle.zip.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish bundle: %w", err)
+	}
+	slog.Info("Exported instance", "files", len(manifest.Files), "secrets", includeSecrets)
+	return manifest, nil
+}
+
+// addJobFiles bundles the job logs and the job timeline. Only those are taken, the log folder
+// may be shared with other files.
+func (s *Service) addJobFiles(bundle *bundleWriter, logPath string) error {
+	if logPath == "" {
+		return nil
+	}
+	entries, err := os.ReadDir(logPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read job logs: %w", err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.Type().IsRegular() || (!strings.HasSuffix(name, ".log") && name != "timeline.jsonl") {
+			continue
+		}
+		if err := bundle.addFile(jobsDir+name, filepath.Join(logPath, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Service) addPlaylists(ctx context.Context, bundle *bundleWriter, work string) error {
+	playlists, err := s.playlists.GetAllPlaylists(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list playlists: %w", err)
+	}
+	seen := map[string]bool{}
+	for _, playlist := range playlists {
+		name := playlistFileName(playlist.Name)
+		if seen[name] {
+			name = playlistFileName(playlist.Name + " " + playlist.ID)
+		}
+		seen[name] = true
+		path := filepath.Join(work, name)
+		if err := s.playlists.ExportM3U(ctx, playlist.ID, path); err != nil {
+			return fmt.Errorf("failed to export playlist %s: %w", playlist.Name, err)
+		}
+		if err := bundle.addFile(playlistsDir+name, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// playlistFileName names the M3U of a playlist, without the characters a path can't hold.
+func playlistFileName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) || r < ' ' {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(name))
+	if name == "" || strings.Trim(name, ".") == "" {
+		name = "playlist"
+	}
+	return name + ".m3u"
+}
+
+// locations measures the folders left out of the bundle.
+func locations(cfg *config.Config) []Location {
+	folders := []Location{
+		{Name: "library", Path: cfg.LibraryPath},
+		{Name: "downloads", Path: cfg.DownloadPath},
+	}
+	if cfg.Import.TrashPath != "" {
+		folders = append(folders, Location{Name: "trash", Path: cfg.Import.TrashPath})
+	}
+	for i := range folders {
+		folders[i].Files, folders[i].Bytes = measure(folders[i].Path)
+	}
+	return folders
+}
+
+// measure counts the files under a folder and their size, unreadable entries are skipped.
+func measure(root string) (int, int64) {
+	files, size := 0, int64(0)
+	filepath.WalkDir(root, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			files++
+			size += info.Size()
+		}
+		return nil
+	})
+	return files, size
+}
@@ -0,0 +1,288 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/contre95/soulsolid/src/features/config"
+	"github.com/contre95/soulsolid/src/features/jobs"
+	"github.com/contre95/soulsolid/src/music"
+)
+
+// Ensure Service can collect the failed jobs
+var _ jobs.JobObserver = (*Service)(nil)
+
+const (
+	// digestCheckInterval is how often the digest schedule is checked.
+	digestCheckInterval = 10 * time.Minute
+	// digestMaxAlbums caps the albums listed in a digest, the rest are only counted.
+	digestMaxAlbums = 30
+	// digestMaxFailedJobs caps the failed jobs remembered between digests.
+	digestMaxFailedJobs = 50
+)
+
+// Library counts and lists the tracks added since the last digest.
+type Library interface {
+	GetTracksFilteredCount(ctx context.Context, filter *music.TrackFilter) (int, error)
+	GetTracksFilteredPaginated(ctx context.Context, limit, offset int, filter *music.TrackFilter) ([]*music.Track, error)
+}
+
+// ReviewQueue lists the import queue items waiting for a review.
+type ReviewQueue interface {
+	GetQueuedItems() map[string]music.QueueItem
+}
+
+// DigestAlbum is an album that got new tracks since the last digest.
+type DigestAlbum struct {
+	Title  string
+	Artist string
+	Tracks int
+}
+
+// Digest summarizes what happened since the last digest.
+type Digest struct {
+	Since          time.Time
+	NewTracks      int
+	Albums         []DigestAlbum // albums of the new tracks, most tracks first
+	MoreAlbums     int           // albums left out of Albums
+	FailedJobs     []*music.Job
+	PendingReviews int
+	URL            string
+}
+
+// Empty reports whether the digest has nothing to tell.
+func (d *Digest) Empty() bool {
+	return d.NewTracks == 0 && len(d.FailedJobs) == 0 && d.PendingReviews == 0
+}
+
+var digestTemplate = template.Must(template.New("digest").Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: sans-serif; color: #1e293b; max-width: 600px; margin: 0 auto; padding: 16px;">
+  <h2 style="margin: 0 0 4px;">Soulsolid digest</h2>
+  <p style="font-size: 13px; color: #64748b; margin: 0 0 24px;">Since {{.Since.Format "Mon Jan 2 15:04"}}</p>
+
+  <h3 style="margin: 0 0 8px;">New music</h3>
+  {{if .NewTracks}}
+  <p style="font-size: 14px;">{{.NewTracks}} track{{if ne .NewTracks 1}}s{{end}} added</p>
+  <ul style="font-size: 14px; padding-left: 20px;">
+    {{range .Albums}}<li><strong>{{.Title}}</strong> by {{.Artist}} ({{.Tracks}})</li>{{end}}
+  </ul>
+  {{if .MoreAlbums}}<p style="font-size: 13px; color: #64748b;">and {{.MoreAlbums}} more albums</p>{{end}}
+  {{else}}
+  <p style="font-size: 14px; color: #64748b;">Nothing added.</p>
+  {{end}}
+
+  <h3 style="margin: 24px 0 8px;">Failed jobs</h3>
+  {{if .FailedJobs}}
+  <ul style="font-size: 14px; padding-left: 20px;">
+    {{range .FailedJobs}}<li><strong>{{.Name}}</strong> {{.UpdatedAt.Format "Jan 2 15:04"}}: <span style="color: #b91c1c;">{{.Error}}</span></li>{{end}}
+  </ul>
+  {{else}}
+  <p style="font-size: 14px; color: #64748b;">No failures.</p>
+  {{end}}
+
+  <h3 style="margin: 24px 0 8px;">Pending reviews</h3>
+  <p style="font-size: 14px;">{{if .PendingReviews}}{{.PendingReviews}} item{{if ne .PendingReviews 1}}s{{end}} waiting in the import queue{{else}}The import queue is empty.{{end}}</p>
+
+  {{if .URL}}<p style="font-size: 13px; margin-top: 24px;"><a href="{{.URL}}">Open Soulsolid</a></p>{{end}}
+</body>
+</html>`))
+
+// Service builds the email digest and sends it on the configured schedule.
+type Service struct {
+	config   *config.Manager
+	mailer   *Mailer
+	library  Library
+	queue    ReviewQueue
+	mu       sync.Mutex
+	failed   []*music.Job // failed since the last digest, oldest first
+	lastSent time.Time
+}
+
+// NewService creates a new digest service and starts its schedule.
+func NewService(cfg *config.Manager, mailer *Mailer, library Library, queue ReviewQueue) *Service {
+	s := &Service{config: cfg, mailer: mailer, library: library, queue: queue}
+	go s.watchDigest()
+	return s
+}
+
+// JobFinished remembers the failed jobs for the next digest.
+func (s *Service) JobFinished(job *music.Job, duration time.Duration) {
+	if job.Status != music.JobStatusFailed {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failed = append(s.failed, job)
+	if len(s.failed) > digestMaxFailedJobs {
+		s.failed = s.failed[len(s.failed)-digestMaxFailedJobs:]
+	}
+}
+
+// BuildDigest collects what happened since the last digest, or over the last digest period
+// when none was sent since startup.
+func (s *Service) BuildDigest(ctx context.Context) (*Digest, error) {
+	s.mu.Lock()
+	since := s.lastSent
+	failed := append([]*music.Job(nil), s.failed...)
+	s.mu.Unlock()
+	cfg := s.config.Get()
+	if since.IsZero() {
+		since = time.Now().Add(-digestPeriod(cfg.Email.Digest.Frequency))
+	}
+
+	digest := &Digest{
+		Since:          since,
+		FailedJobs:     failed,
+		PendingReviews: len(s.queue.GetQueuedItems()),
+		URL:            strings.TrimRight(cfg.Server.PublicURL, "/"),
+	}
+	filter := &music.TrackFilter{AddedAfter: since.Format("2006-01-02")}
+	count, err := s.library.GetTracksFilteredCount(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count new tracks: %w", err)
+	}
+	tracks, err := s.library.GetTracksFilteredPaginated(ctx, count, 0, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list new tracks: %w", err)
+	}
+	albums := map[string]*DigestAlbum{}
+	for _, track := range tracks {
+		// The filter works by day, the time of the last digest is finer
+		if !track.AddedDate.After(since) {
+			continue
+		}
+		digest.NewTracks++
+		album := &DigestAlbum{Title: "Unknown album"}
+		if track.Album != nil {
+			album.Title = track.Album.Title
+			if len(track.Album.Artists) > 0 && track.Album.Artists[0].Artist != nil {
+				album.Artist = track.Album.Artists[0].Artist.Name
+			}
+		}
+		if album.Artist == "" && len(track.Artists) > 0 && track.Artists[0].Artist != nil {
+			album.Artist = track.Artists[0].Artist.Name
+		}
+		key := album.Title + "\x00" + album.Artist
+		if albums[key] == nil {
+			albums[key] = album
+		}
+		albums[key].Tracks++
+	}
+	for _, album := range albums {
+		digest.Albums = append(digest.Albums, *album)
+	}
+	sort.Slice(digest.Albums, func(i, j int) bool {
+		if digest.Albums[i].Tracks != digest.Albums[j].Tracks {
+			return digest.Albums[i].Tracks > digest.Albums[j].Tracks
+		}
+		return digest.Albums[i].Title < digest.Albums[j].Title
+	})
+	if len(digest.Albums) > digestMaxAlbums {
+		digest.MoreAlbums = len(digest.Albums) - digestMaxAlbums
+		digest.Albums = digest.Albums[:digestMaxAlbums]
+	}
+	return digest, nil
+}
+
+// RenderDigest renders a digest as the HTML body of its email.
+func RenderDigest(digest *Digest) (string, error) {
+	var body bytes.Buffer
+	if err := digestTemplate.Execute(&body, digest); err != nil {
+		return "", fmt.Errorf("failed to render digest: %w", err)
+	}
+	return body.String(), nil
+}
+
+// SendDigest builds and emails the digest, even when it's empty, and starts the next period.
+func (s *Service) SendDigest(ctx context.Context) error {
+	digest, err := s.BuildDigest(ctx)
+	if err != nil {
+		return err
+	}
+	return s.send(digest)
+}
+
+func (s *Service) send(digest *Digest) error {
+	body, err := RenderDigest(digest)
+	if err != nil {
+		return err
+	}
+	subject := fmt.Sprintf("Soulsolid digest: %d new tracks, %d failed jobs, %d pending reviews", digest.NewTracks, len(digest.FailedJobs), digest.PendingReviews)
+	if err := s.mailer.Send(subject, body); err != nil {
+		return err
+	}
+	sent := make(map[*music.Job]bool, len(digest.FailedJobs))
+	for _, job := range digest.FailedJobs {
+		sent[job] = true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastSent = time.Now()
+	// Jobs may have failed, or been trimmed, while the digest was sent, so only the sent ones go
+	var failed []*music.Job
+	for _, job := range s.failed {
+		if !sent[job] {
+			failed = append(failed, job)
+		}
+	}
+	s.failed = failed
+	return nil
+}
+
+// watchDigest sends the digest at the configured hour, daily or on Mondays. Empty digests are skipped.
+func (s *Service) watchDigest() {
+	ticker := time.NewTicker(digestCheckInterval)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		email := s.config.Get().Email
+		if !email.Enabled || !email.Digest.Enabled || now.Hour() != email.Digest.Hour {
+			continue
+		}
+		if email.Digest.Frequency == "weekly" && now.Weekday() != time.Monday {
+			continue
+		}
+		s.mu.Lock()
+		sentToday := sameDay(s.lastSent, now)
+		s.mu.Unlock()
+		if sentToday {
+			continue
+		}
+		digest, err := s.BuildDigest(context.Background())
+		if err != nil {
+			slog.Error("Failed to build the email digest", "error", err)
+			continue
+		}
+		if digest.Empty() {
+			slog.Info("Nothing to report, email digest skipped")
+			s.mu.Lock()
+			s.lastSent = now
+			s.mu.Unlock()
+			continue
+		}
+		if err := s.send(digest); err != nil {
+			slog.Error("Failed to send the email digest", "error", err)
+		}
+	}
+}
+
+// digestPeriod returns the time covered by a digest of the given frequency.
+func digestPeriod(frequency string) time.Duration {
+	if frequency == "weekly" {
+		return 7 * 24 * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
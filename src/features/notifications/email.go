@@ -0,0 +1,144 @@
+package notifications
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/contre95/soulsolid/src/features/config"
+)
+
+// smtpTimeout bounds connecting to the SMTP server.
+const smtpTimeout = 30 * time.Second
+
+var alertTemplate = template.Must(template.New("alert").Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: sans-serif; color: #1e293b; max-width: 600px; margin: 0 auto; padding: 16px;">
+  <h2 style="margin: 0 0 16px;">Soulsolid</h2>
+  <p style="font-size: 15px; white-space: pre-line;">{{.Message}}</p>
+  {{if .URL}}<p style="font-size: 13px; color: #64748b;"><a href="{{.URL}}">Open Soulsolid</a></p>{{end}}
+</body>
+</html>`))
+
+// Mailer sends emails through the SMTP server of the email config.
+type Mailer struct {
+	config *config.Manager
+}
+
+// NewMailer creates a new Mailer.
+func NewMailer(cfg *config.Manager) *Mailer {
+	return &Mailer{config: cfg}
+}
+
+// Notify emails a notification to the configured recipients when instant alerts are on.
+func (m *Mailer) Notify(message string) {
+	email := m.config.Get().Email
+	if !email.Enabled || !email.Instant {
+		return
+	}
+	if err := m.SendAlert(message); err != nil {
+		slog.Error("Failed to send notification email", "error", err)
+	}
+}
+
+// SendAlert emails a single message, its first line being the subject.
+func (m *Mailer) SendAlert(message string) error {
+	var body bytes.Buffer
+	data := map[string]string{"Message": message, "URL": m.config.Get().Server.PublicURL}
+	if err := alertTemplate.Execute(&body, data); err != nil {
+		return fmt.Errorf("failed to render notification email: %w", err)
+	}
+	subject, _, _ := strings.Cut(message, "\n")
+	return m.Send("Soulsolid: "+subject, body.String())
+}
+
+// Send emails an HTML body to the configured recipients.
+func (m *Mailer) Send(subject, html string) error {
+	cfg := m.config.Get().Email
+	if cfg.Host == "" || len(cfg.To) == 0 {
+		return errors.New("email host and recipients must be configured")
+	}
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return fmt.Errorf("invalid from address %q: %w", cfg.From, err)
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", from.String())
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(cfg.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/html; charset=UTF-8\r\n\r\n")
+	msg.WriteString(html)
+
+	client, err := m.dial(cfg)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	if cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)); err != nil {
+			return fmt.Errorf("smtp authentication failed: %w", err)
+		}
+	}
+	if err := client.Mail(from.Address); err != nil {
+		return fmt.Errorf("smtp MAIL FROM failed: %w", err)
+	}
+	for _, to := range cfg.To {
+		if err := client.Rcpt(to); err != nil {
+			return fmt.Errorf("smtp RCPT TO %s failed: %w", to, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("smtp DATA failed: %w", err)
+	}
+	if _, err := w.Write(msg.Bytes()); err != nil {
+		return fmt.Errorf("failed to write email: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	slog.Info("Email sent", "subject", subject, "to", cfg.To)
+	return client.Quit()
+}
+
+// dial connects to the SMTP server, with implicit TLS on port 465 and STARTTLS elsewhere when
+// the server offers it.
+func (m *Mailer) dial(cfg config.Email) (*smtp.Client, error) {
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
+	tlsConfig := &tls.Config{ServerName: cfg.Host}
+	var conn net.Conn
+	var err error
+	if cfg.Port == 465 {
+		conn, err = tls.DialWithDialer(&net.Dialer{Timeout: smtpTimeout}, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = net.DialTimeout("tcp", addr, smtpTimeout)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+	client, err := smtp.NewClient(conn, cfg.Host)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to start smtp session: %w", err)
+	}
+	if ok, _ := client.Extension("STARTTLS"); ok && cfg.Port != 465 {
+		if err := client.StartTLS(tlsConfig); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("smtp STARTTLS failed: %w", err)
+		}
+	}
+	return client, nil
+}
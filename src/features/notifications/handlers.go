@@ -0,0 +1,61 @@
+package notifications
+
+import (
+	"log/slog"
+
+	"github.com/contre95/soulsolid/src/features/hosting/respond"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Handler handles HTTP requests for the email notifications.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new notifications handler.
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// GetPanel renders the email card of the settings section.
+func (h *Handler) GetPanel(c *fiber.Ctx) error {
+	email := h.service.config.Get().Email
+	email.Password = "" // the JSON response must not leak it
+	return respond.Partial(c, "cards/email", fiber.Map{"Email": email})
+}
+
+// SendTest emails a test message to the configured recipients.
+func (h *Handler) SendTest(c *fiber.Ctx) error {
+	if err := h.service.mailer.SendAlert("Test email\nEmail notifications are working."); err != nil {
+		slog.Error("Failed to send test email", "error", err)
+		return respond.ToastErr(c, fiber.StatusBadGateway, "Failed to send test email: "+err.Error())
+	}
+	return respond.ToastOk(c, "Test email sent")
+}
+
+// PreviewDigest renders the digest that would be sent now, as HTML by default or JSON with ?fmt=json.
+func (h *Handler) PreviewDigest(c *fiber.Ctx) error {
+	digest, err := h.service.BuildDigest(c.Context())
+	if err != nil {
+		slog.Error("Failed to build digest", "error", err)
+		return respond.ToastErr(c, fiber.StatusInternalServerError, "Failed to build digest")
+	}
+	if c.Query("fmt") == "json" {
+		return c.JSON(digest)
+	}
+	body, err := RenderDigest(digest)
+	if err != nil {
+		return respond.ToastErr(c, fiber.StatusInternalServerError, err.Error())
+	}
+	c.Type("html")
+	return c.SendString(body)
+}
+
+// SendDigest emails the digest right away and starts a new digest period.
+func (h *Handler) SendDigest(c *fiber.Ctx) error {
+	if err := h.service.SendDigest(c.Context()); err != nil {
+		slog.Error("Failed to send digest", "error", err)
+		return respond.ToastErr(c, fiber.StatusBadGateway, "Failed to send digest: "+err.Error())
+	}
+	return respond.ToastOk(c, "Digest sent")
+}
@@ -0,0 +1,14 @@
+package notifications
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// RegisterRoutes registers the email notification routes with the Fiber app.
+func RegisterRoutes(app *fiber.App, handler *Handler) {
+	group := app.Group("/notifications/email")
+	group.Get("/panel", handler.GetPanel)
+	group.Post("/test", handler.SendTest)
+	group.Get("/digest", handler.PreviewDigest)
+	group.Post("/digest", handler.SendDigest)
+}
@@ -0,0 +1,40 @@
+package recommendations
+
+import (
+	"log/slog"
+
+	"github.com/contre95/soulsolid/src/features/hosting/respond"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Handler is the handler for the recommendations feature.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new recommendations handler.
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// GetTrackRecommendations renders similar artists and tracks for a library track.
+func (h *Handler) GetTrackRecommendations(c *fiber.Ctx) error {
+	trackID := c.Params("trackId")
+	recs, err := h.service.ForTrack(c.Context(), trackID)
+	if err != nil {
+		slog.Error("Failed to get track recommendations", "error", err, "trackId", trackID)
+		return respond.ToastErr(c, fiber.StatusInternalServerError, "Failed to get recommendations")
+	}
+	return respond.Partial(c, "recommendations/panel", fiber.Map{"Recommendations": recs})
+}
+
+// GetArtistRecommendations renders similar artists for a library artist.
+func (h *Handler) GetArtistRecommendations(c *fiber.Ctx) error {
+	artistID := c.Params("artistId")
+	recs, err := h.service.ForArtist(c.Context(), artistID)
+	if err != nil {
+		slog.Error("Failed to get artist recommendations", "error", err, "artistId", artistID)
+		return respond.ToastErr(c, fiber.StatusInternalServerError, "Failed to get recommendations")
+	}
+	return respond.Partial(c, "recommendations/panel", fiber.Map{"Recommendations": recs})
+}
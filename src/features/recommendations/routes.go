@@ -0,0 +1,14 @@
+package recommendations
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// RegisterRoutes registers the routes for the recommendations feature.
+func RegisterRoutes(app *fiber.App, service *Service) {
+	handler := NewHandler(service)
+
+	recommendations := app.Group("/recommendations")
+	recommendations.Get("/tracks/:trackId", handler.GetTrackRecommendations)
+	recommendations.Get("/artists/:artistId", handler.GetArtistRecommendations)
+}
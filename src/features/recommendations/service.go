@@ -0,0 +1,151 @@
+package recommendations
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/contre95/soulsolid/src/music"
+)
+
+const (
+	relatedArtistsLimit = 10
+	tracksPerArtist     = 3
+	sameGenreLimit      = 6
+)
+
+// RelatedArtist is an artist an external catalogue considers similar to another one.
+type RelatedArtist struct {
+	Name     string
+	URL      string
+	ImageURL string
+}
+
+// SimilarityProvider returns related artists from an external service (e.g. Deezer).
+type SimilarityProvider interface {
+	RelatedArtists(ctx context.Context, artistName string, limit int) ([]RelatedArtist, error)
+	Name() string
+	IsEnabled() bool
+}
+
+// Suggestion is a related artist, either already in the library or available to download.
+type Suggestion struct {
+	Artist          string
+	Provider        string
+	URL             string
+	ImageURL        string
+	LibraryArtistID string         // set when the artist is already in the library
+	Tracks          []*music.Track // a few library tracks by the artist
+}
+
+// Recommendations groups everything suggested for a track or artist.
+type Recommendations struct {
+	Seed         string // artist the suggestions are based on
+	InLibrary    []Suggestion
+	Downloadable []Suggestion
+	SameGenre    []*music.Track // library tracks sharing the seed track's genre
+}
+
+// Service builds recommendations from provider similarity data and the library.
+type Service struct {
+	library   music.Library
+	providers []SimilarityProvider
+}
+
+// NewService creates a new recommendations service.
+func NewService(lib music.Library, providers []SimilarityProvider) *Service {
+	return &Service{library: lib, providers: providers}
+}
+
+// ForTrack returns recommendations based on a library track's main artist and genre.
+func (s *Service) ForTrack(ctx context.Context, trackID string) (*Recommendations, error) {
+	track, err := s.library.GetTrack(ctx, trackID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get track: %w", err)
+	}
+	if track == nil {
+		return nil, fmt.Errorf("track not found: %s", trackID)
+	}
+	if len(track.Artists) == 0 || track.Artists[0].Artist == nil {
+		return &Recommendations{}, nil
+	}
+	seed := track.Artists[0].Artist
+	recs := s.forArtist(ctx, seed.Name)
+
+	if track.Metadata.Genre != "" {
+		filter := &music.TrackFilter{Genre: track.Metadata.Genre}
+		// Over-fetch so there's enough left after dropping the seed artist's own tracks.
+		tracks, err := s.library.GetTracksFilteredPaginated(ctx, sameGenreLimit*4, 0, filter)
+		if err != nil {
+			slog.Warn("Failed to get same-genre tracks", "genre", track.Metadata.Genre, "error", err)
+		}
+		for _, t := range tracks {
+			if len(recs.SameGenre) >= sameGenreLimit {
+				break
+			}
+			if t.ID == track.ID || hasArtist(t, seed.ID) {
+				continue
+			}
+			recs.SameGenre = append(recs.SameGenre, t)
+		}
+	}
+	return recs, nil
+}
+
+// ForArtist returns recommendations based on a library artist.
+func (s *Service) ForArtist(ctx context.Context, artistID string) (*Recommendations, error) {
+	artist, err := s.library.GetArtist(ctx, artistID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get artist: %w", err)
+	}
+	if artist == nil {
+		return nil, fmt.Errorf("artist not found: %s", artistID)
+	}
+	return s.forArtist(ctx, artist.Name), nil
+}
+
+// forArtist asks every enabled provider for related artists and splits them into those already
+// in the library and those that could be downloaded. Provider failures are logged and skipped.
+func (s *Service) forArtist(ctx context.Context, artistName string) *Recommendations {
+	recs := &Recommendations{Seed: artistName}
+	seen := map[string]bool{strings.ToLower(artistName): true}
+	for _, p := range s.providers {
+		if !p.IsEnabled() {
+			continue
+		}
+		related, err := p.RelatedArtists(ctx, artistName, relatedArtistsLimit)
+		if err != nil {
+			slog.Warn("Failed to get related artists", "provider", p.Name(), "artist", artistName, "error", err)
+			continue
+		}
+		for _, r := range related {
+			key := strings.ToLower(r.Name)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			suggestion := Suggestion{Artist: r.Name, Provider: p.Name(), URL: r.URL, ImageURL: r.ImageURL}
+			if libArtist, err := s.library.GetArtistByName(ctx, r.Name); err == nil && libArtist != nil {
+				suggestion.LibraryArtistID = libArtist.ID
+				filter := &music.TrackFilter{ArtistIDs: []string{libArtist.ID}}
+				if tracks, err := s.library.GetTracksFilteredPaginated(ctx, tracksPerArtist, 0, filter); err == nil {
+					suggestion.Tracks = tracks
+				}
+				recs.InLibrary = append(recs.InLibrary, suggestion)
+			} else {
+				recs.Downloadable = append(recs.Downloadable, suggestion)
+			}
+		}
+	}
+	return recs
+}
+
+func hasArtist(track *music.Track, artistID string) bool {
+	for _, ar := range track.Artists {
+		if ar.Artist != nil && ar.Artist.ID == artistID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,141 @@
+package remote
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"github.com/contre95/soulsolid/src/features/hosting/respond"
+	"github.com/contre95/soulsolid/src/music"
+	"github.com/gofiber/fiber/v2"
+)
+
+// JSON-RPC 2.0 error codes.
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeServerError    = -32000
+	codeMaintenance    = -32001
+)
+
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// Handler serves the remote-control API.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new remote-control handler.
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RequireToken rejects API requests without the bearer token of the remote_control section.
+func (h *Handler) RequireToken(c *fiber.Ctx) error {
+	if !h.service.Enabled() {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "remote control is disabled"})
+	}
+	token, ok := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+	if !ok || !h.service.Authorized(strings.TrimSpace(token)) {
+		slog.Warn("Rejected remote control request with an invalid token", "ip", c.IP(), "path", c.Path())
+		c.Set(fiber.HeaderWWWAuthenticate, "Bearer")
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid token"})
+	}
+	return c.Next()
+}
+
+// RPC serves a JSON-RPC 2.0 call. Batches are not supported, and notifications (calls without
+// an id) are answered like any other call.
+func (h *Handler) RPC(c *fiber.Ctx) error {
+	var req rpcRequest
+	if err := json.Unmarshal(c.Body(), &req); err != nil {
+		return c.JSON(rpcResponse{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &rpcError{Code: codeParseError, Message: "parse error: " + err.Error()}})
+	}
+	id := req.ID
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return c.JSON(rpcResponse{JSONRPC: "2.0", ID: id, Error: &rpcError{Code: codeInvalidRequest, Message: "invalid request: jsonrpc must be \"2.0\" and method is required"}})
+	}
+	slog.Info("Remote control call", "method", req.Method, "ip", c.IP())
+	result, err := h.service.Call(c.Context(), req.Method, req.Params)
+	if err != nil {
+		code, _ := errorCodes(err)
+		return c.JSON(rpcResponse{JSONRPC: "2.0", ID: id, Error: &rpcError{Code: code, Message: err.Error()}})
+	}
+	return c.JSON(rpcResponse{JSONRPC: "2.0", ID: id, Result: result})
+}
+
+// Call serves a method on its own route, with the params as the JSON body and the bare result
+// as the response. Read-only methods are also served on GET.
+func (h *Handler) Call(c *fiber.Ctx) error {
+	method, ok := h.service.Method(c.Params("method"))
+	if !ok {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "unknown method " + c.Params("method")})
+	}
+	if c.Method() == fiber.MethodGet && !method.ReadOnly {
+		c.Set(fiber.HeaderAllow, fiber.MethodPost)
+		return c.Status(fiber.StatusMethodNotAllowed).JSON(fiber.Map{"error": method.Name + " must be called with POST"})
+	}
+	slog.Info("Remote control call", "method", method.Name, "ip", c.IP())
+	result, err := h.service.Call(c.Context(), method.Name, c.Body())
+	if err != nil {
+		_, status := errorCodes(err)
+		return c.Status(status).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(result)
+}
+
+// GetPanel renders the remote control card of the settings section.
+func (h *Handler) GetPanel(c *fiber.Ctx) error {
+	return respond.Partial(c, "cards/remote", fiber.Map{
+		"Enabled": h.service.Enabled(),
+		"Recipes": h.service.Recipes(c.BaseURL()),
+	})
+}
+
+// GetRecipes sends the curl recipes of every method as a shell script.
+func (h *Handler) GetRecipes(c *fiber.Ctx) error {
+	recipes := h.service.Recipes(c.BaseURL())
+	if c.Accepts(fiber.MIMETextPlain, fiber.MIMEApplicationJSON) == fiber.MIMEApplicationJSON {
+		return c.JSON(recipes)
+	}
+	c.Set(fiber.HeaderContentType, fiber.MIMETextPlainCharsetUTF8)
+	return c.SendString(Script(recipes))
+}
+
+// errorCodes maps an error of a call to its JSON-RPC code and HTTP status.
+func errorCodes(err error) (int, int) {
+	switch {
+	case errors.Is(err, errUnknownMethod):
+		return codeMethodNotFound, fiber.StatusNotFound
+	case errors.Is(err, errInvalidParams):
+		return codeInvalidParams, fiber.StatusBadRequest
+	case errors.Is(err, music.ErrMaintenance):
+		return codeMaintenance, fiber.StatusServiceUnavailable
+	default:
+		slog.Error("Remote control call failed", "error", err)
+		return codeServerError, fiber.StatusInternalServerError
+	}
+}
@@ -0,0 +1,73 @@
+package remote
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// tokenVariable is the shell variable the recipes read the token from, so it stays out of
+// the shell history.
+const tokenVariable = "$SOULSOLID_TOKEN"
+
+// Recipe is a ready-to-run curl command calling a method, on its own route and as JSON-RPC.
+type Recipe struct {
+	Method  string `json:"method"`
+	Summary string `json:"summary"`
+	Curl    string `json:"curl"`
+	JSONRPC string `json:"jsonrpc"`
+}
+
+// Recipes generates a curl command for every method against baseURL.
+func (s *Service) Recipes(baseURL string) []Recipe {
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	auth := fmt.Sprintf(`-H "Authorization: Bearer %s"`, tokenVariable)
+	recipes := []Recipe{}
+	for _, method := range s.methods {
+		route := baseURL + "/api/rpc/" + method.Name
+		var curl string
+		switch {
+		case method.ReadOnly:
+			curl = fmt.Sprintf("curl -fsS %s %s", auth, route)
+		case method.Example != nil:
+			curl = fmt.Sprintf("curl -fsS -X POST %s -H \"Content-Type: application/json\" -d %s %s", auth, shellQuote(mustJSON(method.Example)), route)
+		default:
+			curl = fmt.Sprintf("curl -fsS -X POST %s %s", auth, route)
+		}
+		request := map[string]any{"jsonrpc": "2.0", "id": 1, "method": method.Name}
+		if method.Example != nil {
+			request["params"] = method.Example
+		}
+		recipes = append(recipes, Recipe{
+			Method:  method.Name,
+			Summary: method.Summary,
+			Curl:    curl,
+			JSONRPC: fmt.Sprintf("curl -fsS %s -H \"Content-Type: application/json\" -d %s %s/api/rpc", auth, shellQuote(mustJSON(request)), baseURL),
+		})
+	}
+	return recipes
+}
+
+// Script renders the recipes as a shell script, one commented command per method.
+func Script(recipes []Recipe) string {
+	var b strings.Builder
+	b.WriteString("#!/bin/sh\n# Soulsolid remote control, set SOULSOLID_TOKEN to the remote_control token first.\n")
+	for _, recipe := range recipes {
+		fmt.Fprintf(&b, "\n# %s\n%s\n# %s\n", recipe.Summary, recipe.Curl, recipe.JSONRPC)
+	}
+	return b.String()
+}
+
+// mustJSON encodes the example params, which are always encodable.
+func mustJSON(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return string(data)
+}
+
+// shellQuote wraps s in single quotes for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
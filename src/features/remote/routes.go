@@ -0,0 +1,18 @@
+package remote
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// RegisterRoutes registers the remote-control routes with the Fiber app.
+func RegisterRoutes(app *fiber.App, handler *Handler) {
+	// Token-authenticated API for scripts and home automation
+	api := app.Group("/api/rpc", handler.RequireToken)
+	api.Post("/", handler.RPC)
+	api.Get("/:method", handler.Call)
+	api.Post("/:method", handler.Call)
+
+	group := app.Group("/remote")
+	group.Get("/panel", handler.GetPanel)
+	group.Get("/recipes", handler.GetRecipes)
+}
@@ -0,0 +1,242 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/contre95/soulsolid/src/features/config"
+	"github.com/contre95/soulsolid/src/music"
+)
+
+var (
+	errUnknownMethod = errors.New("unknown method")
+	errInvalidParams = errors.New("invalid params")
+)
+
+// Importer starts directory imports and reports on the download folder watcher.
+type Importer interface {
+	ImportDirectory(ctx context.Context, pathToImport string) (string, error)
+	GetWatcherStatus() bool
+}
+
+// Syncer brings the library files in line with the database and the path templates.
+type Syncer interface {
+	StartReorganizeAnalysis(ctx context.Context, fat32Safe, folderArtwork bool) (string, error)
+}
+
+// Downloads pauses and resumes the download jobs.
+type Downloads interface {
+	PauseDownloads()
+	ResumeDownloads()
+	DownloadsPaused() bool
+}
+
+// Queue is the job queue, paused as a whole by maintenance mode.
+type Queue interface {
+	GetJobs() []*music.Job
+	Paused() bool
+}
+
+// Catalog counts the library tracks for the status.
+type Catalog interface {
+	GetTracksCount(ctx context.Context) (int, error)
+}
+
+// Method is a remote-control call. Every method is served as JSON-RPC and on its own route,
+// and the curl recipes are generated from them.
+type Method struct {
+	Name     string
+	Summary  string
+	ReadOnly bool           // also served on GET, e.g. for polling sensors
+	Example  map[string]any // params shown in the recipes, nil when it takes none
+	call     func(ctx context.Context, params json.RawMessage) (any, error)
+}
+
+// Status is the state of the instance, as polled by scripts and home automation.
+type Status struct {
+	Tracks          int          `json:"tracks"`
+	Maintenance     bool         `json:"maintenance"`
+	DownloadsPaused bool         `json:"downloads_paused"`
+	Watcher         bool         `json:"watcher"`
+	Jobs            JobCounts    `json:"jobs"`
+	Running         []RunningJob `json:"running"`
+}
+
+// JobCounts counts the jobs of the queue by status.
+type JobCounts struct {
+	Pending   int `json:"pending"`
+	Running   int `json:"running"`
+	Completed int `json:"completed"`
+	Failed    int `json:"failed"`
+}
+
+// RunningJob is a job in progress.
+type RunningJob struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Name     string `json:"name"`
+	Progress int    `json:"progress"`
+	Message  string `json:"message,omitempty"`
+}
+
+// Service is the remote-control API: a few coarse calls for shell scripts and home automation
+// systems, authenticated with the token of the remote_control config section.
+type Service struct {
+	config    *config.Manager
+	importer  Importer
+	syncer    Syncer
+	downloads Downloads
+	queue     Queue
+	catalog   Catalog
+	methods   []Method
+}
+
+// NewService creates a new remote-control service.
+func NewService(cfg *config.Manager, importer Importer, syncer Syncer, downloads Downloads, queue Queue, catalog Catalog) *Service {
+	s := &Service{config: cfg, importer: importer, syncer: syncer, downloads: downloads, queue: queue, catalog: catalog}
+	s.methods = []Method{
+		{Name: "status", Summary: "Fetch the status: jobs, downloads, watcher and maintenance mode", ReadOnly: true, call: s.status},
+		{Name: "import.start", Summary: "Import a folder, the download folder when path is left out", Example: map[string]any{"path": "/downloads/album"}, call: s.startImport},
+		{Name: "library.sync", Summary: "Sync the library files with the database and the path templates", Example: map[string]any{"fat32_safe": false, "folder_artwork": false}, call: s.sync},
+		{Name: "downloads.pause", Summary: "Pause downloads, a running download finishes", call: s.pauseDownloads},
+		{Name: "downloads.resume", Summary: "Resume downloads", call: s.resumeDownloads},
+	}
+	return s
+}
+
+// Enabled reports whether a token is set, the API answers nothing without one.
+func (s *Service) Enabled() bool {
+	return s.config.Get().RemoteControl.Token != ""
+}
+
+// Authorized reports whether token is the configured one.
+func (s *Service) Authorized(token string) bool {
+	expected := s.config.Get().RemoteControl.Token
+	return expected != "" && subtle.ConstantTimeCompare([]byte(token), []byte(expected)) == 1
+}
+
+// Methods returns the methods of the API.
+func (s *Service) Methods() []Method {
+	return s.methods
+}
+
+// Method returns the method called name.
+func (s *Service) Method(name string) (Method, bool) {
+	for _, method := range s.methods {
+		if method.Name == name {
+			return method, true
+		}
+	}
+	return Method{}, false
+}
+
+// Call runs a method with its JSON params, which may be empty.
+func (s *Service) Call(ctx context.Context, name string, params json.RawMessage) (any, error) {
+	method, ok := s.Method(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", errUnknownMethod, name)
+	}
+	return method.call(ctx, params)
+}
+
+// decodeParams reads the params of a call into v. Unknown params are rejected so a typo isn't
+// silently ignored.
+func decodeParams(params json.RawMessage, v any) error {
+	params = bytes.TrimSpace(params)
+	if len(params) == 0 || bytes.Equal(params, []byte("null")) {
+		return nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(params))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("%w: %v", errInvalidParams, err)
+	}
+	return nil
+}
+
+func (s *Service) status(ctx context.Context, params json.RawMessage) (any, error) {
+	if err := decodeParams(params, &struct{}{}); err != nil {
+		return nil, err
+	}
+	tracks, err := s.catalog.GetTracksCount(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count tracks: %w", err)
+	}
+	status := Status{
+		Tracks:          tracks,
+		Maintenance:     s.queue.Paused(),
+		DownloadsPaused: s.downloads.DownloadsPaused(),
+		Watcher:         s.importer.GetWatcherStatus(),
+		Running:         []RunningJob{},
+	}
+	for _, job := range s.queue.GetJobs() {
+		switch job.Status {
+		case music.JobStatusPending:
+			status.Jobs.Pending++
+		case music.JobStatusRunning:
+			status.Jobs.Running++
+			status.Running = append(status.Running, RunningJob{ID: job.ID, Type: job.Type, Name: job.Name, Progress: job.Progress, Message: job.Message})
+		case music.JobStatusCompleted:
+			status.Jobs.Completed++
+		case music.JobStatusFailed:
+			status.Jobs.Failed++
+		}
+	}
+	return status, nil
+}
+
+func (s *Service) startImport(ctx context.Context, params json.RawMessage) (any, error) {
+	var p struct {
+		Path string `json:"path"`
+	}
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	if p.Path == "" {
+		p.Path = s.config.Get().DownloadPath
+	}
+	if info, err := os.Stat(p.Path); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("%w: %s is not a folder", errInvalidParams, p.Path)
+	}
+	jobID, err := s.importer.ImportDirectory(ctx, p.Path)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{"job_id": jobID, "path": p.Path}, nil
+}
+
+func (s *Service) sync(ctx context.Context, params json.RawMessage) (any, error) {
+	var p struct {
+		FAT32Safe     bool `json:"fat32_safe"`
+		FolderArtwork bool `json:"folder_artwork"`
+	}
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	jobID, err := s.syncer.StartReorganizeAnalysis(ctx, p.FAT32Safe, p.FolderArtwork)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{"job_id": jobID}, nil
+}
+
+func (s *Service) pauseDownloads(_ context.Context, params json.RawMessage) (any, error) {
+	if err := decodeParams(params, &struct{}{}); err != nil {
+		return nil, err
+	}
+	s.downloads.PauseDownloads()
+	return map[string]bool{"downloads_paused": true}, nil
+}
+
+func (s *Service) resumeDownloads(_ context.Context, params json.RawMessage) (any, error) {
+	if err := decodeParams(params, &struct{}{}); err != nil {
+		return nil, err
+	}
+	s.downloads.ResumeDownloads()
+	return map[string]bool{"downloads_paused": false}, nil
+}
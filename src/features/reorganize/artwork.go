@@ -0,0 +1,123 @@
+package reorganize
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ArtworkReader reads the artwork embedded in a music file.
+type ArtworkReader interface {
+	ReadArtwork(filePath string) ([]byte, string, error)
+}
+
+// folderArtNames are the sidecar images that belong to a folder and follow it when the folder is renamed.
+var folderArtNames = map[string]bool{
+	"folder.jpg": true, "folder.png": true,
+	"cover.jpg": true, "cover.png": true,
+	"artist.jpg": true, "artist.png": true,
+}
+
+// audioExtensions are the files that keep a folder "in use" for the library.
+var audioExtensions = map[string]bool{".mp3": true, ".flac": true, ".wav": true, ".aif": true, ".aiff": true}
+
+// artworkExt maps an artwork mime type to the file extension used for sidecars.
+func artworkExt(mimeType string) string {
+	if mimeType == "image/png" {
+		return ".png"
+	}
+	return ".jpg"
+}
+
+// hasArtwork reports whether dir already contains an image named base with any supported extension.
+func hasArtwork(dir, base string) bool {
+	for _, ext := range []string{".jpg", ".png"} {
+		if _, err := os.Stat(filepath.Join(dir, base+ext)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// writeFolderArtwork writes folder.jpg into the album directory and artist.jpg into its parent
+// (the artist directory) from the artwork embedded in trackPath, without overwriting existing images.
+// The artist image reuses album artwork, since artist pictures are not stored in the library.
+func (t *ReorganizeJobTask) writeFolderArtwork(trackPath string, logger *slog.Logger) (int, error) {
+	albumDir := filepath.Dir(trackPath)
+	artistDir := filepath.Dir(albumDir)
+	libraryRoot := filepath.Clean(t.service.config.Get().LibraryPath)
+
+	needAlbum := !hasArtwork(albumDir, "folder")
+	needArtist := artistDir != libraryRoot && strings.HasPrefix(artistDir, libraryRoot) && !hasArtwork(artistDir, "artist")
+	if !needAlbum && !needArtist {
+		return 0, nil
+	}
+
+	data, mimeType, err := t.service.artworkReader.ReadArtwork(trackPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read artwork: %w", err)
+	}
+	if len(data) == 0 {
+		return 0, nil
+	}
+
+	written := 0
+	ext := artworkExt(mimeType)
+	if needAlbum {
+		if err := os.WriteFile(filepath.Join(albumDir, "folder"+ext), data, 0644); err != nil {
+			return written, fmt.Errorf("failed to write folder artwork: %w", err)
+		}
+		logger.Info("Wrote album folder artwork", "dir", albumDir, "color", "cyan")
+		written++
+	}
+	if needArtist {
+		if err := os.WriteFile(filepath.Join(artistDir, "artist"+ext), data, 0644); err != nil {
+			return written, fmt.Errorf("failed to write artist artwork: %w", err)
+		}
+		logger.Info("Wrote artist folder artwork", "dir", artistDir, "color", "cyan")
+		written++
+	}
+	return written, nil
+}
+
+// moveFolderArtwork moves sidecar images left behind in oldDir to newDir once oldDir holds no
+// more audio files or subfolders, so renamed folders don't leave stale directories behind.
+func (s *Service) moveFolderArtwork(ctx context.Context, oldDir, newDir string, logger *slog.Logger) (int, error) {
+	entries, err := os.ReadDir(oldDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to read directory %s: %w", oldDir, err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || audioExtensions[strings.ToLower(filepath.Ext(entry.Name()))] {
+			return 0, nil // folder is still in use, leave it alone
+		}
+	}
+
+	moved := 0
+	for _, entry := range entries {
+		name := entry.Name()
+		if !folderArtNames[strings.ToLower(name)] {
+			continue
+		}
+		src := filepath.Join(oldDir, name)
+		dst := filepath.Join(newDir, name)
+		if _, err := os.Stat(dst); err == nil {
+			if err := s.fileManager.DeleteTrack(ctx, src); err != nil {
+				return moved, fmt.Errorf("failed to remove stale artwork %s: %w", src, err)
+			}
+			continue
+		}
+		if _, err := s.fileManager.MoveTrackFile(ctx, src, dst); err != nil {
+			return moved, fmt.Errorf("failed to move artwork %s: %w", src, err)
+		}
+		logger.Info("Moved folder artwork to renamed folder", "from", src, "to", dst, "color", "yellow")
+		moved++
+	}
+	return moved, nil
+}
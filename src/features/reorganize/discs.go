@@ -0,0 +1,138 @@
+package reorganize
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/contre95/soulsolid/src/music"
+)
+
+// firstDiscFolder matches folders holding the first disc of an album, e.g. "CD1", "Disc 1" or "disk_01".
+var firstDiscFolder = regexp.MustCompile(`(?i)^(cd|disc|disk)[ _-]*0*1$`)
+
+// StartCollapseDiscFolders starts a job that moves the tracks of single-disc albums out of their
+// CD1/Disc 1 subfolder into the album folder.
+func (s *Service) StartCollapseDiscFolders(ctx context.Context) (string, error) {
+	jobID, err := s.jobService.StartJob("collapse_disc_folders", "Collapse Single-Disc Folders", map[string]any{})
+	if err != nil {
+		return "", fmt.Errorf("failed to start disc folder job: %w", err)
+	}
+	return jobID, nil
+}
+
+// CollapseDiscFoldersTask moves tracks of albums with a single disc out of their disc subfolder.
+type CollapseDiscFoldersTask struct {
+	service *Service
+}
+
+// NewCollapseDiscFoldersTask creates a new disc folder migration task.
+func NewCollapseDiscFoldersTask(service *Service) *CollapseDiscFoldersTask {
+	return &CollapseDiscFoldersTask{service: service}
+}
+
+func (t *CollapseDiscFoldersTask) MetadataKeys() []string {
+	return []string{}
+}
+
+func (t *CollapseDiscFoldersTask) Execute(ctx context.Context, job *music.Job, progressUpdater func(int, string)) (map[string]any, error) {
+	totalTracks, err := t.service.library.GetTracksCount(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tracks count: %w", err)
+	}
+	job.Logger.Info("Looking for single-disc albums in disc folders", "totalTracks", totalTracks, "color", "blue")
+
+	attempted, moved, skipped, errors, artwork := 0, 0, 0, 0, 0
+	discCounts := map[string]int{}
+	// movedDirs maps each collapsed disc folder to its album folder.
+	movedDirs := map[string]string{}
+
+	batchSize := 100
+	for offset := 0; offset < totalTracks; offset += batchSize {
+		tracks, err := t.service.library.GetTracksPaginated(ctx, batchSize, offset)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tracks batch (offset %d): %w", offset, err)
+		}
+		for _, track := range tracks {
+			if err := ctx.Err(); err != nil {
+				job.Logger.Info("Disc folder migration cancelled", "moved", moved, "color", "orange")
+				return nil, err
+			}
+			attempted++
+			progressUpdater((attempted*100)/totalTracks, fmt.Sprintf("Checking track %d/%d: %s", attempted, totalTracks, track.Title))
+
+			discDir := filepath.Dir(track.Path)
+			if !firstDiscFolder.MatchString(filepath.Base(discDir)) || track.Album == nil {
+				continue
+			}
+			discs, ok := discCounts[track.Album.ID]
+			if !ok {
+				discs, err = t.service.library.GetAlbumDiscCount(ctx, track.Album.ID)
+				if err != nil {
+					job.Logger.Warn("Failed to get album disc count", "albumID", track.Album.ID, "error", err, "color", "red")
+					errors++
+					continue
+				}
+				discCounts[track.Album.ID] = discs
+			}
+			if discs > 1 {
+				continue
+			}
+
+			albumDir := filepath.Dir(discDir)
+			dest := filepath.Join(albumDir, filepath.Base(track.Path))
+			if _, err := os.Stat(dest); err == nil {
+				job.Logger.Warn("Skipping track, a file with the same name exists in the album folder", "trackID", track.ID, "path", dest, "color", "orange")
+				skipped++
+				continue
+			}
+			newPath, err := t.service.fileManager.MoveTrackFile(ctx, track.Path, dest)
+			if err != nil {
+				job.Logger.Warn("Failed to move track", "trackID", track.ID, "title", track.Title, "error", err, "color", "red")
+				errors++
+				continue
+			}
+			movedDirs[discDir] = albumDir
+			track.Path = newPath
+			if err := t.service.library.UpdateTrack(ctx, track); err != nil {
+				job.Logger.Warn("Failed to update track path in database", "trackID", track.ID, "newPath", newPath, "error", err, "color", "red")
+				errors++
+				continue
+			}
+			job.Logger.Info("Moved track out of disc folder", "trackID", track.ID, "title", track.Title, "newPath", newPath, "color", "green")
+			moved++
+		}
+	}
+
+	for discDir, albumDir := range movedDirs {
+		n, err := t.service.moveFolderArtwork(ctx, discDir, albumDir, job.Logger)
+		if err != nil {
+			job.Logger.Warn("Failed to move folder artwork", "from", discDir, "to", albumDir, "error", err, "color", "red")
+			errors++
+			continue
+		}
+		artwork += n
+	}
+
+	finalMsg := fmt.Sprintf("Disc folders collapsed: %d track(s) moved out of %d folder(s), %d skipped, %d artwork file(s) moved, %d errors", moved, len(movedDirs), skipped, artwork, errors)
+	job.Logger.Info("Disc folder migration completed", "moved", moved, "folders", len(movedDirs), "skipped", skipped, "errors", errors, "color", "green")
+	progressUpdater(100, fmt.Sprintf("Done — %d track(s) moved, %d skipped, %d errors", moved, skipped, errors))
+
+	return map[string]any{
+		"totalTracks": totalTracks,
+		"moved":       moved,
+		"folders":     len(movedDirs),
+		"skipped":     skipped,
+		"errors":      errors,
+		"artwork":     artwork,
+		"msg":         finalMsg,
+	}, nil
+}
+
+func (t *CollapseDiscFoldersTask) Cleanup(job *music.Job) error {
+	slog.Debug("Cleaning up disc folder job", "jobID", job.ID)
+	return nil
+}
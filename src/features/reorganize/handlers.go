@@ -27,7 +27,8 @@ func (h *Handler) StartReorganizeAnalysis(c *fiber.Ctx) error {
 	slog.Info("Starting file reorganization job from web request")
 
 	fat32Safe := c.FormValue("fat32_safe") == "true"
-	jobID, err := h.service.StartReorganizeAnalysis(c.Context(), fat32Safe)
+	folderArtwork := c.FormValue("folder_artwork") == "true"
+	jobID, err := h.service.StartReorganizeAnalysis(c.Context(), fat32Safe, folderArtwork)
 	if err != nil {
 		slog.Error("Failed to start file reorganization job", "error", err)
 		return respond.ToastErr(c, fiber.StatusInternalServerError, "Failed to start file reorganization job: "+err.Error())
@@ -39,6 +40,17 @@ func (h *Handler) StartReorganizeAnalysis(c *fiber.Ctx) error {
 	return respond.ToastJob(c, jobID, "File reorganization started successfully")
 }
 
+// StartCollapseDiscFolders handles starting the single-disc folder migration job
+func (h *Handler) StartCollapseDiscFolders(c *fiber.Ctx) error {
+	jobID, err := h.service.StartCollapseDiscFolders(c.Context())
+	if err != nil {
+		slog.Error("Failed to start disc folder job", "error", err)
+		return respond.ToastErr(c, fiber.StatusInternalServerError, "Failed to start disc folder job: "+err.Error())
+	}
+	c.Set("HX-Trigger", "refreshJobList")
+	return respond.ToastJob(c, jobID, "Disc folder cleanup started")
+}
+
 // RenderFilesReorganizationSection renders the file paths section page
 func (h *Handler) RenderFilesReorganizationSection(c *fiber.Ctx) error {
 	slog.Debug("Rendering file paths section")
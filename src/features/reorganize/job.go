@@ -31,6 +31,7 @@ func (t *ReorganizeJobTask) Execute(ctx context.Context, job *music.Job, progres
 			fat32Safe = b
 		}
 	}
+	folderArtwork, _ := job.Metadata["folder_artwork"].(bool)
 
 	totalTracks, err := t.service.library.GetTracksCount(ctx)
 	if err != nil {
@@ -55,6 +56,13 @@ func (t *ReorganizeJobTask) Execute(ctx context.Context, job *music.Job, progres
 	moved := 0
 	skipped := 0
 	errors := 0
+	artwork := 0
+	relinked := 0
+	relink := newRelinker(t.service.library, t.service.fingerprinter, t.service.config.Get().LibraryPath)
+	// movedDirs maps a track's previous folder to the folder it was moved into.
+	movedDirs := map[string]string{}
+	// albumDirs holds one track per album folder, used as the artwork source.
+	albumDirs := map[string]string{}
 
 	batchSize := 100
 	for offset := 0; offset < totalTracks; offset += batchSize {
@@ -90,9 +98,20 @@ func (t *ReorganizeJobTask) Execute(ctx context.Context, job *music.Job, progres
 			}
 
 			if _, err := os.Stat(track.Path); os.IsNotExist(err) {
-				job.Logger.Info("Skipping track with missing file", "trackID", track.ID, "title", track.Title, "path", track.Path, "color", "orange")
-				skipped++
-				continue
+				foundPath := relink.find(ctx, track, job.Logger)
+				if foundPath == "" {
+					job.Logger.Info("Skipping track with missing file", "trackID", track.ID, "title", track.Title, "path", track.Path, "color", "orange")
+					skipped++
+					continue
+				}
+				job.Logger.Info("Re-linking track to moved file", "trackID", track.ID, "title", track.Title, "from", track.Path, "to", foundPath, "color", "green")
+				track.Path = foundPath
+				if err := t.service.library.UpdateTrack(ctx, track); err != nil {
+					job.Logger.Warn("Failed to update re-linked track path in database", "trackID", track.ID, "title", track.Title, "path", foundPath, "error", err, "color", "red")
+					errors++
+					continue
+				}
+				relinked++
 			}
 
 			currentPath := filepath.Clean(track.Path)
@@ -107,6 +126,9 @@ func (t *ReorganizeJobTask) Execute(ctx context.Context, job *music.Job, progres
 
 			if currentPath == desiredPath {
 				job.Logger.Info("Track already in correct location", "trackID", track.ID, "title", track.Title, "path", currentPath, "color", "cyan")
+				if _, ok := albumDirs[filepath.Dir(currentPath)]; !ok {
+					albumDirs[filepath.Dir(currentPath)] = currentPath
+				}
 				skipped++
 				continue
 			}
@@ -119,6 +141,13 @@ func (t *ReorganizeJobTask) Execute(ctx context.Context, job *music.Job, progres
 				continue
 			}
 
+			if _, ok := movedDirs[filepath.Dir(currentPath)]; !ok {
+				movedDirs[filepath.Dir(currentPath)] = filepath.Dir(newPath)
+			}
+			if _, ok := albumDirs[filepath.Dir(newPath)]; !ok {
+				albumDirs[filepath.Dir(newPath)] = newPath
+			}
+
 			track.Path = newPath
 			err = t.service.library.UpdateTrack(ctx, track)
 			if err != nil {
@@ -132,8 +161,38 @@ func (t *ReorganizeJobTask) Execute(ctx context.Context, job *music.Job, progres
 		}
 	}
 
-	finalMsg := fmt.Sprintf("Reorganization completed: %d path(s) modified, %d already correct, %d errors (of %d total tracks)", moved, skipped, errors, totalTracks)
-	job.Logger.Info("File reorganization completed", "totalTracks", totalTracks, "moved", moved, "skipped", skipped, "errors", errors, "color", "green")
+	// Carry sidecar artwork over to renamed album folders, then to renamed artist folders.
+	for oldDir, newDir := range movedDirs {
+		for range 2 {
+			n, err := t.service.moveFolderArtwork(ctx, oldDir, newDir, job.Logger)
+			if err != nil {
+				job.Logger.Warn("Failed to move folder artwork", "from", oldDir, "to", newDir, "error", err, "color", "red")
+				errors++
+				break
+			}
+			artwork += n
+			oldDir, newDir = filepath.Dir(oldDir), filepath.Dir(newDir)
+			if oldDir == newDir {
+				break
+			}
+		}
+	}
+
+	if folderArtwork {
+		progressUpdater(99, "Writing folder artwork")
+		for _, trackPath := range albumDirs {
+			n, err := t.writeFolderArtwork(trackPath, job.Logger)
+			if err != nil {
+				job.Logger.Warn("Failed to write folder artwork", "path", trackPath, "error", err, "color", "red")
+				errors++
+				continue
+			}
+			artwork += n
+		}
+	}
+
+	finalMsg := fmt.Sprintf("Reorganization completed: %d path(s) modified, %d already correct, %d re-linked, %d artwork file(s) written or moved, %d errors (of %d total tracks)", moved, skipped, relinked, artwork, errors, totalTracks)
+	job.Logger.Info("File reorganization completed", "totalTracks", totalTracks, "moved", moved, "skipped", skipped, "relinked", relinked, "errors", errors, "color", "green")
 	progressUpdater(100, fmt.Sprintf("Done — %d path(s) modified, %d skipped, %d errors", moved, skipped, errors))
 
 	return map[string]any{
@@ -141,6 +200,8 @@ func (t *ReorganizeJobTask) Execute(ctx context.Context, job *music.Job, progres
 		"moved":       moved,
 		"skipped":     skipped,
 		"errors":      errors,
+		"artwork":     artwork,
+		"relinked":    relinked,
 		"msg":         finalMsg,
 	}, nil
 }
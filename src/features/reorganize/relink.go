@@ -0,0 +1,92 @@
+package reorganize
+
+import (
+	"context"
+	"io/fs"
+	"log/slog"
+	"path/filepath"
+	"strings"
+
+	"github.com/contre95/soulsolid/src/music"
+)
+
+// Fingerprinter generates the chromaprint fingerprint of a music file.
+type Fingerprinter interface {
+	GenerateFingerprint(ctx context.Context, filePath string) (string, error)
+}
+
+// relinker finds the new location of tracks whose file disappeared, by looking for a file
+// with the same fingerprint among the library files no track points to.
+// Candidates are collected on first use and fingerprinted at most once per job.
+type relinker struct {
+	library       music.Library
+	fingerprinter Fingerprinter
+	libraryPath   string
+	candidates    []string
+	fingerprints  map[string]string
+	scanned       bool
+}
+
+func newRelinker(library music.Library, fingerprinter Fingerprinter, libraryPath string) *relinker {
+	return &relinker{
+		library:       library,
+		fingerprinter: fingerprinter,
+		libraryPath:   libraryPath,
+		fingerprints:  map[string]string{},
+	}
+}
+
+// scan collects the audio files under the library path that are not referenced by any track.
+func (r *relinker) scan(ctx context.Context, logger *slog.Logger) {
+	r.scanned = true
+	err := filepath.WalkDir(r.libraryPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // unreadable entries are simply not candidates
+		}
+		if d.IsDir() || !audioExtensions[strings.ToLower(filepath.Ext(path))] {
+			return nil
+		}
+		track, err := r.library.FindTrackByPath(ctx, path)
+		if err != nil || track != nil {
+			return nil
+		}
+		r.candidates = append(r.candidates, path)
+		return nil
+	})
+	if err != nil {
+		logger.Warn("Failed to scan library for moved files", "path", r.libraryPath, "error", err, "color", "orange")
+	}
+	logger.Info("Scanned library for untracked files", "candidates", len(r.candidates), "color", "blue")
+}
+
+// find returns the path of an untracked file identical to track, or "" when there is none.
+// A matched candidate is consumed so it can't be linked to a second track.
+func (r *relinker) find(ctx context.Context, track *music.Track, logger *slog.Logger) string {
+	if r.fingerprinter == nil || r.libraryPath == "" {
+		return ""
+	}
+	if !r.scanned {
+		r.scan(ctx, logger)
+	}
+	for i, path := range r.candidates {
+		fingerprint, ok := r.fingerprints[path]
+		if !ok {
+			var err error
+			fingerprint, err = r.fingerprinter.GenerateFingerprint(ctx, path)
+			if err != nil {
+				logger.Warn("Failed to fingerprint candidate file", "path", path, "error", err, "color", "orange")
+			}
+			r.fingerprints[path] = fingerprint
+		}
+		if fingerprint == "" {
+			continue
+		}
+		// Fingerprinted imports derive the track ID from the fingerprint, so either matching
+		// the stored fingerprint or the ID proves it's the same audio.
+		if fingerprint == track.ChromaprintFingerprint || music.GenerateTrackID(fingerprint) == track.ID {
+			r.candidates = append(r.candidates[:i], r.candidates[i+1:]...)
+			return path
+		}
+	}
+	return ""
+}
@@ -7,5 +7,6 @@ import (
 // RegisterRoutes registers the routes for the reorganize feature.
 func RegisterRoutes(app *fiber.App, handler *Handler) {
 	app.Post("/analyze/reorganize", handler.StartReorganizeAnalysis)
+	app.Post("/analyze/reorganize/discs", handler.StartCollapseDiscFolders)
 	app.Get("/analyze/files", handler.RenderFilesReorganizationSection)
 }
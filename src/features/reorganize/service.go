@@ -11,28 +11,34 @@ import (
 
 // Service is the domain service for the reorganize feature.
 type Service struct {
-	fileManager music.FileManager
-	library     music.Library
-	config      *config.Manager
-	jobService  music.JobService
+	fileManager   music.FileManager
+	library       music.Library
+	artworkReader ArtworkReader
+	fingerprinter Fingerprinter
+	config        *config.Manager
+	jobService    music.JobService
 }
 
 // NewService creates a new reorganize service.
-func NewService(lib music.Library, fileManager music.FileManager, cfg *config.Manager, jobService music.JobService) *Service {
+func NewService(lib music.Library, fileManager music.FileManager, artworkReader ArtworkReader, fingerprinter Fingerprinter, cfg *config.Manager, jobService music.JobService) *Service {
 	return &Service{
-		library:     lib,
-		fileManager: fileManager,
-		config:      cfg,
-		jobService:  jobService,
+		library:       lib,
+		fileManager:   fileManager,
+		artworkReader: artworkReader,
+		fingerprinter: fingerprinter,
+		config:        cfg,
+		jobService:    jobService,
 	}
 }
 
 // StartReorganizeAnalysis starts a job to reorganize all tracks based on current path configuration.
 // When fat32Safe is true the job will also strip FAT32-forbidden characters from every path segment.
-func (s *Service) StartReorganizeAnalysis(ctx context.Context, fat32Safe bool) (string, error) {
-	slog.Info("Starting file reorganization job", "fat32Safe", fat32Safe)
+// When folderArtwork is true the job writes missing folder.jpg/artist.jpg images from embedded artwork.
+func (s *Service) StartReorganizeAnalysis(ctx context.Context, fat32Safe, folderArtwork bool) (string, error) {
+	slog.Info("Starting file reorganization job", "fat32Safe", fat32Safe, "folderArtwork", folderArtwork)
 	jobID, err := s.jobService.StartJob("analyze_reorganize", "Reorganize Library Files", map[string]any{
-		"fat32_safe": fat32Safe,
+		"fat32_safe":     fat32Safe,
+		"folder_artwork": folderArtwork,
 	})
 	if err != nil {
 		return "", fmt.Errorf("failed to start reorganization job: %w", err)
@@ -1,9 +1,17 @@
 package streaming
 
 import (
+	"encoding/csv"
+	"fmt"
 	"log/slog"
 	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/contre95/soulsolid/src/features/config"
+	"github.com/contre95/soulsolid/src/features/hosting/respond"
+	"github.com/contre95/soulsolid/src/features/ui"
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -27,11 +35,18 @@ func (h *Handler) Stream(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).SendString("invalid path")
 	}
-	resolved, mimeType, err := h.service.Stream(path)
+	resolved, mimeType, err := h.service.Stream(path, ui.Guest(c))
 	if err != nil {
 		slog.Error("Stream: rejected path", "path", path, "error", err)
 		return c.Status(fiber.StatusNotFound).SendString("track not found")
 	}
+	if ui.FamilyFilter(c) && (h.service.IsExplicit(c.Context(), path) || resolved != path && h.service.IsExplicit(c.Context(), resolved)) {
+		return c.Status(fiber.StatusForbidden).SendString("explicit track blocked by the family filter")
+	}
+	// Players fetch a file in several range requests, only the first one counts as a play
+	if rng := c.Get("Range"); rng == "" || strings.HasPrefix(rng, "bytes=0-") {
+		h.service.RecordPlay(c.Context(), config.RequestUser(c), resolved)
+	}
 	c.Set("Content-Type", mimeType)
 	c.Set("Accept-Ranges", "bytes")
 	// Fiber's SendFile feeds the path to fasthttp as a request URI, so characters
@@ -40,3 +55,65 @@ func (h *Handler) Stream(c *fiber.Ctx) error {
 	// through fasthttp's URI decoding intact.
 	return c.SendFile((&url.URL{Path: resolved}).EscapedPath())
 }
+
+// RenderProfileSection renders the listening profile of a user, the requesting user by default.
+func (h *Handler) RenderProfileSection(c *fiber.Ctx) error {
+	listeners, err := h.service.GetListeners(c.Context())
+	if err != nil {
+		slog.Error("Failed to list listeners", "error", err)
+	}
+	return respond.Section(c, "profile", fiber.Map{
+		"Title":     "Profile",
+		"User":      profileUser(c),
+		"Listeners": listeners,
+	})
+}
+
+// GetListeningStats returns the listening stats of a user over the last days (query: user, days).
+func (h *Handler) GetListeningStats(c *fiber.Ctx) error {
+	user := profileUser(c)
+	days, _ := strconv.Atoi(c.Query("days", "30"))
+	stats, err := h.service.GetListeningStats(c.Context(), user, days)
+	if err != nil {
+		slog.Error("Failed to get listening stats", "user", user, "error", err)
+		return respond.ToastErr(c, fiber.StatusInternalServerError, "Failed to get listening stats")
+	}
+	return respond.Partial(c, "streaming/profile_stats", fiber.Map{
+		"Stats": stats,
+		"Days":  days,
+	})
+}
+
+// ExportPlays downloads the play log as CSV, or JSON with fmt=json (query: user, empty for
+// everyone, and days, 0 for all time).
+func (h *Handler) ExportPlays(c *fiber.Ctx) error {
+	user := c.Query("user")
+	days, _ := strconv.Atoi(c.Query("days"))
+	plays, err := h.service.GetPlays(c.Context(), user, days)
+	if err != nil {
+		slog.Error("Failed to export plays", "user", user, "error", err)
+		return respond.ToastErr(c, fiber.StatusInternalServerError, "Failed to export plays")
+	}
+	name := "plays-" + time.Now().Format("2006-01-02")
+	if c.Query("fmt") == "json" {
+		c.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.json"`, name))
+		return c.JSON(plays)
+	}
+	c.Set("Content-Type", "text/csv")
+	c.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.csv"`, name))
+	w := csv.NewWriter(c)
+	w.Write([]string{"played_at", "user", "track_id", "title", "artist", "album", "duration"})
+	for _, p := range plays {
+		w.Write([]string{p.PlayedAt.Format(time.RFC3339), p.User, p.TrackID, p.Title, p.Artist, p.Album, strconv.Itoa(p.Duration)})
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// profileUser returns the user query parameter, or the requesting user when it's empty.
+func profileUser(c *fiber.Ctx) string {
+	if user := strings.TrimSpace(c.Query("user")); user != "" {
+		return user
+	}
+	return config.RequestUser(c)
+}
@@ -0,0 +1,126 @@
+package streaming
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// replayWindow is how long repeated requests of a track by the same user count as one play,
+// since players fetch a file in several range requests and may restart it.
+const replayWindow = time.Minute
+
+// Play is a track streamed by a user. Title, artist and duration are copied from the track so
+// the history survives it being removed from the library.
+type Play struct {
+	User     string    `json:"user"`
+	TrackID  string    `json:"track_id"`
+	Title    string    `json:"title"`
+	Artist   string    `json:"artist"`
+	Album    string    `json:"album"`
+	Duration int       `json:"duration"` // seconds
+	PlayedAt time.Time `json:"played_at"`
+}
+
+// PlayCount is an artist or track with how often and how long it was played.
+type PlayCount struct {
+	Name    string `json:"name"`
+	Artist  string `json:"artist,omitempty"` // tracks only
+	Plays   int    `json:"plays"`
+	Seconds int    `json:"seconds"`
+}
+
+// ListeningStats summarizes what a user played since a given time.
+type ListeningStats struct {
+	User       string      `json:"user"`
+	Since      time.Time   `json:"since"`
+	Plays      int         `json:"plays"`
+	Seconds    int         `json:"seconds"`
+	TopArtists []PlayCount `json:"top_artists"`
+	TopTracks  []PlayCount `json:"top_tracks"`
+}
+
+// Hours returns the time listened in hours.
+func (s *ListeningStats) Hours() float64 {
+	return float64(s.Seconds) / 3600
+}
+
+// Listener is a user that streamed at least one track.
+type Listener struct {
+	User       string    `json:"user"`
+	Plays      int       `json:"plays"`
+	LastPlayed time.Time `json:"last_played"`
+}
+
+// PlayLog stores the plays and computes the listening statistics.
+type PlayLog interface {
+	RecordPlay(ctx context.Context, play Play) error
+	GetListeningStats(ctx context.Context, user string, since time.Time, limit int) (*ListeningStats, error)
+	GetListeners(ctx context.Context) ([]Listener, error)
+	GetPlays(ctx context.Context, user string, since time.Time) ([]Play, error)
+}
+
+// RecordPlay logs that user streamed the library track at path. Requests of the same track by
+// the same user within replayWindow are counted once, and files outside the library are ignored.
+func (s *Service) RecordPlay(ctx context.Context, user, path string) {
+	track, err := s.tracks.FindTrackByPath(ctx, path)
+	if err != nil || track == nil {
+		return
+	}
+	now := time.Now()
+	key := user + "\x00" + track.ID
+	s.recentMu.Lock()
+	last, seen := s.recent[key]
+	s.recent[key] = now
+	for k, t := range s.recent {
+		if now.Sub(t) > replayWindow {
+			delete(s.recent, k)
+		}
+	}
+	s.recentMu.Unlock()
+	if seen && now.Sub(last) <= replayWindow {
+		return
+	}
+	// The path lookup doesn't load the artists and album
+	if full, err := s.tracks.GetTrack(ctx, track.ID); err == nil && full != nil {
+		track = full
+	}
+	play := Play{User: user, TrackID: track.ID, Title: track.Title, Duration: track.Metadata.Duration, PlayedAt: now}
+	artists := make([]string, 0, len(track.Artists))
+	for _, role := range track.Artists {
+		if role.Artist != nil && (role.Role == "main" || role.Role == "") {
+			artists = append(artists, role.Artist.Name)
+		}
+	}
+	play.Artist = strings.Join(artists, ", ")
+	if track.Album != nil {
+		play.Album = track.Album.Title
+	}
+	if err := s.plays.RecordPlay(ctx, play); err != nil {
+		slog.Error("Failed to record play", "user", user, "track", track.ID, "error", err)
+	}
+}
+
+// GetListeningStats returns what user played over the last days, all time with 0.
+func (s *Service) GetListeningStats(ctx context.Context, user string, days int) (*ListeningStats, error) {
+	return s.plays.GetListeningStats(ctx, user, since(days), 10)
+}
+
+// GetListeners returns the users that streamed something, most plays first.
+func (s *Service) GetListeners(ctx context.Context) ([]Listener, error) {
+	return s.plays.GetListeners(ctx)
+}
+
+// GetPlays returns the plays of user over the last days, all users when user is empty and all
+// time with 0, oldest first.
+func (s *Service) GetPlays(ctx context.Context, user string, days int) ([]Play, error) {
+	return s.plays.GetPlays(ctx, user, since(days))
+}
+
+func since(days int) time.Time {
+	if days <= 0 {
+		return time.Time{}
+	}
+	return time.Now().AddDate(0, 0, -days)
+}
@@ -6,4 +6,7 @@ import "github.com/gofiber/fiber/v2"
 func RegisterRoutes(app *fiber.App, service *Service) {
 	handler := NewHandler(service)
 	app.Get("/stream", handler.Stream)
+	app.Get("/profile", handler.RenderProfileSection)
+	app.Get("/profile/stats", handler.GetListeningStats)
+	app.Get("/profile/export", handler.ExportPlays)
 }
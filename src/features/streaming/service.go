@@ -1,11 +1,16 @@
 package streaming
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/contre95/soulsolid/src/features/config"
+	"github.com/contre95/soulsolid/src/music"
 )
 
 // containedIn guards against path traversal attacks: it resolves symlinks on
@@ -26,21 +31,44 @@ func containedIn(candidate, base string) (string, error) {
 	return resolved, nil
 }
 
+// TrackFinder looks up library tracks by their file path.
+type TrackFinder interface {
+	FindTrackByPath(ctx context.Context, path string) (*music.Track, error)
+	GetTrack(ctx context.Context, id string) (*music.Track, error)
+}
+
 // Service handles audio streaming by validating and serving file paths.
 type Service struct {
-	cfg *config.Manager
+	cfg      *config.Manager
+	tracks   TrackFinder
+	plays    PlayLog
+	recentMu sync.Mutex
+	recent   map[string]time.Time // last request of a track by a user, see replayWindow
 }
 
 // NewService creates a new streaming service.
-func NewService(cfg *config.Manager) *Service {
-	return &Service{cfg: cfg}
+func NewService(cfg *config.Manager, tracks TrackFinder, plays PlayLog) *Service {
+	return &Service{cfg: cfg, tracks: tracks, plays: plays, recent: make(map[string]time.Time)}
+}
+
+// IsExplicit reports whether the library track at path is flagged as explicit.
+// Files that aren't in the library, e.g. pending downloads, are never reported as explicit.
+func (s *Service) IsExplicit(ctx context.Context, path string) bool {
+	track, err := s.tracks.FindTrackByPath(ctx, path)
+	if err != nil {
+		slog.Warn("Failed to look up streamed track", "path", path, "error", err)
+		return false
+	}
+	return track != nil && track.IsExplicit()
 }
 
 var audioMIME = map[string]string{
 	".mp3":  "audio/mpeg",
 	".flac": "audio/flac",
-	// Not supported in soulsolid yet
 	".wav":  "audio/wav",
+	".aif":  "audio/aiff",
+	".aiff": "audio/aiff",
+	// Not supported in soulsolid yet
 	".aac":  "audio/aac",
 	".m4a":  "audio/mp4",
 	".ogg":  "audio/ogg",
@@ -50,9 +78,14 @@ var audioMIME = map[string]string{
 
 // Stream validates that path is within the library or download directory,
 // has an allowed audio extension, and returns the resolved path and MIME type.
-func (s *Service) Stream(path string) (string, string, error) {
+// With libraryOnly, e.g. for guests, files in the download directory are rejected.
+func (s *Service) Stream(path string, libraryOnly bool) (string, string, error) {
 	cfg := s.cfg.Get()
-	for _, base := range []string{cfg.LibraryPath, cfg.DownloadPath} {
+	bases := []string{cfg.LibraryPath, cfg.DownloadPath}
+	if libraryOnly {
+		bases = bases[:1]
+	}
+	for _, base := range bases {
 		resolved, err := containedIn(path, base)
 		if err == nil {
 			mime, ok := audioMIME[strings.ToLower(filepath.Ext(resolved))]
@@ -0,0 +1,80 @@
+package ui
+
+import (
+	"net"
+	"regexp"
+	"strings"
+
+	"github.com/contre95/soulsolid/src/features/config"
+	"github.com/contre95/soulsolid/src/features/hosting/respond"
+	"github.com/gofiber/fiber/v2"
+)
+
+// guestLocal is the request local holding whether the request comes from a guest.
+const guestLocal = "Guest"
+
+// guestRoutes are the GET routes open to guests: browsing the library and playlists and
+// listening to tracks. Anything that changes the library or exposes settings, jobs, downloads
+// or folder trees is left out.
+var guestRoutes = []*regexp.Regexp{
+	regexp.MustCompile(`^/(library|playlists|stream|health|maintenance)$`),
+	regexp.MustCompile(`^/api/v1/suggest$`),
+	regexp.MustCompile(`^/library/(search|table|recent|storage/size|(tracks|albums|artists)/count)$`),
+	regexp.MustCompile(`^/library/(tracks|albums|artists)/[^/]+$`),
+	regexp.MustCompile(`^/library/tracks/[^/]+/(overview|lyrics|albums)$`),
+	regexp.MustCompile(`^/playlists/[^/]+$`),
+	regexp.MustCompile(`^/recommendations/(tracks|artists)/[^/]+$`),
+	regexp.MustCompile(`^/tag/[^/]+/artwork$`),
+	regexp.MustCompile(`^/(css|js|img|svg|fontawesome|node_modules)/`),
+}
+
+// GuestMiddleware marks requests for the guest host as guest requests and rejects the ones
+// outside of the read-only guest routes. The host is matched against both the Host and the
+// X-Forwarded-Host headers, so a guest can't leave guest mode by spoofing either of them.
+func GuestMiddleware(cfg *config.Manager) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		guest := cfg.Get().Server.Guest
+		isGuest := false
+		if guest.Enabled && guest.Host != "" {
+			hosts := append([]string{string(c.Request().Host())}, strings.Split(c.Get(fiber.HeaderXForwardedHost), ",")...)
+			for _, host := range hosts {
+				if sameHost(strings.TrimSpace(host), guest.Host) {
+					isGuest = true
+				}
+			}
+		}
+		c.Locals(guestLocal, isGuest)
+		if !isGuest {
+			return c.Next()
+		}
+		if c.Method() != fiber.MethodGet && c.Method() != fiber.MethodHead {
+			return respond.ToastErr(c, fiber.StatusForbidden, "Not available in guest mode")
+		}
+		if c.Path() == "/" {
+			return c.Redirect("/library")
+		}
+		for _, route := range guestRoutes {
+			if route.MatchString(c.Path()) {
+				return c.Next()
+			}
+		}
+		return respond.ToastErr(c, fiber.StatusForbidden, "Not available in guest mode")
+	}
+}
+
+// Guest reports whether the current request comes from a guest.
+func Guest(c *fiber.Ctx) bool {
+	guest, _ := c.Locals(guestLocal).(bool)
+	return guest
+}
+
+// sameHost compares a Host header value with the configured host, ignoring the port and case.
+func sameHost(header, host string) bool {
+	if header == "" {
+		return false
+	}
+	if h, _, err := net.SplitHostPort(header); err == nil {
+		header = h
+	}
+	return strings.EqualFold(header, host)
+}
@@ -2,6 +2,7 @@ package ui
 
 import (
 	"log/slog"
+	"slices"
 
 	"github.com/contre95/soulsolid/src/features/config"
 	"github.com/contre95/soulsolid/src/features/hosting/respond"
@@ -11,19 +12,82 @@ import (
 // Handler is the handler for the UI feature.
 type Handler struct {
 	configManager *config.Manager
+	preferences   PreferencesStore
 }
 
 // NewHandler creates a new handler for the UI feature.
-func NewHandler(configManager *config.Manager) *Handler {
+func NewHandler(configManager *config.Manager, preferences PreferencesStore) *Handler {
 	return &Handler{
 		configManager: configManager,
+		preferences:   preferences,
 	}
 }
 
+// RenderStartPage sends full page loads of / to the user's start page.
+func (h *Handler) RenderStartPage(c *fiber.Ctx) error {
+	prefs := h.preferences.Load(c)
+	if c.Get("HX-Request") != "true" && prefs.StartPage != "dashboard" {
+		return c.Redirect(StartPages[prefs.StartPage])
+	}
+	return h.RenderDashboard(c)
+}
+
 // RenderDashboard renders the main dashboard page.
 func (h *Handler) RenderDashboard(c *fiber.Ctx) error {
 	slog.Debug("RenderDashboard handler called")
-	return respond.Section(c, "dashboard", fiber.Map{"Title": "Dashboard"})
+	return respond.Section(c, "dashboard", fiber.Map{
+		"Title":   "Dashboard",
+		"Widgets": h.preferences.Load(c).VisibleWidgets(),
+	})
+}
+
+// GetPreferencesForm renders the start page and dashboard widget preferences.
+func (h *Handler) GetPreferencesForm(c *fiber.Ctx) error {
+	prefs := h.preferences.Load(c)
+	return respond.Partial(c, "cards/preferences", fiber.Map{
+		"StartPage":    prefs.StartPage,
+		"StartPages":   []string{"dashboard", "library", "downloads"},
+		"Widgets":      DashboardWidgets,
+		"Visible":      prefs.VisibleWidgets(),
+		"Family":       prefs.FamilyFilter,
+		"TableColumns": TableColumns,
+		"ShownColumns": prefs.ShownColumns(),
+		"Density":      prefs.Density,
+		"Densities":    Densities,
+	})
+}
+
+// SavePreferences stores the submitted preferences for the current user.
+func (h *Handler) SavePreferences(c *fiber.Ctx) error {
+	startPage := c.FormValue("start_page")
+	if _, ok := StartPages[startPage]; !ok {
+		return respond.ToastErr(c, fiber.StatusBadRequest, "Unknown start page")
+	}
+	shown := map[string]bool{}
+	for _, key := range c.Request().PostArgs().PeekMulti("widgets") {
+		shown[string(key)] = true
+	}
+	density := c.FormValue("density", Densities[0])
+	if !slices.Contains(Densities, density) {
+		return respond.ToastErr(c, fiber.StatusBadRequest, "Unknown table density")
+	}
+	prefs := Preferences{StartPage: startPage, HiddenWidgets: []string{}, FamilyFilter: c.FormValue("family_filter") == "on", Columns: []string{}, Density: density}
+	for _, w := range DashboardWidgets {
+		if !shown[w.Key] {
+			prefs.HiddenWidgets = append(prefs.HiddenWidgets, w.Key)
+		}
+	}
+	columns := c.Request().PostArgs().PeekMulti("columns")
+	for _, col := range TableColumns {
+		if slices.ContainsFunc(columns, func(key []byte) bool { return string(key) == col.Key }) {
+			prefs.Columns = append(prefs.Columns, col.Key)
+		}
+	}
+	if err := h.preferences.Save(c, prefs); err != nil {
+		slog.Error("Failed to save preferences", "error", err)
+		return respond.ToastErr(c, fiber.StatusInternalServerError, "Failed to save preferences")
+	}
+	return respond.ToastOk(c, "Preferences saved")
 }
 
 // GetQuickActionsCard renders the quick actions card for the dashboard.
@@ -0,0 +1,165 @@
+package ui
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"slices"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const preferencesCookie = "soulsolid_prefs"
+
+// familyFilterLocal is the request local holding whether the family filter is on.
+const familyFilterLocal = "FamilyFilter"
+
+// Request locals holding the table layout, read by the views as .Columns and .Density.
+const (
+	columnsLocal = "Columns"
+	densityLocal = "Density"
+)
+
+// StartPages maps the selectable start pages to their routes.
+var StartPages = map[string]string{
+	"dashboard": "/dashboard",
+	"library":   "/library",
+	"downloads": "/downloads",
+}
+
+// Widget is a dashboard card that can be hidden.
+type Widget struct {
+	Key   string
+	Label string
+}
+
+// DashboardWidgets lists the toggleable dashboard widgets in display order.
+var DashboardWidgets = []Widget{
+	{Key: "stats", Label: "Library stats"},
+	{Key: "quick_actions", Label: "Quick actions"},
+	{Key: "recent", Label: "Recent additions"},
+	{Key: "queue", Label: "Import queue"},
+	{Key: "goals", Label: "Goals"},
+	{Key: "storage", Label: "Storage forecast"},
+	{Key: "jobs", Label: "Recent jobs"},
+}
+
+// TableColumn is an optional column of the library tables.
+type TableColumn struct {
+	Key   string
+	Label string
+}
+
+// TableColumns lists the optional library table columns in display order. Title, artist,
+// album and duration are always shown.
+var TableColumns = []TableColumn{
+	{Key: "bitrate", Label: "Bitrate"},
+	{Key: "year", Label: "Year"},
+	{Key: "added", Label: "Added"},
+	{Key: "genre", Label: "Genre"},
+	{Key: "plays", Label: "Play count"},
+}
+
+// Densities are the row densities of the library tables, the first is the default.
+var Densities = []string{"comfortable", "compact"}
+
+// Preferences are the UI settings of a single user or browser session.
+type Preferences struct {
+	StartPage     string   `json:"startPage"`
+	HiddenWidgets []string `json:"hiddenWidgets"`
+	FamilyFilter  bool     `json:"familyFilter"` // hides explicit tracks and blocks their downloads
+	Columns       []string `json:"columns"`      // optional table columns shown, in no particular order
+	Density       string   `json:"density"`
+}
+
+// VisibleWidgets returns the widget keys that should be rendered.
+func (p Preferences) VisibleWidgets() map[string]bool {
+	visible := make(map[string]bool, len(DashboardWidgets))
+	for _, w := range DashboardWidgets {
+		visible[w.Key] = !slices.Contains(p.HiddenWidgets, w.Key)
+	}
+	return visible
+}
+
+// ShownColumns returns the optional table columns that should be rendered.
+func (p Preferences) ShownColumns() map[string]bool {
+	shown := make(map[string]bool, len(TableColumns))
+	for _, col := range TableColumns {
+		shown[col.Key] = slices.Contains(p.Columns, col.Key)
+	}
+	return shown
+}
+
+// PreferencesStore loads and saves the preferences of the requesting user.
+type PreferencesStore interface {
+	Load(c *fiber.Ctx) Preferences
+	Save(c *fiber.Ctx, prefs Preferences) error
+}
+
+// CookiePreferencesStore keeps preferences in a long-lived cookie, so they are per browser.
+type CookiePreferencesStore struct{}
+
+// NewCookiePreferencesStore creates a cookie backed preferences store.
+func NewCookiePreferencesStore() *CookiePreferencesStore {
+	return &CookiePreferencesStore{}
+}
+
+// Load reads the preferences cookie, falling back to the defaults when it's missing or invalid.
+func (s *CookiePreferencesStore) Load(c *fiber.Ctx) Preferences {
+	prefs := Preferences{StartPage: "dashboard", Density: Densities[0]}
+	raw, err := base64.RawURLEncoding.DecodeString(c.Cookies(preferencesCookie))
+	if err != nil || len(raw) == 0 {
+		return prefs
+	}
+	if err := json.Unmarshal(raw, &prefs); err != nil {
+		return Preferences{StartPage: "dashboard", Density: Densities[0]}
+	}
+	if _, ok := StartPages[prefs.StartPage]; !ok {
+		prefs.StartPage = "dashboard"
+	}
+	if !slices.Contains(Densities, prefs.Density) {
+		prefs.Density = Densities[0]
+	}
+	return prefs
+}
+
+// Save writes the preferences cookie.
+func (s *CookiePreferencesStore) Save(c *fiber.Ctx, prefs Preferences) error {
+	raw, err := json.Marshal(prefs)
+	if err != nil {
+		return err
+	}
+	c.Cookie(&fiber.Cookie{
+		Name:     preferencesCookie,
+		Value:    base64.RawURLEncoding.EncodeToString(raw),
+		Path:     "/",
+		Expires:  time.Now().AddDate(1, 0, 0),
+		HTTPOnly: true,
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+	return nil
+}
+
+// PreferencesMiddleware exposes the requesting user's family filter and table layout to
+// handlers and views, so features can follow them without depending on the preferences store.
+func PreferencesMiddleware(store PreferencesStore) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		prefs := store.Load(c)
+		c.Locals(familyFilterLocal, prefs.FamilyFilter)
+		c.Locals(columnsLocal, prefs.ShownColumns())
+		c.Locals(densityLocal, prefs.Density)
+		return c.Next()
+	}
+}
+
+// FamilyFilter reports whether explicit content must be hidden from the current request.
+func FamilyFilter(c *fiber.Ctx) bool {
+	enabled, _ := c.Locals(familyFilterLocal).(bool)
+	return enabled
+}
+
+// Columns returns the optional table columns the current request shows.
+func Columns(c *fiber.Ctx) map[string]bool {
+	shown, _ := c.Locals(columnsLocal).(map[string]bool)
+	return shown
+}
@@ -6,8 +6,10 @@ import (
 
 // RegisterRoutes registers the routes for the UI feature.
 func RegisterRoutes(app *fiber.App, handler *Handler) {
-	app.Get("/", handler.RenderDashboard)
+	app.Get("/", handler.RenderStartPage)
 	app.Get("/dashboard", handler.RenderDashboard)
 	app.Get("/analyze", handler.RenderAnalyzeSection)
 	app.Get("/dashboard/quick-actions", handler.GetQuickActionsCard)
+	app.Get("/preferences", handler.GetPreferencesForm)
+	app.Post("/preferences", handler.SavePreferences)
 }
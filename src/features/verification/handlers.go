@@ -0,0 +1,75 @@
+package verification
+
+import (
+	"log/slog"
+
+	"github.com/contre95/soulsolid/src/features/hosting/respond"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Handler handles HTTP requests for the verification feature.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new verification handler.
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// GetStatus renders the verification card with how many tracks are due or failed.
+func (h *Handler) GetStatus(c *fiber.Ctx) error {
+	status, err := h.service.GetStatus(c.Context())
+	if err != nil {
+		slog.Error("Error loading verification status", "error", err)
+		return respond.ToastErr(c, fiber.StatusInternalServerError, "Failed to load verification status")
+	}
+	return respond.Partial(c, "verification/status", fiber.Map{"Status": status})
+}
+
+// StartVerification starts the job verifying a batch of the files due for verification, or all
+// of them with all=true.
+func (h *Handler) StartVerification(c *fiber.Ctx) error {
+	limit := h.service.configManager.Get().Verification.BatchSize
+	if c.FormValue("all") == "true" {
+		limit = 0
+	}
+	jobID, err := h.service.StartVerification(limit)
+	if err != nil {
+		slog.Error("Failed to start file verification", "error", err)
+		return respond.ToastErr(c, fiber.StatusInternalServerError, "Failed to start verification: "+err.Error())
+	}
+	c.Set("HX-Trigger", "refreshJobList")
+	return respond.ToastJob(c, jobID, "File verification started")
+}
+
+// GetTrackVerification renders when the file of a track was last verified.
+func (h *Handler) GetTrackVerification(c *fiber.Ctx) error {
+	trackID := c.Params("trackId")
+	v, err := h.service.GetTrackVerification(c.Context(), trackID)
+	if err != nil {
+		slog.Error("Error loading track verification", "trackID", trackID, "error", err)
+		return respond.ToastErr(c, fiber.StatusInternalServerError, "Failed to load verification")
+	}
+	return respond.Partial(c, "verification/track", fiber.Map{
+		"TrackID":      trackID,
+		"Verification": v,
+		"Stale":        h.service.Stale(v),
+	})
+}
+
+// VerifyTrack verifies the file of a track now.
+func (h *Handler) VerifyTrack(c *fiber.Ctx) error {
+	trackID := c.Params("trackId")
+	v, err := h.service.VerifyTrack(c.Context(), trackID)
+	if err != nil {
+		slog.Error("Failed to verify track", "trackID", trackID, "error", err)
+		return respond.ToastErr(c, fiber.StatusNotFound, err.Error())
+	}
+	slog.Info("Verified track file", "trackID", trackID, "status", v.Status)
+	return respond.Partial(c, "verification/track", fiber.Map{
+		"TrackID":      trackID,
+		"Verification": v,
+		"Stale":        false,
+	})
+}
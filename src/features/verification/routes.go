@@ -0,0 +1,13 @@
+package verification
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// RegisterRoutes registers the verification routes with the Fiber app.
+func RegisterRoutes(app *fiber.App, handler *Handler) {
+	app.Get("/verification/status", handler.GetStatus)
+	app.Post("/verification/run", handler.StartVerification)
+	app.Get("/verification/track/:trackId", handler.GetTrackVerification)
+	app.Post("/verification/track/:trackId", handler.VerifyTrack)
+}
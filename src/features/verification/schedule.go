@@ -0,0 +1,48 @@
+package verification
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// checkInterval is how often the nightly verification schedule is checked.
+const checkInterval = 10 * time.Minute
+
+// watchVerification verifies a batch of the files due for verification once a night at the
+// configured hour.
+func (s *Service) watchVerification() {
+	var lastDay string
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		cfg := s.configManager.Get().Verification
+		if cfg.Hour < 0 || s.paused.Load() {
+			continue
+		}
+		// A run missed while paused or down is caught up on the same day, unless files were
+		// verified since the scheduled hour
+		now := time.Now()
+		today := now.Format("2006-01-02")
+		scheduled := time.Date(now.Year(), now.Month(), now.Day(), cfg.Hour, 0, 0, 0, time.Local)
+		if now.Before(scheduled) || lastDay == today {
+			continue
+		}
+		status, err := s.GetStatus(context.Background())
+		if err != nil {
+			slog.Warn("Failed to check the verification status", "error", err)
+			continue
+		}
+		if status.Due == 0 || !status.Latest.Before(scheduled) {
+			lastDay = today
+			continue
+		}
+		jobID, err := s.StartVerification(cfg.BatchSize)
+		if err != nil {
+			slog.Error("Failed to start the nightly file verification", "error", err)
+			continue
+		}
+		lastDay = today
+		slog.Info("Started the nightly file verification", "jobID", jobID, "due", status.Due, "batchSize", cfg.BatchSize)
+	}
+}
@@ -0,0 +1,228 @@
+package verification
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/contre95/soulsolid/src/features/config"
+	"github.com/contre95/soulsolid/src/music"
+)
+
+// Verification statuses of a track file.
+const (
+	StatusOK         = "ok"
+	StatusMissing    = "missing"
+	StatusUnreadable = "unreadable"
+	// StatusCorrupted is a file whose content changed while its size and modification time
+	// didn't, the kind of change left by bit rot rather than by an edit.
+	StatusCorrupted = "corrupted"
+)
+
+// Store keeps when each track file was last verified.
+type Store interface {
+	GetTrack(ctx context.Context, id string) (*music.Track, error)
+	// GetVerification returns the last verification of a track, nil when it was never verified.
+	GetVerification(ctx context.Context, trackID string) (*Verification, error)
+	SaveVerification(ctx context.Context, v *Verification) error
+	// GetUnverifiedTrackIDs returns up to limit tracks never verified or last verified before
+	// the given time, the longest unverified first. A limit of 0 returns all of them.
+	GetUnverifiedTrackIDs(ctx context.Context, before time.Time, limit int) ([]string, error)
+	GetVerificationSummary(ctx context.Context, before time.Time) (*Summary, error)
+}
+
+// TagReader reads the tags of a file, a file whose tags can't be read fails its verification.
+type TagReader interface {
+	ReadFileTags(ctx context.Context, path string) (*music.Track, error)
+}
+
+// Jobs starts the verification job.
+type Jobs interface {
+	StartJob(jobType string, name string, metadata map[string]any) (string, error)
+}
+
+// Verification is the last check of the file of a track: it was read in full to hash it and
+// its tags were read.
+type Verification struct {
+	TrackID    string    `json:"track_id"`
+	VerifiedAt time.Time `json:"verified_at"`
+	Status     string    `json:"status"`
+	Error      string    `json:"error,omitempty"`
+	Hash       string    `json:"hash,omitempty"` // SHA-256 of the file, the last good one for a corrupted file
+	Size       int64     `json:"size,omitempty"`
+	ModTime    time.Time `json:"mod_time,omitzero"`
+}
+
+// OK reports whether the file passed its verification.
+func (v *Verification) OK() bool {
+	return v.Status == StatusOK
+}
+
+// Summary counts the tracks by how recently and how well their files were verified.
+type Summary struct {
+	Tracks   int       `json:"tracks"`
+	Verified int       `json:"verified"` // verified at least once
+	Due      int       `json:"due"`      // never verified or not within verification.after_months
+	Problems int       `json:"problems"` // whose last verification wasn't ok
+	Oldest   time.Time `json:"oldest,omitzero"`
+	Latest   time.Time `json:"latest,omitzero"`
+}
+
+// Status is the verification summary of the library with its schedule.
+type Status struct {
+	Summary
+	AfterMonths int       `json:"after_months"`
+	BatchSize   int       `json:"batch_size"`
+	NextRun     time.Time `json:"next_run,omitzero"` // zero when the nightly verification is disabled
+}
+
+// Service verifies the files of the library and keeps when each one was last verified.
+type Service struct {
+	store         Store
+	tagReader     TagReader
+	jobs          Jobs
+	configManager *config.Manager
+	paused        atomic.Bool // maintenance mode: no nightly verification
+}
+
+// NewService creates a new verification service and starts its nightly schedule.
+func NewService(store Store, tagReader TagReader, jobs Jobs, cfgManager *config.Manager) *Service {
+	s := &Service{
+		store:         store,
+		tagReader:     tagReader,
+		jobs:          jobs,
+		configManager: cfgManager,
+	}
+	go s.watchVerification()
+	return s
+}
+
+// Pause stops the nightly verification for maintenance mode.
+func (s *Service) Pause() { s.paused.Store(true) }
+
+// Resume restarts the nightly verification, catching up on a run missed the same day.
+func (s *Service) Resume() { s.paused.Store(false) }
+
+// dueBefore returns the time before which a verification is too old to count.
+func (s *Service) dueBefore() time.Time {
+	return time.Now().AddDate(0, -s.configManager.Get().Verification.AfterMonths, 0)
+}
+
+// GetStatus counts the verified, due and failed tracks and tells when the next nightly
+// verification runs.
+func (s *Service) GetStatus(ctx context.Context) (*Status, error) {
+	cfg := s.configManager.Get().Verification
+	summary, err := s.store.GetVerificationSummary(ctx, s.dueBefore())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get verification summary: %w", err)
+	}
+	status := &Status{Summary: *summary, AfterMonths: cfg.AfterMonths, BatchSize: cfg.BatchSize}
+	if cfg.Hour >= 0 {
+		now := time.Now()
+		status.NextRun = time.Date(now.Year(), now.Month(), now.Day(), cfg.Hour, 0, 0, 0, time.Local)
+		if !status.NextRun.After(now) {
+			status.NextRun = status.NextRun.AddDate(0, 0, 1)
+		}
+	}
+	return status, nil
+}
+
+// GetTrackVerification returns the last verification of a track, nil when it was never
+// verified.
+func (s *Service) GetTrackVerification(ctx context.Context, trackID string) (*Verification, error) {
+	v, err := s.store.GetVerification(ctx, trackID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get verification: %w", err)
+	}
+	return v, nil
+}
+
+// Stale reports whether a verification is older than verification.after_months.
+func (s *Service) Stale(v *Verification) bool {
+	return v == nil || v.VerifiedAt.Before(s.dueBefore())
+}
+
+// VerifyTrack reads the file of a track in full and its tags, and stores the outcome. A
+// problem with the file is recorded in the verification, not returned as an error.
+func (s *Service) VerifyTrack(ctx context.Context, trackID string) (*Verification, error) {
+	track, err := s.store.GetTrack(ctx, trackID)
+	if err != nil || track == nil {
+		return nil, fmt.Errorf("track not found: %s", trackID)
+	}
+	previous, err := s.store.GetVerification(ctx, trackID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the last verification: %w", err)
+	}
+	v := s.verifyFile(ctx, track.Path, previous)
+	v.TrackID = track.ID
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := s.store.SaveVerification(ctx, v); err != nil {
+		return nil, fmt.Errorf("failed to save verification: %w", err)
+	}
+	return v, nil
+}
+
+// verifyFile checks a file against its previous verification.
+func (s *Service) verifyFile(ctx context.Context, path string, previous *Verification) *Verification {
+	v := &Verification{VerifiedAt: time.Now()}
+	info, err := os.Stat(path)
+	if err != nil {
+		v.Status, v.Error = StatusUnreadable, err.Error()
+		if errors.Is(err, fs.ErrNotExist) {
+			v.Status = StatusMissing
+		}
+		return v
+	}
+	v.Size, v.ModTime = info.Size(), info.ModTime()
+	if v.Hash, err = hashFile(path); err != nil {
+		v.Status, v.Error = StatusUnreadable, err.Error()
+		return v
+	}
+
+	// An unchanged size and modification time mean the file wasn't rewritten since the last
+	// verification, a different hash then means its content got damaged. The last good hash is
+	// kept so the file stays flagged until it's replaced.
+	if previous != nil && previous.Hash != "" && previous.Size == v.Size && previous.ModTime.Equal(v.ModTime) && previous.Hash != v.Hash {
+		v.Status, v.Error, v.Hash = StatusCorrupted, "content changed since "+previous.VerifiedAt.Format("2006-01-02")+" without being modified", previous.Hash
+		return v
+	}
+	if _, err := s.tagReader.ReadFileTags(ctx, path); err != nil {
+		v.Status, v.Error = StatusUnreadable, fmt.Sprintf("failed to read tags: %v", err)
+		return v
+	}
+	v.Status = StatusOK
+	return v
+}
+
+// hashFile returns the SHA-256 of a file, reading all of it.
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// StartVerification starts the job verifying the files due for verification, up to limit
+// of them, or all of them with a limit of 0.
+func (s *Service) StartVerification(limit int) (string, error) {
+	jobID, err := s.jobs.StartJob("verify_files", "File Verification", map[string]any{"limit": limit})
+	if err != nil {
+		return "", fmt.Errorf("failed to start file verification: %w", err)
+	}
+	return jobID, nil
+}
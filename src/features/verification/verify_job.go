@@ -0,0 +1,94 @@
+package verification
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/contre95/soulsolid/src/music"
+)
+
+// maxReportedProblems caps the problems listed in the result of a verification job, all of
+// them are in the job log and behind the library filter.
+const maxReportedProblems = 50
+
+// VerifyJobTask verifies the files due for verification, the never verified first.
+type VerifyJobTask struct {
+	service *Service
+}
+
+// NewVerifyJobTask creates a new file verification job task.
+func NewVerifyJobTask(service *Service) *VerifyJobTask {
+	return &VerifyJobTask{
+		service: service,
+	}
+}
+
+// MetadataKeys returns the required metadata keys for verification jobs.
+func (t *VerifyJobTask) MetadataKeys() []string {
+	return []string{"limit"}
+}
+
+// Execute verifies up to the limit of files due for verification.
+func (t *VerifyJobTask) Execute(ctx context.Context, job *music.Job, progressUpdater func(int, string)) (map[string]any, error) {
+	limit, _ := job.Metadata["limit"].(int)
+	ids, err := t.service.store.GetUnverifiedTrackIDs(ctx, t.service.dueBefore(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the tracks due for verification: %w", err)
+	}
+	total := len(ids)
+	job.Logger.Info("Starting file verification", "due", total, "limit", limit, "color", "blue")
+
+	counts := map[string]int{}
+	problems := []map[string]string{}
+	for i, id := range ids {
+		if err := ctx.Err(); err != nil {
+			job.Logger.Info("File verification cancelled", "verified", i, "color", "orange")
+			return nil, err
+		}
+		progressUpdater((i*100)/total, fmt.Sprintf("Verifying file %d/%d", i+1, total))
+
+		v, err := t.service.VerifyTrack(ctx, id)
+		if err != nil {
+			job.Logger.Warn("Failed to verify track", "trackID", id, "error", err, "color", "red")
+			counts["errors"]++
+			continue
+		}
+		counts[v.Status]++
+		if v.OK() {
+			continue
+		}
+		track, _ := t.service.store.GetTrack(ctx, id)
+		title, path := id, ""
+		if track != nil {
+			title, path = track.Title, track.Path
+		}
+		job.Logger.Warn("File failed verification", "trackID", id, "title", title, "path", path, "status", v.Status, "error", v.Error, "color", "red")
+		if len(problems) < maxReportedProblems {
+			problems = append(problems, map[string]string{"track_id": id, "title": title, "path": path, "status": v.Status, "error": v.Error})
+		}
+	}
+
+	failed := counts[StatusMissing] + counts[StatusUnreadable] + counts[StatusCorrupted]
+	finalMsg := fmt.Sprintf("File verification completed: %d file(s) verified, %d ok, %d with problems, %d errors", total, counts[StatusOK], failed, counts["errors"])
+	job.Logger.Info("File verification completed", "verified", total, "ok", counts[StatusOK], "missing", counts[StatusMissing],
+		"unreadable", counts[StatusUnreadable], "corrupted", counts[StatusCorrupted], "errors", counts["errors"], "color", "green")
+	progressUpdater(100, fmt.Sprintf("Done — %d file(s) verified, %d with problems", total, failed))
+
+	return map[string]any{
+		"verified":   total,
+		"ok":         counts[StatusOK],
+		"missing":    counts[StatusMissing],
+		"unreadable": counts[StatusUnreadable],
+		"corrupted":  counts[StatusCorrupted],
+		"errors":     counts["errors"],
+		"problems":   problems,
+		"msg":        finalMsg,
+	}, nil
+}
+
+// Cleanup performs cleanup after verification job completion.
+func (t *VerifyJobTask) Cleanup(job *music.Job) error {
+	slog.Debug("Cleaning up file verification job", "jobID", job.ID)
+	return nil
+}
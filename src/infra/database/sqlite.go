@@ -3,12 +3,15 @@ package database
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log/slog"
 	"strings"
 	"time"
 
 	"github.com/contre95/soulsolid/src/features/metrics"
+	"github.com/contre95/soulsolid/src/features/streaming"
+	"github.com/contre95/soulsolid/src/features/verification"
 	"github.com/contre95/soulsolid/src/music"
 	"github.com/google/uuid"
 	_ "github.com/mattn/go-sqlite3"
@@ -16,11 +19,20 @@ import (
 
 // SqliteLibrary is a SQLite implementation of the Library interface.
 type SqliteLibrary struct {
-	db *sql.DB
+	db       *sql.DB
+	sortKeys SortKeyer
 }
 
-// NewSqliteLibrary creates a new SqliteLibrary.
-func NewSqliteLibrary(path string) (*SqliteLibrary, error) {
+// SortKeyer builds the keys names are sorted by.
+type SortKeyer interface {
+	Key(name string) string
+	// Signature identifies the settings the keys are built with.
+	Signature() string
+}
+
+// NewSqliteLibrary creates a new SqliteLibrary. Names are sorted by the keys of sortKeys, the
+// stored keys are rebuilt when its signature changed since the last start.
+func NewSqliteLibrary(path string, sortKeys SortKeyer) (*SqliteLibrary, error) {
 	db, err := sql.Open("sqlite3", path)
 	if err != nil {
 		return nil, err
@@ -30,7 +42,85 @@ func NewSqliteLibrary(path string) (*SqliteLibrary, error) {
 		return nil, err
 	}
 
-	return &SqliteLibrary{db: db}, nil
+	d := &SqliteLibrary{db: db, sortKeys: sortKeys}
+	if err := d.refreshSortKeys(); err != nil {
+		return nil, fmt.Errorf("failed to build sort keys: %w", err)
+	}
+	return d, nil
+}
+
+// refreshSortKeys rebuilds the sort keys of every artist, album and track when they were built
+// with other settings, or never.
+func (d *SqliteLibrary) refreshSortKeys() error {
+	var stored string
+	err := d.db.QueryRow(`SELECT value FROM library_settings WHERE key = 'sort_keys'`).Scan(&stored)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	signature := d.sortKeys.Signature()
+	if stored == signature {
+		return nil
+	}
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	total := 0
+	for _, table := range []struct{ name, column string }{
+		{"artists", "COALESCE(NULLIF(sort_name, ''), name)"},
+		{"albums", "title"},
+		{"tracks", "title"},
+	} {
+		rows, err := tx.Query(fmt.Sprintf(`SELECT id, COALESCE(%s, '') FROM %s`, table.column, table.name))
+		if err != nil {
+			return err
+		}
+		keys := map[string]string{}
+		for rows.Next() {
+			var id, name string
+			if err := rows.Scan(&id, &name); err != nil {
+				rows.Close()
+				return err
+			}
+			keys[id] = d.sortKeys.Key(name)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		for id, key := range keys {
+			if _, err := tx.Exec(fmt.Sprintf(`UPDATE %s SET sort_key = ? WHERE id = ?`, table.name), key, id); err != nil {
+				return err
+			}
+		}
+		total += len(keys)
+	}
+	if _, err := tx.Exec(`INSERT INTO library_settings (key, value) VALUES ('sort_keys', ?) ON CONFLICT(key) DO UPDATE SET value = excluded.value`, signature); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	slog.Info("Rebuilt sort keys", "names", total, "settings", signature)
+	return nil
+}
+
+// artistSortName is the name an artist is sorted by, its sort name when set.
+func artistSortName(artist *music.Artist) string {
+	if artist.SortName != "" {
+		return artist.SortName
+	}
+	return artist.Name
+}
+
+// Snapshot writes a consistent copy of the database to dest, which must not exist yet. The
+// library stays usable while the copy is written.
+func (d *SqliteLibrary) Snapshot(ctx context.Context, dest string) error {
+	if _, err := d.db.ExecContext(ctx, `VACUUM INTO ?`, dest); err != nil {
+		return fmt.Errorf("failed to snapshot database: %w", err)
+	}
+	return nil
 }
 
 func createTables(db *sql.DB) error {
@@ -38,7 +128,9 @@ func createTables(db *sql.DB) error {
 		CREATE TABLE IF NOT EXISTS artists (
 			id TEXT PRIMARY KEY,
 			name TEXT NOT NULL UNIQUE,
-			sort_name TEXT
+			sort_name TEXT,
+			track_count INTEGER DEFAULT 0,
+			total_duration INTEGER DEFAULT 0
 		);
 		
 		CREATE TABLE IF NOT EXISTS albums (
@@ -53,7 +145,9 @@ func createTables(db *sql.DB) error {
 			status TEXT,
 			barcode TEXT,
 			added_date TEXT,
-			modified_date TEXT
+			modified_date TEXT,
+			track_count INTEGER DEFAULT 0,
+			total_duration INTEGER DEFAULT 0
 		);
 		
 		CREATE TABLE IF NOT EXISTS tracks (
@@ -107,8 +201,10 @@ func createTables(db *sql.DB) error {
 		);
 		
 		CREATE TABLE IF NOT EXISTS track_albums (
-			track_id TEXT PRIMARY KEY,
+			track_id TEXT,
 			album_id TEXT,
+			is_primary BOOLEAN DEFAULT TRUE,
+			PRIMARY KEY (track_id, album_id),
 			FOREIGN KEY (track_id) REFERENCES tracks(id),
 			FOREIGN KEY (album_id) REFERENCES albums(id)
 		);
@@ -167,9 +263,53 @@ func createTables(db *sql.DB) error {
 			UNIQUE(metric_type, metric_key)
 		);
 
+		CREATE TABLE IF NOT EXISTS goals (
+			id TEXT PRIMARY KEY,
+			criterion TEXT NOT NULL,
+			decade INTEGER DEFAULT 0,
+			genre TEXT DEFAULT '',
+			playlist_id TEXT DEFAULT '',
+			target INTEGER NOT NULL,
+			created_at TEXT
+		);
+
+		CREATE TABLE IF NOT EXISTS disk_usage (
+			day TEXT PRIMARY KEY,
+			library_bytes INTEGER NOT NULL,
+			free_bytes INTEGER NOT NULL,
+			total_bytes INTEGER NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS plays (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user TEXT NOT NULL,
+			track_id TEXT NOT NULL,
+			title TEXT NOT NULL,
+			artist TEXT NOT NULL,
+			album TEXT NOT NULL,
+			duration INTEGER NOT NULL,
+			played_at TEXT NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS library_settings (
+			key TEXT PRIMARY KEY,
+			value TEXT NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS track_verifications (
+			track_id TEXT PRIMARY KEY,
+			verified_at TEXT NOT NULL,
+			status TEXT NOT NULL,
+			error TEXT,
+			hash TEXT,
+			size INTEGER,
+			mod_time INTEGER
+		);
+
 		CREATE INDEX IF NOT EXISTS idx_track_artists_track ON track_artists(track_id);
 		CREATE INDEX IF NOT EXISTS idx_track_artists_artist ON track_artists(artist_id);
 		CREATE INDEX IF NOT EXISTS idx_album_artists_album ON album_artists(album_id);
+		CREATE INDEX IF NOT EXISTS idx_track_albums_album ON track_albums(album_id);
 		CREATE INDEX IF NOT EXISTS idx_album_artists_artist ON album_artists(artist_id);
 		CREATE INDEX IF NOT EXISTS idx_track_attributes_track ON track_attributes(track_id);
 		CREATE INDEX IF NOT EXISTS idx_track_attributes_key_value ON track_attributes(key, value);
@@ -178,6 +318,14 @@ func createTables(db *sql.DB) error {
 		CREATE INDEX IF NOT EXISTS idx_playlist_tracks_playlist ON playlist_tracks(playlist_id);
 		CREATE INDEX IF NOT EXISTS idx_playlist_tracks_track ON playlist_tracks(track_id);
 		CREATE INDEX IF NOT EXISTS idx_tracks_genre ON tracks(genre);
+		CREATE INDEX IF NOT EXISTS idx_tracks_added_id ON tracks(COALESCE(added_date, ''), id);
+		CREATE INDEX IF NOT EXISTS idx_artists_name_nocase ON artists(name COLLATE NOCASE);
+		CREATE INDEX IF NOT EXISTS idx_albums_title_nocase ON albums(title COLLATE NOCASE);
+		CREATE INDEX IF NOT EXISTS idx_tracks_title_nocase ON tracks(title COLLATE NOCASE);
+		CREATE INDEX IF NOT EXISTS idx_plays_user_played ON plays(user, played_at);
+		CREATE INDEX IF NOT EXISTS idx_plays_played ON plays(played_at);
+		CREATE INDEX IF NOT EXISTS idx_plays_track ON plays(track_id);
+		CREATE INDEX IF NOT EXISTS idx_track_verifications_verified ON track_verifications(verified_at);
 	`)
 	if err != nil {
 		return err
@@ -206,6 +354,71 @@ func createTables(db *sql.DB) error {
 		}
 		slog.Info("Added missing column", "col", col.name)
 	}
+	// Migrate #2: track count and duration rollups, backfilled once when the columns are added
+	backfill := false
+	for _, table := range []string{"albums", "artists"} {
+		for _, col := range []colDef{{"track_count", "INTEGER DEFAULT 0"}, {"total_duration", "INTEGER DEFAULT 0"}} {
+			var count int
+			if err := db.QueryRow("SELECT count(*) FROM pragma_table_info(?) WHERE name=?", table, col.name).Scan(&count); err != nil || count > 0 {
+				continue
+			}
+			if _, err := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, col.name, col.typ)); err != nil {
+				return fmt.Errorf("failed to add %s.%s: %w", table, col.name, err)
+			}
+			slog.Info("Added missing column", "table", table, "col", col.name)
+			backfill = true
+		}
+	}
+	if backfill {
+		if _, err := db.Exec(albumRollupUpdate); err != nil {
+			return fmt.Errorf("failed to backfill album rollups: %w", err)
+		}
+		if _, err := db.Exec(artistRollupUpdate); err != nil {
+			return fmt.Errorf("failed to backfill artist rollups: %w", err)
+		}
+	}
+
+	// Migrate #3: tracks can appear on several albums, one of them being the primary album their
+	// file and tags follow. The one-to-one table is rebuilt with every existing link as primary.
+	var hasPrimary int
+	if err := db.QueryRow("SELECT count(*) FROM pragma_table_info('track_albums') WHERE name='is_primary'").Scan(&hasPrimary); err != nil {
+		return fmt.Errorf("failed to inspect track_albums: %w", err)
+	}
+	if hasPrimary == 0 {
+		if err := migrateTrackAlbums(db); err != nil {
+			return fmt.Errorf("failed to migrate track_albums: %w", err)
+		}
+		slog.Info("Migrated track_albums to support album appearances")
+	}
+	if _, err := db.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_track_albums_primary ON track_albums(track_id) WHERE is_primary = 1`); err != nil {
+		return fmt.Errorf("failed to create primary album index: %w", err)
+	}
+
+	// Migrate #4: collation sort keys, filled in by refreshSortKeys
+	for _, table := range []string{"artists", "albums", "tracks"} {
+		var count int
+		if err := db.QueryRow("SELECT count(*) FROM pragma_table_info(?) WHERE name='sort_key'", table).Scan(&count); err != nil || count > 0 {
+			continue
+		}
+		if _, err := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN sort_key TEXT", table)); err != nil {
+			return fmt.Errorf("failed to add %s.sort_key: %w", table, err)
+		}
+		slog.Info("Added missing column", "table", table, "col", "sort_key")
+	}
+	if _, err := db.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_artists_sort_key ON artists(sort_key);
+		CREATE INDEX IF NOT EXISTS idx_albums_sort_key ON albums(sort_key);
+		CREATE INDEX IF NOT EXISTS idx_tracks_sort_key ON tracks(sort_key);
+	`); err != nil {
+		return fmt.Errorf("failed to create sort key indexes: %w", err)
+	}
+
+	// Migrate #5: the stored metrics follow every track, album and artist change between two full
+	// recomputations. The triggers only run once a recomputation stored its status row.
+	if _, err := db.Exec(metricsTriggers()); err != nil {
+		return fmt.Errorf("failed to create metrics triggers: %w", err)
+	}
+
 	// Verify critical
 	var verifyCount int
 	if err := db.QueryRow("SELECT count(*) FROM pragma_table_info('tracks') WHERE name='has_lyrics'").Scan(&verifyCount); err != nil || verifyCount == 0 {
@@ -215,6 +428,139 @@ func createTables(db *sql.DB) error {
 	return nil
 }
 
+// trackMetricDeltas are the stored metrics a track counts towards: the metric type, its key and
+// the condition for the track to be counted, with row standing for NEW or OLD.
+var trackMetricDeltas = []struct{ metricType, key, when string }{
+	{"totals", "'tracks'", "1"},
+	{"genre_counts", "COALESCE(row.genre, 'Unknown')", "1"},
+	{"format_distribution", "COALESCE(row.format, 'Unknown')", "1"},
+	{"year_distribution", "CAST(row.year AS TEXT)", "row.year > 0"},
+	{"lyrics_stats", "CASE WHEN row.lyrics IS NOT NULL AND row.lyrics != '' THEN 'has_lyrics' ELSE 'no_lyrics' END", "1"},
+	{"metadata_completeness", "'missing_genre'", "row.genre IS NULL OR row.genre = ''"},
+	{"metadata_completeness", "'missing_year'", "row.year IS NULL OR row.year = 0"},
+	{"metadata_completeness", "'missing_lyrics'", "row.lyrics IS NULL OR row.lyrics = ''"},
+}
+
+// metricsTriggers builds the triggers keeping the stored metrics up to date. The completeness of
+// tracks and the field coverage depend on other tables and are left to the full recomputation.
+func metricsTriggers() string {
+	const computed = "EXISTS (SELECT 1 FROM library_metrics WHERE metric_type = 'status' AND metric_key = 'computed_at')"
+	adjust := func(metricType, key, when string, delta int) string {
+		return fmt.Sprintf(`INSERT INTO library_metrics (metric_type, metric_key, metric_value, updated_at)
+			SELECT '%s', %s, %d, datetime('now') WHERE %s
+			ON CONFLICT(metric_type, metric_key) DO UPDATE SET metric_value = metric_value + excluded.metric_value, updated_at = excluded.updated_at;
+			`, metricType, key, delta, when)
+	}
+	trackDeltas := func(row string, delta int) string {
+		var b strings.Builder
+		for _, m := range trackMetricDeltas {
+			b.WriteString(adjust(m.metricType, strings.ReplaceAll(m.key, "row.", row+"."), strings.ReplaceAll(m.when, "row.", row+"."), delta))
+		}
+		return b.String()
+	}
+	changed := adjust("status", "'changes'", "1", 1)
+	cleanup := "DELETE FROM library_metrics WHERE metric_value <= 0 AND metric_type IN ('genre_counts', 'format_distribution', 'year_distribution');\n"
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "CREATE TRIGGER IF NOT EXISTS metrics_track_insert AFTER INSERT ON tracks WHEN %s BEGIN\n%s%sEND;\n", computed, trackDeltas("NEW", 1), changed)
+	fmt.Fprintf(&b, "CREATE TRIGGER IF NOT EXISTS metrics_track_delete AFTER DELETE ON tracks WHEN %s BEGIN\n%s%s%sEND;\n", computed, trackDeltas("OLD", -1), changed, cleanup)
+	fmt.Fprintf(&b, `CREATE TRIGGER IF NOT EXISTS metrics_track_update AFTER UPDATE OF genre, format, year, lyrics ON tracks
+		WHEN %s AND (OLD.genre IS NOT NEW.genre OR OLD.format IS NOT NEW.format OR OLD.year IS NOT NEW.year OR OLD.lyrics IS NOT NEW.lyrics) BEGIN
+		%s%s%s%sEND;
+		`, computed, trackDeltas("OLD", -1), trackDeltas("NEW", 1), changed, cleanup)
+	for _, table := range []struct{ name, key, when string }{{"albums", "'albums'", "1"}, {"artists", "'artists'", "row.name != ''"}} {
+		fmt.Fprintf(&b, "CREATE TRIGGER IF NOT EXISTS metrics_%s_insert AFTER INSERT ON %s WHEN %s BEGIN\n%s%sEND;\n",
+			table.name, table.name, computed, adjust("totals", table.key, strings.ReplaceAll(table.when, "row.", "NEW."), 1), changed)
+		fmt.Fprintf(&b, "CREATE TRIGGER IF NOT EXISTS metrics_%s_delete AFTER DELETE ON %s WHEN %s BEGIN\n%s%sEND;\n",
+			table.name, table.name, computed, adjust("totals", table.key, strings.ReplaceAll(table.when, "row.", "OLD."), -1), changed)
+	}
+	return b.String()
+}
+
+// migrateTrackAlbums rebuilds the one-to-one track_albums table with a composite key and a primary flag.
+func migrateTrackAlbums(db *sql.DB) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, stmt := range []string{
+		`CREATE TABLE track_albums_new (
+			track_id TEXT,
+			album_id TEXT,
+			is_primary BOOLEAN DEFAULT TRUE,
+			PRIMARY KEY (track_id, album_id),
+			FOREIGN KEY (track_id) REFERENCES tracks(id),
+			FOREIGN KEY (album_id) REFERENCES albums(id)
+		)`,
+		`INSERT INTO track_albums_new (track_id, album_id, is_primary) SELECT track_id, album_id, 1 FROM track_albums WHERE album_id IS NOT NULL`,
+		`DROP TABLE track_albums`,
+		`ALTER TABLE track_albums_new RENAME TO track_albums`,
+		`CREATE INDEX IF NOT EXISTS idx_track_albums_album ON track_albums(album_id)`,
+	} {
+		if _, err := tx.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Rollup statements recompute the stored track count and total duration (seconds) of albums and
+// artists, so list views don't need to aggregate tracks per row.
+const (
+	albumRollupUpdate = `UPDATE albums SET
+		track_count = (SELECT COUNT(*) FROM track_albums ta WHERE ta.album_id = albums.id),
+		total_duration = (SELECT COALESCE(SUM(t.duration), 0) FROM track_albums ta JOIN tracks t ON t.id = ta.track_id WHERE ta.album_id = albums.id)`
+	artistRollupUpdate = `UPDATE artists SET
+		track_count = (SELECT COUNT(DISTINCT ta.track_id) FROM track_artists ta WHERE ta.artist_id = artists.id),
+		total_duration = (SELECT COALESCE(SUM(t.duration), 0) FROM tracks t WHERE t.id IN (SELECT ta.track_id FROM track_artists ta WHERE ta.artist_id = artists.id))`
+)
+
+// rollupTargets returns the albums and artists the given tracks are linked to.
+func rollupTargets(ctx context.Context, tx *sql.Tx, trackIDs ...string) (albumIDs, artistIDs []string, err error) {
+	for _, trackID := range trackIDs {
+		rows, err := tx.QueryContext(ctx, `
+			SELECT 'album', album_id FROM track_albums WHERE track_id = ?
+			UNION SELECT 'artist', artist_id FROM track_artists WHERE track_id = ?
+		`, trackID, trackID)
+		if err != nil {
+			return nil, nil, err
+		}
+		for rows.Next() {
+			var kind, id string
+			if err := rows.Scan(&kind, &id); err != nil {
+				rows.Close()
+				return nil, nil, err
+			}
+			if kind == "album" {
+				albumIDs = append(albumIDs, id)
+			} else {
+				artistIDs = append(artistIDs, id)
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, nil, err
+		}
+	}
+	return albumIDs, artistIDs, nil
+}
+
+// refreshRollups recomputes the rollups of the given albums and artists.
+func refreshRollups(ctx context.Context, tx *sql.Tx, albumIDs, artistIDs []string) error {
+	for _, id := range albumIDs {
+		if _, err := tx.ExecContext(ctx, albumRollupUpdate+` WHERE id = ?`, id); err != nil {
+			return err
+		}
+	}
+	for _, id := range artistIDs {
+		if _, err := tx.ExecContext(ctx, artistRollupUpdate+` WHERE id = ?`, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // AddTrack adds a track to the database.
 func (d *SqliteLibrary) AddTrack(ctx context.Context, track *music.Track) error {
 	// Validate track using domain validation
@@ -244,13 +590,14 @@ func (d *SqliteLibrary) AddTrack(ctx context.Context, track *music.Track) error
     INSERT INTO tracks (id, path, title, title_version, duration, track_number, disc_number,
       isrc, chromaprint_fingerprint, bitrate, format, sample_rate, bit_depth, channels,
       explicit_content, preview_url, composer, genre, year, original_year, lyrics,
-      explicit_lyrics, has_lyrics, bpm, gain, source, source_url, added_date, modified_date)
-    VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+      explicit_lyrics, has_lyrics, bpm, gain, source, source_url, added_date, modified_date, sort_key)
+    VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
   `, track.ID, track.Path, track.Title, track.TitleVersion, track.Metadata.Duration, track.Metadata.TrackNumber, track.Metadata.DiscNumber,
 		track.ISRC, track.ChromaprintFingerprint, track.Bitrate, track.Format, track.SampleRate, track.BitDepth, track.Channels,
 		track.ExplicitContent, track.PreviewURL, track.Metadata.Composer, track.Metadata.Genre, track.Metadata.Year,
 		track.Metadata.OriginalYear, track.Metadata.Lyrics, track.Metadata.ExplicitLyrics, track.HasLyrics, track.Metadata.BPM, track.Metadata.Gain,
-		track.MetadataSource.Source, track.MetadataSource.MetadataSourceURL, track.AddedDate.Format(time.RFC3339), track.ModifiedDate.Format(time.RFC3339))
+		track.MetadataSource.Source, track.MetadataSource.MetadataSourceURL, track.AddedDate.Format(time.RFC3339), track.ModifiedDate.Format(time.RFC3339),
+		d.sortKeys.Key(track.Title))
 	if err != nil {
 		return err
 	}
@@ -312,6 +659,14 @@ func (d *SqliteLibrary) AddTrack(ctx context.Context, track *music.Track) error
 		}
 	}
 
+	albumIDs, artistIDs, err := rollupTargets(ctx, tx, track.ID)
+	if err != nil {
+		return err
+	}
+	if err := refreshRollups(ctx, tx, albumIDs, artistIDs); err != nil {
+		return err
+	}
+
 	return tx.Commit()
 }
 
@@ -329,15 +684,23 @@ func (d *SqliteLibrary) UpdateAlbum(ctx context.Context, album *music.Album) err
 	}
 	defer tx.Rollback()
 
+	if err := d.updateAlbum(ctx, tx, album); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// updateAlbum writes an album with its artists and attributes within tx.
+func (d *SqliteLibrary) updateAlbum(ctx context.Context, tx *sql.Tx, album *music.Album) error {
 	// Update album
-	_, err = tx.ExecContext(ctx, `
+	_, err := tx.ExecContext(ctx, `
 		UPDATE albums
 		SET title = ?, type = ?, release_date = ?, release_group_id = ?,
-			label = ?, catalog_number = ?, country = ?, status = ?, barcode = ?, modified_date = ?
+			label = ?, catalog_number = ?, country = ?, status = ?, barcode = ?, modified_date = ?, sort_key = ?
 		WHERE id = ?
 	`, album.Title, string(album.Type), album.ReleaseDate.Format(time.RFC3339),
 		album.ReleaseGroupID, album.Label, album.CatalogNumber,
-		album.Country, album.Status, album.Barcode, album.ModifiedDate.Format(time.RFC3339), album.ID)
+		album.Country, album.Status, album.Barcode, album.ModifiedDate.Format(time.RFC3339), d.sortKeys.Key(album.Title), album.ID)
 	if err != nil {
 		return err
 	}
@@ -375,19 +738,19 @@ func (d *SqliteLibrary) UpdateAlbum(ctx context.Context, album *music.Album) err
 		}
 	}
 
-	return tx.Commit()
+	return nil
 }
 
 // DeleteAlbum deletes an album from the database and all its associated tracks.
 func (d *SqliteLibrary) DeleteAlbum(ctx context.Context, id string) error {
 	slog.Debug("DeleteAlbum called", "albumID", id)
 
-	// First, find all tracks associated with this album
+	// First, find all tracks whose primary album is this one. Tracks that only appear on it are kept.
 	rows, err := d.db.QueryContext(ctx, `
 		SELECT t.id
 		FROM tracks t
 		INNER JOIN track_albums ta ON t.id = ta.track_id
-		WHERE ta.album_id = ?
+		WHERE ta.album_id = ? AND ta.is_primary = 1
 	`, id)
 	if err != nil {
 		return err
@@ -409,6 +772,11 @@ func (d *SqliteLibrary) DeleteAlbum(ctx context.Context, id string) error {
 	}
 	defer tx.Rollback()
 
+	albumIDs, artistIDs, err := rollupTargets(ctx, tx, trackIDs...)
+	if err != nil {
+		return err
+	}
+
 	// Delete all tracks associated with this album
 	for _, trackID := range trackIDs {
 		// Delete track attributes
@@ -436,6 +804,12 @@ func (d *SqliteLibrary) DeleteAlbum(ctx context.Context, id string) error {
 		}
 	}
 
+	// Delete the appearances of other tracks on this album
+	_, err = tx.ExecContext(ctx, `DELETE FROM track_albums WHERE album_id = ?`, id)
+	if err != nil {
+		return err
+	}
+
 	// Delete album attributes
 	_, err = tx.ExecContext(ctx, `DELETE FROM album_attributes WHERE album_id = ?`, id)
 	if err != nil {
@@ -454,6 +828,63 @@ func (d *SqliteLibrary) DeleteAlbum(ctx context.Context, id string) error {
 		return err
 	}
 
+	if err := refreshRollups(ctx, tx, albumIDs, artistIDs); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// MergeAlbum updates keep and the tracks moved onto it, moves the remaining appearances of the
+// duplicate album onto keep and deletes the duplicate, all in one transaction.
+func (d *SqliteLibrary) MergeAlbum(ctx context.Context, keep *music.Album, tracks []*music.Track, duplicateID string) error {
+	if err := keep.Validate(); err != nil {
+		return err
+	}
+	for _, track := range tracks {
+		if err := track.Validate(); err != nil {
+			return err
+		}
+	}
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := d.updateAlbum(ctx, tx, keep); err != nil {
+		return err
+	}
+	for _, track := range tracks {
+		if err := d.updateTrack(ctx, tx, track); err != nil {
+			return fmt.Errorf("failed to update track %s: %w", track.ID, err)
+		}
+	}
+
+	// Only appearances are left on the duplicate, a track already on keep keeps its link
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO track_albums (track_id, album_id, is_primary)
+		SELECT track_id, ?, 0 FROM track_albums WHERE album_id = ?
+		ON CONFLICT (track_id, album_id) DO NOTHING
+	`, keep.ID, duplicateID)
+	if err != nil {
+		return err
+	}
+	for _, stmt := range []string{
+		`DELETE FROM track_albums WHERE album_id = ?`,
+		`DELETE FROM album_attributes WHERE album_id = ?`,
+		`DELETE FROM album_artists WHERE album_id = ?`,
+		`DELETE FROM albums WHERE id = ?`,
+	} {
+		if _, err := tx.ExecContext(ctx, stmt, duplicateID); err != nil {
+			return err
+		}
+	}
+
+	if err := refreshRollups(ctx, tx, []string{keep.ID}, nil); err != nil {
+		return err
+	}
 	return tx.Commit()
 }
 
@@ -542,9 +973,9 @@ func (d *SqliteLibrary) GetTrack(ctx context.Context, id string) (*music.Track,
 		track.Artists = append(track.Artists, music.ArtistRole{Artist: &artist, Role: role})
 	}
 
-	// Get track album
+	// Get track primary album
 	var albumID string
-	err = tx.QueryRowContext(ctx, `SELECT album_id FROM track_albums WHERE track_id = ?`, id).Scan(&albumID)
+	err = tx.QueryRowContext(ctx, `SELECT album_id FROM track_albums WHERE track_id = ? AND is_primary = 1`, id).Scan(&albumID)
 	if err == nil {
 		album, err := d.GetAlbum(ctx, albumID)
 		if err != nil {
@@ -815,6 +1246,347 @@ func (d *SqliteLibrary) ClearStoredMetrics(ctx context.Context) error {
 	return err
 }
 
+// goalConditions maps goal criteria to the per-track condition used by the completeness metrics.
+var goalConditions = map[string]string{
+	metrics.GoalTagged:   "t.genre IS NOT NULL AND t.genre != '' AND t.year > 0",
+	metrics.GoalLyrics:   "t.lyrics IS NOT NULL AND t.lyrics != ''",
+	metrics.GoalAcoustID: "EXISTS (SELECT 1 FROM track_attributes a WHERE a.track_id = t.id AND a.key = 'acoustid' AND a.value != '')",
+	metrics.GoalISRC:     "t.isrc IS NOT NULL AND t.isrc != ''",
+}
+
+// AddGoal stores a library goal.
+func (d *SqliteLibrary) AddGoal(ctx context.Context, goal *metrics.Goal) error {
+	_, err := d.db.ExecContext(ctx, `
+		INSERT INTO goals (id, criterion, decade, genre, playlist_id, target, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, goal.ID, goal.Criterion, goal.Decade, goal.Genre, goal.PlaylistID, goal.Target, goal.CreatedAt.Format(time.RFC3339))
+	return err
+}
+
+// GetGoals returns all stored goals, oldest first.
+func (d *SqliteLibrary) GetGoals(ctx context.Context) ([]*metrics.Goal, error) {
+	rows, err := d.db.QueryContext(ctx, `
+		SELECT id, criterion, decade, genre, playlist_id, target, created_at
+		FROM goals
+		ORDER BY created_at
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	goals := []*metrics.Goal{}
+	for rows.Next() {
+		g := &metrics.Goal{}
+		var createdAt string
+		if err := rows.Scan(&g.ID, &g.Criterion, &g.Decade, &g.Genre, &g.PlaylistID, &g.Target, &createdAt); err != nil {
+			return nil, err
+		}
+		g.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+		goals = append(goals, g)
+	}
+	return goals, rows.Err()
+}
+
+// DeleteGoal removes a goal.
+func (d *SqliteLibrary) DeleteGoal(ctx context.Context, id string) error {
+	_, err := d.db.ExecContext(ctx, "DELETE FROM goals WHERE id = ?", id)
+	return err
+}
+
+// GetGoalProgress counts the tracks in the goal's scope and how many of them meet its criterion.
+func (d *SqliteLibrary) GetGoalProgress(ctx context.Context, goal *metrics.Goal) (int, int, error) {
+	condition, ok := goalConditions[goal.Criterion]
+	if !ok {
+		return 0, 0, fmt.Errorf("unknown goal criterion %q", goal.Criterion)
+	}
+
+	scope := []string{"1 = 1"}
+	args := []any{}
+	if goal.Decade > 0 {
+		scope = append(scope, "t.year BETWEEN ? AND ?")
+		args = append(args, goal.Decade, goal.Decade+9)
+	}
+	if goal.Genre != "" {
+		scope = append(scope, "t.genre = ?")
+		args = append(args, goal.Genre)
+	}
+	if goal.PlaylistID != "" {
+		scope = append(scope, "EXISTS (SELECT 1 FROM playlist_tracks pt WHERE pt.track_id = t.id AND pt.playlist_id = ?)")
+		args = append(args, goal.PlaylistID)
+	}
+
+	var done, total int
+	err := d.db.QueryRowContext(ctx, fmt.Sprintf(`
+		SELECT COUNT(*), COALESCE(SUM(CASE WHEN %s THEN 1 ELSE 0 END), 0)
+		FROM tracks t
+		WHERE %s
+	`, condition, strings.Join(scope, " AND ")), args...).Scan(&total, &done)
+	return done, total, err
+}
+
+// RecordDiskUsage stores the disk usage sample of a day, replacing an earlier one of the same day.
+func (d *SqliteLibrary) RecordDiskUsage(ctx context.Context, usage *metrics.DiskUsage) error {
+	_, err := d.db.ExecContext(ctx, `
+		INSERT OR REPLACE INTO disk_usage (day, library_bytes, free_bytes, total_bytes)
+		VALUES (?, ?, ?, ?)
+	`, usage.Day.Format("2006-01-02"), usage.LibraryBytes, usage.FreeBytes, usage.TotalBytes)
+	return err
+}
+
+// GetDiskUsage returns the disk usage samples taken since the given time, oldest first.
+func (d *SqliteLibrary) GetDiskUsage(ctx context.Context, since time.Time) ([]metrics.DiskUsage, error) {
+	rows, err := d.db.QueryContext(ctx, `
+		SELECT day, library_bytes, free_bytes, total_bytes
+		FROM disk_usage
+		WHERE day >= ?
+		ORDER BY day
+	`, since.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var usage []metrics.DiskUsage
+	for rows.Next() {
+		var u metrics.DiskUsage
+		var day string
+		if err := rows.Scan(&day, &u.LibraryBytes, &u.FreeBytes, &u.TotalBytes); err != nil {
+			return nil, err
+		}
+		u.Day, _ = time.ParseInLocation("2006-01-02", day, time.Local)
+		usage = append(usage, u)
+	}
+	return usage, rows.Err()
+}
+
+// GetVerification returns the last verification of a track, nil when it was never verified.
+func (d *SqliteLibrary) GetVerification(ctx context.Context, trackID string) (*verification.Verification, error) {
+	v := &verification.Verification{TrackID: trackID}
+	var verifiedAt string
+	var errMsg, hash sql.NullString
+	var size, modTime sql.NullInt64
+	err := d.db.QueryRowContext(ctx, `
+		SELECT verified_at, status, error, hash, size, mod_time
+		FROM track_verifications
+		WHERE track_id = ?
+	`, trackID).Scan(&verifiedAt, &v.Status, &errMsg, &hash, &size, &modTime)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	v.VerifiedAt, _ = time.Parse(time.RFC3339, verifiedAt)
+	v.Error, v.Hash, v.Size = errMsg.String, hash.String, size.Int64
+	if modTime.Valid {
+		v.ModTime = time.Unix(0, modTime.Int64)
+	}
+	return v, nil
+}
+
+// SaveVerification stores the verification of a track, replacing the previous one.
+// verified_at is stored as UTC RFC 3339 so it sorts as text.
+func (d *SqliteLibrary) SaveVerification(ctx context.Context, v *verification.Verification) error {
+	var modTime sql.NullInt64
+	if !v.ModTime.IsZero() {
+		modTime = sql.NullInt64{Int64: v.ModTime.UnixNano(), Valid: true}
+	}
+	_, err := d.db.ExecContext(ctx, `
+		INSERT OR REPLACE INTO track_verifications (track_id, verified_at, status, error, hash, size, mod_time)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, v.TrackID, v.VerifiedAt.UTC().Format(time.RFC3339), v.Status, v.Error, v.Hash, v.Size, modTime)
+	return err
+}
+
+// GetUnverifiedTrackIDs returns up to limit tracks never verified or last verified before the
+// given time, the never verified first and then the longest unverified. A limit of 0 returns
+// all of them.
+func (d *SqliteLibrary) GetUnverifiedTrackIDs(ctx context.Context, before time.Time, limit int) ([]string, error) {
+	if limit <= 0 {
+		limit = -1
+	}
+	rows, err := d.db.QueryContext(ctx, `
+		SELECT t.id
+		FROM tracks t
+		LEFT JOIN track_verifications tv ON tv.track_id = t.id
+		WHERE tv.verified_at IS NULL OR tv.verified_at < ?
+		ORDER BY COALESCE(tv.verified_at, ''), t.id
+		LIMIT ?
+	`, before.UTC().Format(time.RFC3339), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// GetVerificationSummary counts the tracks by how recently and how well they were verified,
+// tracks last verified before the given time count as due.
+func (d *SqliteLibrary) GetVerificationSummary(ctx context.Context, before time.Time) (*verification.Summary, error) {
+	summary := &verification.Summary{}
+	var oldest, latest sql.NullString
+	err := d.db.QueryRowContext(ctx, `
+		SELECT
+			COUNT(*),
+			COUNT(tv.track_id),
+			COALESCE(SUM(CASE WHEN tv.verified_at IS NULL OR tv.verified_at < ? THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN tv.status != ? THEN 1 ELSE 0 END), 0),
+			MIN(tv.verified_at),
+			MAX(tv.verified_at)
+		FROM tracks t
+		LEFT JOIN track_verifications tv ON tv.track_id = t.id
+	`, before.UTC().Format(time.RFC3339), verification.StatusOK).Scan(&summary.Tracks, &summary.Verified, &summary.Due, &summary.Problems, &oldest, &latest)
+	if err != nil {
+		return nil, err
+	}
+	summary.Oldest, _ = time.Parse(time.RFC3339, oldest.String)
+	summary.Latest, _ = time.Parse(time.RFC3339, latest.String)
+	return summary, nil
+}
+
+// RecordPlay stores a streamed track. played_at is stored as UTC RFC 3339 so it sorts as text.
+func (d *SqliteLibrary) RecordPlay(ctx context.Context, play streaming.Play) error {
+	_, err := d.db.ExecContext(ctx, `
+		INSERT INTO plays (user, track_id, title, artist, album, duration, played_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, play.User, play.TrackID, play.Title, play.Artist, play.Album, play.Duration, play.PlayedAt.UTC().Format(time.RFC3339))
+	return err
+}
+
+// GetPlayCounts returns how many times each of the tracks was streamed, by any user.
+func (d *SqliteLibrary) GetPlayCounts(ctx context.Context, trackIDs []string) (map[string]int, error) {
+	counts := map[string]int{}
+	if len(trackIDs) == 0 {
+		return counts, nil
+	}
+	args := make([]any, len(trackIDs))
+	for i, id := range trackIDs {
+		args[i] = id
+	}
+	rows, err := d.db.QueryContext(ctx, `
+		SELECT track_id, COUNT(*) FROM plays
+		WHERE track_id IN (?`+strings.Repeat(", ?", len(trackIDs)-1)+`)
+		GROUP BY track_id
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id string
+		var n int
+		if err := rows.Scan(&id, &n); err != nil {
+			return nil, err
+		}
+		counts[id] = n
+	}
+	return counts, rows.Err()
+}
+
+// GetListeningStats returns the plays of a user since the given time, with their top artists
+// and tracks, up to limit of each.
+func (d *SqliteLibrary) GetListeningStats(ctx context.Context, user string, since time.Time, limit int) (*streaming.ListeningStats, error) {
+	from := since.UTC().Format(time.RFC3339)
+	stats := &streaming.ListeningStats{User: user, Since: since, TopArtists: []streaming.PlayCount{}, TopTracks: []streaming.PlayCount{}}
+	err := d.db.QueryRowContext(ctx, `
+		SELECT COUNT(*), COALESCE(SUM(duration), 0) FROM plays WHERE user = ? AND played_at >= ?
+	`, user, from).Scan(&stats.Plays, &stats.Seconds)
+	if err != nil {
+		return nil, err
+	}
+	top := func(query string, withArtist bool) ([]streaming.PlayCount, error) {
+		rows, err := d.db.QueryContext(ctx, query, user, from, limit)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+		counts := []streaming.PlayCount{}
+		for rows.Next() {
+			var c streaming.PlayCount
+			dest := []any{&c.Name, &c.Plays, &c.Seconds}
+			if withArtist {
+				dest = append(dest, &c.Artist)
+			}
+			if err := rows.Scan(dest...); err != nil {
+				return nil, err
+			}
+			counts = append(counts, c)
+		}
+		return counts, rows.Err()
+	}
+	if stats.TopArtists, err = top(`
+		SELECT artist, COUNT(*) AS n, SUM(duration) FROM plays
+		WHERE user = ? AND played_at >= ? AND artist != ''
+		GROUP BY artist ORDER BY n DESC, artist LIMIT ?
+	`, false); err != nil {
+		return nil, err
+	}
+	if stats.TopTracks, err = top(`
+		SELECT title, COUNT(*) AS n, SUM(duration), artist FROM plays
+		WHERE user = ? AND played_at >= ?
+		GROUP BY track_id ORDER BY n DESC, title LIMIT ?
+	`, true); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// GetListeners returns the users that played something, most plays first.
+func (d *SqliteLibrary) GetListeners(ctx context.Context) ([]streaming.Listener, error) {
+	rows, err := d.db.QueryContext(ctx, `
+		SELECT user, COUNT(*) AS n, MAX(played_at) FROM plays GROUP BY user ORDER BY n DESC, user
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	listeners := []streaming.Listener{}
+	for rows.Next() {
+		var l streaming.Listener
+		var last string
+		if err := rows.Scan(&l.User, &l.Plays, &last); err != nil {
+			return nil, err
+		}
+		l.LastPlayed, _ = time.Parse(time.RFC3339, last)
+		listeners = append(listeners, l)
+	}
+	return listeners, rows.Err()
+}
+
+// GetPlays returns the plays since the given time, oldest first, of every user when user is empty.
+func (d *SqliteLibrary) GetPlays(ctx context.Context, user string, since time.Time) ([]streaming.Play, error) {
+	rows, err := d.db.QueryContext(ctx, `
+		SELECT user, track_id, title, artist, album, duration, played_at FROM plays
+		WHERE (? = '' OR user = ?) AND played_at >= ?
+		ORDER BY played_at, id
+	`, user, user, since.UTC().Format(time.RFC3339))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	plays := []streaming.Play{}
+	for rows.Next() {
+		var p streaming.Play
+		var playedAt string
+		if err := rows.Scan(&p.User, &p.TrackID, &p.Title, &p.Artist, &p.Album, &p.Duration, &playedAt); err != nil {
+			return nil, err
+		}
+		p.PlayedAt, _ = time.Parse(time.RFC3339, playedAt)
+		plays = append(plays, p)
+	}
+	return plays, rows.Err()
+}
+
 // GetGenres returns all distinct non-empty genres in the library, sorted alphabetically.
 func (d *SqliteLibrary) GetGenres(ctx context.Context) ([]string, error) {
 	rows, err := d.db.QueryContext(ctx, `
@@ -852,19 +1624,34 @@ func (d *SqliteLibrary) UpdateTrack(ctx context.Context, track *music.Track) err
 	}
 	defer tx.Rollback()
 
+	if err := d.updateTrack(ctx, tx, track); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// updateTrack writes a track with its artists, primary album and attributes within tx, and
+// refreshes the rollups of the albums and artists it's moved between.
+func (d *SqliteLibrary) updateTrack(ctx context.Context, tx *sql.Tx, track *music.Track) error {
 	// Update track
-	_, err = tx.ExecContext(ctx, `
+	_, err := tx.ExecContext(ctx, `
     UPDATE tracks
     SET path = ?, title = ?, title_version = ?, duration = ?, track_number = ?, disc_number = ?,
       isrc = ?, bitrate = ?, format = ?, chromaprint_fingerprint = ?, sample_rate = ?, bit_depth = ?, channels = ?,
       explicit_content = ?, preview_url = ?, composer = ?, genre = ?, year = ?,
-      original_year = ?, lyrics = ?, explicit_lyrics = ?, has_lyrics = ?, bpm = ?, gain = ?, source = ?, source_url = ?, modified_date = ?
+      original_year = ?, lyrics = ?, explicit_lyrics = ?, has_lyrics = ?, bpm = ?, gain = ?, source = ?, source_url = ?, modified_date = ?, sort_key = ?
     WHERE id = ?
   `, track.Path, track.Title, track.TitleVersion, track.Metadata.Duration, track.Metadata.TrackNumber, track.Metadata.DiscNumber,
 		track.ISRC, track.Bitrate, track.Format, track.ChromaprintFingerprint, track.SampleRate, track.BitDepth, track.Channels,
 		track.ExplicitContent, track.PreviewURL, track.Metadata.Composer, track.Metadata.Genre, track.Metadata.Year,
 		track.Metadata.OriginalYear, track.Metadata.Lyrics, track.Metadata.ExplicitLyrics, track.HasLyrics, track.Metadata.BPM, track.Metadata.Gain,
-		track.MetadataSource.Source, track.MetadataSource.MetadataSourceURL, track.ModifiedDate.Format(time.RFC3339), track.ID)
+		track.MetadataSource.Source, track.MetadataSource.MetadataSourceURL, track.ModifiedDate.Format(time.RFC3339), d.sortKeys.Key(track.Title), track.ID)
+	if err != nil {
+		return err
+	}
+
+	// Rollups of the albums and artists the track is moved away from need refreshing too
+	oldAlbumIDs, oldArtistIDs, err := rollupTargets(ctx, tx, track.ID)
 	if err != nil {
 		return err
 	}
@@ -886,16 +1673,21 @@ func (d *SqliteLibrary) UpdateTrack(ctx context.Context, track *music.Track) err
 		}
 	}
 
-	// Update track-album relationship
-	_, err = tx.ExecContext(ctx, `DELETE FROM track_albums WHERE track_id = ?`, track.ID)
+	// Update the primary track-album relationship, appearances on other albums are kept.
+	// An appearance on the new primary album is replaced by the primary link.
+	newAlbumID := ""
+	if track.Album != nil {
+		newAlbumID = track.Album.ID
+	}
+	_, err = tx.ExecContext(ctx, `DELETE FROM track_albums WHERE track_id = ? AND (is_primary = 1 OR album_id = ?)`, track.ID, newAlbumID)
 	if err != nil {
 		return err
 	}
 
 	if track.Album != nil {
 		_, err = tx.ExecContext(ctx, `
-			INSERT INTO track_albums (track_id, album_id)
-			VALUES (?, ?)
+			INSERT INTO track_albums (track_id, album_id, is_primary)
+			VALUES (?, ?, 1)
 		`, track.ID, track.Album.ID)
 		if err != nil {
 			return err
@@ -918,7 +1710,17 @@ func (d *SqliteLibrary) UpdateTrack(ctx context.Context, track *music.Track) err
 		}
 	}
 
-	return tx.Commit()
+	albumIDs, artistIDs, err := rollupTargets(ctx, tx, track.ID)
+	if err != nil {
+		return err
+	}
+	albumIDs = append(albumIDs, oldAlbumIDs...)
+	artistIDs = append(artistIDs, oldArtistIDs...)
+	if err := refreshRollups(ctx, tx, albumIDs, artistIDs); err != nil {
+		return err
+	}
+
+	return nil
 }
 
 // DeleteTrack deletes a track from the database.
@@ -931,6 +1733,11 @@ func (d *SqliteLibrary) DeleteTrack(ctx context.Context, id string) error {
 	}
 	defer tx.Rollback()
 
+	albumIDs, artistIDs, err := rollupTargets(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
 	// Delete track attributes
 	_, err = tx.ExecContext(ctx, `DELETE FROM track_attributes WHERE track_id = ?`, id)
 	if err != nil {
@@ -949,12 +1756,22 @@ func (d *SqliteLibrary) DeleteTrack(ctx context.Context, id string) error {
 		return err
 	}
 
+	// Delete track verification
+	_, err = tx.ExecContext(ctx, `DELETE FROM track_verifications WHERE track_id = ?`, id)
+	if err != nil {
+		return err
+	}
+
 	// Delete track
 	_, err = tx.ExecContext(ctx, `DELETE FROM tracks WHERE id = ?`, id)
 	if err != nil {
 		return err
 	}
 
+	if err := refreshRollups(ctx, tx, albumIDs, artistIDs); err != nil {
+		return err
+	}
+
 	return tx.Commit()
 }
 
@@ -975,11 +1792,11 @@ func (d *SqliteLibrary) AddAlbum(ctx context.Context, album *music.Album) error
 	// Insert album
 	_, err = tx.ExecContext(ctx, `
 		INSERT INTO albums (id, title, type, release_date, release_group_id,
-			label, catalog_number, country, status, barcode)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			label, catalog_number, country, status, barcode, sort_key)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`, album.ID, album.Title, string(album.Type), album.ReleaseDate.Format(time.RFC3339),
 		album.ReleaseGroupID, album.Label, album.CatalogNumber,
-		album.Country, album.Status, album.Barcode)
+		album.Country, album.Status, album.Barcode, d.sortKeys.Key(album.Title))
 	if err != nil {
 		return err
 	}
@@ -1020,7 +1837,8 @@ func (d *SqliteLibrary) GetAlbum(ctx context.Context, id string) (*music.Album,
 	// Get album basic info
 	row := tx.QueryRowContext(ctx, `
 		SELECT id, title, type, release_date, release_group_id,
-			label, catalog_number, country, status, barcode
+			label, catalog_number, country, status, barcode,
+			COALESCE(track_count, 0), COALESCE(total_duration, 0)
 		FROM albums
 		WHERE id = ?
 	`, id)
@@ -1031,7 +1849,7 @@ func (d *SqliteLibrary) GetAlbum(ctx context.Context, id string) (*music.Album,
 
 	err = row.Scan(&album.ID, &album.Title, &albumType, &releaseDateStr,
 		&album.ReleaseGroupID, &album.Label, &album.CatalogNumber, &album.Country,
-		&album.Status, &album.Barcode)
+		&album.Status, &album.Barcode, &album.TrackCount, &album.TotalDuration)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -1121,9 +1939,9 @@ func (d *SqliteLibrary) AddArtist(ctx context.Context, artist *music.Artist) err
 
 	// Insert artist
 	_, err = tx.ExecContext(ctx, `
-		INSERT INTO artists (id, name, sort_name)
-		VALUES (?, ?, ?)
-	`, artist.ID, artist.Name, artist.SortName)
+		INSERT INTO artists (id, name, sort_name, sort_key)
+		VALUES (?, ?, ?, ?)
+	`, artist.ID, artist.Name, artist.SortName, d.sortKeys.Key(artistSortName(artist)))
 	if err != nil {
 		slog.Error("AddArtist: failed to insert artist", "error", err, "artistID", artist.ID, "artistName", artist.Name)
 		return err
@@ -1159,7 +1977,7 @@ func (d *SqliteLibrary) DeleteArtist(ctx context.Context, id string) error {
 		SELECT DISTINCT t.id
 		FROM tracks t
 		LEFT JOIN track_artists ta ON t.id = ta.track_id
-		LEFT JOIN track_albums tal ON t.id = tal.track_id
+		LEFT JOIN track_albums tal ON t.id = tal.track_id AND tal.is_primary = 1
 		LEFT JOIN album_artists aa ON tal.album_id = aa.album_id
 		WHERE ta.artist_id = ? OR aa.artist_id = ?
 	`, id, id)
@@ -1183,6 +2001,11 @@ func (d *SqliteLibrary) DeleteArtist(ctx context.Context, id string) error {
 	}
 	defer tx.Rollback()
 
+	albumIDs, artistIDs, err := rollupTargets(ctx, tx, trackIDs...)
+	if err != nil {
+		return err
+	}
+
 	// Delete all tracks associated with this artist
 	for _, trackID := range trackIDs {
 		// Delete track attributes
@@ -1234,6 +2057,10 @@ func (d *SqliteLibrary) DeleteArtist(ctx context.Context, id string) error {
 		return err
 	}
 
+	if err := refreshRollups(ctx, tx, albumIDs, artistIDs); err != nil {
+		return err
+	}
+
 	return tx.Commit()
 }
 
@@ -1247,14 +2074,14 @@ func (d *SqliteLibrary) GetArtist(ctx context.Context, id string) (*music.Artist
 
 	// Get artist basic info
 	row := tx.QueryRowContext(ctx, `
-		SELECT id, name, sort_name
+		SELECT id, name, sort_name, COALESCE(track_count, 0), COALESCE(total_duration, 0)
 		FROM artists
 		WHERE id = ?
 	`, id)
 
 	artist := &music.Artist{}
 
-	err = row.Scan(&artist.ID, &artist.Name, &artist.SortName)
+	err = row.Scan(&artist.ID, &artist.Name, &artist.SortName, &artist.TrackCount, &artist.TotalDuration)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -1377,7 +2204,7 @@ func (d *SqliteLibrary) GetTracks(ctx context.Context) ([]*music.Track, error) {
 // GetTracksPaginated gets paginated tracks from the database.
 func (d *SqliteLibrary) GetTracksPaginated(ctx context.Context, limit, offset int) ([]*music.Track, error) {
 	// Get paginated track IDs first
-	rows, err := d.db.QueryContext(ctx, `SELECT id FROM tracks ORDER BY title LIMIT ? OFFSET ?`, limit, offset)
+	rows, err := d.db.QueryContext(ctx, `SELECT id FROM tracks ORDER BY sort_key, title LIMIT ? OFFSET ?`, limit, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -1407,9 +2234,9 @@ func (d *SqliteLibrary) GetTracksPaginated(ctx context.Context, limit, offset in
 	return tracks, nil
 }
 
-// GetTracksFilteredPaginated gets paginated tracks from the database with filtering.
-func (d *SqliteLibrary) GetTracksFilteredPaginated(ctx context.Context, limit, offset int, filter *music.TrackFilter) ([]*music.Track, error) {
-	query := `SELECT DISTINCT t.id FROM tracks t`
+// trackFilterConditions builds the WHERE conditions and arguments of a track filter.
+// Conditions reference the tracks table as "t".
+func trackFilterConditions(filter *music.TrackFilter) ([]string, []interface{}) {
 	args := []interface{}{}
 	conditions := []string{}
 
@@ -1422,8 +2249,9 @@ func (d *SqliteLibrary) GetTracksFilteredPaginated(ctx context.Context, limit, o
 	// Add text search: OR-match across track title, artist name, album title
 	if filter.TextSearch != "" {
 		like := "%" + filter.TextSearch + "%"
-		conditions = append(conditions, `(t.title LIKE ? OR EXISTS (SELECT 1 FROM track_artists ta2 JOIN artists a2 ON ta2.artist_id = a2.id WHERE ta2.track_id = t.id AND a2.name LIKE ?) OR EXISTS (SELECT 1 FROM track_albums tal2 JOIN albums al2 ON tal2.album_id = al2.id WHERE tal2.track_id = t.id AND al2.title LIKE ?))`)
-		args = append(args, like, like, like)
+		conditions = append(conditions, `(t.title LIKE ? OR EXISTS (SELECT 1 FROM track_artists ta2 JOIN artists a2 ON ta2.artist_id = a2.id WHERE ta2.track_id = t.id AND a2.name LIKE ?) OR EXISTS (SELECT 1 FROM track_albums tal2 JOIN albums al2 ON tal2.album_id = al2.id WHERE tal2.track_id = t.id AND al2.title LIKE ?)`+
+			` OR EXISTS (SELECT 1 FROM track_attributes tat2 WHERE tat2.track_id = t.id AND tat2.key = ? AND tat2.value LIKE ?))`)
+		args = append(args, like, like, like, music.NotesAttribute, like)
 	}
 
 	// Add artist filter
@@ -1488,11 +2316,41 @@ func (d *SqliteLibrary) GetTracksFilteredPaginated(ctx context.Context, limit, o
 		args = append(args, filter.AddedBefore)
 	}
 
+	// Family filter
+	if filter.ExcludeExplicit {
+		conditions = append(conditions, "(COALESCE(t.explicit_content, 0) = 0 AND COALESCE(t.explicit_lyrics, 0) = 0)")
+	}
+
+	// Added-by-job filter
+	if filter.AddedByJob != "" {
+		conditions = append(conditions, "EXISTS (SELECT 1 FROM track_attributes ta WHERE ta.track_id = t.id AND ta.key = ? AND ta.value = ?)")
+		args = append(args, music.AddedByJobAttribute, filter.AddedByJob)
+	}
+
+	// Verification filters. verified_at is a UTC RFC3339 timestamp, so a plain date compares
+	// as the start of that day.
+	if filter.NotVerifiedSince != "" {
+		conditions = append(conditions, "NOT EXISTS (SELECT 1 FROM track_verifications tv WHERE tv.track_id = t.id AND tv.verified_at >= ?)")
+		args = append(args, filter.NotVerifiedSince)
+	}
+	if filter.VerificationProblems {
+		conditions = append(conditions, "EXISTS (SELECT 1 FROM track_verifications tv WHERE tv.track_id = t.id AND tv.status != ?)")
+		args = append(args, verification.StatusOK)
+	}
+
+	return conditions, args
+}
+
+// GetTracksFilteredPaginated gets paginated tracks from the database with filtering.
+func (d *SqliteLibrary) GetTracksFilteredPaginated(ctx context.Context, limit, offset int, filter *music.TrackFilter) ([]*music.Track, error) {
+	query := `SELECT DISTINCT t.id FROM tracks t`
+	conditions, args := trackFilterConditions(filter)
+
 	if len(conditions) > 0 {
 		query += " WHERE " + strings.Join(conditions, " AND ")
 	}
 
-	query += " ORDER BY t.title LIMIT ? OFFSET ?"
+	query += " ORDER BY t.sort_key, t.title LIMIT ? OFFSET ?"
 	args = append(args, limit, offset)
 
 	rows, err := d.db.QueryContext(ctx, query, args...)
@@ -1525,86 +2383,73 @@ func (d *SqliteLibrary) GetTracksFilteredPaginated(ctx context.Context, limit, o
 	return tracks, nil
 }
 
-// GetTracksFilteredCount gets the filtered count of tracks in the database.
-func (d *SqliteLibrary) GetTracksFilteredCount(ctx context.Context, filter *music.TrackFilter) (int, error) {
-	query := `SELECT COUNT(DISTINCT t.id) FROM tracks t`
-	args := []interface{}{}
-	conditions := []string{}
-
-	// Add title filter
-	if filter.Title != "" {
-		conditions = append(conditions, "t.title LIKE ?")
-		args = append(args, "%"+filter.Title+"%")
+// GetTracksAfter gets a page of tracks ordered by added date (newest first) and ID using keyset
+// pagination, so deep pages cost the same as the first one.
+func (d *SqliteLibrary) GetTracksAfter(ctx context.Context, limit int, after *music.TrackCursor, filter *music.TrackFilter) ([]*music.Track, *music.TrackCursor, error) {
+	query := `SELECT t.id, COALESCE(t.added_date, '') FROM tracks t`
+	conditions, args := trackFilterConditions(filter)
+	if after != nil {
+		conditions = append(conditions, "(COALESCE(t.added_date, '') < ? OR (COALESCE(t.added_date, '') = ? AND t.id < ?))")
+		args = append(args, after.AddedDate, after.AddedDate, after.ID)
 	}
-
-	// Add text search: OR-match across track title, artist name, album title
-	if filter.TextSearch != "" {
-		like := "%" + filter.TextSearch + "%"
-		conditions = append(conditions, `(t.title LIKE ? OR EXISTS (SELECT 1 FROM track_artists ta2 JOIN artists a2 ON ta2.artist_id = a2.id WHERE ta2.track_id = t.id AND a2.name LIKE ?) OR EXISTS (SELECT 1 FROM track_albums tal2 JOIN albums al2 ON tal2.album_id = al2.id WHERE tal2.track_id = t.id AND al2.title LIKE ?))`)
-		args = append(args, like, like, like)
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
 	}
+	// Fetch one extra row to know whether there is a next page.
+	query += " ORDER BY COALESCE(t.added_date, '') DESC, t.id DESC LIMIT ?"
+	args = append(args, limit+1)
 
-	// Add artist filter
-	if len(filter.ArtistIDs) > 0 {
-		placeholders := strings.Repeat("?,", len(filter.ArtistIDs))
-		placeholders = placeholders[:len(placeholders)-1]
-		conditions = append(conditions, "EXISTS (SELECT 1 FROM track_artists ta WHERE ta.track_id = t.id AND ta.artist_id IN ("+placeholders+"))")
-		for _, id := range filter.ArtistIDs {
-			args = append(args, id)
-		}
+	rows, err := d.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, nil, err
 	}
-
-	// Add album filter
-	if len(filter.AlbumIDs) > 0 {
-		placeholders := strings.Repeat("?,", len(filter.AlbumIDs))
-		placeholders = placeholders[:len(placeholders)-1]
-		conditions = append(conditions, "EXISTS (SELECT 1 FROM track_albums ta WHERE ta.track_id = t.id AND ta.album_id IN ("+placeholders+"))")
-		for _, id := range filter.AlbumIDs {
-			args = append(args, id)
+	var keys []music.TrackCursor
+	for rows.Next() {
+		var key music.TrackCursor
+		if err := rows.Scan(&key.ID, &key.AddedDate); err != nil {
+			rows.Close()
+			return nil, nil, err
 		}
+		keys = append(keys, key)
 	}
-
-	// Genre filter
-	if filter.Genre != "" {
-		conditions = append(conditions, "t.genre = ?")
-		args = append(args, filter.Genre)
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
 	}
 
-	// AcoustID filter
-	if filter.HasAcoustID != nil {
-		if *filter.HasAcoustID {
-			conditions = append(conditions, "EXISTS (SELECT 1 FROM track_attributes ta WHERE ta.track_id = t.id AND ta.key = 'acoustid' AND ta.value != '')")
-		} else {
-			conditions = append(conditions, "NOT EXISTS (SELECT 1 FROM track_attributes ta WHERE ta.track_id = t.id AND ta.key = 'acoustid' AND ta.value != '')")
-		}
+	var next *music.TrackCursor
+	if len(keys) > limit {
+		keys = keys[:limit]
+		next = &keys[limit-1]
 	}
-
-	// Lyrics filter
-	switch filter.LyricsFilter {
-	case "has":
-		conditions = append(conditions, "(t.has_lyrics = 1 AND t.lyrics IS NOT NULL AND t.lyrics != '')")
-	case "empty":
-		conditions = append(conditions, "(t.has_lyrics = 1 AND (t.lyrics IS NULL OR t.lyrics = ''))")
-	case "instrumental":
-		conditions = append(conditions, "t.has_lyrics = 0")
+	tracks := []*music.Track{}
+	for _, key := range keys {
+		track, err := d.GetTrack(ctx, key.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if track == nil {
+			continue
+		}
+		tracks = append(tracks, track)
 	}
+	return tracks, next, nil
+}
 
-	// Lyrics text search
-	if filter.LyricsText != "" {
-		conditions = append(conditions, "t.lyrics LIKE ?")
-		args = append(args, "%"+filter.LyricsText+"%")
+// EstimateTracksCount returns the highest track rowid, an upper bound of the track count that
+// doesn't need a full table scan on large libraries.
+func (d *SqliteLibrary) EstimateTracksCount(ctx context.Context) (int, error) {
+	var count sql.NullInt64
+	if err := d.db.QueryRowContext(ctx, `SELECT MAX(rowid) FROM tracks`).Scan(&count); err != nil {
+		return 0, err
 	}
+	return int(count.Int64), nil
+}
 
-	// Added-date range filter (inclusive). Compares calendar dates so the
-	// RFC3339 timestamps stored in added_date match by day.
-	if filter.AddedAfter != "" {
-		conditions = append(conditions, "date(t.added_date) >= ?")
-		args = append(args, filter.AddedAfter)
-	}
-	if filter.AddedBefore != "" {
-		conditions = append(conditions, "date(t.added_date) <= ?")
-		args = append(args, filter.AddedBefore)
-	}
+// GetTracksFilteredCount gets the filtered count of tracks in the database.
+func (d *SqliteLibrary) GetTracksFilteredCount(ctx context.Context, filter *music.TrackFilter) (int, error) {
+	query := `SELECT COUNT(DISTINCT t.id) FROM tracks t`
+	conditions, args := trackFilterConditions(filter)
 
 	if len(conditions) > 0 {
 		query += " WHERE " + strings.Join(conditions, " AND ")
@@ -1619,19 +2464,21 @@ func (d *SqliteLibrary) GetTracksFilteredCount(ctx context.Context, filter *musi
 }
 
 // SearchAlbums returns albums matching the query with a single JOIN query (no N+1).
-// Only fields needed for search display are populated: ID, Title, ReleaseDate, Artists[].Artist.Name.
+// Only fields needed for search display are populated: ID, Title, ReleaseDate, rollups, Artists[].Artist.Name.
 func (d *SqliteLibrary) SearchAlbums(ctx context.Context, query string, limit, offset int) ([]*music.Album, error) {
 	rows, err := d.db.QueryContext(ctx, `
 		SELECT a.id, a.title, a.release_date,
+		       COALESCE(a.track_count, 0), COALESCE(a.total_duration, 0),
 		       GROUP_CONCAT(ar.name, '|||') as artist_names
 		FROM albums a
 		LEFT JOIN album_artists aa ON a.id = aa.album_id
 		LEFT JOIN artists ar ON aa.artist_id = ar.id
 		WHERE a.title LIKE ?
+		   OR EXISTS (SELECT 1 FROM album_attributes aat WHERE aat.album_id = a.id AND aat.key = ? AND aat.value LIKE ?)
 		GROUP BY a.id
-		ORDER BY a.title
+		ORDER BY a.sort_key, a.title
 		LIMIT ? OFFSET ?
-	`, "%"+query+"%", limit, offset)
+	`, "%"+query+"%", music.NotesAttribute, "%"+query+"%", limit, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -1642,7 +2489,7 @@ func (d *SqliteLibrary) SearchAlbums(ctx context.Context, query string, limit, o
 		album := &music.Album{}
 		var releaseDateStr string
 		var artistNamesNull sql.NullString
-		if err := rows.Scan(&album.ID, &album.Title, &releaseDateStr, &artistNamesNull); err != nil {
+		if err := rows.Scan(&album.ID, &album.Title, &releaseDateStr, &album.TrackCount, &album.TotalDuration, &artistNamesNull); err != nil {
 			return nil, err
 		}
 		album.ReleaseDate, _ = time.Parse(time.RFC3339, releaseDateStr)
@@ -1654,7 +2501,80 @@ func (d *SqliteLibrary) SearchAlbums(ctx context.Context, query string, limit, o
 		}
 		albums = append(albums, album)
 	}
-	return albums, rows.Err()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := d.loadAlbumAttributes(ctx, albums); err != nil {
+		return nil, err
+	}
+	return albums, nil
+}
+
+// loadAlbumAttributes fills the attributes of albums with a single query.
+func (d *SqliteLibrary) loadAlbumAttributes(ctx context.Context, albums []*music.Album) error {
+	if len(albums) == 0 {
+		return nil
+	}
+	byID := make(map[string]*music.Album, len(albums))
+	args := make([]interface{}, 0, len(albums))
+	for _, album := range albums {
+		byID[album.ID] = album
+		args = append(args, album.ID)
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(albums)), ",")
+	rows, err := d.db.QueryContext(ctx, `SELECT album_id, key, value FROM album_attributes WHERE album_id IN (`+placeholders+`)`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var albumID, key string
+		var value sql.NullString
+		if err := rows.Scan(&albumID, &key, &value); err != nil {
+			return err
+		}
+		album := byID[albumID]
+		if album.Attributes == nil {
+			album.Attributes = make(map[string]string)
+		}
+		album.Attributes[key] = value.String
+	}
+	return rows.Err()
+}
+
+// SuggestNames returns artists, albums and tracks whose name starts with prefix. The prefix is
+// matched as a NOCASE range so the lookups stay on the name indexes instead of scanning with LIKE.
+func (d *SqliteLibrary) SuggestNames(ctx context.Context, prefix string, limit int, excludeExplicit bool) ([]music.NameSuggestion, error) {
+	upper := prefix + "\uffff"
+	explicit := ""
+	if excludeExplicit {
+		explicit = " AND COALESCE(explicit_content, 0) = 0 AND COALESCE(explicit_lyrics, 0) = 0"
+	}
+	queries := []struct{ typ, query string }{
+		{"artist", `SELECT id, name FROM artists WHERE name >= ? COLLATE NOCASE AND name < ? COLLATE NOCASE ORDER BY name COLLATE NOCASE LIMIT ?`},
+		{"album", `SELECT id, title FROM albums WHERE title >= ? COLLATE NOCASE AND title < ? COLLATE NOCASE ORDER BY title COLLATE NOCASE LIMIT ?`},
+		{"track", `SELECT id, title FROM tracks WHERE title >= ? COLLATE NOCASE AND title < ? COLLATE NOCASE` + explicit + ` ORDER BY title COLLATE NOCASE LIMIT ?`},
+	}
+	suggestions := []music.NameSuggestion{}
+	for _, q := range queries {
+		rows, err := d.db.QueryContext(ctx, q.query, prefix, upper, limit)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			s := music.NameSuggestion{Type: q.typ}
+			if err := rows.Scan(&s.ID, &s.Name); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			suggestions = append(suggestions, s)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return suggestions, nil
 }
 
 // GetTracksCount gets the total count of tracks in the database.
@@ -1669,7 +2589,7 @@ func (d *SqliteLibrary) GetTracksCount(ctx context.Context) (int, error) {
 
 // GetArtistsPaginated gets paginated artists from the database.
 func (d *SqliteLibrary) GetArtistsPaginated(ctx context.Context, limit, offset int) ([]*music.Artist, error) {
-	rows, err := d.db.QueryContext(ctx, `SELECT id, name FROM artists WHERE name != '' AND name IS NOT NULL ORDER BY name LIMIT ? OFFSET ?`, limit, offset)
+	rows, err := d.db.QueryContext(ctx, `SELECT id, name, COALESCE(track_count, 0), COALESCE(total_duration, 0) FROM artists WHERE name != '' AND name IS NOT NULL ORDER BY sort_key, name LIMIT ? OFFSET ?`, limit, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -1679,7 +2599,7 @@ func (d *SqliteLibrary) GetArtistsPaginated(ctx context.Context, limit, offset i
 
 	for rows.Next() {
 		artist := &music.Artist{}
-		err := rows.Scan(&artist.ID, &artist.Name)
+		err := rows.Scan(&artist.ID, &artist.Name, &artist.TrackCount, &artist.TotalDuration)
 		if err != nil {
 			return nil, err
 		}
@@ -1691,7 +2611,7 @@ func (d *SqliteLibrary) GetArtistsPaginated(ctx context.Context, limit, offset i
 
 // GetArtistsFilteredPaginated gets paginated artists from the database with filtering.
 func (d *SqliteLibrary) GetArtistsFilteredPaginated(ctx context.Context, limit, offset int, nameFilter string) ([]*music.Artist, error) {
-	query := `SELECT id, name FROM artists WHERE name != '' AND name IS NOT NULL`
+	query := `SELECT id, name, COALESCE(track_count, 0), COALESCE(total_duration, 0) FROM artists WHERE name != '' AND name IS NOT NULL`
 	args := []interface{}{}
 
 	// Add name filter
@@ -1700,7 +2620,7 @@ func (d *SqliteLibrary) GetArtistsFilteredPaginated(ctx context.Context, limit,
 		args = append(args, "%"+nameFilter+"%")
 	}
 
-	query += " ORDER BY name LIMIT ? OFFSET ?"
+	query += " ORDER BY sort_key, name LIMIT ? OFFSET ?"
 	args = append(args, limit, offset)
 
 	rows, err := d.db.QueryContext(ctx, query, args...)
@@ -1713,7 +2633,7 @@ func (d *SqliteLibrary) GetArtistsFilteredPaginated(ctx context.Context, limit,
 
 	for rows.Next() {
 		artist := &music.Artist{}
-		err := rows.Scan(&artist.ID, &artist.Name)
+		err := rows.Scan(&artist.ID, &artist.Name, &artist.TrackCount, &artist.TotalDuration)
 		if err != nil {
 			return nil, err
 		}
@@ -1742,6 +2662,53 @@ func (d *SqliteLibrary) GetArtistsFilteredCount(ctx context.Context, nameFilter
 	return count, nil
 }
 
+// SetArtistAttribute sets an attribute of an artist, an empty value removes it.
+func (d *SqliteLibrary) SetArtistAttribute(ctx context.Context, artistID, key, value string) error {
+	var err error
+	if value == "" {
+		_, err = d.db.ExecContext(ctx, `DELETE FROM artist_attributes WHERE artist_id = ? AND key = ?`, artistID, key)
+	} else {
+		_, err = d.db.ExecContext(ctx, `INSERT INTO artist_attributes (artist_id, key, value) VALUES (?, ?, ?)`, artistID, key, value)
+	}
+	return err
+}
+
+// SetAlbumAttribute sets an attribute of an album, an empty value removes it.
+func (d *SqliteLibrary) SetAlbumAttribute(ctx context.Context, albumID, key, value string) error {
+	var err error
+	if value == "" {
+		_, err = d.db.ExecContext(ctx, `DELETE FROM album_attributes WHERE album_id = ? AND key = ?`, albumID, key)
+	} else {
+		_, err = d.db.ExecContext(ctx, `INSERT INTO album_attributes (album_id, key, value) VALUES (?, ?, ?)`, albumID, key, value)
+	}
+	return err
+}
+
+// GetArtistsWithAttribute returns the artists that have the attribute set to value, ordered by name.
+func (d *SqliteLibrary) GetArtistsWithAttribute(ctx context.Context, key, value string) ([]*music.Artist, error) {
+	rows, err := d.db.QueryContext(ctx, `
+		SELECT a.id, a.name, COALESCE(a.track_count, 0), COALESCE(a.total_duration, 0)
+		FROM artists a
+		JOIN artist_attributes aa ON aa.artist_id = a.id
+		WHERE aa.key = ? AND aa.value = ?
+		ORDER BY a.sort_key, a.name
+	`, key, value)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	artists := []*music.Artist{}
+	for rows.Next() {
+		artist := &music.Artist{Attributes: map[string]string{key: value}}
+		if err := rows.Scan(&artist.ID, &artist.Name, &artist.TrackCount, &artist.TotalDuration); err != nil {
+			return nil, err
+		}
+		artists = append(artists, artist)
+	}
+	return artists, rows.Err()
+}
+
 // GetArtistsCount gets the total count of artists in the database.
 func (d *SqliteLibrary) GetArtistsCount(ctx context.Context) (int, error) {
 	var count int
@@ -1755,7 +2722,7 @@ func (d *SqliteLibrary) GetArtistsCount(ctx context.Context) (int, error) {
 // GetAlbumsPaginated gets paginated albums from the database.
 func (d *SqliteLibrary) GetAlbumsPaginated(ctx context.Context, limit, offset int) ([]*music.Album, error) {
 	// Get paginated album IDs first
-	rows, err := d.db.QueryContext(ctx, `SELECT id FROM albums ORDER BY title LIMIT ? OFFSET ?`, limit, offset)
+	rows, err := d.db.QueryContext(ctx, `SELECT id FROM albums ORDER BY sort_key, title LIMIT ? OFFSET ?`, limit, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -1811,7 +2778,7 @@ func (d *SqliteLibrary) GetAlbumsFilteredPaginated(ctx context.Context, limit, o
 		query += " WHERE " + strings.Join(conditions, " AND ")
 	}
 
-	query += " ORDER BY a.title LIMIT ? OFFSET ?"
+	query += " ORDER BY a.sort_key, a.title LIMIT ? OFFSET ?"
 	args = append(args, limit, offset)
 
 	rows, err := d.db.QueryContext(ctx, query, args...)
@@ -1888,6 +2855,134 @@ func (d *SqliteLibrary) GetAlbumsCount(ctx context.Context) (int, error) {
 	return count, nil
 }
 
+func (d *SqliteLibrary) GetAlbumDiscCount(ctx context.Context, albumID string) (int, error) {
+	var count int
+	err := d.db.QueryRowContext(ctx, `
+		SELECT COALESCE(MAX(t.disc_number), 0) FROM tracks t
+		JOIN track_albums ta ON ta.track_id = t.id
+		WHERE ta.album_id = ? AND ta.is_primary = 1`, albumID).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// GetTrackAppearances returns the albums a track appears on besides its primary album.
+func (d *SqliteLibrary) GetTrackAppearances(ctx context.Context, trackID string) ([]*music.Album, error) {
+	rows, err := d.db.QueryContext(ctx, `
+		SELECT ta.album_id FROM track_albums ta
+		JOIN albums a ON a.id = ta.album_id
+		WHERE ta.track_id = ? AND ta.is_primary = 0
+		ORDER BY a.sort_key, a.title COLLATE NOCASE`, trackID)
+	if err != nil {
+		return nil, err
+	}
+	var albumIDs []string
+	for rows.Next() {
+		var albumID string
+		if err := rows.Scan(&albumID); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		albumIDs = append(albumIDs, albumID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	albums := make([]*music.Album, 0, len(albumIDs))
+	for _, albumID := range albumIDs {
+		album, err := d.GetAlbum(ctx, albumID)
+		if err != nil {
+			return nil, err
+		}
+		albums = append(albums, album)
+	}
+	return albums, nil
+}
+
+// AddTrackAppearance links a track to an album besides its primary one. Linking it again is a no-op.
+func (d *SqliteLibrary) AddTrackAppearance(ctx context.Context, trackID, albumID string) error {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO track_albums (track_id, album_id, is_primary)
+		VALUES (?, ?, 0)
+		ON CONFLICT (track_id, album_id) DO NOTHING
+	`, trackID, albumID)
+	if err != nil {
+		return err
+	}
+	if err := refreshRollups(ctx, tx, []string{albumID}, nil); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// RemoveTrackAppearance unlinks a track from an album it appears on. The primary album can't be removed this way.
+func (d *SqliteLibrary) RemoveTrackAppearance(ctx context.Context, trackID, albumID string) error {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var primary bool
+	err = tx.QueryRowContext(ctx, `SELECT is_primary FROM track_albums WHERE track_id = ? AND album_id = ?`, trackID, albumID).Scan(&primary)
+	if err == sql.ErrNoRows {
+		return music.ErrNotAnAppearance
+	}
+	if err != nil {
+		return err
+	}
+	if primary {
+		return music.ErrPrimaryAlbum
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM track_albums WHERE track_id = ? AND album_id = ?`, trackID, albumID); err != nil {
+		return err
+	}
+	if err := refreshRollups(ctx, tx, []string{albumID}, nil); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SetTrackPrimaryAlbum swaps the primary flag of a track to one of the albums it appears on.
+func (d *SqliteLibrary) SetTrackPrimaryAlbum(ctx context.Context, trackID, albumID string) error {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var primary bool
+	err = tx.QueryRowContext(ctx, `SELECT is_primary FROM track_albums WHERE track_id = ? AND album_id = ?`, trackID, albumID).Scan(&primary)
+	if err == sql.ErrNoRows {
+		return music.ErrNotAnAppearance
+	}
+	if err != nil {
+		return err
+	}
+	if primary {
+		return nil
+	}
+	// The partial unique index allows a single primary album, so the old one is cleared first
+	if _, err := tx.ExecContext(ctx, `UPDATE track_albums SET is_primary = 0 WHERE track_id = ? AND is_primary = 1`, trackID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE track_albums SET is_primary = 1 WHERE track_id = ? AND album_id = ?`, trackID, albumID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE tracks SET modified_date = ? WHERE id = ?`, time.Now().Format(time.RFC3339), trackID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
 func (d *SqliteLibrary) GetArtistByName(ctx context.Context, name string) (*music.Artist, error) {
 	row := d.db.QueryRowContext(ctx, `SELECT id, name, sort_name FROM artists WHERE name = ? AND name != '' AND name IS NOT NULL`, name)
 	artist := &music.Artist{}
@@ -2068,9 +3163,9 @@ func (d *SqliteLibrary) FindTrackByMetadata(ctx context.Context, title, artistNa
 		track.Artists = append(track.Artists, music.ArtistRole{Artist: &artist, Role: role})
 	}
 
-	// Get track album
+	// Get track primary album
 	var albumID string
-	err = d.db.QueryRowContext(ctx, `SELECT album_id FROM track_albums WHERE track_id = ?`, track.ID).Scan(&albumID)
+	err = d.db.QueryRowContext(ctx, `SELECT album_id FROM track_albums WHERE track_id = ? AND is_primary = 1`, track.ID).Scan(&albumID)
 	if err == nil {
 		album, err := d.GetAlbum(ctx, albumID)
 		if err != nil {
@@ -18,22 +18,28 @@ type FileOrganizer struct {
 	downloadPath func() string
 	pathParser   importing.PathParser
 	fat32Safe    func() bool
+	windowsSafe  func() bool
 }
 
 // NewFileOrganizer creates a new file organizer implementation.
-// The path and fat32Safe funcs are called at operation time so config changes
+// The path, fat32Safe and windowsSafe funcs are called at operation time so config changes
 // are picked up without restarting.
-func NewFileOrganizer(libraryPath, downloadPath func() string, pathParser importing.PathParser, fat32Safe func() bool) *FileOrganizer {
-	return &FileOrganizer{libraryPath: libraryPath, downloadPath: downloadPath, pathParser: pathParser, fat32Safe: fat32Safe}
+func NewFileOrganizer(libraryPath, downloadPath func() string, pathParser importing.PathParser, fat32Safe, windowsSafe func() bool) *FileOrganizer {
+	return &FileOrganizer{libraryPath: libraryPath, downloadPath: downloadPath, pathParser: pathParser, fat32Safe: fat32Safe, windowsSafe: windowsSafe}
 }
 
-// buildPath renders the raw library path for a track without FAT32 sanitization.
+// buildPath renders the library path for a track without FAT32 sanitization. Windows
+// sanitization only touches the rendered part, so the library path itself is kept.
 func (o *FileOrganizer) buildPath(track *music.Track) (string, error) {
 	renderedPath, err := o.pathParser.RenderPath(track)
 	if err != nil {
 		return "", fmt.Errorf("failed to render path: %w", err)
 	}
-	return filepath.Join(o.libraryPath(), renderedPath+filepath.Ext(track.Path)), nil
+	renderedPath += filepath.Ext(track.Path)
+	if o.windowsSafe() {
+		renderedPath = SanitizeWindowsPath(renderedPath, utf16Len(o.libraryPath()))
+	}
+	return filepath.Join(o.libraryPath(), renderedPath), nil
 }
 
 // GetLibraryPath generates the library path for a track without moving it.
@@ -1,24 +1,32 @@
 package files
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
 	"regexp"
 	"strconv"
 	"strings"
 
 	"github.com/contre95/soulsolid/src/features/config"
+	"github.com/contre95/soulsolid/src/infra/sortkey"
 	"github.com/contre95/soulsolid/src/music"
-	"github.com/gosimple/unidecode"
 )
 
+// DiscCounter reports how many discs an album in the library has.
+type DiscCounter interface {
+	GetAlbumDiscCount(ctx context.Context, albumID string) (int, error)
+}
+
 // TemplatePathParser is an implementation of the PathParser interface that uses templates.
 type TemplatePathParser struct {
 	config *config.Manager
+	discs  DiscCounter
 }
 
 // NewTemplatePathParser creates a new TemplatePathParser.
-func NewTemplatePathParser(cfg *config.Manager) *TemplatePathParser {
-	return &TemplatePathParser{config: cfg}
+func NewTemplatePathParser(cfg *config.Manager, discs DiscCounter) *TemplatePathParser {
+	return &TemplatePathParser{config: cfg, discs: discs}
 }
 
 // RenderPath renders a path for a track based on templates in the config.
@@ -61,13 +69,13 @@ func (p *TemplatePathParser) renderPathTemplate(template string, track *music.Tr
 
 		switch funcName {
 		case "asciify":
-			asciified := unidecode.Unidecode(argValue)
+			asciified := sortkey.Transliterate(p.config.Get().Database.Sorting.Locale, argValue)
 			if len(asciified) == 0 {
 				return ""
 			}
 			return strings.ReplaceAll(asciified, "/", "-")
 		case "artistfolder":
-			asciified := unidecode.Unidecode(argValue)
+			asciified := sortkey.Transliterate(p.config.Get().Database.Sorting.Locale, argValue)
 			if len(asciified) == 0 {
 				return "#/"
 			}
@@ -77,6 +85,12 @@ func (p *TemplatePathParser) renderPathTemplate(template string, track *music.Tr
 			} else {
 				return "#/" + asciified
 			}
+		case "discfolder":
+			// Disc subfolder, only for albums with more than one disc: %discfolder{CD} -> "CD2/"
+			if p.discCount(track) <= 1 {
+				return ""
+			}
+			return strings.ReplaceAll(argValue, "/", "-") + strconv.Itoa(max(track.Metadata.DiscNumber, 1)) + "/"
 		case "if":
 			// Simple if: %if{condition,true_value,false_value}
 			args := strings.Split(argValue, ",")
@@ -136,10 +150,18 @@ func (p *TemplatePathParser) renderValues(template string, track *music.Track) (
 			val = fmt.Sprintf("%02d", track.Metadata.TrackNumber)
 		case "title":
 			val = track.Title
+		case "fulltitle":
+			val = track.FullTitle()
+		case "version":
+			val = track.TitleVersion
 		case "format":
 			val = track.Format
 		case "genre":
 			val = track.Metadata.Genre
+		case "disc":
+			val = strconv.Itoa(max(track.Metadata.DiscNumber, 1))
+		case "disctotal":
+			val = strconv.Itoa(max(p.discCount(track), 1))
 		default:
 			return raw // Unknown placeholder
 		}
@@ -149,3 +171,17 @@ func (p *TemplatePathParser) renderValues(template string, track *music.Track) (
 	})
 	return rendered, nil
 }
+
+// discCount returns the number of discs of the track's album: the largest of the disc total read from
+// the tags, the track's own disc number and the highest disc number already in the library for the album.
+func (p *TemplatePathParser) discCount(track *music.Track) int {
+	count := max(track.Metadata.DiscTotal, track.Metadata.DiscNumber)
+	if p.discs != nil && track.Album != nil && track.Album.ID != "" {
+		n, err := p.discs.GetAlbumDiscCount(context.Background(), track.Album.ID)
+		if err != nil {
+			slog.Warn("Failed to get album disc count", "albumID", track.Album.ID, "error", err)
+		}
+		count = max(count, n)
+	}
+	return count
+}
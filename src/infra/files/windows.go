@@ -0,0 +1,102 @@
+package files
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf16"
+)
+
+const (
+	// maxWindowsPath is MAX_PATH without the terminating NUL. Paths are counted in UTF-16 code units.
+	maxWindowsPath = 259
+	// maxWindowsSegment is the NTFS limit for a single file or folder name.
+	maxWindowsSegment = 255
+	// minWindowsSegment is how short segments may get when a path is cut to fit maxWindowsPath.
+	minWindowsSegment = 16
+)
+
+// windowsReserved matches the device names Windows refuses as file names, with or without an extension.
+var windowsReserved = regexp.MustCompile(`(?i)^(con|prn|aux|nul|com[0-9¹²³]|lpt[0-9¹²³])(\.|$)`)
+
+// SanitizeWindowsPath makes a library-relative path usable on Windows and SMB shares served to Windows.
+// On top of the FAT32 character rules it replaces control characters, renames reserved device
+// names (CON, NUL, COM1, …) and cuts the path so that, joined to a root of rootLen characters, it
+// stays under MAX_PATH. Unlike SanitizeFAT32Path the casing is kept.
+func SanitizeWindowsPath(path string, rootLen int) string {
+	segments := strings.Split(strings.ReplaceAll(path, `\`, "/"), "/")
+	last := len(segments) - 1
+	for i, seg := range segments {
+		segments[i] = sanitizeWindowsSegment(seg, i == last)
+	}
+	fitWindowsPath(segments, maxWindowsPath-rootLen-1)
+	return strings.Join(segments, "/")
+}
+
+func sanitizeWindowsSegment(seg string, isFilename bool) string {
+	if seg == "" {
+		return seg
+	}
+	seg = strings.ToValidUTF8(seg, "")
+	seg = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return '-'
+		}
+		return r
+	}, seg)
+	seg = strings.TrimRight(fat32Replacer.Replace(seg), ". ")
+	if loc := windowsReserved.FindStringSubmatchIndex(seg); loc != nil {
+		seg = seg[:loc[3]] + "_" + seg[loc[3]:]
+	}
+	return truncateSegment(seg, isFilename, maxWindowsSegment)
+}
+
+// fitWindowsPath shortens the longest segments until the joined path is at most budget UTF-16
+// units long. Segments are never cut below minWindowsSegment, so very deep paths may still exceed it.
+func fitWindowsPath(segments []string, budget int) {
+	last := len(segments) - 1
+	for {
+		total := len(segments) - 1 // separators
+		longest := -1
+		for i, seg := range segments {
+			total += utf16Len(seg)
+			if utf16Len(seg) > minWindowsSegment && (longest < 0 || utf16Len(seg) > utf16Len(segments[longest])) {
+				longest = i
+			}
+		}
+		if total <= budget || longest < 0 {
+			return
+		}
+		keep := max(utf16Len(segments[longest])-(total-budget), minWindowsSegment)
+		cut := truncateSegment(segments[longest], longest == last, keep)
+		if cut == segments[longest] {
+			return // only a long extension is left
+		}
+		segments[longest] = cut
+	}
+}
+
+// truncateSegment cuts seg to maxUnits UTF-16 units, keeping the extension of filenames.
+func truncateSegment(seg string, isFilename bool, maxUnits int) string {
+	if utf16Len(seg) <= maxUnits {
+		return seg
+	}
+	ext := ""
+	if isFilename {
+		if i := strings.LastIndex(seg, "."); i > 0 {
+			ext = seg[i:]
+		}
+	}
+	stem := []rune(seg[:len(seg)-len(ext)])
+	for len(stem) > 1 && utf16Len(string(stem))+utf16Len(ext) > maxUnits {
+		stem = stem[:len(stem)-1]
+	}
+	return strings.TrimRight(string(stem), ". ") + ext
+}
+
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		n += utf16.RuneLen(r)
+	}
+	return n
+}
@@ -0,0 +1,65 @@
+package files
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSanitizeWindowsPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		rootLen int
+		want    string
+	}{
+		{"plain path is kept", "Artist/Album/01 - Song.flac", 10, "Artist/Album/01 - Song.flac"},
+		{"casing is kept", "ABBA/Arrival/01 - When I Kissed the Teacher.flac", 10, "ABBA/Arrival/01 - When I Kissed the Teacher.flac"},
+		{"backslashes are separators", `Artist\Album\01 - Song.flac`, 10, "Artist/Album/01 - Song.flac"},
+		{"reserved folder name", "CON/Album/01 - Song.flac", 10, "CON_/Album/01 - Song.flac"},
+		{"reserved name is case insensitive", "Artist/nul/01 - Song.flac", 10, "Artist/nul_/01 - Song.flac"},
+		{"reserved file name with extension", "Artist/Album/com1.flac", 10, "Artist/Album/com1_.flac"},
+		{"reserved name with superscript digit", "Artist/Album/LPT¹.mp3", 10, "Artist/Album/LPT¹_.mp3"},
+		{"reserved name as prefix is allowed", "Console/Auxiliary/Nullify.flac", 10, "Console/Auxiliary/Nullify.flac"},
+		{"trailing dots are trimmed", "Dr. Dre.../Album.../01 - Song.flac", 10, "Dr. Dre/Album/01 - Song.flac"},
+		{"trailing spaces are trimmed", "Artist  /Album /01 - Song.flac ", 10, "Artist/Album/01 - Song.flac"},
+		{"trailing dots and spaces mixed", "Artist. . /Album/01 - Song.flac", 10, "Artist/Album/01 - Song.flac"},
+		{"invalid characters", `Artist/What? "Live": <Part> 1|2*/01 - Song.flac`, 10, "Artist/What- -Live-- -Part- 1-2-/01 - Song.flac"},
+		{"control characters", "Art\tist/Al\x7fbum/01\n - Song.flac", 10, "Art-ist/Al-bum/01- - Song.flac"},
+		{"invalid utf8 is dropped", "Art\xffist/Album/01 - Song.flac", 10, "Artist/Album/01 - Song.flac"},
+		{"long file name keeps its extension", "Artist/Album/" + strings.Repeat("a", 300) + ".flac", 0, "Artist/Album/" + strings.Repeat("a", 240) + ".flac"},
+		{"longest segment is cut to fit the root", strings.Repeat("b", 40) + "/" + strings.Repeat("c", 200) + "/01.flac", 100, strings.Repeat("b", 40) + "/" + strings.Repeat("c", 109) + "/01.flac"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SanitizeWindowsPath(tt.path, tt.rootLen)
+			if got != tt.want {
+				t.Errorf("SanitizeWindowsPath(%q, %d) = %q, want %q", tt.path, tt.rootLen, got, tt.want)
+			}
+			if n := utf16Len(got) + tt.rootLen + 1; n > maxWindowsPath {
+				t.Errorf("SanitizeWindowsPath(%q, %d) is %d units long with its root, over MAX_PATH", tt.path, tt.rootLen, n)
+			}
+		})
+	}
+}
+
+func TestSanitizeWindowsSegmentLength(t *testing.T) {
+	tests := []struct {
+		name       string
+		seg        string
+		isFilename bool
+		want       string
+	}{
+		{"folder is cut to the NTFS limit", strings.Repeat("d", 300), false, strings.Repeat("d", maxWindowsSegment)},
+		{"file keeps its extension", strings.Repeat("f", 300) + ".mp3", true, strings.Repeat("f", maxWindowsSegment-4) + ".mp3"},
+		{"cut counts UTF-16 units", strings.Repeat("𝄞", 200), false, strings.Repeat("𝄞", 127)},
+		{"cut never ends in a dot", strings.Repeat("e", 254) + ".x" + strings.Repeat("y", 10), false, strings.Repeat("e", 254)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := sanitizeWindowsSegment(tt.seg, tt.isFilename)
+			if got != tt.want {
+				t.Errorf("sanitizeWindowsSegment(%q, %v) = %q, want %q", tt.seg, tt.isFilename, got, tt.want)
+			}
+		})
+	}
+}
@@ -101,10 +101,5 @@ func (s *Service) getAudioDuration(filePath string) (int, error) {
 
 // CompareFingerprints compares two fingerprints and returns a similarity score (0.0 to 1.0)
 func (s *Service) CompareFingerprints(fp1, fp2 string) (float64, error) {
-	// For now, use simple string comparison
-	// In a full implementation, this would use proper audio fingerprint comparison
-	if fp1 == fp2 {
-		return 1.0, nil
-	}
-	return 0.0, nil
+	return Similarity(fp1, fp2)
 }
@@ -0,0 +1,150 @@
+package fingerprint
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math/bits"
+	"strings"
+)
+
+const (
+	// maxOffset is how far apart, in fingerprint items (about 0.12 s each), two fingerprints are
+	// aligned when compared, for files with different leading silences.
+	maxOffset = 80
+	// minOverlap is the fewest items two aligned fingerprints must share to be compared.
+	minOverlap = 40
+	// ItemSeconds is the length of audio covered by one fingerprint item.
+	ItemSeconds = 0.1238
+)
+
+// Decode decompresses a fingerprint as printed by fpcalc into its 32-bit items. The format is
+// a base64url header of one algorithm byte and a 24-bit item count, followed by the XOR deltas
+// of the items as 3-bit bit positions, with the positions of 7 and more continued in 5 bits.
+func Decode(fingerprint string) ([]uint32, error) {
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(strings.TrimSpace(fingerprint), "="))
+	if err != nil {
+		return nil, fmt.Errorf("invalid fingerprint encoding: %w", err)
+	}
+	if len(data) < 4 {
+		return nil, fmt.Errorf("fingerprint too short")
+	}
+	count := int(data[1])<<16 | int(data[2])<<8 | int(data[3])
+	body := data[4:]
+
+	// Normal bits: 3-bit values, a 0 ends an item
+	var normal []int
+	for pos, ends := 0, 0; ends < count; pos += 3 {
+		if pos+3 > len(body)*8 {
+			return nil, fmt.Errorf("fingerprint truncated: %d of %d items", ends, count)
+		}
+		value := unpack(body, pos, 3)
+		normal = append(normal, value)
+		if value == 0 {
+			ends++
+		}
+	}
+
+	// Exceptional bits: 5-bit values added to every normal value of 7
+	exceptional := body[(len(normal)*3+7)/8:]
+	next := 0
+	for i, value := range normal {
+		if value != 7 {
+			continue
+		}
+		if (next+1)*5 > len(exceptional)*8 {
+			return nil, fmt.Errorf("fingerprint truncated in exceptional bits")
+		}
+		normal[i] += unpack(exceptional, next*5, 5)
+		next++
+	}
+
+	items := make([]uint32, 0, count)
+	var value uint32
+	lastBit := 0
+	for _, bit := range normal {
+		if bit == 0 {
+			if n := len(items); n > 0 {
+				value ^= items[n-1]
+			}
+			items = append(items, value)
+			value, lastBit = 0, 0
+			continue
+		}
+		lastBit += bit
+		if lastBit > 32 {
+			return nil, fmt.Errorf("invalid fingerprint bit position %d", lastBit)
+		}
+		value |= 1 << (lastBit - 1)
+	}
+	return items, nil
+}
+
+// unpack reads the n-bit little-endian value starting at bit pos of data.
+func unpack(data []byte, pos, n int) int {
+	value := 0
+	for i := range n {
+		bit := pos + i
+		if data[bit/8]&(1<<(bit%8)) != 0 {
+			value |= 1 << i
+		}
+	}
+	return value
+}
+
+// Comparison is how alike two fingerprints are at their best alignment.
+type Comparison struct {
+	Similarity float64 // 1 for identical audio, 0 for unrelated audio
+	BitErrors  float64 // share of differing bits, about 0.5 for unrelated audio
+	Offset     int     // items the second fingerprint is shifted by, positive when its audio starts later
+	Overlap    int     // items compared
+}
+
+// Compare aligns two decoded fingerprints within maxOffset items and reports the alignment with
+// the fewest differing bits. Unrelated audio differs in about half of the bits, so the
+// similarity scales the bit error rate from 0.5 (0) to 0 (1).
+func Compare(a, b []uint32) (Comparison, error) {
+	if min(len(a), len(b)) < minOverlap {
+		return Comparison{}, fmt.Errorf("fingerprints too short to compare")
+	}
+	best := Comparison{BitErrors: 1}
+	for offset := -maxOffset; offset <= maxOffset; offset++ {
+		// a[i] is compared with b[i+offset]
+		start, end := max(0, -offset), min(len(a), len(b)-offset)
+		if end-start < minOverlap {
+			continue
+		}
+		differing := 0
+		for i := start; i < end; i++ {
+			differing += bits.OnesCount32(a[i] ^ b[i+offset])
+		}
+		rate := float64(differing) / float64((end-start)*32)
+		if rate < best.BitErrors {
+			best = Comparison{BitErrors: rate, Offset: offset, Overlap: end - start}
+		}
+	}
+	if best.Overlap == 0 {
+		return Comparison{}, fmt.Errorf("fingerprints too short to compare")
+	}
+	best.Similarity = max(0, 1-2*best.BitErrors)
+	return best, nil
+}
+
+// Similarity decodes and compares two fingerprints as printed by fpcalc.
+func Similarity(fp1, fp2 string) (float64, error) {
+	if fp1 == fp2 {
+		return 1.0, nil
+	}
+	a, err := Decode(fp1)
+	if err != nil {
+		return 0, err
+	}
+	b, err := Decode(fp2)
+	if err != nil {
+		return 0, err
+	}
+	comparison, err := Compare(a, b)
+	if err != nil {
+		return 0, err
+	}
+	return comparison.Similarity, nil
+}
@@ -7,9 +7,11 @@ import (
 	"net/http"
 	"net/url"
 	"os/exec"
+	"sort"
 
 	"github.com/contre95/soulsolid/src/features/config"
 	"github.com/contre95/soulsolid/src/features/metadata"
+	"github.com/contre95/soulsolid/src/infra/fingerprint"
 )
 
 // AcoustIDResponse represents response from AcoustID API
@@ -54,16 +56,60 @@ func NewAcoustIDService(cfg *config.Manager) metadata.ChromaprintAcoustID {
 
 // LookupAcoustID looks up AcoustID using chromaprint
 func (s *AcoustIDAPI) LookupAcoustID(ctx context.Context, chromaprint string, duration int) (string, error) {
+	results, err := s.lookup(ctx, chromaprint, duration)
+	if err != nil {
+		return "", err
+	}
+	if len(results) == 0 {
+		return "", nil // No results found
+	}
+
+	// Return best match (highest score)
+	bestResult := results[0]
+	for _, result := range results {
+		if result.Score > bestResult.Score {
+			bestResult = result
+		}
+	}
+
+	return bestResult.ID, nil
+}
+
+// LookupRecordings looks up the AcoustID matches of a chromaprint with their recordings, best
+// match first
+func (s *AcoustIDAPI) LookupRecordings(ctx context.Context, chromaprint string, duration int) ([]metadata.AcoustIDMatch, error) {
+	results, err := s.lookup(ctx, chromaprint, duration)
+	if err != nil {
+		return nil, err
+	}
+	matches := make([]metadata.AcoustIDMatch, 0, len(results))
+	for _, result := range results {
+		match := metadata.AcoustIDMatch{ID: result.ID, Score: result.Score}
+		for _, recording := range result.Recordings {
+			rec := metadata.AcoustIDRecording{ID: recording.ID, Title: recording.Title, Duration: recording.Duration}
+			for _, artist := range recording.Artists {
+				rec.Artists = append(rec.Artists, artist.Name)
+			}
+			match.Recordings = append(match.Recordings, rec)
+		}
+		matches = append(matches, match)
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Score > matches[j].Score })
+	return matches, nil
+}
+
+// lookup queries the AcoustID API with a chromaprint and its duration
+func (s *AcoustIDAPI) lookup(ctx context.Context, chromaprint string, duration int) ([]AcoustIDResult, error) {
 	cfg := s.config.Get()
 
 	// Check if AcoustID is enabled
 	acoustidProvider, exists := cfg.Metadata.Providers["acoustid"]
 	if !exists || !acoustidProvider.Enabled {
-		return "", fmt.Errorf("AcoustID lookup is disabled in configuration")
+		return nil, fmt.Errorf("AcoustID lookup is disabled in configuration")
 	}
 
 	if acoustidProvider.Secret == nil || *acoustidProvider.Secret == "" {
-		return "", fmt.Errorf("AcoustID secret not configured")
+		return nil, fmt.Errorf("AcoustID secret not configured")
 	}
 
 	// Prepare API request
@@ -79,46 +125,34 @@ func (s *AcoustIDAPI) LookupAcoustID(ctx context.Context, chromaprint string, du
 	// Create HTTP request
 	req, err := http.NewRequestWithContext(ctx, "GET", requestURL, nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to create HTTP request: %w", err)
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
 	}
 
 	// Make HTTP request
 	resp, err := httpClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to query AcoustID API: %w", err)
+		return nil, fmt.Errorf("failed to query AcoustID API: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("AcoustID API returned status: %d", resp.StatusCode)
+		return nil, fmt.Errorf("AcoustID API returned status: %d", resp.StatusCode)
 	}
 
 	// Parse response
 	var response AcoustIDResponse
 	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return "", fmt.Errorf("failed to parse AcoustID response: %w", err)
+		return nil, fmt.Errorf("failed to parse AcoustID response: %w", err)
 	}
 
 	if response.Status != "ok" {
 		if response.Error != nil {
-			return "", fmt.Errorf("AcoustID API error: %s", response.Error.Message)
+			return nil, fmt.Errorf("AcoustID API error: %s", response.Error.Message)
 		}
-		return "", fmt.Errorf("AcoustID API returned status: %s", response.Status)
+		return nil, fmt.Errorf("AcoustID API returned status: %s", response.Status)
 	}
 
-	if len(response.Results) == 0 {
-		return "", nil // No results found
-	}
-
-	// Return best match (highest score)
-	bestResult := response.Results[0]
-	for _, result := range response.Results {
-		if result.Score > bestResult.Score {
-			bestResult = result
-		}
-	}
-
-	return bestResult.ID, nil
+	return response.Results, nil
 }
 
 // GenerateChromaprint generates a chromaprint fingerprint for an audio file and returns the duration
@@ -169,10 +203,5 @@ func (s *AcoustIDAPI) generateFingerprintWithFpcalc(ctx context.Context, filePat
 
 // CompareChromaprints compares two chromaprints and returns a similarity score (0.0 to 1.0)
 func (s *AcoustIDAPI) CompareChromaprints(cp1, cp2 string) (float64, error) {
-	// For now, use simple string comparison
-	// In a full implementation, this would use proper audio fingerprint comparison
-	if cp1 == cp2 {
-		return 1.0, nil
-	}
-	return 0.0, nil
+	return fingerprint.Similarity(cp1, cp2)
 }
@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"github.com/contre95/soulsolid/src/features/metadata"
+	"github.com/contre95/soulsolid/src/features/recommendations"
 	"github.com/contre95/soulsolid/src/music"
 )
 
@@ -39,8 +40,14 @@ type deezerTrack struct {
 }
 
 type deezerArtist struct {
-	ID   int    `json:"id"`
-	Name string `json:"name"`
+	ID           int    `json:"id"`
+	Name         string `json:"name"`
+	Link         string `json:"link"`
+	PictureSmall string `json:"picture_small"`
+}
+
+type deezerArtistList struct {
+	Data []deezerArtist `json:"data"`
 }
 
 type deezerGenre struct {
@@ -59,6 +66,7 @@ type deezerAlbum struct {
 	Title       string       `json:"title"`
 	ReleaseDate string       `json:"release_date"`
 	Genres      deezerGenres `json:"genres"`
+	CoverXL     string       `json:"cover_xl"`
 }
 
 // DeezerProvider implements MetadataProvider for Deezer
@@ -222,7 +230,8 @@ func (p *DeezerProvider) convertDeezerTrackToTrack(deezerTrack deezerTrack, albu
 			TrackNumber: deezerTrack.TrackPosition,
 			DiscNumber:  deezerTrack.DiskNumber,
 		},
-		ISRC: deezerTrack.ISRC,
+		ISRC:      deezerTrack.ISRC,
+		Thumbnail: deezerTrack.Album.CoverXL,
 		MetadataSource: music.MetadataSource{
 			Source:            "deezer",
 			MetadataSourceURL: deezerTrack.Link,
@@ -243,5 +252,46 @@ func (p *DeezerProvider) FetchMetadata(ctx context.Context, fingerprint string)
 	return tracks[0], nil
 }
 
+// RelatedArtists returns the artists Deezer lists as related to the best match for artistName.
+func (p *DeezerProvider) RelatedArtists(ctx context.Context, artistName string, limit int) ([]recommendations.RelatedArtist, error) {
+	var found deezerArtistList
+	searchURL := fmt.Sprintf("https://api.deezer.com/search/artist?q=%s&limit=1", url.QueryEscape(artistName))
+	if err := p.getJSON(ctx, searchURL, &found); err != nil {
+		return nil, fmt.Errorf("failed to search artist: %w", err)
+	}
+	if len(found.Data) == 0 {
+		return []recommendations.RelatedArtist{}, nil
+	}
+
+	var related deezerArtistList
+	relatedURL := fmt.Sprintf("https://api.deezer.com/artist/%d/related?limit=%d", found.Data[0].ID, limit)
+	if err := p.getJSON(ctx, relatedURL, &related); err != nil {
+		return nil, fmt.Errorf("failed to get related artists: %w", err)
+	}
+	artists := make([]recommendations.RelatedArtist, 0, len(related.Data))
+	for _, a := range related.Data {
+		artists = append(artists, recommendations.RelatedArtist{Name: a.Name, URL: a.Link, ImageURL: a.PictureSmall})
+	}
+	return artists, nil
+}
+
+// getJSON performs a GET request against the Deezer API and decodes the JSON body into v.
+func (p *DeezerProvider) getJSON(ctx context.Context, reqURL string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "SoulSolid/1.0")
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Deezer API request failed with status %d", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
 func (p *DeezerProvider) Name() string    { return "deezer" }
 func (p *DeezerProvider) IsEnabled() bool { return p.enabled }
@@ -49,10 +49,23 @@ type discogsReleaseResponse struct {
 	Styles      []string        `json:"styles"`
 	Artists     []discogsArtist `json:"artists"`
 	Tracklist   []discogsTrack  `json:"tracklist"`
+	Images      []discogsImage  `json:"images"`
+	Country     string          `json:"country"`
+	Formats     []discogsFormat `json:"formats"`
 	ResourceURL string          `json:"resource_url"`
 	URI         string          `json:"uri"`
 }
 
+type discogsFormat struct {
+	Name         string   `json:"name"`
+	Descriptions []string `json:"descriptions"` // e.g. "Album", "Promo"
+}
+
+type discogsImage struct {
+	Type string `json:"type"` // "primary" or "secondary"
+	URI  string `json:"uri"`
+}
+
 type discogsArtist struct {
 	Name string `json:"name"`
 }
@@ -224,6 +237,8 @@ func (p *DiscogsProvider) convertDiscogsResultToTrack(result discogsResult) *mus
 		Artists: []music.ArtistRole{
 			{Artist: artist, Role: "main"},
 		},
+		Country: discogsCountryCode(result.Country),
+		Status:  discogsStatus(result.Format),
 	}
 
 	// Create track (using album title as track title for now, since Discogs search returns releases)
@@ -237,6 +252,7 @@ func (p *DiscogsProvider) convertDiscogsResultToTrack(result discogsResult) *mus
 			Year:  year,
 			Genre: genre,
 		},
+		Thumbnail: result.CoverImage,
 		HasLyrics: true,
 	}
 
@@ -266,11 +282,17 @@ func (p *DiscogsProvider) convertDiscogsTrackToTrack(discogsTrack discogsTrack,
 	artist := &music.Artist{Name: artistName}
 
 	// Create album
+	var descriptions []string
+	for _, format := range release.Formats {
+		descriptions = append(descriptions, format.Descriptions...)
+	}
 	album := &music.Album{
 		Title: release.Title,
 		Artists: []music.ArtistRole{
 			{Artist: artist, Role: "main"},
 		},
+		Country: discogsCountryCode(release.Country),
+		Status:  discogsStatus(descriptions),
 	}
 
 	// Determine genre
@@ -299,6 +321,7 @@ func (p *DiscogsProvider) convertDiscogsTrackToTrack(discogsTrack discogsTrack,
 			Genre:       genre,
 			TrackNumber: trackNumber,
 		},
+		Thumbnail: primaryImage(release.Images),
 		HasLyrics: true,
 	}
 
@@ -316,6 +339,32 @@ func (p *DiscogsProvider) convertDiscogsTrackToTrack(discogsTrack discogsTrack,
 	return track
 }
 
+// discogsCountries maps the Discogs country names to the ISO 3166 codes MusicBrainz uses.
+// Regions Discogs lists that have no code are left out.
+var discogsCountries = map[string]string{
+	"US": "US", "UK": "GB", "Europe": "XE", "Worldwide": "XW", "Germany": "DE", "France": "FR",
+	"Japan": "JP", "Canada": "CA", "Australia": "AU", "Netherlands": "NL", "Italy": "IT", "Spain": "ES",
+	"Sweden": "SE", "Belgium": "BE", "Brazil": "BR", "Mexico": "MX", "Argentina": "AR", "Russia": "RU",
+	"Poland": "PL", "Norway": "NO", "Denmark": "DK", "Finland": "FI", "Switzerland": "CH", "Austria": "AT",
+	"Portugal": "PT", "Greece": "GR", "Ireland": "IE", "New Zealand": "NZ", "South Korea": "KR", "Taiwan": "TW",
+}
+
+// discogsCountryCode returns the ISO code of a Discogs country, empty when it's unknown.
+func discogsCountryCode(country string) string {
+	return discogsCountries[country]
+}
+
+// discogsStatus returns the MusicBrainz release status matching the Discogs format descriptions.
+// Discogs only flags promos, every other release is treated as official.
+func discogsStatus(descriptions []string) string {
+	for _, d := range descriptions {
+		if strings.EqualFold(d, "Promo") {
+			return "Promotion"
+		}
+	}
+	return "Official"
+}
+
 // fetchReleaseDetails fetches the full release data from Discogs API
 func (p *DiscogsProvider) fetchReleaseDetails(ctx context.Context, resourceURL string) (*discogsReleaseResponse, error) {
 	// Create request
@@ -363,6 +412,19 @@ func (p *DiscogsProvider) fetchReleaseDetails(ctx context.Context, resourceURL s
 	return &releaseResp, nil
 }
 
+// primaryImage returns the release's primary image, falling back to the first one.
+func primaryImage(images []discogsImage) string {
+	for _, img := range images {
+		if img.Type == "primary" {
+			return img.URI
+		}
+	}
+	if len(images) > 0 {
+		return images[0].URI
+	}
+	return ""
+}
+
 func (p *DiscogsProvider) FetchMetadata(ctx context.Context, fingerprint string) (*music.Track, error) {
 	// TODO: Implement full Discogs API integration
 	// For now, return realistic placeholder data that demonstrates the functionality
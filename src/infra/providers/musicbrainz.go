@@ -40,10 +40,12 @@ type mbArtist struct {
 }
 
 type mbRelease struct {
-	ID    string     `json:"id"`
-	Title string     `json:"title"`
-	Date  string     `json:"date"`
-	Media []mbMedium `json:"media"`
+	ID      string     `json:"id"`
+	Title   string     `json:"title"`
+	Date    string     `json:"date"`
+	Country string     `json:"country"`
+	Status  string     `json:"status"` // "Official", "Promotion", "Bootleg" or "Pseudo-Release"
+	Media   []mbMedium `json:"media"`
 }
 
 type mbMedium struct {
@@ -56,14 +58,24 @@ type mbTrack struct {
 	Length int    `json:"length"`
 }
 
+// musicBrainzURL is the public MusicBrainz server used when no mirror is configured
+const musicBrainzURL = "https://musicbrainz.org"
+
 // MusicBrainzProvider implements MetadataProvider for MusicBrainz
 type MusicBrainzProvider struct {
 	enabled bool
+	baseURL string
 }
 
-// NewMusicBrainzProvider creates a new MusicBrainz provider
-func NewMusicBrainzProvider(enabled bool) *MusicBrainzProvider {
-	return &MusicBrainzProvider{enabled: enabled}
+// NewMusicBrainzProvider creates a new MusicBrainz provider. baseURL points the provider at a
+// MusicBrainz server exposing the /ws/2 API, e.g. a local mirror for instances without internet
+// access. An empty baseURL uses the public musicbrainz.org server.
+func NewMusicBrainzProvider(enabled bool, baseURL string) *MusicBrainzProvider {
+	baseURL = strings.TrimRight(strings.TrimSpace(baseURL), "/")
+	if baseURL == "" {
+		baseURL = musicBrainzURL
+	}
+	return &MusicBrainzProvider{enabled: enabled, baseURL: baseURL}
 }
 
 func (p *MusicBrainzProvider) SearchTracks(ctx context.Context, params metadata.SearchParams) ([]*music.Track, error) {
@@ -94,8 +106,7 @@ func (p *MusicBrainzProvider) SearchTracks(ctx context.Context, params metadata.
 	query := strings.Join(queryParts, " AND")
 
 	// Build URL
-	baseURL := "https://musicbrainz.org/ws/2/recording"
-	searchURL := fmt.Sprintf("%s?query=%s&fmt=json&limit=10", baseURL, url.QueryEscape(query))
+	searchURL := fmt.Sprintf("%s/ws/2/recording?query=%s&fmt=json&limit=10", p.baseURL, url.QueryEscape(query))
 
 	// Create request
 	req, err := http.NewRequestWithContext(ctx, "GET", searchURL, nil)
@@ -115,7 +126,7 @@ func (p *MusicBrainzProvider) SearchTracks(ctx context.Context, params metadata.
 
 	// Check status
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("MusicBrainz API request failed with status %d", resp.StatusCode)
+		return nil, fmt.Errorf("MusicBrainz API request to %s failed with status %d", p.baseURL, resp.StatusCode)
 	}
 
 	// Parse response
@@ -127,7 +138,7 @@ func (p *MusicBrainzProvider) SearchTracks(ctx context.Context, params metadata.
 	// Convert to music.Track
 	var tracks []*music.Track
 	for _, recording := range searchResp.Recordings {
-		track := p.convertMBRecordingToTrack(recording)
+		track := p.convertMBRecordingToTrack(recording, params.Release)
 		if track != nil {
 			tracks = append(tracks, track)
 		}
@@ -137,7 +148,7 @@ func (p *MusicBrainzProvider) SearchTracks(ctx context.Context, params metadata.
 }
 
 // convertMBRecordingToTrack converts a MusicBrainz recording to a music.Track
-func (p *MusicBrainzProvider) convertMBRecordingToTrack(recording mbRecording) *music.Track {
+func (p *MusicBrainzProvider) convertMBRecordingToTrack(recording mbRecording, pref metadata.ReleasePreference) *music.Track {
 	// Create artists from artist-credit
 	var artists []music.ArtistRole
 	for _, credit := range recording.ArtistCredit {
@@ -155,12 +166,13 @@ func (p *MusicBrainzProvider) convertMBRecordingToTrack(recording mbRecording) *
 		}
 	}
 
-	// Find the most relevant release (prefer the one with the earliest date)
+	// Find the most relevant release (prefer the configured country and status, then the earliest date)
 	var selectedRelease *mbRelease
 	if len(recording.Releases) > 0 {
 		selectedRelease = &recording.Releases[0]
 		for _, release := range recording.Releases {
-			if release.Date != "" && (selectedRelease.Date == "" || release.Date < selectedRelease.Date) {
+			rank, selectedRank := pref.Rank(release.Country, release.Status), pref.Rank(selectedRelease.Country, selectedRelease.Status)
+			if rank < selectedRank || rank == selectedRank && release.Date != "" && (selectedRelease.Date == "" || release.Date < selectedRelease.Date) {
 				selectedRelease = &release
 			}
 		}
@@ -173,6 +185,8 @@ func (p *MusicBrainzProvider) convertMBRecordingToTrack(recording mbRecording) *
 		album = &music.Album{
 			Title:   selectedRelease.Title,
 			Artists: artists, // Use the same artists for the album
+			Country: selectedRelease.Country,
+			Status:  selectedRelease.Status,
 		}
 
 		// Parse year from date
@@ -183,6 +197,12 @@ func (p *MusicBrainzProvider) convertMBRecordingToTrack(recording mbRecording) *
 		}
 	}
 
+	// Cover Art Archive serves the release's front cover, if one was uploaded
+	var thumbnail string
+	if selectedRelease != nil && selectedRelease.ID != "" {
+		thumbnail = fmt.Sprintf("https://coverartarchive.org/release/%s/front-500", selectedRelease.ID)
+	}
+
 	// Get ISRC if available
 	var isrc string
 	if len(recording.ISRCs) > 0 {
@@ -197,10 +217,11 @@ func (p *MusicBrainzProvider) convertMBRecordingToTrack(recording mbRecording) *
 		Metadata: music.Metadata{
 			Year: year,
 		},
-		ISRC: isrc,
+		ISRC:      isrc,
+		Thumbnail: thumbnail,
 		MetadataSource: music.MetadataSource{
 			Source:            "musicbrainz",
-			MetadataSourceURL: fmt.Sprintf("https://musicbrainz.org/recording/%s", recording.ID),
+			MetadataSourceURL: fmt.Sprintf("%s/recording/%s", p.baseURL, recording.ID),
 		},
 		HasLyrics: true,
 	}
@@ -0,0 +1,85 @@
+// Package sortkey builds the keys library names are sorted by and the transliterations of the
+// organizer's %asciify, both following the configured locale.
+package sortkey
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/gosimple/unidecode"
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+)
+
+// version is bumped when the keys change for the same settings, so stored keys are rebuilt.
+const version = 1
+
+// Keyer turns names into sort keys. Keys are hex strings whose byte order is the order of the
+// locale's collation, so the database sorts them with a plain ORDER BY.
+type Keyer struct {
+	locale        string
+	transliterate bool
+	mu            sync.Mutex // a Collator is not safe for concurrent use
+	collator      *collate.Collator
+	buf           collate.Buffer
+}
+
+// New creates a Keyer for a BCP 47 locale like "ja", "ru" or "zh-u-co-stroke", the root
+// collation when empty. With transliterate, names are sorted by their Latin transliteration.
+func New(locale string, transliterate bool) (*Keyer, error) {
+	tag := language.Und
+	if locale != "" {
+		var err error
+		if tag, err = language.Parse(locale); err != nil {
+			return nil, fmt.Errorf("invalid sort locale %q: %w", locale, err)
+		}
+	}
+	return &Keyer{
+		locale:        locale,
+		transliterate: transliterate,
+		collator:      collate.New(tag, collate.IgnoreCase, collate.Numeric),
+	}, nil
+}
+
+// Signature identifies the settings the keys are built with. Stored keys built with another
+// signature are stale.
+func (k *Keyer) Signature() string {
+	return fmt.Sprintf("v%d:%s:%t", version, k.locale, k.transliterate)
+}
+
+// Key returns the sort key of a name.
+func (k *Keyer) Key(name string) string {
+	name = strings.TrimSpace(name)
+	if k.transliterate {
+		name = Transliterate(k.locale, name)
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	key := hex.EncodeToString(k.collator.KeyFromString(&k.buf, name))
+	k.buf.Reset()
+	return key
+}
+
+// localeLetters are the transliterations a language writes differently from the generic ones,
+// e.g. "ü" is "ue" in German but "u" elsewhere.
+var localeLetters = map[string]*strings.Replacer{
+	"de": strings.NewReplacer("ä", "ae", "ö", "oe", "ü", "ue", "Ä", "Ae", "Ö", "Oe", "Ü", "Ue", "ß", "ss"),
+	"da": strings.NewReplacer("æ", "ae", "ø", "oe", "å", "aa", "Æ", "Ae", "Ø", "Oe", "Å", "Aa"),
+	"nb": strings.NewReplacer("æ", "ae", "ø", "oe", "å", "aa", "Æ", "Ae", "Ø", "Oe", "Å", "Aa"),
+	"nn": strings.NewReplacer("æ", "ae", "ø", "oe", "å", "aa", "Æ", "Ae", "Ø", "Oe", "Å", "Aa"),
+	"no": strings.NewReplacer("æ", "ae", "ø", "oe", "å", "aa", "Æ", "Ae", "Ø", "Oe", "Å", "Aa"),
+}
+
+// Transliterate writes s in ASCII, with the conventions of the locale's language where they
+// differ from the generic transliteration.
+func Transliterate(locale string, s string) string {
+	if tag, err := language.Parse(locale); err == nil {
+		base, _ := tag.Base()
+		if replacer, ok := localeLetters[base.String()]; ok {
+			s = replacer.Replace(s)
+		}
+	}
+	return strings.TrimSpace(unidecode.Unidecode(s))
+}
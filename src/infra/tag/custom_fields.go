@@ -0,0 +1,43 @@
+package tag
+
+import (
+	"log/slog"
+	"strings"
+)
+
+// managedFields are the fields the tag writer fills from the track itself, a custom field can't
+// take one of their names.
+var managedFields = map[string]bool{
+	"TITLE": true, "ARTIST": true, "ALBUM": true, "ALBUMARTIST": true, "DATE": true, "YEAR": true,
+	"GENRE": true, "ISRC": true, "TRACKNUMBER": true, "DISCNUMBER": true, "COMPOSER": true,
+	"LYRICS": true, "VERSION": true, "BPM": true, "LABEL": true, "BARCODE": true,
+	"CHROMAPRINT_FINGERPRINT": true, "ACOUSTID_ID": true, "REPLAYGAIN_TRACK_GAIN": true,
+}
+
+// customField maps a track attribute to the TXXX description (MP3) or Vorbis comment (FLAC) it's
+// embedded as, the attribute key in upper case.
+type customField struct {
+	attribute string
+	name      string
+}
+
+// parseCustomFields returns the fields of the configured attribute keys, dropping the empty ones
+// and the ones clashing with a managed field.
+func parseCustomFields(keys []string) []customField {
+	fields := make([]customField, 0, len(keys))
+	seen := map[string]bool{}
+	for _, key := range keys {
+		key = strings.TrimSpace(key)
+		name := strings.ToUpper(key)
+		if key == "" || seen[name] {
+			continue
+		}
+		if managedFields[name] {
+			slog.Warn("Custom field clashes with a managed tag, ignoring it", "field", key)
+			continue
+		}
+		seen[name] = true
+		fields = append(fields, customField{attribute: key, name: name})
+	}
+	return fields
+}
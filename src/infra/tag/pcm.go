@@ -0,0 +1,486 @@
+package tag
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/bogem/id3v2/v2"
+	"github.com/contre95/soulsolid/src/music"
+	"github.com/dhowden/tag"
+)
+
+// maxTagChunk caps the size of the ID3 and INFO chunks read from WAV and AIFF files.
+const maxTagChunk = 64 << 20
+
+// infoFields maps the RIFF INFO and AIFF text chunks to the tag fields they hold. The INFO
+// "ISRC" chunk is the source of the file, not a recording code, and is left out.
+var infoFields = map[string]string{
+	"INAM": "TITLE",
+	"IART": "ARTIST",
+	"IPRD": "ALBUM",
+	"ICRD": "DATE",
+	"IGNR": "GENRE",
+	"ITRK": "TRACKNUMBER",
+	"IPRT": "TRACKNUMBER",
+	"ICMT": "COMMENT",
+	"NAME": "TITLE",
+	"AUTH": "ARTIST",
+	"ANNO": "COMMENT",
+}
+
+// sidecarAliases maps the sidecar JSON keys to the tag field they stand for.
+var sidecarAliases = map[string]string{
+	"ALBUM_ARTIST": "ALBUMARTIST",
+	"ARTISTS":      "ARTIST",
+	"TRACK":        "TRACKNUMBER",
+	"TRACK_NUMBER": "TRACKNUMBER",
+	"TRACK_TOTAL":  "TRACKTOTAL",
+	"DISC":         "DISCNUMBER",
+	"DISC_NUMBER":  "DISCNUMBER",
+	"DISC_TOTAL":   "DISCTOTAL",
+	"YEAR":         "DATE",
+}
+
+// isPCM reports whether the file is a WAV or AIFF file.
+func isPCM(filePath string) bool {
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".wav", ".aif", ".aiff":
+		return true
+	}
+	return false
+}
+
+// pcmChunk is a chunk of a WAV or AIFF file, offset is where its header starts.
+type pcmChunk struct {
+	id     string
+	offset int64
+	size   int64
+}
+
+// pcmFile is what is read from the chunks of a WAV (RIFF) or AIFF (FORM) file.
+type pcmFile struct {
+	aiff       bool // big-endian chunk sizes
+	formType   string
+	chunks     []pcmChunk
+	id3        []byte    // the ID3 chunk, nil without one
+	info       fieldTags // the INFO list or the AIFF text chunks
+	sampleRate int
+	channels   int
+	bitDepth   int
+	frames     int64 // sample frames per channel
+}
+
+// readPCMTags reads the tags of a WAV or AIFF file: from its ID3 chunk, from its INFO list (or
+// AIFF text chunks) without one, or else from a sidecar JSON file. A file without any of them
+// is read with empty tags, for the import to fill in or queue.
+func (r *TagReader) readPCMTags(filePath string) (*music.Track, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+	pcm, err := parsePCM(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tags: %w", err)
+	}
+
+	var tags tag.Metadata
+	var id3 *id3v2.Tag
+	switch {
+	case pcm.id3 != nil:
+		if tags, err = tag.ReadID3v2Tags(bytes.NewReader(pcm.id3)); err != nil {
+			return nil, fmt.Errorf("failed to read ID3 chunk: %w", err)
+		}
+		if id3, err = id3v2.ParseReader(bytes.NewReader(pcm.id3), id3v2.Options{Parse: true}); err != nil {
+			slog.Debug("Failed to parse ID3 chunk frames", "path", filePath, "error", err)
+		}
+	case len(pcm.info) > 0:
+		tags = pcm.info
+	default:
+		sidecar, err := readSidecar(filePath)
+		if err != nil {
+			return nil, err
+		}
+		tags = sidecar
+	}
+
+	track := r.trackFromTags(tags, filePath)
+	if id3 != nil {
+		if track.Metadata.Lyrics == "" {
+			track.Metadata.Lyrics = lyricsFromID3(id3)
+		}
+		for key, value := range r.customFieldsFromID3(id3) {
+			if track.Attributes == nil {
+				track.Attributes = make(map[string]string)
+			}
+			track.Attributes[key] = value
+		}
+	}
+	track.SampleRate = pcm.sampleRate
+	track.Channels = pcm.channels
+	track.BitDepth = pcm.bitDepth
+	track.Bitrate = pcm.sampleRate * pcm.channels * pcm.bitDepth / 1000
+	if pcm.sampleRate > 0 {
+		track.Metadata.Duration = int(pcm.frames / int64(pcm.sampleRate))
+	}
+	return track, nil
+}
+
+// parsePCM walks the chunks of a WAV or AIFF file, reading its tag chunks and audio format.
+// A truncated last chunk ends the walk.
+func parsePCM(r io.ReadSeeker) (*pcmFile, error) {
+	header := make([]byte, 12)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("file too short: %w", err)
+	}
+	pcm := &pcmFile{formType: string(header[8:12])}
+	switch {
+	case string(header[0:4]) == "RIFF" && pcm.formType == "WAVE":
+	case string(header[0:4]) == "FORM" && (pcm.formType == "AIFF" || pcm.formType == "AIFC"):
+		pcm.aiff = true
+	default:
+		return nil, errors.New("not a WAV or AIFF file")
+	}
+
+	offset := int64(12)
+	chunkHeader := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(r, chunkHeader); err != nil {
+			break
+		}
+		chunk := pcmChunk{id: string(chunkHeader[0:4]), offset: offset, size: int64(pcm.uint32(chunkHeader[4:8]))}
+		pcm.chunks = append(pcm.chunks, chunk)
+
+		var data []byte
+		switch strings.ToUpper(chunk.id) {
+		case "FMT ", "COMM", "LIST", "ID3 ", "NAME", "AUTH", "ANNO":
+			if chunk.size > maxTagChunk {
+				return nil, fmt.Errorf("%q chunk too large: %d bytes", chunk.id, chunk.size)
+			}
+			data = make([]byte, chunk.size)
+			if _, err := io.ReadFull(r, data); err != nil {
+				pcm.chunks = pcm.chunks[:len(pcm.chunks)-1]
+				return pcm, nil
+			}
+		}
+		pcm.readChunk(chunk, data)
+
+		offset += 8 + chunk.size + chunk.size&1
+		if _, err := r.Seek(offset, io.SeekStart); err != nil {
+			return nil, err
+		}
+	}
+	return pcm, nil
+}
+
+// readChunk reads the audio format or the tags held by a chunk. data is nil for the chunks
+// not read, like the audio data.
+func (pcm *pcmFile) readChunk(chunk pcmChunk, data []byte) {
+	switch {
+	case chunk.id == "fmt " && len(data) >= 16:
+		pcm.channels = int(binary.LittleEndian.Uint16(data[2:4]))
+		pcm.sampleRate = int(binary.LittleEndian.Uint32(data[4:8]))
+		pcm.bitDepth = int(binary.LittleEndian.Uint16(data[14:16]))
+	case chunk.id == "data" && !pcm.aiff:
+		if blockAlign := pcm.channels * ((pcm.bitDepth + 7) / 8); blockAlign > 0 {
+			pcm.frames = chunk.size / int64(blockAlign)
+		}
+	case chunk.id == "COMM" && len(data) >= 18:
+		pcm.channels = int(binary.BigEndian.Uint16(data[0:2]))
+		pcm.frames = int64(binary.BigEndian.Uint32(data[2:6]))
+		pcm.bitDepth = int(binary.BigEndian.Uint16(data[6:8]))
+		pcm.sampleRate = int(extendedFloat(data[8:18]))
+	case strings.EqualFold(chunk.id, "ID3 "):
+		pcm.id3 = data
+	case chunk.id == "LIST" && len(data) >= 4 && string(data[0:4]) == "INFO":
+		for sub := data[4:]; len(sub) >= 8; {
+			size := int(binary.LittleEndian.Uint32(sub[4:8]))
+			if 8+size > len(sub) {
+				break
+			}
+			pcm.addInfo(string(sub[0:4]), sub[8:8+size])
+			sub = sub[min(len(sub), 8+size+size&1):]
+		}
+	case chunk.id == "NAME" || chunk.id == "AUTH" || chunk.id == "ANNO":
+		pcm.addInfo(chunk.id, data)
+	}
+}
+
+// addInfo records the text of an INFO or AIFF text chunk under its tag field.
+func (pcm *pcmFile) addInfo(id string, data []byte) {
+	field, ok := infoFields[id]
+	value := strings.TrimSpace(strings.TrimRight(string(data), "\x00"))
+	if !ok || value == "" {
+		return
+	}
+	if pcm.info == nil {
+		pcm.info = fieldTags{}
+	}
+	if _, set := pcm.info[field]; !set {
+		pcm.info[field] = value
+	}
+}
+
+// uint32 reads a chunk size in the byte order of the file.
+func (pcm *pcmFile) uint32(b []byte) uint32 {
+	if pcm.aiff {
+		return binary.BigEndian.Uint32(b)
+	}
+	return binary.LittleEndian.Uint32(b)
+}
+
+// putUint32 writes a chunk size in the byte order of the file.
+func (pcm *pcmFile) putUint32(b []byte, v uint32) {
+	if pcm.aiff {
+		binary.BigEndian.PutUint32(b, v)
+	} else {
+		binary.LittleEndian.PutUint32(b, v)
+	}
+}
+
+// extendedFloat decodes the 80-bit IEEE 754 extended float of an AIFF sample rate.
+func extendedFloat(b []byte) float64 {
+	exponent := int(binary.BigEndian.Uint16(b[0:2]) & 0x7fff)
+	mantissa := binary.BigEndian.Uint64(b[2:10])
+	if exponent == 0 && mantissa == 0 {
+		return 0
+	}
+	return math.Ldexp(float64(mantissa), exponent-16383-63)
+}
+
+// readSidecar reads the tags of an untagged file from the JSON file next to it, named after the
+// file with or without its extension, e.g. "song.wav.json" or "song.json". The keys are tag
+// field names in any case, like "title", "album_artist" or "track"; numbers and lists of
+// artists are accepted. It returns empty tags when there is no sidecar.
+func readSidecar(filePath string) (fieldTags, error) {
+	candidates := []string{filePath + ".json", strings.TrimSuffix(filePath, filepath.Ext(filePath)) + ".json"}
+	for _, candidate := range candidates {
+		data, err := os.ReadFile(candidate)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read sidecar %s: %w", candidate, err)
+		}
+		var values map[string]any
+		if err := json.Unmarshal(data, &values); err != nil {
+			return nil, fmt.Errorf("invalid sidecar %s: %w", candidate, err)
+		}
+		tags := fieldTags{}
+		for key, value := range values {
+			key = strings.ReplaceAll(strings.ToUpper(strings.TrimSpace(key)), " ", "_")
+			if alias, ok := sidecarAliases[key]; ok {
+				key = alias
+			}
+			if text := sidecarText(value); text != "" {
+				tags[key] = text
+			}
+		}
+		slog.Debug("Read tags from sidecar", "path", filePath, "sidecar", candidate, "fields", len(tags))
+		return tags, nil
+	}
+	return fieldTags{}, nil
+}
+
+// sidecarText returns a sidecar JSON value as tag text, lists are joined like multiple artists.
+func sidecarText(value any) string {
+	switch v := value.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case []any:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			if text := sidecarText(item); text != "" {
+				parts = append(parts, text)
+			}
+		}
+		return strings.Join(parts, "; ")
+	}
+	return ""
+}
+
+// fieldTags are tags read from INFO chunks or a sidecar file, keyed by upper case field names
+// like Vorbis comments.
+type fieldTags map[string]string
+
+func (f fieldTags) Format() tag.Format     { return tag.UnknownFormat }
+func (f fieldTags) FileType() tag.FileType { return tag.UnknownFileType }
+func (f fieldTags) Title() string          { return f["TITLE"] }
+func (f fieldTags) Album() string          { return f["ALBUM"] }
+func (f fieldTags) Artist() string         { return f["ARTIST"] }
+func (f fieldTags) AlbumArtist() string    { return f["ALBUMARTIST"] }
+func (f fieldTags) Composer() string       { return f["COMPOSER"] }
+func (f fieldTags) Genre() string          { return f["GENRE"] }
+func (f fieldTags) Picture() *tag.Picture  { return nil }
+func (f fieldTags) Lyrics() string         { return f["LYRICS"] }
+func (f fieldTags) Comment() string        { return f["COMMENT"] }
+
+// Year returns the year the date starts with, e.g. 1999 for "1999-05-03".
+func (f fieldTags) Year() int {
+	date := f["DATE"]
+	if len(date) > 4 {
+		date = date[:4]
+	}
+	year, _ := strconv.Atoi(date)
+	return year
+}
+
+// Track returns the track number and total, from "3/12" or separate fields.
+func (f fieldTags) Track() (int, int) {
+	return numberAndTotal(f["TRACKNUMBER"], f["TRACKTOTAL"])
+}
+
+// Disc returns the disc number and total, from "1/2" or separate fields.
+func (f fieldTags) Disc() (int, int) {
+	return numberAndTotal(f["DISCNUMBER"], f["DISCTOTAL"])
+}
+
+func (f fieldTags) Raw() map[string]any {
+	raw := make(map[string]any, len(f))
+	for key, value := range f {
+		raw[key] = value
+	}
+	return raw
+}
+
+func numberAndTotal(value, total string) (int, int) {
+	number, slashTotal, _ := strings.Cut(value, "/")
+	n, _ := strconv.Atoi(strings.TrimSpace(number))
+	if total == "" {
+		total = slashTotal
+	}
+	t, _ := strconv.Atoi(strings.TrimSpace(total))
+	return n, t
+}
+
+// tagPCM writes the tags of a WAV or AIFF file as an ID3 chunk, replacing the existing one and
+// keeping its other frames. The INFO list is left as it is, the ID3 chunk is read first.
+func (t *TagWriter) tagPCM(filePath string, track *music.Track) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	src, err := os.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to open file for tagging: %w", err)
+	}
+	defer src.Close()
+	pcm, err := parsePCM(src)
+	if err != nil {
+		return fmt.Errorf("failed to read file for tagging: %w", err)
+	}
+
+	id3 := id3v2.NewEmptyTag()
+	if pcm.id3 != nil {
+		if existing, err := id3v2.ParseReader(bytes.NewReader(pcm.id3), id3v2.Options{Parse: true}); err == nil {
+			id3 = existing
+		} else {
+			slog.Warn("Failed to parse existing ID3 chunk, replacing it", "filePath", filePath, "error", err)
+		}
+	}
+	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(filePath)), ".")
+	if format == "aif" {
+		format = "aiff"
+	}
+	t.setID3Frames(id3, filePath, track, format)
+	var frames bytes.Buffer
+	if _, err := id3.WriteTo(&frames); err != nil {
+		return fmt.Errorf("failed to encode ID3 chunk: %w", err)
+	}
+
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), ".soulsolid-tag-*"+filepath.Ext(filePath))
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if err := pcm.writeWithID3(src, tmp, frames.Bytes()); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write tagged file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write tagged file: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), filePath); err != nil {
+		return fmt.Errorf("failed to replace file: %w", err)
+	}
+
+	slog.Info("Tagged PCM file successfully", "filePath", filePath, "title", track.Title, "format", format)
+	return nil
+}
+
+// writeWithID3 copies the chunks of src to dst, dropping its ID3 chunks and appending frames as
+// the new one, then fixes the container size.
+func (pcm *pcmFile) writeWithID3(src io.ReaderAt, dst io.WriteSeeker, frames []byte) error {
+	header := make([]byte, 12)
+	if _, err := src.ReadAt(header, 0); err != nil {
+		return err
+	}
+	if _, err := dst.Write(header); err != nil {
+		return err
+	}
+	size := int64(4) // the form type
+	for _, chunk := range pcm.chunks {
+		if strings.EqualFold(chunk.id, "ID3 ") {
+			continue
+		}
+		length := 8 + chunk.size + chunk.size&1
+		n, err := io.Copy(dst, io.NewSectionReader(src, chunk.offset, length))
+		if err != nil {
+			return err
+		}
+		if n < 8+chunk.size {
+			return fmt.Errorf("%q chunk is truncated", chunk.id)
+		}
+		// The pad byte of an odd last chunk is often missing
+		if n < length {
+			if _, err := dst.Write([]byte{0}); err != nil {
+				return err
+			}
+		}
+		size += length
+	}
+
+	id := "id3 "
+	if pcm.aiff {
+		id = "ID3 "
+	}
+	chunkHeader := []byte(id + "\x00\x00\x00\x00")
+	pcm.putUint32(chunkHeader[4:8], uint32(len(frames)))
+	if len(frames)%2 == 1 {
+		frames = append(frames, 0)
+	}
+	if _, err := dst.Write(append(chunkHeader, frames...)); err != nil {
+		return err
+	}
+	size += int64(8 + len(frames))
+	if size > math.MaxUint32 {
+		return errors.New("file too large for its container")
+	}
+
+	pcm.putUint32(header[4:8], uint32(size))
+	if _, err := dst.Seek(4, io.SeekStart); err != nil {
+		return err
+	}
+	_, err := dst.Write(header[4:8])
+	return err
+}
@@ -1,6 +1,7 @@
 package tag
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"log/slog"
@@ -15,11 +16,16 @@ import (
 )
 
 // TagReader is an implementation of the MetadataReader interface that uses the dhowden/tag library.
-type TagReader struct{}
+type TagReader struct {
+	customFields []customField
+	notesComment bool
+}
 
-// NewTagReader creates a new TagReader
-func NewTagReader() *TagReader {
-	return &TagReader{}
+// NewTagReader creates a new TagReader. The TXXX frames and Vorbis comments of customFields are
+// read back into the track attributes of the same name, and the comment into the track notes
+// when notesComment is set.
+func NewTagReader(customFields []string, notesComment bool) *TagReader {
+	return &TagReader{customFields: parseCustomFields(customFields), notesComment: notesComment}
 }
 
 // parseArtists parses a string containing multiple artists separated by common delimiters
@@ -54,6 +60,9 @@ func parseArtists(artistString string) []*music.Artist {
 
 // Read reads metadata from a music file.
 func (r *TagReader) ReadFileTags(ctx context.Context, filePath string) (*music.Track, error) {
+	if isPCM(filePath) {
+		return r.readPCMTags(filePath)
+	}
 	file, err := os.Open(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open file: %w", err)
@@ -64,9 +73,13 @@ func (r *TagReader) ReadFileTags(ctx context.Context, filePath string) (*music.T
 	if err != nil {
 		return nil, fmt.Errorf("failed to read tags: %w", err)
 	}
+	return r.trackFromTags(tags, filePath), nil
+}
 
+// trackFromTags builds the track of the file at filePath from its tags.
+func (r *TagReader) trackFromTags(tags tag.Metadata, filePath string) *music.Track {
 	trackNumber, _ := tags.Track()
-	discNumber, _ := tags.Disc()
+	discNumber, discTotal := tags.Disc()
 
 	// Get album artist, fall back to track artist if empty
 	albumArtist := tags.AlbumArtist()
@@ -91,6 +104,7 @@ func (r *TagReader) ReadFileTags(ctx context.Context, filePath string) (*music.T
 			Genre:       tags.Genre(),
 			TrackNumber: trackNumber,
 			DiscNumber:  discNumber,
+			DiscTotal:   discTotal,
 			Composer:    tags.Composer(),
 		},
 		HasLyrics: true,
@@ -119,7 +133,7 @@ func (r *TagReader) ReadFileTags(ctx context.Context, filePath string) (*music.T
 	// Try to read additional metadata from raw tags
 	r.readAdditionalMetadata(tags, track, filePath)
 
-	return track, nil
+	return track
 }
 
 // readAdditionalMetadata attempts to read additional metadata fields from tags
@@ -129,6 +143,16 @@ func (r *TagReader) readAdditionalMetadata(tags tag.Metadata, track *music.Track
 		track.ISRC = isrc
 	}
 
+	// The version is written to TIT3 for MP3 and VERSION for FLAC
+	if raw := tags.Raw(); raw != nil {
+		for _, field := range []string{"TIT3", "version", "VERSION"} {
+			if version, ok := raw[field].(string); ok && strings.TrimSpace(version) != "" {
+				track.TitleVersion = strings.TrimSpace(version)
+				break
+			}
+		}
+	}
+
 	// Try to read BPM from various tag fields
 	if bpm := r.findBPM(tags); bpm > 0 {
 		track.Metadata.BPM = bpm
@@ -162,6 +186,20 @@ func (r *TagReader) readAdditionalMetadata(tags tag.Metadata, track *music.Track
 		slog.Debug("No AcoustID found in file", "path", track.Path)
 	}
 
+	for key, value := range r.readCustomFields(tags, filePath) {
+		if track.Attributes == nil {
+			track.Attributes = make(map[string]string)
+		}
+		track.Attributes[key] = value
+	}
+
+	if comment := strings.TrimSpace(tags.Comment()); r.notesComment && comment != "" && music.ValidateNotes(comment) == nil {
+		if track.Attributes == nil {
+			track.Attributes = make(map[string]string)
+		}
+		track.Attributes[music.NotesAttribute] = comment
+	}
+
 	// Try to extract basic audio properties
 	r.extractAudioProperties(track)
 }
@@ -242,6 +280,11 @@ func (r *TagReader) readLyricsFromMP3(filePath string) string {
 	}
 	defer tag.Close()
 
+	return lyricsFromID3(tag)
+}
+
+// lyricsFromID3 returns the lyrics of the LYRICS TXXX frame of an ID3 tag.
+func lyricsFromID3(tag *id3v2.Tag) string {
 	// Get TXXX frames (user-defined text frames)
 	frames := tag.GetFrames("TXXX")
 	for _, f := range frames {
@@ -256,6 +299,54 @@ func (r *TagReader) readLyricsFromMP3(filePath string) string {
 	return ""
 }
 
+// readCustomFields returns the values of the custom fields found in the file, keyed by attribute.
+func (r *TagReader) readCustomFields(tags tag.Metadata, filePath string) map[string]string {
+	if len(r.customFields) == 0 {
+		return nil
+	}
+	values := map[string]string{}
+	if strings.ToLower(filepath.Ext(filePath)) == ".mp3" {
+		id3, err := id3v2.Open(filePath, id3v2.Options{Parse: true})
+		if err != nil {
+			slog.Debug("Failed to open MP3 file for custom fields reading", "error", err)
+			return nil
+		}
+		defer id3.Close()
+		return r.customFieldsFromID3(id3)
+	}
+	// Vorbis comment names are case-insensitive
+	for key, value := range tags.Raw() {
+		str, ok := value.(string)
+		if !ok || strings.TrimSpace(str) == "" {
+			continue
+		}
+		for _, field := range r.customFields {
+			if strings.EqualFold(key, field.name) {
+				values[field.attribute] = strings.TrimSpace(str)
+			}
+		}
+	}
+	return values
+}
+
+// customFieldsFromID3 returns the values of the custom fields found in the TXXX frames of an
+// ID3 tag, keyed by attribute.
+func (r *TagReader) customFieldsFromID3(id3 *id3v2.Tag) map[string]string {
+	values := map[string]string{}
+	for _, f := range id3.GetFrames("TXXX") {
+		frame, ok := f.(id3v2.UserDefinedTextFrame)
+		if !ok || strings.TrimSpace(frame.Value) == "" {
+			continue
+		}
+		for _, field := range r.customFields {
+			if strings.EqualFold(frame.Description, field.name) {
+				values[field.attribute] = strings.TrimSpace(frame.Value)
+			}
+		}
+	}
+	return values
+}
+
 // readChromaprintFingerprint attempts to read chromaprint fingerprint from various tag fields
 func (r *TagReader) readChromaprintFingerprint(tags tag.Metadata) string {
 	// Try to read from raw tags for chromaprint fingerprint fields
@@ -403,7 +494,19 @@ func (r *TagReader) ReadArtwork(filePath string) ([]byte, string, error) {
 	}
 	defer file.Close()
 
-	tags, err := tag.ReadFrom(file)
+	var tags tag.Metadata
+	if isPCM(filePath) {
+		pcm, err := parsePCM(file)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read tags: %w", err)
+		}
+		if pcm.id3 == nil {
+			return nil, "", nil
+		}
+		tags, err = tag.ReadID3v2Tags(bytes.NewReader(pcm.id3))
+	} else {
+		tags, err = tag.ReadFrom(file)
+	}
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to read tags: %w", err)
 	}
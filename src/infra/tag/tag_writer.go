@@ -9,6 +9,7 @@ import (
 	"image/png"
 	"log/slog"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -25,9 +26,11 @@ import (
 	_ "golang.org/x/image/webp"
 )
 
-// TagWriter implements writing tags into files for MP3 and FLAC formats.
+// TagWriter implements writing tags into files for MP3, FLAC, WAV and AIFF formats.
 type TagWriter struct {
 	artworkConfig config.EmbeddedArtwork
+	customFields  []customField
+	notesComment  bool
 	mu            sync.Mutex
 }
 
@@ -40,13 +43,30 @@ func (t *TagWriter) SetCover(tag *id3v2.Tag, imgData []byte, mimeType string) er
 		Description: "Front cover",
 		Picture:     imgData,
 	}
+	tag.DeleteFrames(tag.CommonID("Attached picture"))
 	tag.AddAttachedPicture(pic)
 	return nil
 }
 
-// NewTagWriter creates a new TagWriter.
-func NewTagWriter(artworkConfig config.EmbeddedArtwork) *TagWriter {
-	return &TagWriter{artworkConfig: artworkConfig}
+// NewTagWriter creates a new TagWriter. The track attributes listed in customFields are
+// embedded as TXXX frames and Vorbis comments, and the track notes replace the comment when
+// notesComment is set.
+func NewTagWriter(artworkConfig config.EmbeddedArtwork, customFields []string, notesComment bool) *TagWriter {
+	return &TagWriter{artworkConfig: artworkConfig, customFields: parseCustomFields(customFields), notesComment: notesComment}
+}
+
+// NotesComment reports whether the track notes are written to the comment tag.
+func (t *TagWriter) NotesComment() bool {
+	return t.notesComment
+}
+
+// CustomFields returns the track attributes embedded as custom fields.
+func (t *TagWriter) CustomFields() []string {
+	keys := make([]string, len(t.customFields))
+	for i, field := range t.customFields {
+		keys[i] = field.attribute
+	}
+	return keys
 }
 
 // removeExistingFields removes all existing fields with the given key from the Vorbis comment (case-insensitive)
@@ -61,6 +81,24 @@ func removeExistingFields(vorbisComment *flacvorbis.MetaDataBlockVorbisComment,
 	vorbisComment.Comments = filtered
 }
 
+// artworkFor returns the artwork settings for files of the given format, with its overrides
+// applied, and whether artwork is embedded in them at all.
+func (t *TagWriter) artworkFor(format string) (config.EmbeddedArtwork, bool) {
+	artwork := t.artworkConfig
+	override, ok := artwork.Formats[format]
+	if !ok {
+		return artwork, true
+	}
+	if override.Size > 0 {
+		artwork.Enabled = true
+		artwork.Size = override.Size
+	}
+	if override.Quality > 0 {
+		artwork.Quality = override.Quality
+	}
+	return artwork, !override.Skip
+}
+
 // WriteFileTags writes metadata to the file.
 func (t *TagWriter) WriteFileTags(ctx context.Context, filePath string, track *music.Track) error {
 	ext := strings.ToLower(filepath.Ext(filePath))
@@ -70,6 +108,8 @@ func (t *TagWriter) WriteFileTags(ctx context.Context, filePath string, track *m
 		return t.tagMP3(filePath, track)
 	case ".flac":
 		return t.tagFLAC(filePath, track)
+	case ".wav", ".aif", ".aiff":
+		return t.tagPCM(filePath, track)
 	default:
 		return fmt.Errorf("unsupported format: %s", ext)
 	}
@@ -86,6 +126,24 @@ func (t *TagWriter) tagMP3(filePath string, track *music.Track) error {
 	}
 	defer tag.Close()
 
+	t.setID3Frames(tag, filePath, track, "mp3")
+
+	if err := tag.Save(); err != nil {
+		return fmt.Errorf("failed to save MP3 tags: %w", err)
+	}
+
+	slog.Info("Tagged MP3 successfully",
+		"filePath", filePath,
+		"title", track.Title,
+		"artist", tag.Artist(),
+		"album", tag.Album(),
+	)
+
+	return nil
+}
+
+// setID3Frames sets the ID3 frames of a track on tag, the artwork settings of format apply.
+func (t *TagWriter) setID3Frames(tag *id3v2.Tag, filePath string, track *music.Track, format string) {
 	// Set default encoding to UTF-8 to match working example app
 	tag.SetDefaultEncoding(id3v2.EncodingUTF8)
 
@@ -206,14 +264,36 @@ func (t *TagWriter) tagMP3(filePath string, track *music.Track) error {
 		})
 	}
 
+	// Custom fields, the TXXX frames of unset attributes are gone with the others
+	for _, field := range t.customFields {
+		if value := track.Attributes[field.attribute]; value != "" {
+			tag.AddUserDefinedTextFrame(id3v2.UserDefinedTextFrame{
+				Encoding:    id3v2.EncodingUTF8,
+				Description: field.name,
+				Value:       value,
+			})
+		}
+	}
+
+	// Notes, the comment of a track without notes is left alone
+	if notes := track.Notes(); t.notesComment && notes != "" {
+		tag.DeleteFrames(tag.CommonID("Comments"))
+		tag.AddCommentFrame(id3v2.CommentFrame{
+			Encoding: id3v2.EncodingUTF8,
+			Language: "eng",
+			Text:     notes,
+		})
+	}
+
 	// Cover artwork - embedded image only (URL references cause compatibility issues)
-	if track.Album != nil && len(track.Album.ArtworkData) > 0 {
-		mimeType := t.detectMimeType(track.Album.ArtworkData)
+	artwork, embed := t.artworkFor(format)
+	if cover := track.CoverArt(); len(cover) > 0 && embed {
+		mimeType := t.detectMimeType(cover)
 
 		// Convert WebP to JPEG for better compatibility
 		if mimeType == "image/webp" {
-			if converted, err := t.convertToJPEG(track.Album.ArtworkData); err == nil {
-				track.Album.ArtworkData = converted
+			if converted, err := t.convertToJPEG(cover, artwork.Quality); err == nil {
+				cover = converted
 				mimeType = "image/jpeg"
 				slog.Debug("Converted WebP artwork to JPEG", "filePath", filePath)
 			} else {
@@ -222,13 +302,13 @@ func (t *TagWriter) tagMP3(filePath string, track *music.Track) error {
 		}
 
 		// Resize if configured
-		imgData := track.Album.ArtworkData
-		if t.artworkConfig.Enabled && t.artworkConfig.Size > 0 {
-			if resized, err := t.resizeImage(track.Album.ArtworkData, t.artworkConfig.Size); err == nil {
+		imgData := cover
+		if artwork.Enabled && artwork.Size > 0 {
+			if resized, err := t.resizeImage(cover, artwork.Size, artwork.Quality); err == nil {
 				// Validate that resized image is still valid
 				if _, _, err := image.Decode(bytes.NewReader(resized)); err == nil {
 					imgData = resized
-					slog.Debug("Artwork resized successfully", "filePath", filePath, "originalSize", len(track.Album.ArtworkData), "resizedSize", len(resized))
+					slog.Debug("Artwork resized successfully", "filePath", filePath, "originalSize", len(cover), "resizedSize", len(resized))
 				} else {
 					slog.Warn("Resized artwork is invalid, using original", "filePath", filePath, "error", err)
 				}
@@ -248,19 +328,6 @@ func (t *TagWriter) tagMP3(filePath string, track *music.Track) error {
 			}
 		}
 	}
-
-	if err := tag.Save(); err != nil {
-		return fmt.Errorf("failed to save MP3 tags: %w", err)
-	}
-
-	slog.Info("Tagged MP3 successfully",
-		"filePath", filePath,
-		"title", track.Title,
-		"artist", tag.Artist(),
-		"album", tag.Album(),
-	)
-
-	return nil
 }
 
 // Helper: build title + version (e.g., "Song (Live)")
@@ -395,6 +462,16 @@ func (t *TagWriter) tagFLAC(filePath string, track *music.Track) error {
 	if track.Metadata.Gain != 0 {
 		vorbisComment.Add("REPLAYGAIN_TRACK_GAIN", fmt.Sprintf("%.2f dB", track.Metadata.Gain))
 	}
+	for _, field := range t.customFields {
+		removeExistingFields(vorbisComment, field.name)
+		if value := track.Attributes[field.attribute]; value != "" {
+			vorbisComment.Add(field.name, value)
+		}
+	}
+	if notes := track.Notes(); t.notesComment && notes != "" {
+		removeExistingFields(vorbisComment, "COMMENT")
+		vorbisComment.Add("COMMENT", notes)
+	}
 	if track.Album != nil {
 		if track.Album.Label != "" {
 			removeExistingFields(vorbisComment, "LABEL")
@@ -417,15 +494,16 @@ func (t *TagWriter) tagFLAC(filePath string, track *music.Track) error {
 	}
 
 	// Embed artwork if available
-	if track.Album != nil && len(track.Album.ArtworkData) > 0 {
-		imgData := track.Album.ArtworkData
+	artwork, embed := t.artworkFor("flac")
+	if cover := track.CoverArt(); len(cover) > 0 && embed {
+		imgData := cover
 
 		// Resize image if configured
-		if t.artworkConfig.Enabled && t.artworkConfig.Size > 0 {
-			maxSize := t.artworkConfig.Size
+		if artwork.Enabled && artwork.Size > 0 {
+			maxSize := artwork.Size
 			if maxSize > 0 {
 				slog.Debug("Resizing artwork for FLAC", "filePath", filePath, "maxSize", maxSize)
-				resizedData, err := t.resizeImage(imgData, maxSize)
+				resizedData, err := t.resizeImage(imgData, maxSize, artwork.Quality)
 				if err != nil {
 					slog.Warn("Failed to resize artwork for FLAC", "filePath", filePath, "error", err)
 				} else {
@@ -452,6 +530,8 @@ func (t *TagWriter) tagFLAC(filePath string, track *music.Track) error {
 			Type: goflac.Picture,
 			Data: marshaled.Data,
 		}
+		// Replace the existing cover instead of stacking pictures
+		f.Meta = slices.DeleteFunc(f.Meta, func(m *goflac.MetaDataBlock) bool { return m.Type == goflac.Picture })
 		f.Meta = append(f.Meta, pictureBlock)
 		slog.Info("Embedded artwork in FLAC", "filePath", filePath, "size", len(imgData), "type", mimeType, "blocks", len(f.Meta))
 	}
@@ -486,7 +566,7 @@ func (t *TagWriter) detectMimeType(imgData []byte) string {
 }
 
 // convertToJPEG converts image data to JPEG format for better compatibility
-func (t *TagWriter) convertToJPEG(imgData []byte) ([]byte, error) {
+func (t *TagWriter) convertToJPEG(imgData []byte, quality int) ([]byte, error) {
 	// Decode image
 	img, _, err := image.Decode(bytes.NewReader(imgData))
 	if err != nil {
@@ -495,7 +575,6 @@ func (t *TagWriter) convertToJPEG(imgData []byte) ([]byte, error) {
 
 	// Encode to JPEG
 	var buf bytes.Buffer
-	quality := t.artworkConfig.Quality
 	if quality <= 0 {
 		quality = 85
 	}
@@ -508,7 +587,7 @@ func (t *TagWriter) convertToJPEG(imgData []byte) ([]byte, error) {
 }
 
 // resizeImage resizes image data to fit within maxSize pixels, maintaining aspect ratio.
-func (t *TagWriter) resizeImage(imgData []byte, maxSize int) ([]byte, error) {
+func (t *TagWriter) resizeImage(imgData []byte, maxSize, quality int) ([]byte, error) {
 	if maxSize <= 0 {
 		return imgData, nil
 	}
@@ -543,21 +622,16 @@ func (t *TagWriter) resizeImage(imgData []byte, maxSize int) ([]byte, error) {
 
 	// Encode back
 	var buf bytes.Buffer
+	if quality <= 0 {
+		quality = 85
+	}
 	switch strings.ToLower(format) {
 	case "jpeg":
-		quality := t.artworkConfig.Quality
-		if quality <= 0 {
-			quality = 85
-		}
 		err = jpeg.Encode(&buf, resizedImg, &jpeg.Options{Quality: quality})
 	case "png":
 		err = png.Encode(&buf, resizedImg)
 	default:
 		// Default to JPEG
-		quality := t.artworkConfig.Quality
-		if quality <= 0 {
-			quality = 85
-		}
 		err = jpeg.Encode(&buf, resizedImg, &jpeg.Options{Quality: quality})
 	}
 	if err != nil {
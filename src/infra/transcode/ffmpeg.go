@@ -0,0 +1,101 @@
+package transcode
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/contre95/soulsolid/src/features/importing"
+	"github.com/contre95/soulsolid/src/music"
+)
+
+// codecs maps the target formats to their ffmpeg audio encoder arguments, MP3 is encoded as
+// VBR V0.
+var codecs = map[string][]string{
+	"flac": {"-c:a", "flac"},
+	"mp3":  {"-c:a", "libmp3lame", "-q:a", "0", "-id3v2_version", "3"},
+}
+
+// TagWriter writes the tags of a track into a converted file.
+type TagWriter interface {
+	WriteFileTags(ctx context.Context, filePath string, track *music.Track) error
+}
+
+// FFmpeg converts audio files with the ffmpeg command.
+type FFmpeg struct {
+	tagWriter TagWriter
+}
+
+// NewFFmpeg creates a transcoder tagging the converted files with tagWriter.
+func NewFFmpeg(tagWriter TagWriter) importing.Transcoder {
+	return &FFmpeg{tagWriter: tagWriter}
+}
+
+// Transcode converts the file of track to format into dir. The tags of the source file are
+// dropped and the track is written as tags instead, since INFO chunks and sidecar files don't
+// survive a conversion.
+func (f *FFmpeg) Transcode(ctx context.Context, track *music.Track, format string, dir string) (string, error) {
+	dst, err := convert(ctx, track.Path, format, dir, "-1")
+	if err != nil {
+		return "", err
+	}
+	if err := f.tagWriter.WriteFileTags(ctx, dst, track); err != nil {
+		return "", fmt.Errorf("failed to tag converted file: %w", err)
+	}
+	return dst, nil
+}
+
+// TranscodeFile converts a file to format into dir, ffmpeg carries its tags over.
+func (f *FFmpeg) TranscodeFile(ctx context.Context, path string, format string, dir string) (string, error) {
+	// Ogg files keep their Vorbis comments on the audio stream rather than the container
+	metadata := "0"
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ogg", ".oga", ".opus":
+		metadata = "0:s:a:0"
+	}
+	return convert(ctx, path, format, dir, metadata)
+}
+
+// Bitrate reads the overall bitrate of a file in kbps with ffprobe.
+func (f *FFmpeg) Bitrate(ctx context.Context, path string) (int, error) {
+	if _, err := exec.LookPath("ffprobe"); err != nil {
+		return 0, fmt.Errorf("ffprobe not found. Please install ffmpeg: %w", err)
+	}
+	cmd := exec.CommandContext(ctx, "ffprobe", "-v", "error", "-show_entries", "format=bit_rate",
+		"-of", "default=noprint_wrappers=1:nokey=1", path)
+	output, err := cmd.Output()
+	if err != nil {
+		return 0, fmt.Errorf("ffprobe failed: %w", err)
+	}
+	bitrate, err := strconv.Atoi(strings.TrimSpace(string(output)))
+	if err != nil {
+		return 0, fmt.Errorf("unknown bitrate %q", strings.TrimSpace(string(output)))
+	}
+	return bitrate / 1000, nil
+}
+
+// convert runs ffmpeg on the first audio stream of src and its embedded artwork, writing them to
+// dir as format. metadata is the -map_metadata input the tags are taken from, "-1" drops them.
+func convert(ctx context.Context, src, format, dir, metadata string) (string, error) {
+	codec, ok := codecs[format]
+	if !ok {
+		return "", fmt.Errorf("unsupported target format: %s", format)
+	}
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		return "", fmt.Errorf("ffmpeg not found. Please install ffmpeg: %w", err)
+	}
+
+	name := strings.TrimSuffix(filepath.Base(src), filepath.Ext(src)) + "." + format
+	dst := filepath.Join(dir, name)
+	args := []string{"-nostdin", "-hide_banner", "-loglevel", "error", "-y",
+		"-i", src, "-map", "0:a:0", "-map", "0:v?", "-c:v", "copy", "-disposition:v", "attached_pic",
+		"-map_metadata", metadata}
+	args = append(append(args, codec...), dst)
+	if output, err := exec.CommandContext(ctx, "ffmpeg", args...).CombinedOutput(); err != nil {
+		return "", fmt.Errorf("ffmpeg failed: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return dst, nil
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"io/fs"
 	"log/slog"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -16,6 +18,9 @@ import (
 
 const debounceSeconds = 5
 
+// recentFiles is how many created files and directories the stats remember.
+const recentFiles = 100
+
 // Watcher monitors the download path for new files and emits events
 type Watcher struct {
 	watcher       *fsnotify.Watcher
@@ -25,7 +30,11 @@ type Watcher struct {
 	running       atomic.Bool
 	stopChan      chan struct{}
 	eventChan     chan importing.FileEvent
-	lastFile      string
+	pending       []string // files created since the last debounced batch
+
+	statsMutex sync.Mutex
+	stats      importing.WatcherStats
+	watched    map[string]bool
 }
 
 // NewWatcher creates a new file system watcher
@@ -39,6 +48,8 @@ func NewWatcher() (*Watcher, error) {
 		watcher:   watcher,
 		eventChan: make(chan importing.FileEvent, 10),
 		stopChan:  make(chan struct{}),
+		stats:     importing.WatcherStats{Events: map[string]int{}},
+		watched:   map[string]bool{},
 	}, nil
 }
 
@@ -52,6 +63,18 @@ func (w *Watcher) IsRunning() bool {
 	return w.running.Load()
 }
 
+// Stats returns a snapshot of the watcher internals.
+func (w *Watcher) Stats() importing.WatcherStats {
+	w.statsMutex.Lock()
+	defer w.statsMutex.Unlock()
+	stats := w.stats
+	stats.Running = w.running.Load()
+	stats.Events = maps.Clone(w.stats.Events)
+	stats.Recent = slices.Clone(w.stats.Recent)
+	stats.WatchedPaths = slices.Sorted(maps.Keys(w.watched))
+	return stats
+}
+
 // Start begins watching the download path for file changes
 func (w *Watcher) Start(ctx context.Context, watchPath string) error {
 	w.watchPath = watchPath
@@ -77,9 +100,15 @@ func (w *Watcher) Start(ctx context.Context, watchPath string) error {
 	slog.Debug("Recreating stop channel")
 	w.stopChan = make(chan struct{})
 
+	w.statsMutex.Lock()
+	w.stats.Root = watchPath
+	w.stats.StartedAt = time.Now()
+	clear(w.watched)
+	w.statsMutex.Unlock()
+
 	// Add the download path to watch
 	slog.Debug("Adding root watch path", "path", watchPath)
-	if err := w.watcher.Add(watchPath); err != nil {
+	if err := w.add(watchPath); err != nil {
 		return err
 	}
 
@@ -92,7 +121,7 @@ func (w *Watcher) Start(ctx context.Context, watchPath string) error {
 		}
 		if d.IsDir() && path != watchPath {
 			slog.Debug("Adding subdirectory to watcher", "path", path)
-			w.watcher.Add(path)
+			w.add(path)
 		}
 		return nil
 	})
@@ -130,6 +159,9 @@ func (w *Watcher) Stop() {
 	slog.Debug("Closing fsnotify watcher")
 	w.watcher.Close()
 	w.watcher = nil
+	w.statsMutex.Lock()
+	clear(w.watched)
+	w.statsMutex.Unlock()
 	slog.Debug("Closing event channel")
 	close(w.eventChan)
 	w.eventChan = nil
@@ -150,6 +182,7 @@ func (w *Watcher) watchLoop(ctx context.Context) {
 				return
 			}
 			slog.Error("File watcher error", "error", err)
+			w.recordError(err)
 
 		case <-w.stopChan:
 			return
@@ -163,9 +196,19 @@ func (w *Watcher) watchLoop(ctx context.Context) {
 // handleEvent processes a single file system event
 func (w *Watcher) handleEvent(event fsnotify.Event) {
 	slog.Debug("Handling fsnotify event", "op", event.Op, "name", event.Name)
+	w.statsMutex.Lock()
+	w.stats.Events[event.Op.String()]++
+	w.statsMutex.Unlock()
 	// Only process file creation events
 	if event.Op&fsnotify.Create == fsnotify.Create {
-		if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+		info, err := os.Stat(event.Name)
+		if err != nil {
+			// Temporary files of downloaders are often renamed away before we get to them
+			slog.Debug("Created file is gone", "file", event.Name, "error", err)
+			w.recordFile(event.Name, "skipped", "gone before it could be checked, e.g. a temporary file renamed away")
+			return
+		}
+		if info.IsDir() {
 			// Add all subdirectories recursively
 			filepath.WalkDir(event.Name, func(path string, d fs.DirEntry, err error) error {
 				if err != nil {
@@ -174,18 +217,21 @@ func (w *Watcher) handleEvent(event fsnotify.Event) {
 				}
 				if d.IsDir() && path != event.Name {
 					slog.Debug("Adding subdirectory to watcher", "path", path)
-					w.watcher.Add(path)
+					w.add(path)
 				}
 				return nil
 			})
 			slog.Debug("Detected new directory, adding to watcher", "dir", event.Name)
-			w.watcher.Add(event.Name)
+			if err := w.add(event.Name); err == nil {
+				w.recordFile(event.Name, "watched", "")
+			}
 		} else {
 			slog.Info("Detected new file", "file", event.Name)
+			w.recordFile(event.Name, "batched", "")
 
 			// Start or reset the debounce timer
 			w.debounceMutex.Lock()
-			w.lastFile = event.Name
+			w.pending = append(w.pending, event.Name)
 			defer w.debounceMutex.Unlock()
 
 			if w.debounceTimer != nil {
@@ -203,21 +249,68 @@ func (w *Watcher) handleEvent(event fsnotify.Event) {
 
 // emitDebounceEvent emits a file event after debounce period
 func (w *Watcher) emitDebounceEvent() {
-	slog.Debug("Emitting debounced file event", "file", w.lastFile)
+	w.debounceMutex.Lock()
+	files := w.pending
+	w.pending = nil
+	w.debounceMutex.Unlock()
+	if len(files) == 0 {
+		return
+	}
+	slog.Debug("Emitting debounced file event", "file", files[len(files)-1], "files", len(files))
 	if !w.running.Load() {
 		slog.Debug("Watcher not running, skipping emit")
 		return
 	}
 	event := importing.FileEvent{
-		Path:      w.lastFile,
+		Path:      files[len(files)-1],
+		Files:     files,
 		EventType: importing.FileCreated,
 		Timestamp: time.Now(),
 	}
 
 	select {
 	case w.eventChan <- event:
-		slog.Info("Emitted file event after debounce", "path", event.Path)
+		slog.Info("Emitted file event after debounce", "path", event.Path, "files", len(files))
+		w.statsMutex.Lock()
+		w.stats.Batches++
+		w.statsMutex.Unlock()
 	default:
-		slog.Debug("Event channel full, dropping file event", "path", event.Path)
+		slog.Warn("Event channel full, dropping file event", "path", event.Path, "files", len(files))
+		w.statsMutex.Lock()
+		w.stats.Dropped++
+		w.statsMutex.Unlock()
+		for _, file := range files {
+			w.recordFile(file, "skipped", "batch dropped, too many batches waiting for running jobs")
+		}
+	}
+}
+
+// add watches a directory, recording it in the stats or why it couldn't be watched.
+func (w *Watcher) add(path string) error {
+	if err := w.watcher.Add(path); err != nil {
+		slog.Error("Failed to watch directory", "path", path, "error", err)
+		w.recordError(err)
+		w.recordFile(path, "skipped", "directory not watched: "+err.Error())
+		return err
 	}
+	w.statsMutex.Lock()
+	w.watched[path] = true
+	w.statsMutex.Unlock()
+	return nil
+}
+
+// recordFile remembers what became of a created file or directory.
+func (w *Watcher) recordFile(path, outcome, reason string) {
+	w.statsMutex.Lock()
+	defer w.statsMutex.Unlock()
+	file := importing.WatchedFile{Time: time.Now(), Path: path, Outcome: outcome, Reason: reason}
+	w.stats.Recent = append([]importing.WatchedFile{file}, w.stats.Recent[:min(len(w.stats.Recent), recentFiles-1)]...)
+}
+
+// recordError counts an error of the watcher and keeps the last one.
+func (w *Watcher) recordError(err error) {
+	w.statsMutex.Lock()
+	w.stats.Errors++
+	w.stats.LastError = err.Error()
+	w.statsMutex.Unlock()
 }
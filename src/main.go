@@ -5,26 +5,38 @@ import (
 	"log/slog"
 	"os"
 	"os/signal"
+	"syscall"
 
+	"github.com/contre95/soulsolid/src/features/automation"
 	"github.com/contre95/soulsolid/src/features/config"
+	"github.com/contre95/soulsolid/src/features/diagnostics"
 	"github.com/contre95/soulsolid/src/features/downloading"
+	"github.com/contre95/soulsolid/src/features/federation"
 	"github.com/contre95/soulsolid/src/features/hosting"
 	"github.com/contre95/soulsolid/src/features/importing"
 	"github.com/contre95/soulsolid/src/features/jobs"
 	"github.com/contre95/soulsolid/src/features/library"
 	"github.com/contre95/soulsolid/src/features/logging"
 	"github.com/contre95/soulsolid/src/features/lyrics"
+	"github.com/contre95/soulsolid/src/features/maintenance"
 	"github.com/contre95/soulsolid/src/features/metadata"
 	"github.com/contre95/soulsolid/src/features/metrics"
+	"github.com/contre95/soulsolid/src/features/migration"
+	"github.com/contre95/soulsolid/src/features/notifications"
 	"github.com/contre95/soulsolid/src/features/playlists"
+	"github.com/contre95/soulsolid/src/features/recommendations"
+	"github.com/contre95/soulsolid/src/features/remote"
 	"github.com/contre95/soulsolid/src/features/reorganize"
 	"github.com/contre95/soulsolid/src/features/streaming"
+	"github.com/contre95/soulsolid/src/features/verification"
 	"github.com/contre95/soulsolid/src/infra/database"
 	"github.com/contre95/soulsolid/src/infra/files"
 	"github.com/contre95/soulsolid/src/infra/fingerprint"
 	"github.com/contre95/soulsolid/src/infra/providers"
 	"github.com/contre95/soulsolid/src/infra/queue"
+	"github.com/contre95/soulsolid/src/infra/sortkey"
 	"github.com/contre95/soulsolid/src/infra/tag"
+	"github.com/contre95/soulsolid/src/infra/transcode"
 	"github.com/contre95/soulsolid/src/infra/watcher"
 )
 
@@ -33,6 +45,10 @@ func main() {
 	if envPath := os.Getenv("SOULSOLID_CONFIG_PATH"); envPath != "" {
 		configPath = envPath
 	}
+	// An import staged from the settings replaces the config and the database before they're opened
+	if _, err := migration.ApplyStaged(configPath); err != nil {
+		log.Fatalf("failed to apply staged import: %v", err)
+	}
 	cfgManager, err := config.NewManager(configPath)
 	if err != nil {
 		log.Fatalf("failed to load config manager: %v", err)
@@ -41,26 +57,43 @@ func main() {
 	logger := logging.SetupLogger(cfgManager)
 	slog.SetDefault(logger)
 
-	pathParser := files.NewTemplatePathParser(cfgManager)
+	// Permission problems are reported rather than fatal, the UI is still needed to fix the paths
+	for _, check := range cfgManager.CheckPaths() {
+		if !check.Writable {
+			slog.Warn("Configured path is not writable", "name", check.Name, "path", check.Path, "error", check.Error)
+		}
+	}
+
+	sorting := cfgManager.Get().Database.Sorting
+	sortKeys, err := sortkey.New(sorting.Locale, sorting.Transliterate)
+	if err != nil {
+		log.Fatalf("failed to create sort keys: %v", err)
+	}
+	db, err := database.NewSqliteLibrary(cfgManager.Get().Database.Path, sortKeys)
+	if err != nil {
+		log.Fatalf("failed to create library: %v", err)
+	}
+	pathParser := files.NewTemplatePathParser(cfgManager, db)
 	fileOrganizer := files.NewFileOrganizer(
 		func() string { return cfgManager.Get().LibraryPath },
 		func() string { return cfgManager.Get().DownloadPath },
 		pathParser,
 		func() bool { return cfgManager.Get().Import.PathOptions.Fat32Safe },
+		func() bool { return cfgManager.Get().Import.PathOptions.WindowsSafe },
 	)
 
-	db, err := database.NewSqliteLibrary(cfgManager.Get().Database.Path)
-	if err != nil {
-		log.Fatalf("failed to create library: %v", err)
-	}
 	libraryService := library.NewService(db, cfgManager, fileOrganizer)
 	playlistsService := playlists.NewService(db, db, cfgManager)
-	metricsService := metrics.NewService(db, cfgManager)
 	jobService := jobs.NewService(cfgManager)
+	metricsService := metrics.NewService(db, jobService, cfgManager)
+	diagnosticsService := diagnostics.NewService(cfgManager)
+	jobService.AddObserver(diagnosticsService)
+	automationService := automation.NewService(cfgManager)
+	jobService.AddObserver(automationService)
 
-	tagReader := tag.NewTagReader()
+	tagReader := tag.NewTagReader(cfgManager.Get().Metadata.CustomFields, cfgManager.Get().Metadata.NotesComment)
 	fingerprintReader := fingerprint.NewFingerprintService(cfgManager)
-	tagWriter := tag.NewTagWriter(cfgManager.Get().Downloaders.Artwork.Embedded)
+	tagWriter := tag.NewTagWriter(cfgManager.Get().Downloaders.Artwork.Embedded, cfgManager.Get().Metadata.CustomFields, cfgManager.Get().Metadata.NotesComment)
 
 	importQueue := queue.NewInMemoryQueue()
 	lyricsQueue := queue.NewInMemoryQueue()
@@ -68,12 +101,17 @@ func main() {
 	if err != nil {
 		log.Fatalf("failed to create watcher: %v", err)
 	}
-	importingService := importing.NewService(db, tagReader, fingerprintReader, fileOrganizer, cfgManager, jobService, importQueue, dirWatcher)
+	importingService := importing.NewService(db, tagReader, fingerprintReader, fileOrganizer, cfgManager, jobService, importQueue, dirWatcher, automationService, metadata.NewNormalizer(cfgManager), libraryService, transcode.NewFFmpeg(tagWriter))
 
-	reorganizeService := reorganize.NewService(db, fileOrganizer, cfgManager, jobService)
+	reorganizeService := reorganize.NewService(db, fileOrganizer, tagReader, fingerprintReader, cfgManager, jobService)
 
 	directoryImportTask := importing.NewDirectoryImportTask(importingService)
 	jobService.RegisterHandler("directory_import", jobs.NewBaseTaskHandler(directoryImportTask))
+	urlImportTask := importing.NewURLImportTask(importingService)
+	jobService.RegisterHandler("url_import", jobs.NewBaseTaskHandler(urlImportTask))
+	jobService.RegisterHandler("rollback_import", jobs.NewBaseTaskHandler(importing.NewRollbackImportTask(importingService)))
+	jobService.RegisterHandler("download_leftovers", jobs.NewBaseTaskHandler(importing.NewLeftoversTask(importingService)))
+	jobService.RegisterHandler("download_retention", jobs.NewBaseTaskHandler(importing.NewRetentionTask(importingService)))
 
 	metricsTask := metrics.NewMetricsCalculationTask(db)
 	jobService.RegisterHandler("calculate_metrics", jobs.NewBaseTaskHandler(metricsTask))
@@ -85,7 +123,7 @@ func main() {
 		panic("Failed to load plugins")
 	}
 
-	musicbrainzProvider := providers.NewMusicBrainzProvider(cfgManager.Get().Metadata.Providers["musicbrainz"].Enabled)
+	musicbrainzProvider := providers.NewMusicBrainzProvider(cfgManager.Get().Metadata.Providers["musicbrainz"].Enabled, cfgManager.Get().Metadata.Providers["musicbrainz"].URL)
 	discogsSecret := ""
 	if cfgManager.Get().Metadata.Providers["discogs"].Secret != nil {
 		discogsSecret = *cfgManager.Get().Metadata.Providers["discogs"].Secret
@@ -105,44 +143,65 @@ func main() {
 		"deezer":      deezerProvider,
 	}, acoustIDService, cfgManager, jobService)
 
-	downloadingService := downloading.NewService(cfgManager, jobService, pluginManager, tagWriter)
+	recommendationsService := recommendations.NewService(db, []recommendations.SimilarityProvider{deezerProvider})
+
+	downloadingService := downloading.NewService(cfgManager, jobService, jobService, pluginManager, tagWriter, importingService, db)
 
 	downloadTask := downloading.NewDownloadJobTask(downloadingService)
-	jobService.RegisterHandler("download_track", jobs.NewBaseTaskHandler(downloadTask))
-	jobService.RegisterHandler("download_album", jobs.NewBaseTaskHandler(downloadTask))
-	jobService.RegisterHandler("download_artist", jobs.NewBaseTaskHandler(downloadTask))
-	jobService.RegisterHandler("download_tracks", jobs.NewBaseTaskHandler(downloadTask))
-	jobService.RegisterHandler("download_playlist", jobs.NewBaseTaskHandler(downloadTask))
+	for _, jobType := range downloading.JobTypes {
+		jobService.RegisterHandler(jobType, jobs.NewBaseTaskHandler(downloadTask))
+	}
 
 	acoustIDTask := metadata.NewAcoustIDJobTask(tagService)
 	jobService.RegisterHandler("analyze_acoustid", jobs.NewBaseTaskHandler(acoustIDTask))
+	jobService.RegisterHandler("normalize_tags", jobs.NewBaseTaskHandler(metadata.NewNormalizeJobTask(tagService)))
+	jobService.RegisterHandler("upgrade_artwork", jobs.NewBaseTaskHandler(metadata.NewArtworkUpgradeJobTask(tagService)))
 
 	lyricsTask := lyrics.NewLyricsJobTask(lyricsService)
 	jobService.RegisterHandler("analyze_lyrics", jobs.NewBaseTaskHandler(lyricsTask))
 
 	reorganizeTask := reorganize.NewReorganizeJobTask(reorganizeService)
 	jobService.RegisterHandler("analyze_reorganize", jobs.NewBaseTaskHandler(reorganizeTask))
+	jobService.RegisterHandler("collapse_disc_folders", jobs.NewBaseTaskHandler(reorganize.NewCollapseDiscFoldersTask(reorganizeService)))
+
+	verificationService := verification.NewService(db, tagReader, jobService, cfgManager)
+	jobService.RegisterHandler("verify_files", jobs.NewBaseTaskHandler(verification.NewVerifyJobTask(verificationService)))
+
+	maintenanceService := maintenance.NewService(jobService, jobService, importingService, metricsService, verificationService)
+
+	mailer := notifications.NewMailer(cfgManager)
+	notificationsService := notifications.NewService(cfgManager, mailer, db, importingService)
+	jobService.AddObserver(notificationsService)
+	automationService.AddNotifier(mailer)
+	libraryService.AddNotifier(mailer)
+	metricsService.AddNotifier(mailer)
+	maintenanceService.AddNotifier(mailer)
 
 	var telegramBot *hosting.TelegramBot
 	if cfgManager.Get().Telegram.Enabled {
 		var err error
-		telegramBot, err = hosting.NewTelegramBot(cfgManager, libraryService, jobService, importingService)
+		telegramBot, err = hosting.NewTelegramBot(cfgManager, libraryService, jobService, importingService, downloadingService)
 		if err != nil {
 			slog.Error("Failed to initialize Telegram bot", "error", err)
 		} else {
+			automationService.AddNotifier(telegramBot)
+			libraryService.AddNotifier(telegramBot)
+			metricsService.AddNotifier(telegramBot)
+			maintenanceService.AddNotifier(telegramBot)
+			cfgManager.AddProposalNotifier(telegramBot)
 			go telegramBot.Start()
 			slog.Info("Telegram bot started")
 		}
 	}
 
-	streamingService := streaming.NewService(cfgManager)
-	server := hosting.NewServer(cfgManager, importingService, libraryService, playlistsService, downloadingService, jobService, tagService, lyricsService, metricsService, reorganizeService, streamingService)
-	slog.Info("Starting server", "port", cfgManager.Get().Server.Port)
-	if err := server.Start(); err != nil {
-		slog.Error("server stopped", "error", err)
-	}
+	streamingService := streaming.NewService(cfgManager, db, db)
+	federationService := federation.NewService(cfgManager, db, importingService, jobService)
+	jobService.RegisterHandler("federation_pull", jobs.NewBaseTaskHandler(federation.NewPullTask(federationService)))
+	migrationService := migration.NewService(cfgManager, db, playlistsService)
+	remoteService := remote.NewService(cfgManager, importingService, reorganizeService, downloadingService, jobService, db)
+	server := hosting.NewServer(cfgManager, importingService, libraryService, playlistsService, downloadingService, jobService, tagService, lyricsService, metricsService, reorganizeService, streamingService, diagnosticsService, recommendationsService, maintenanceService, notificationsService, federationService, migrationService, remoteService, verificationService)
 	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, os.Interrupt)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
 	serverErr := make(chan error, 1)
 	go func() {
 		slog.Info("Starting server", "port", cfgManager.Get().Server.Port)
@@ -164,8 +223,13 @@ func main() {
 	}
 
 	importingService.StopWatcher()
-	if err := server.Shutdown(); err != nil {
-		log.Fatalf("failed to shutdown server: %v", err)
+	shutdownErr := server.Shutdown()
+	// Usage is written periodically, what was recorded since the last write is saved now
+	if err := diagnosticsService.Flush(); err != nil {
+		slog.Error("Failed to save the recorded usage", "error", err)
+	}
+	if shutdownErr != nil {
+		log.Fatalf("failed to shutdown server: %v", shutdownErr)
 	}
 	slog.Info("Server gracefully shut down.")
 }
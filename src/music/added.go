@@ -0,0 +1,43 @@
+package music
+
+const (
+	// AddedByJobAttribute is the attribute key holding the ID of the job that added a track or
+	// album to the library, so a batch can be reviewed or rolled back as a unit.
+	AddedByJobAttribute = "added_by_job"
+	// AddedSourceAttribute is the attribute key holding the type of that job, e.g. "directory_import".
+	AddedSourceAttribute = "added_source"
+)
+
+// addedSourceLabels are the badge labels of the job types that add tracks to the library.
+var addedSourceLabels = map[string]string{
+	"directory_import": "Import",
+	"url_import":       "URL import",
+}
+
+// AddedSourceLabel returns the badge label of the job type that added a track or album.
+func AddedSourceLabel(source string) string {
+	if label, ok := addedSourceLabels[source]; ok {
+		return label
+	}
+	return source
+}
+
+// SetAddedBy records job as the job adding the track to the library.
+func (t *Track) SetAddedBy(job *Job) {
+	if t.Attributes == nil {
+		t.Attributes = make(map[string]string)
+	}
+	t.Attributes[AddedByJobAttribute] = job.ID
+	t.Attributes[AddedSourceAttribute] = job.Type
+}
+
+// AddedBy returns the ID and type of the job that added the track, empty for tracks added
+// before jobs were recorded or by hand.
+func (t *Track) AddedBy() (jobID, source string) {
+	return t.Attributes[AddedByJobAttribute], t.Attributes[AddedSourceAttribute]
+}
+
+// AddedBy returns the ID and type of the job that added the album's first track.
+func (a *Album) AddedBy() (jobID, source string) {
+	return a.Attributes[AddedByJobAttribute], a.Attributes[AddedSourceAttribute]
+}
@@ -3,8 +3,10 @@ package music
 import (
 	"fmt"
 	"log/slog"
+	"math"
 	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 type AlbumType string
@@ -40,6 +42,8 @@ type Album struct {
 	Attributes     map[string]string
 	AddedDate      time.Time
 	ModifiedDate   time.Time
+	TrackCount     int // stored rollup, maintained by the library on track add/update/delete
+	TotalDuration  int // stored rollup of the tracks' durations in seconds
 	// Image* are used by plugins to set URL that will be displayed in the search results of the downloaders
 	ImageSmall  string
 	ImageMedium string
@@ -93,3 +97,55 @@ func (a *Album) Validate() error {
 	}
 	return nil
 }
+
+// LockedAttribute is the attribute key that marks an album or track as locked. Locked items keep
+// their hand-curated tags: bulk jobs, provider lookups and re-imports leave them untouched.
+const LockedAttribute = "locked"
+
+// NotesAttribute is the attribute key holding the free-text notes of an album or track, e.g. its
+// provenance, vinyl matrix numbers or personal memories.
+const NotesAttribute = "notes"
+
+// MaxNotesLength caps the notes of an album or track, in characters.
+const MaxNotesLength = 5000
+
+// ValidateNotes checks the notes of an album or track.
+func ValidateNotes(notes string) error {
+	if utf8.RuneCountInString(notes) > MaxNotesLength {
+		return fmt.Errorf("notes cannot exceed %d characters", MaxNotesLength)
+	}
+	return nil
+}
+
+// Notes returns the notes of the album.
+func (a *Album) Notes() string {
+	if a == nil {
+		return ""
+	}
+	return a.Attributes[NotesAttribute]
+}
+
+// IsLocked reports whether the album is locked.
+func (a *Album) IsLocked() bool {
+	return a != nil && a.Attributes[LockedAttribute] == "true"
+}
+
+// AlbumGain derives an album ReplayGain value (in dB) from the track gains of its tracks.
+// Track loudness is averaged in the energy domain, weighted by duration, so loud tracks
+// dominate the same way they do when the album is analysed as a whole. Tracks without a
+// gain value are ignored; ok is false when none of the tracks carries one.
+func AlbumGain(tracks []*Track) (gain float64, ok bool) {
+	var energy, weight float64
+	for _, t := range tracks {
+		if t == nil || t.Metadata.Gain == 0 {
+			continue
+		}
+		w := float64(max(t.Metadata.Duration, 1))
+		energy += w * math.Pow(10, -t.Metadata.Gain/10)
+		weight += w
+	}
+	if weight == 0 {
+		return 0, false
+	}
+	return -10 * math.Log10(energy/weight), true
+}
@@ -8,12 +8,18 @@ import (
 // VariousArtistsName is the standard name for compilation albums
 const VariousArtistsName = "Various Artists"
 
+// FollowedAttribute is the attribute key that marks an artist as followed. New albums of
+// followed artists are notified when they get imported.
+const FollowedAttribute = "followed"
+
 // Artist represents a music artist.
 type Artist struct {
-	ID         string
-	Name       string
-	SortName   string
-	Attributes map[string]string
+	ID            string
+	Name          string
+	SortName      string
+	Attributes    map[string]string
+	TrackCount    int // stored rollup, maintained by the library on track add/update/delete
+	TotalDuration int // stored rollup of the tracks' durations in seconds
 	// Image URLs from external sources
 	ImageSmall  string
 	ImageMedium string
@@ -21,6 +27,11 @@ type Artist struct {
 	ImageXL     string
 }
 
+// IsFollowed reports whether the artist is followed.
+func (a *Artist) IsFollowed() bool {
+	return a != nil && a.Attributes[FollowedAttribute] == "true"
+}
+
 // Validate validates the artist fields.
 func (a *Artist) Validate() error {
 	if strings.TrimSpace(a.Name) == "" {
@@ -2,6 +2,10 @@ package music
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
 )
 
 // TrackFilter represents the filter criteria for tracks.
@@ -9,13 +13,58 @@ type TrackFilter struct {
 	Title       string
 	ArtistIDs   []string
 	AlbumIDs    []string
-	TextSearch  string // OR-match across track title, artist name, and album title
+	TextSearch  string // OR-match across track title, artist name, album title and track notes
 	Genre       string // exact genre match
 	HasAcoustID *bool  // nil=any, true=has acoustid, false=missing
 	LyricsFilter string // "": any, "has": has_lyrics=true AND lyrics not empty, "empty": has_lyrics=true AND lyrics empty, "instrumental": has_lyrics=false
 	LyricsText  string // LIKE search within lyrics content
 	AddedAfter  string // "": any, else "YYYY-MM-DD"; matches tracks added on or after this date (inclusive)
 	AddedBefore string // "": any, else "YYYY-MM-DD"; matches tracks added on or before this date (inclusive)
+	ExcludeExplicit bool // hides tracks flagged with explicit content or explicit lyrics
+	AddedByJob  string // "": any, else the ID of the job that added the tracks
+	NotVerifiedSince string // "": any, else "YYYY-MM-DD"; matches tracks never verified or last verified before this date
+	VerificationProblems bool // matches tracks whose last verification found a problem with their file
+}
+
+// NameSuggestion is a typeahead match on an artist name, album title or track title.
+type NameSuggestion struct {
+	Type string `json:"type"` // "artist", "album" or "track"
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// ErrInvalidCursor is returned for cursors that weren't produced by TrackCursor.Encode.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ErrNotAnAppearance is returned when a track isn't linked to the album it's asked to leave or be moved to.
+var ErrNotAnAppearance = errors.New("track does not appear on the album")
+
+// ErrPrimaryAlbum is returned when removing the primary album of a track as one of its appearances.
+var ErrPrimaryAlbum = errors.New("album is the primary album of the track")
+
+// TrackCursor is a keyset position in the tracks ordered by added date (newest first) and ID.
+// Unlike an offset it stays stable while tracks are added or removed between pages.
+type TrackCursor struct {
+	AddedDate string // raw added_date of the last track of the previous page
+	ID        string
+}
+
+// Encode returns the opaque string handed to API clients.
+func (c *TrackCursor) Encode() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(c.AddedDate + "|" + c.ID))
+}
+
+// DecodeTrackCursor parses a cursor produced by Encode.
+func DecodeTrackCursor(s string) (*TrackCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	addedDate, id, ok := strings.Cut(string(raw), "|")
+	if !ok || id == "" {
+		return nil, ErrInvalidCursor
+	}
+	return &TrackCursor{AddedDate: addedDate, ID: id}, nil
 }
 
 // Library is the interface for managing the music library.
@@ -31,6 +80,12 @@ type Library interface {
 	GetTracksFilteredPaginated(ctx context.Context, limit, offset int, filter *TrackFilter) ([]*Track, error)
 	GetTracksCount(ctx context.Context) (int, error)
 	GetTracksFilteredCount(ctx context.Context, filter *TrackFilter) (int, error)
+	// GetTracksAfter returns up to limit tracks after the cursor (nil for the first page) and the cursor of the next page, nil on the last one.
+	GetTracksAfter(ctx context.Context, limit int, after *TrackCursor, filter *TrackFilter) ([]*Track, *TrackCursor, error)
+	// EstimateTracksCount returns a cheap upper bound of the number of tracks.
+	EstimateTracksCount(ctx context.Context) (int, error)
+	// GetPlayCounts returns how many times each of the tracks was streamed, tracks never played are left out.
+	GetPlayCounts(ctx context.Context, trackIDs []string) (map[string]int, error)
 	FindTrackByMetadata(ctx context.Context, title, artistName, albumTitle string) (*Track, error)
 	FindTrackByPath(ctx context.Context, path string) (*Track, error)
 
@@ -38,16 +93,34 @@ type Library interface {
 	AddAlbum(ctx context.Context, album *Album) error
 	UpdateAlbum(ctx context.Context, album *Album) error
 	DeleteAlbum(ctx context.Context, id string) error
+	// MergeAlbum updates keep and the tracks moved onto it, moves the remaining appearances of the
+	// duplicate album onto keep and deletes the duplicate, in one transaction.
+	MergeAlbum(ctx context.Context, keep *Album, tracks []*Track, duplicateID string) error
 	GetAlbum(ctx context.Context, id string) (*Album, error)
 	GetAlbums(ctx context.Context) ([]*Album, error)
 	GetAlbumsPaginated(ctx context.Context, limit, offset int) ([]*Album, error)
 	GetAlbumsFilteredPaginated(ctx context.Context, limit, offset int, titleFilter string, artistIDs []string) ([]*Album, error)
 	GetAlbumsCount(ctx context.Context) (int, error)
+	// GetAlbumDiscCount returns the highest disc number among the album's tracks, 0 when none is set.
+	GetAlbumDiscCount(ctx context.Context, albumID string) (int, error)
 	GetAlbumsFilteredCount(ctx context.Context, titleFilter string, artistIDs []string) (int, error)
 	SearchAlbums(ctx context.Context, query string, limit, offset int) ([]*Album, error)
+	// SuggestNames returns up to limit artists, albums and tracks each whose name starts with prefix, ignoring ASCII case.
+	// With excludeExplicit, explicit tracks are left out of the suggestions.
+	SuggestNames(ctx context.Context, prefix string, limit int, excludeExplicit bool) ([]NameSuggestion, error)
 	GetGenres(ctx context.Context) ([]string, error)
 	GetAlbumByArtistAndName(ctx context.Context, artistID, name string) (*Album, error)
 	FindOrCreateAlbum(ctx context.Context, artist *Artist, albumTitle string, year int) (*Album, error)
+	// SetAlbumAttribute sets an attribute of an album, an empty value removes it.
+	SetAlbumAttribute(ctx context.Context, albumID, key, value string) error
+
+	// Album appearance methods. A track's file and tags follow its primary album (Track.Album),
+	// it can additionally appear on other albums such as compilations.
+	GetTrackAppearances(ctx context.Context, trackID string) ([]*Album, error)
+	AddTrackAppearance(ctx context.Context, trackID, albumID string) error
+	RemoveTrackAppearance(ctx context.Context, trackID, albumID string) error
+	// SetTrackPrimaryAlbum makes one of the track's appearances its primary album, the previous one becomes an appearance.
+	SetTrackPrimaryAlbum(ctx context.Context, trackID, albumID string) error
 
 	// Artist methods
 	AddArtist(ctx context.Context, artist *Artist) error
@@ -60,4 +133,7 @@ type Library interface {
 	GetArtistsFilteredCount(ctx context.Context, nameFilter string) (int, error)
 	GetArtistByName(ctx context.Context, name string) (*Artist, error)
 	FindOrCreateArtist(ctx context.Context, artistName string) (*Artist, error)
+	// SetArtistAttribute sets an attribute of an artist, an empty value removes it.
+	SetArtistAttribute(ctx context.Context, artistID, key, value string) error
+	GetArtistsWithAttribute(ctx context.Context, key, value string) ([]*Artist, error)
 }
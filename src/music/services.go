@@ -14,6 +14,16 @@ var ErrLyricsNotFound = errors.New("lyrics not found")
 // reported as completed (with a warning message) rather than failed.
 var ErrJobPartialSuccess = errors.New("job completed with some failures")
 
+// ErrNetworkUnavailable marks a job failure caused by the connection rather than the job itself,
+// so the job waits for the network to come back instead of failing.
+var ErrNetworkUnavailable = errors.New("network unavailable")
+
+// ErrMaintenance is returned when a job is started while maintenance mode pauses the job intake.
+var ErrMaintenance = errors.New("maintenance mode is on")
+
+// ErrTrackLocked is returned when an automated change targets a locked track or album.
+var ErrTrackLocked = errors.New("track is locked")
+
 // LyricsSearchParams contains parameters for searching lyrics
 type LyricsSearchParams struct {
 	TrackID     string
@@ -66,6 +76,8 @@ const (
 	JobStatusCompleted JobStatus = "completed"
 	JobStatusFailed    JobStatus = "failed"
 	JobStatusCancelled JobStatus = "cancelled"
+	// JobStatusWaitingNetwork is a job that failed for lack of connectivity and runs again once it's back
+	JobStatusWaitingNetwork JobStatus = "waiting_network"
 )
 
 // Job represents a background job
@@ -79,6 +91,8 @@ type Job struct {
 	Error      string
 	CreatedAt  time.Time
 	UpdatedAt  time.Time
+	StartedAt  time.Time // zero while pending
+	FinishedAt time.Time // zero until the job reaches a terminal state
 	Metadata   map[string]any
 	// json:"-" because func and *slog.Logger are not JSON-serializable; Cancelled is internal runtime state
 	CancelFunc context.CancelFunc `json:"-"`
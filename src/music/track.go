@@ -35,6 +35,7 @@ type Track struct {
 	Attributes             map[string]string
 	PreviewURL             string         // URL for 30-second preview
 	Thumbnail              string         // URL for track thumbnail image
+	ArtworkData            []byte         // Cover embedded in the track itself, e.g. a single without an album
 	MetadataSource         MetadataSource // Information about metadata source
 	HasLyrics              bool
 	AddedDate              time.Time
@@ -48,6 +49,7 @@ type Metadata struct {
 	Duration       int
 	OriginalYear   int
 	DiscNumber     int
+	DiscTotal      int // number of discs in the release as read from the tags, not stored in the library
 	TrackNumber    int
 	Lyrics         string
 	ExplicitLyrics bool
@@ -55,6 +57,17 @@ type Metadata struct {
 	Gain           float64
 }
 
+// CoverArt returns the cover to embed in the track's file: its own artwork when set, otherwise the album's.
+func (t *Track) CoverArt() []byte {
+	if len(t.ArtworkData) > 0 {
+		return t.ArtworkData
+	}
+	if t.Album != nil {
+		return t.Album.ArtworkData
+	}
+	return nil
+}
+
 // Validate validates the track fields.
 func (t *Track) Validate() error {
 	if strings.TrimSpace(t.Title) == "" {
@@ -170,11 +183,9 @@ func (t *Track) Pretty() string {
 		}
 	}
 	fmt.Fprintf(&builder, "%-30s : %s\n", "Artist", strings.Join(artistNames, ", "))
+	fmt.Fprintf(&builder, "%-30s : %d\n", "Artwork", len(t.CoverArt()))
 	if t.Album != nil {
-		fmt.Fprintf(&builder, "%-30s : %d\n", "Artwork", len(t.Album.ArtworkData))
 		fmt.Fprintf(&builder, "%-30s : %s\n", "Album", t.Album.Title)
-	} else {
-		fmt.Fprintf(&builder, "%-30s : %d\n", "Artwork", 0)
 	}
 	if t.Metadata.Composer != "" {
 		fmt.Fprintf(&builder, "%-30s : %s\n", "Composer", t.Metadata.Composer)
@@ -239,6 +250,21 @@ func firstValidArtist(t *Track) *ArtistRole {
 	return nil
 }
 
+// IsLocked reports whether the track is locked, either on its own or through its album.
+func (t *Track) IsLocked() bool {
+	return t.Attributes[LockedAttribute] == "true" || t.Album.IsLocked()
+}
+
+// Notes returns the notes of the track, without those of its album.
+func (t *Track) Notes() string {
+	return t.Attributes[NotesAttribute]
+}
+
+// IsExplicit reports whether the track is flagged as explicit, either as content or through its lyrics.
+func (t *Track) IsExplicit() bool {
+	return t.ExplicitContent || t.Metadata.ExplicitLyrics
+}
+
 // EnsureMetadataDefaults adds fallback values for missing metadata fields whose absence is
 // permitted by the per-field flags. Fields that are not permitted are left untouched so that
 // downstream validation can reject the track. The caller (e.g. the importing feature) owns the
@@ -323,3 +349,12 @@ func GenerateTrackID(fingerprint string) string {
 	inputBytes := []byte(fingerprint)
 	return uuid.NewSHA1(uuid.NameSpaceDNS, inputBytes).String()
 }
+
+// TagChange is a tag value rewritten by an automatic cleanup, e.g. tag normalization.
+type TagChange struct {
+	TrackID string
+	Track   string // track title before the change
+	Field   string
+	Old     string
+	New     string
+}
@@ -0,0 +1,60 @@
+package music
+
+import (
+	"regexp"
+	"strings"
+)
+
+// versionKeywords are the words that mark a title suffix as a version of the song rather than part of its name,
+// e.g. "Song (Live)" or "Song - Remastered 2011", but not "Song (Part 2)".
+var versionKeywords = regexp.MustCompile(`(?i)\b(live|remaster(ed)?|acoustic|demo|remix|mix|edit|version|instrumental|mono|stereo|unplugged|extended|radio|session|take|reprise|a cappella|acapella)\b`)
+
+// versionSuffix matches a trailing "(...)", "[...]" or " - ..." of a title.
+var versionSuffix = regexp.MustCompile(`^(.+?)\s*(?:\(([^()]+)\)|\[([^\[\]]+)\]|\s-\s([^-]+))\s*$`)
+
+// ParseTitleVersion splits a title such as "Song (Remastered 2011)" into its title "Song" and version
+// "Remastered 2011". Titles without a version suffix are returned as is with an empty version.
+func ParseTitleVersion(title string) (string, string) {
+	title = strings.TrimSpace(title)
+	m := versionSuffix.FindStringSubmatch(title)
+	if m == nil {
+		return title, ""
+	}
+	version := strings.TrimSpace(m[2] + m[3] + m[4])
+	if !versionKeywords.MatchString(version) {
+		return title, ""
+	}
+	return strings.TrimSpace(m[1]), version
+}
+
+// SplitTitleVersion moves a version suffix of the track's title into TitleVersion. When the track
+// already has a version, e.g. read from the TIT3 or VERSION tag, a copy of it at the end of the
+// title is dropped instead.
+func (t *Track) SplitTitleVersion() {
+	title, version := ParseTitleVersion(t.Title)
+	if version == "" {
+		return
+	}
+	if t.TitleVersion == "" {
+		t.Title, t.TitleVersion = title, version
+		return
+	}
+	if strings.EqualFold(version, t.TitleVersion) {
+		t.Title = title
+	}
+}
+
+// FullTitle returns the title with its version, e.g. "Song (Live)". A title that already
+// mentions the version, as those of tracks imported before versions were split, is returned as is.
+func (t *Track) FullTitle() string {
+	if t.TitleVersion == "" || strings.Contains(strings.ToLower(t.Title), strings.ToLower(t.TitleVersion)) {
+		return t.Title
+	}
+	return t.Title + " (" + t.TitleVersion + ")"
+}
+
+// SongKey identifies the song a track is a version of: its title without version, case-insensitive.
+func (t *Track) SongKey() string {
+	title, _ := ParseTitleVersion(t.Title)
+	return strings.ToLower(title)
+}